@@ -0,0 +1,373 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// AutoBatch is a Target that collects everything drawn onto it and draws it onto another Target
+// with as few draw calls as possible. Unlike Batch, it isn't limited to a single Picture, so
+// sprites from several Pictures can be drawn onto it and it takes care of the batching:
+//
+//	ab := pixel.NewAutoBatch(win)
+//	for !win.Closed() {
+//		win.Clear(colornames.Black)
+//		player.Draw(ab, playerMatrix)  // sprites from
+//		enemy.Draw(ab, enemyMatrix)    // several different
+//		coin.Draw(ab, coinMatrix)      // Pictures
+//		ab.Flush()
+//		win.Update()
+//	}
+//
+// The draws are grouped by their Picture, ComposeMethod and BlendMode and each group takes a
+// single draw call. By default, only the consecutive draws with the same Picture and state are
+// grouped, so that everything is drawn in the same order as it was drawn onto the AutoBatch. With
+// sorting enabled, see SetSorted, the draws are ordered by their z-order, see SetZ, and all the
+// draws with the same z-order are grouped, no matter in which order they were drawn.
+//
+// The matrix and the color mask of the AutoBatch are applied to the objects when they're drawn
+// onto it, like with Batch. The matrix and the color mask of the underlying Target are applied
+// when flushing.
+type AutoBatch struct {
+	dst Target
+
+	mat    Matrix
+	col    RGBA
+	cmp    ComposeMethod
+	blend  BlendMode
+	z      float64
+	sorted bool
+
+	vertices TrianglesData
+	draws    []autoBatchDraw
+	order    []int
+	groups   []autoBatchGroup
+	pics     map[Picture]TargetPicture
+	calls    int
+}
+
+var (
+	_ ComposeTarget = (*AutoBatch)(nil)
+	_ BlendTarget   = (*AutoBatch)(nil)
+)
+
+// autoBatchState is the state in which the draws of a group are drawn.
+type autoBatchState struct {
+	pic   Picture
+	cmp   ComposeMethod
+	blend BlendMode
+}
+
+// autoBatchDraw is a single draw onto an AutoBatch, its vertices are in range [start, end) of the
+// vertices of the AutoBatch.
+type autoBatchDraw struct {
+	state      autoBatchState
+	z          float64
+	start, end int
+}
+
+// autoBatchGroup holds the triangles of a group of draws. The groups are reused in the following
+// flushes, so that their TargetTriangles are only made once.
+type autoBatchGroup struct {
+	tris   *TrianglesData
+	target TargetTriangles
+}
+
+// NewAutoBatch creates an empty AutoBatch drawing onto the Target.
+func NewAutoBatch(t Target) *AutoBatch {
+	ab := &AutoBatch{
+		dst:  t,
+		pics: make(map[Picture]TargetPicture),
+	}
+	ab.SetMatrix(IM)
+	ab.SetColorMask(Alpha(1))
+	return ab
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (ab *AutoBatch) SetMatrix(m Matrix) {
+	ab.mat = m
+}
+
+// SetColorMask sets a mask color used in the following draws onto the AutoBatch.
+func (ab *AutoBatch) SetColorMask(c color.Color) {
+	if c == nil {
+		ab.col = Alpha(1)
+		return
+	}
+	ab.col = ToRGBA(c)
+}
+
+// SetComposeMethod sets a Porter-Duff composition method used in the following draws onto the
+// AutoBatch. It's only applied if the underlying Target is a ComposeTarget.
+func (ab *AutoBatch) SetComposeMethod(cmp ComposeMethod) {
+	ab.cmp = cmp
+}
+
+// SetBlendMode sets a blend mode used in the following draws onto the AutoBatch. It's only applied
+// if the underlying Target is a BlendTarget.
+func (ab *AutoBatch) SetBlendMode(bm BlendMode) {
+	ab.blend = bm
+}
+
+// SetZ sets the z-order of the following draws onto the AutoBatch. If sorting is enabled, the draws
+// with a lower z-order are drawn first, so they're covered by the draws with a higher z-order. The
+// default z-order is 0.
+func (ab *AutoBatch) SetZ(z float64) {
+	ab.z = z
+}
+
+// SetSorted enables or disables sorting of the draws by their z-order.
+//
+// With sorting enabled, the draws with the same z-order may be drawn in any order, so the objects
+// which overlap must have a different z-order. In return, the draws with the same z-order take a
+// single draw call for each Picture and state used, no matter in which order they were drawn.
+func (ab *AutoBatch) SetSorted(sorted bool) {
+	ab.sorted = sorted
+}
+
+// Sorted returns whether the draws are sorted by their z-order.
+func (ab *AutoBatch) Sorted() bool {
+	return ab.sorted
+}
+
+// Clear removes all the collected draws without drawing them.
+func (ab *AutoBatch) Clear() {
+	ab.vertices = ab.vertices[:0]
+	ab.draws = ab.draws[:0]
+}
+
+// Flush draws all the collected draws onto the underlying Target and removes them from the
+// AutoBatch. Call it at the end of each frame, before updating the window.
+//
+// If the draws use a ComposeMethod or a BlendMode other than ComposeOver and BlendNormal, Flush
+// sets them on the underlying Target for each group of draws and sets ComposeOver and BlendNormal
+// back when it's done.
+func (ab *AutoBatch) Flush() {
+	ab.order = ab.order[:0]
+	for i := range ab.draws {
+		ab.order = append(ab.order, i)
+	}
+	if ab.sorted {
+		ab.sortDraws()
+	}
+
+	ab.calls = 0
+	applied := autoBatchState{cmp: ComposeOver, blend: BlendNormal}
+	for start := 0; start < len(ab.order); {
+		state := ab.draws[ab.order[start]].state
+		end := start + 1
+		for end < len(ab.order) && ab.draws[ab.order[end]].state == state {
+			end++
+		}
+
+		if state.cmp != applied.cmp || state.blend != applied.blend {
+			ab.applyState(state.cmp, state.blend)
+			applied = state
+		}
+		ab.drawGroup(ab.calls, ab.order[start:end], state.pic)
+		ab.calls++
+		start = end
+	}
+	if applied.cmp != ComposeOver || applied.blend != BlendNormal {
+		ab.applyState(ComposeOver, BlendNormal)
+	}
+
+	ab.Clear()
+}
+
+// DrawCalls returns the number of draw calls the last Flush made onto the underlying Target.
+func (ab *AutoBatch) DrawCalls() int {
+	return ab.calls
+}
+
+// sortDraws stably sorts the order of the draws by their z-order and groups the draws with the same
+// z-order by their state. The groups continue with the state the previous z-order ended with and
+// end with a state the next z-order starts with, if possible, so that they're drawn in one call.
+func (ab *AutoBatch) sortDraws() {
+	sort.SliceStable(ab.order, func(i, j int) bool {
+		return ab.draws[ab.order[i]].z < ab.draws[ab.order[j]].z
+	})
+
+	var (
+		last    autoBatchState
+		hasLast bool
+		rank    = make(map[autoBatchState]int)
+	)
+	for start := 0; start < len(ab.order); {
+		end := ab.zRunEnd(start)
+		next := ab.order[end:ab.zRunEnd(end)]
+		run := ab.order[start:end]
+
+		clear(rank)
+		for _, i := range run {
+			if _, ok := rank[ab.draws[i].state]; !ok {
+				rank[ab.draws[i].state] = len(rank)
+			}
+		}
+		if _, ok := rank[last]; ok && hasLast {
+			rank[last] = -1
+		}
+		for _, i := range next {
+			if r, ok := rank[ab.draws[i].state]; ok && r >= 0 && len(rank) > 1 {
+				rank[ab.draws[i].state] = len(rank)
+				break
+			}
+		}
+		sort.SliceStable(run, func(i, j int) bool {
+			return rank[ab.draws[run[i]].state] < rank[ab.draws[run[j]].state]
+		})
+
+		last, hasLast = ab.draws[run[len(run)-1]].state, true
+		start = end
+	}
+}
+
+// zRunEnd returns the end of the draws with the same z-order as the draw at the start of the order.
+func (ab *AutoBatch) zRunEnd(start int) int {
+	end := start
+	for end < len(ab.order) && ab.draws[ab.order[end]].z == ab.draws[ab.order[start]].z {
+		end++
+	}
+	return end
+}
+
+// applyState sets the ComposeMethod and the BlendMode on the underlying Target, if it supports them.
+func (ab *AutoBatch) applyState(cmp ComposeMethod, blend BlendMode) {
+	if t, ok := ab.dst.(ComposeTarget); ok {
+		t.SetComposeMethod(cmp)
+	}
+	if t, ok := ab.dst.(BlendTarget); ok {
+		t.SetBlendMode(blend)
+	}
+}
+
+// drawGroup draws the draws with the Picture onto the underlying Target using the g-th group.
+func (ab *AutoBatch) drawGroup(g int, draws []int, pic Picture) {
+	if g == len(ab.groups) {
+		ab.groups = append(ab.groups, autoBatchGroup{tris: &TrianglesData{}})
+	}
+	group := &ab.groups[g]
+
+	*group.tris = (*group.tris)[:0]
+	for _, i := range draws {
+		*group.tris = append(*group.tris, ab.vertices[ab.draws[i].start:ab.draws[i].end]...)
+	}
+	if group.target == nil {
+		group.target = ab.dst.MakeTriangles(group.tris)
+	} else {
+		group.target.SetLen(group.tris.Len())
+		group.target.Update(group.tris)
+	}
+
+	if pic == nil {
+		group.target.Draw()
+		return
+	}
+	tp := ab.pics[pic]
+	if tp == nil {
+		tp = ab.dst.MakePicture(pic)
+		ab.pics[pic] = tp
+	}
+	tp.Draw(group.target)
+}
+
+// MakeTriangles returns a specialized copy of the provided Triangles that draws onto this
+// AutoBatch.
+func (ab *AutoBatch) MakeTriangles(t Triangles) TargetTriangles {
+	return &autoBatchTriangles{
+		tri: t.Copy(),
+		tmp: MakeTrianglesData(t.Len()),
+		dst: ab,
+	}
+}
+
+// MakePicture returns a specialized copy of the provided Picture that draws onto this AutoBatch.
+//
+// Note, that AutoBatch caches the results of MakePicture of the underlying Target for each Picture
+// drawn onto it, so drawing an unbounded number of Pictures leads to a memory leak, like with
+// Drawer.
+func (ab *AutoBatch) MakePicture(p Picture) TargetPicture {
+	if ap, ok := p.(*autoBatchPicture); ok {
+		p = ap.pic
+	}
+	return &autoBatchPicture{
+		pic: p,
+		dst: ab,
+	}
+}
+
+type autoBatchTriangles struct {
+	tri Triangles
+	tmp *TrianglesData
+	dst *AutoBatch
+}
+
+func (at *autoBatchTriangles) Len() int {
+	return at.tri.Len()
+}
+
+func (at *autoBatchTriangles) SetLen(len int) {
+	at.tri.SetLen(len)
+	at.tmp.SetLen(len)
+}
+
+func (at *autoBatchTriangles) Slice(i, j int) Triangles {
+	return &autoBatchTriangles{
+		tri: at.tri.Slice(i, j),
+		tmp: at.tmp.Slice(i, j).(*TrianglesData),
+		dst: at.dst,
+	}
+}
+
+func (at *autoBatchTriangles) Update(t Triangles) {
+	at.tri.Update(t)
+}
+
+func (at *autoBatchTriangles) Copy() Triangles {
+	return &autoBatchTriangles{
+		tri: at.tri.Copy(),
+		tmp: at.tmp.Copy().(*TrianglesData),
+		dst: at.dst,
+	}
+}
+
+func (at *autoBatchTriangles) draw(pic Picture) {
+	ab := at.dst
+	at.tmp.Update(at.tri)
+	for i := range *at.tmp {
+		(*at.tmp)[i].Position = ab.mat.Project((*at.tmp)[i].Position)
+		(*at.tmp)[i].Color = ab.col.Mul((*at.tmp)[i].Color)
+	}
+
+	start := len(ab.vertices)
+	ab.vertices = append(ab.vertices, *at.tmp...)
+	ab.draws = append(ab.draws, autoBatchDraw{
+		state: autoBatchState{pic: pic, cmp: ab.cmp, blend: ab.blend},
+		z:     ab.z,
+		start: start,
+		end:   len(ab.vertices),
+	})
+}
+
+func (at *autoBatchTriangles) Draw() {
+	at.draw(nil)
+}
+
+type autoBatchPicture struct {
+	pic Picture
+	dst *AutoBatch
+}
+
+func (ap *autoBatchPicture) Bounds() Rect {
+	return ap.pic.Bounds()
+}
+
+func (ap *autoBatchPicture) Draw(t TargetTriangles) {
+	at := t.(*autoBatchTriangles)
+	if ap.dst != at.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different AutoBatch", ap))
+	}
+	at.draw(ap.pic)
+}
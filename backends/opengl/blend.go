@@ -0,0 +1,210 @@
+package opengl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/gopxl/glhf/v2"
+	"github.com/gopxl/pixel/v2"
+	"github.com/pkg/errors"
+)
+
+// layeredBlendMode returns whether the blend mode can't be expressed by OpenGL blending functions,
+// so it needs to be drawn onto a layer first and then blended with a shader.
+func layeredBlendMode(bm pixel.BlendMode) bool {
+	switch bm {
+	case pixel.BlendNormal, pixel.BlendScreen, pixel.BlendAdditive:
+		return false
+	}
+	return true
+}
+
+// must be manually called inside mainthread
+func setBlendMode(cmp pixel.ComposeMethod, bm pixel.BlendMode) {
+	switch bm {
+	case pixel.BlendNormal:
+		setBlendFunc(cmp)
+	case pixel.BlendScreen:
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_COLOR)
+	case pixel.BlendAdditive:
+		gl.BlendFuncSeparate(gl.ONE, gl.ONE, gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+	default:
+		// layered blend modes draw the layer over a transparent background
+		setBlendFunc(pixel.ComposeOver)
+	}
+}
+
+// glBlender blends the content of a layer with the content of a Frame, for the blend modes which
+// OpenGL blending functions can't express.
+//
+// The layer and the backdrop, a copy of the Frame the layer is blended with, are kept the same size
+// as the Frame and reused.
+type glBlender struct {
+	layer    *glhf.Frame
+	backdrop *glhf.Frame
+	shader   *glhf.Shader
+	quad     *glhf.VertexSlice
+}
+
+const (
+	blenderLayer int = iota
+	blenderBackdrop
+	blenderMode
+)
+
+// begin returns the layer, cleared and resized to match the Frame.
+//
+// must be manually called inside mainthread
+func (b *glBlender) begin(frame *glhf.Frame) *glhf.Frame {
+	w, h := frame.Texture().Width(), frame.Texture().Height()
+	if b.layer == nil || b.layer.Texture().Width() != w || b.layer.Texture().Height() != h {
+		b.layer = glhf.NewFrame(w, h, false)
+		b.backdrop = glhf.NewFrame(w, h, false)
+	}
+	if b.shader == nil {
+		b.init()
+	}
+
+	b.layer.Begin()
+	glhf.Clear(0, 0, 0, 0)
+	b.layer.End()
+	return b.layer
+}
+
+// end blends the layer with the Frame using the blend mode.
+//
+// must be manually called inside mainthread
+func (b *glBlender) end(frame *glhf.Frame, bm pixel.BlendMode) {
+	w, h := frame.Texture().Width(), frame.Texture().Height()
+	frame.Blit(b.backdrop, 0, 0, w, h, 0, 0, w, h)
+
+	frame.Begin()
+	b.shader.Begin()
+	glhf.BlendFunc(glhf.One, glhf.Zero)
+
+	b.shader.SetUniformAttr(blenderLayer, int32(0))
+	b.shader.SetUniformAttr(blenderBackdrop, int32(1))
+	b.shader.SetUniformAttr(blenderMode, int32(bm))
+
+	gl.ActiveTexture(gl.TEXTURE1)
+	b.backdrop.Texture().Begin()
+	gl.ActiveTexture(gl.TEXTURE0)
+	b.layer.Texture().Begin()
+
+	b.quad.Begin()
+	b.quad.Draw()
+	b.quad.End()
+
+	b.layer.Texture().End()
+	gl.ActiveTexture(gl.TEXTURE1)
+	b.backdrop.Texture().End()
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	b.shader.End()
+	frame.End()
+}
+
+// must be manually called inside mainthread
+func (b *glBlender) init() {
+	shader, err := glhf.NewShader(
+		glhf.AttrFormat{{Name: "aPosition", Type: glhf.Vec2}},
+		glhf.AttrFormat{
+			blenderLayer:    {Name: "uLayer", Type: glhf.Int},
+			blenderBackdrop: {Name: "uBackdrop", Type: glhf.Int},
+			blenderMode:     {Name: "uMode", Type: glhf.Int},
+		},
+		blendVertexShader,
+		blendFragmentShader,
+	)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to create blend shader, there's a bug in the shader"))
+	}
+	b.shader = shader
+
+	// two triangles covering the whole Frame
+	b.quad = glhf.MakeVertexSlice(b.shader, 6, 6)
+	b.quad.Begin()
+	b.quad.SetVertexData([]float32{
+		-1, -1, 1, -1, 1, 1,
+		-1, -1, 1, 1, -1, 1,
+	})
+	b.quad.End()
+}
+
+var blendVertexShader = `
+#version 330 core
+
+in vec2 aPosition;
+
+void main() {
+	gl_Position = vec4(aPosition, 0.0, 1.0);
+}
+`
+
+// blendFragmentShader implements the same formulas as pixel.BlendMode.Compose.
+var blendFragmentShader = fmt.Sprintf(`
+#version 330 core
+
+out vec4 fragColor;
+
+uniform sampler2D uLayer;
+uniform sampler2D uBackdrop;
+uniform int uMode;
+
+float blend(float cb, float cs) {
+	switch (uMode) {
+	case %d: // multiply
+		return cb * cs;
+	case %d: // overlay
+		if (cb <= 0.5) {
+			return 2.0 * cs * cb;
+		}
+		return cs + (2.0 * cb - 1.0) - cs * (2.0 * cb - 1.0);
+	case %d: // darken
+		return min(cb, cs);
+	case %d: // lighten
+		return max(cb, cs);
+	case %d: // color dodge
+		if (cb == 0.0) {
+			return 0.0;
+		}
+		if (cs >= 1.0) {
+			return 1.0;
+		}
+		return min(1.0, cb / (1.0 - cs));
+	case %d: // color burn
+		if (cb == 1.0) {
+			return 1.0;
+		}
+		if (cs <= 0.0) {
+			return 0.0;
+		}
+		return 1.0 - min(1.0, (1.0 - cb) / cs);
+	case %d: // difference
+		return abs(cb - cs);
+	}
+	return cs;
+}
+
+void main() {
+	ivec2 at = ivec2(gl_FragCoord.xy);
+	vec4 s = texelFetch(uLayer, at, 0);
+	vec4 b = texelFetch(uBackdrop, at, 0);
+
+	// the blend functions work with straight colors, while the textures are premultiplied
+	vec3 cs = s.a > 0.0 ? s.rgb / s.a : vec3(0.0);
+	vec3 cb = b.a > 0.0 ? b.rgb / b.a : vec3(0.0);
+	vec3 mixed = vec3(blend(cb.r, cs.r), blend(cb.g, cs.g), blend(cb.b, cs.b));
+
+	fragColor.rgb = s.rgb * (1.0 - b.a) + b.rgb * (1.0 - s.a) + s.a * b.a * mixed;
+	fragColor.a = s.a + b.a - s.a * b.a;
+}
+`,
+	pixel.BlendMultiply,
+	pixel.BlendOverlay,
+	pixel.BlendDarken,
+	pixel.BlendLighten,
+	pixel.BlendColorDodge,
+	pixel.BlendColorBurn,
+	pixel.BlendDifference,
+)
@@ -20,14 +20,19 @@ type Canvas struct {
 	shader *GLShader
 
 	cmp    pixel.ComposeMethod
+	blend  pixel.BlendMode
 	mat    mgl32.Mat3
 	col    mgl32.Vec4
 	smooth bool
 
-	sprite *pixel.Sprite
+	sprite  *pixel.Sprite
+	blender glBlender
 }
 
-var _ pixel.ComposeTarget = (*Canvas)(nil)
+var (
+	_ pixel.ComposeTarget = (*Canvas)(nil)
+	_ pixel.BlendTarget   = (*Canvas)(nil)
+)
 
 // NewCanvas creates a new empty, fully transparent Canvas with given bounds.
 func NewCanvas(bounds pixel.Rect) *Canvas {
@@ -58,7 +63,8 @@ func (c *Canvas) SetFragmentShader(src string) {
 
 // MakeTriangles creates a specialized copy of the supplied Triangles that draws onto this Canvas.
 //
-// TrianglesPosition, TrianglesColor and TrianglesPicture are supported.
+// TrianglesPosition, TrianglesColor and TrianglesPicture are supported. TrianglesIndexed are drawn using an
+// element buffer, see GLTriangles.
 func (c *Canvas) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
 	if gt, ok := t.(*GLTriangles); ok {
 		return &canvasTriangles{
@@ -126,6 +132,17 @@ func (c *Canvas) SetComposeMethod(cmp pixel.ComposeMethod) {
 	c.cmp = cmp
 }
 
+// SetBlendMode sets a blend mode to be used in the following draws onto this Canvas. BlendNormal
+// uses the compose method set by SetComposeMethod, other blend modes ignore it.
+//
+// BlendScreen and BlendAdditive use OpenGL blending functions. The other blend modes draw the
+// triangles onto an offscreen layer first, which is then blended with the content of the Canvas by
+// a shader. This means that overlapping triangles drawn at once, such as the triangles of a single
+// Batch or IMDraw, are composed with each other like with ComposeOver before they are blended.
+func (c *Canvas) SetBlendMode(bm pixel.BlendMode) {
+	c.blend = bm
+}
+
 // SetBounds resizes the Canvas to the new bounds. Old content will be preserved.
 func (c *Canvas) SetBounds(bounds pixel.Rect) {
 	c.gf.SetBounds(bounds)
@@ -260,6 +277,21 @@ func (c *Canvas) Pixels() []uint8 {
 	return pixels
 }
 
+// Screenshot returns a copy of the content of the Canvas as PictureData with the same bounds. Use
+// its Image, Encode or Save methods to get a top-down image, the flipping of the rows is handled
+// there.
+func (c *Canvas) Screenshot() *pixel.PictureData {
+	pixels := c.Pixels()
+	pd := pixel.MakePictureData(c.Bounds())
+	for i := range pd.Pix {
+		pd.Pix[i].R = pixels[i*4+0]
+		pd.Pix[i].G = pixels[i*4+1]
+		pd.Pix[i].B = pixels[i*4+2]
+		pd.Pix[i].A = pixels[i*4+3]
+	}
+	return pd
+}
+
 // Draw draws the content of the Canvas onto another Target, transformed by the given Matrix, just
 // like if it was a Sprite containing the whole Canvas.
 func (c *Canvas) Draw(t pixel.Target, matrix pixel.Matrix) {
@@ -284,15 +316,20 @@ func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 
 	// save the current state vars to avoid race condition
 	cmp := ct.dst.cmp
+	blend := ct.dst.blend
 	smt := ct.dst.smooth
 	mat := ct.dst.mat
 	col := ct.dst.col
 
 	mainthread.CallNonBlock(func() {
 		ct.dst.setGlhfBounds()
-		setBlendFunc(cmp)
+		setBlendMode(cmp, blend)
 
 		frame := ct.dst.gf.Frame()
+		if layeredBlendMode(blend) {
+			frame = ct.dst.blender.begin(frame)
+			defer ct.dst.blender.end(ct.dst.gf.Frame(), blend)
+		}
 		shader := ct.shader.s
 
 		frame.Begin()
@@ -321,9 +358,7 @@ func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 		}
 
 		if tex == nil {
-			ct.vs.Begin()
-			ct.vs.Draw()
-			ct.vs.End()
+			ct.render()
 		} else {
 			tex.Begin()
 
@@ -331,9 +366,7 @@ func (ct *canvasTriangles) draw(tex *glhf.Texture, bounds pixel.Rect) {
 				tex.SetSmooth(smt)
 			}
 
-			ct.vs.Begin()
-			ct.vs.Draw()
-			ct.vs.End()
+			ct.render()
 
 			tex.End()
 		}
@@ -0,0 +1,73 @@
+package opengl
+
+import (
+	"runtime"
+
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/gopxl/mainthread/v2"
+)
+
+// glElements is an OpenGL element buffer, which holds the indices of the vertices of indexed
+// GLTriangles. The element buffer is bound to the vertex array of the GLTriangles before every use,
+// because glhf replaces the vertex array when the vertices grow.
+//
+// The indices are either uint16 or uint32, the uint16 ones take half the memory and bandwidth.
+type glElements struct {
+	ebo   uint32
+	cap   int
+	short bool
+}
+
+// must be manually called inside mainthread
+func newGLElements(short bool) *glElements {
+	e := &glElements{short: short}
+	gl.GenBuffers(1, &e.ebo)
+	runtime.SetFinalizer(e, (*glElements).delete)
+	return e
+}
+
+func (e *glElements) delete() {
+	mainthread.CallNonBlock(func() {
+		gl.DeleteBuffers(1, &e.ebo)
+	})
+}
+
+// indexSize returns the size of an index in bytes.
+func (e *glElements) indexSize() int {
+	if e.short {
+		return 2
+	}
+	return 4
+}
+
+// set uploads count indices to the element buffer, starting at the offset. The indices are a
+// []uint16 or a []uint32 slice, matching the type of the element buffer. When the element buffer
+// grows to fit them, the indices outside of the uploaded range are lost.
+//
+// must be manually called inside mainthread, with the vertex array of the GLTriangles bound
+func (e *glElements) set(offset, count int, indices interface{}) {
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, e.ebo)
+	size := e.indexSize()
+	if n := offset + count; n > e.cap {
+		e.cap = max(n, 2*e.cap)
+		gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, e.cap*size, nil, gl.DYNAMIC_DRAW)
+	}
+	if count > 0 {
+		gl.BufferSubData(gl.ELEMENT_ARRAY_BUFFER, offset*size, count*size, gl.Ptr(indices))
+	}
+}
+
+// draw draws the triangles of count indices, starting at the offset.
+//
+// must be manually called inside mainthread, with the vertex array of the GLTriangles bound
+func (e *glElements) draw(offset, count int) {
+	if count == 0 {
+		return
+	}
+	typ := uint32(gl.UNSIGNED_INT)
+	if e.short {
+		typ = gl.UNSIGNED_SHORT
+	}
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, e.ebo)
+	gl.DrawElementsWithOffset(gl.TRIANGLES, int32(count), typ, uintptr(offset*e.indexSize()))
+}
@@ -12,10 +12,22 @@ import (
 //
 // Triangles returned from this function support TrianglesPosition, TrianglesColor and
 // TrianglesPicture. If you need to support more, you can "override" SetLen and Update methods.
+//
+// GLTriangles made from TrianglesIndexed are indexed: the vertices are stored only once and the
+// indices are uploaded to an OpenGL element buffer. Like with TrianglesIndexed, Len and the vertex
+// properties then refer to the indices. GLTriangles made from IndexedTrianglesData[uint16] keep
+// uint16 indices, like IndexedTrianglesData[uint16], the others use uint32 indices.
 type GLTriangles struct {
 	vs     *glhf.VertexSlice
 	data   []float32
 	shader *GLShader
+
+	// the indices of indexed GLTriangles, either the uint16 or the uint32 ones depending on the
+	// element buffer they're uploaded to, and the position of the first index in it
+	indices16 []uint16
+	indices32 []uint32
+	elements  *glElements
+	offset    int
 }
 
 var (
@@ -44,16 +56,32 @@ const (
 	trisAttrLen
 )
 
-// NewGLTriangles returns GLTriangles initialized with the data from the supplied Triangles.
+// NewGLTriangles returns GLTriangles initialized with the data from the supplied Triangles. If the
+// supplied Triangles are TrianglesIndexed, the GLTriangles are indexed too.
 //
 // Only draw the Triangles using the provided Shader.
 func NewGLTriangles(shader *GLShader, t pixel.Triangles) *GLTriangles {
+	vertices, indexed, short := t, false, false
+	switch t := t.(type) {
+	case *GLTriangles:
+		if t.elements != nil {
+			vertices, indexed, short = t.vertices(), true, t.elements.short
+		}
+	case *pixel.IndexedTrianglesData[uint16]:
+		vertices, indexed, short = t.Vertices(), true, true
+	case pixel.TrianglesIndexed:
+		vertices, indexed = t.Vertices(), true
+	}
+
 	var gt *GLTriangles
 	mainthread.Call(func() {
 		gt = &GLTriangles{
-			vs:     glhf.MakeVertexSlice(shader.s, 0, t.Len()),
+			vs:     glhf.MakeVertexSlice(shader.s, 0, vertices.Len()),
 			shader: shader,
 		}
+		if indexed {
+			gt.elements = newGLElements(short)
+		}
 	})
 	gt.SetLen(t.Len())
 	gt.Update(t)
@@ -72,18 +100,66 @@ func (gt *GLTriangles) Shader() *GLShader {
 	return gt.shader
 }
 
-// Len returns the number of vertices.
+// Len returns the number of vertices, or the number of indices, if the GLTriangles are indexed.
 func (gt *GLTriangles) Len() int {
+	if gt.elements != nil {
+		return len(gt.indices16) + len(gt.indices32)
+	}
+	return gt.vertexLen()
+}
+
+// vertexLen returns the number of the stored vertices.
+func (gt *GLTriangles) vertexLen() int {
 	return len(gt.data) / gt.vs.Stride()
 }
 
-// SetLen efficiently resizes GLTriangles to len.
+// vertices returns flat GLTriangles of the vertices of indexed GLTriangles.
+func (gt *GLTriangles) vertices() *GLTriangles {
+	return &GLTriangles{
+		vs:     gt.vs,
+		data:   gt.data,
+		shader: gt.shader,
+	}
+}
+
+// SetLen efficiently resizes GLTriangles to len. If the GLTriangles are indexed, the indices are
+// resized and the new indices are zero, the vertices are resized by Update.
 //
 // Time complexity is amortized O(1).
 func (gt *GLTriangles) SetLen(length int) {
+	if gt.elements == nil {
+		gt.setVertexLen(length)
+		return
+	}
+	if length == gt.Len() {
+		return
+	}
+	if gt.elements.short {
+		gt.indices16 = resizeIndices(gt.indices16, length)
+	} else {
+		gt.indices32 = resizeIndices(gt.indices32, length)
+	}
+	gt.copyIndices()
+}
+
+// resizeIndices resizes the indices to len, the new indices are zero.
+func resizeIndices[I uint16 | uint32](indices []I, length int) []I {
+	old := len(indices)
+	if length > cap(indices) {
+		indices = append(indices[:cap(indices)], make([]I, length-cap(indices))...)
+	}
+	indices = indices[:length]
+	for i := old; i < length; i++ {
+		indices[i] = 0
+	}
+	return indices
+}
+
+// setVertexLen resizes the vertices to len.
+func (gt *GLTriangles) setVertexLen(length int) {
 	switch {
-	case length > gt.Len():
-		needAppend := length - gt.Len()
+	case length > gt.vertexLen():
+		needAppend := length - gt.vertexLen()
 		for i := 0; i < needAppend; i++ {
 			gt.data = append(gt.data,
 				0, 0,
@@ -93,7 +169,7 @@ func (gt *GLTriangles) SetLen(length int) {
 				0, 0, 0, 0,
 			)
 		}
-	case length < gt.Len():
+	case length < gt.vertexLen():
 		gt.data = gt.data[:length*gt.vs.Stride()]
 	default:
 		return
@@ -105,8 +181,28 @@ func (gt *GLTriangles) SetLen(length int) {
 	})
 }
 
-// Slice returns a sub-Triangles of this GLTriangles in range [i, j).
+// Slice returns a sub-Triangles of this GLTriangles in range [i, j). If the GLTriangles are
+// indexed, the range is of the indices and the vertices are shared.
+//
+// Updating the returned GLTriangles uploads only the vertices in the range to the GPU, so it's the
+// way to update a small part of large GLTriangles. This does not hold for indexed GLTriangles,
+// which upload all their vertices on every Update.
 func (gt *GLTriangles) Slice(i, j int) pixel.Triangles {
+	if gt.elements != nil {
+		slice := &GLTriangles{
+			vs:       gt.vs,
+			data:     gt.data,
+			shader:   gt.shader,
+			elements: gt.elements,
+			offset:   gt.offset + i,
+		}
+		if gt.elements.short {
+			slice.indices16 = gt.indices16[i:j]
+		} else {
+			slice.indices32 = gt.indices32[i:j]
+		}
+		return slice
+	}
 	return &GLTriangles{
 		vs:     gt.vs.Slice(i, j),
 		data:   gt.data[i*gt.vs.Stride() : j*gt.vs.Stride()],
@@ -114,16 +210,17 @@ func (gt *GLTriangles) Slice(i, j int) pixel.Triangles {
 	}
 }
 
+// updateData copies the vertex properties from the supplied Triangles into the stored vertices.
 func (gt *GLTriangles) updateData(t pixel.Triangles) {
 	// glTriangles short path
-	if t, ok := t.(*GLTriangles); ok {
+	if t, ok := t.(*GLTriangles); ok && t.elements == nil {
 		copy(gt.data, t.data)
 		return
 	}
 
 	// TrianglesData short path
 	stride := gt.vs.Stride()
-	length := gt.Len()
+	length := gt.vertexLen()
 	if t, ok := t.(*pixel.TrianglesData); ok {
 		for i := 0; i < length; i++ {
 			var (
@@ -188,17 +285,98 @@ func (gt *GLTriangles) updateData(t pixel.Triangles) {
 
 // Update copies vertex properties from the supplied Triangles into this GLTriangles.
 //
+// If the GLTriangles are indexed and the supplied Triangles are TrianglesIndexed, their vertices
+// and indices are copied, resizing the vertices if needed. If the supplied Triangles are not
+// indexed, the properties of their i-th vertex are copied to the vertex at the i-th index.
+//
 // The two Triangles (gt and t) must be of the same len.
 func (gt *GLTriangles) Update(t pixel.Triangles) {
 	if gt.Len() != t.Len() {
 		panic(fmt.Errorf("(%T).Update: invalid triangles len", gt))
 	}
+	if gt.elements != nil {
+		gt.updateIndexed(t)
+		gt.CopyVertices()
+		gt.copyIndices()
+		return
+	}
 	gt.updateData(t)
 
 	// Copy the verteces down to the glhf.VertexData
 	gt.CopyVertices()
 }
 
+func (gt *GLTriangles) updateIndexed(t pixel.Triangles) {
+	switch t := t.(type) {
+	case *GLTriangles:
+		if t.elements != nil {
+			gt.setVertexLen(t.vertexLen())
+			copy(gt.data, t.data)
+			if gt.elements.short == t.elements.short {
+				copy(gt.indices16, t.indices16)
+				copy(gt.indices32, t.indices32)
+			} else {
+				gt.setIndices(t.vertexIndex)
+			}
+			return
+		}
+	case *pixel.IndexedTrianglesData[uint16]:
+		gt.setVertexLen(t.VertexData.Len())
+		gt.updateData(t.VertexData)
+		if gt.elements.short {
+			copy(gt.indices16, t.Indices)
+		} else {
+			gt.setIndices(t.Index)
+		}
+		return
+	case *pixel.IndexedTrianglesData[uint32]:
+		gt.setVertexLen(t.VertexData.Len())
+		gt.updateData(t.VertexData)
+		if !gt.elements.short {
+			copy(gt.indices32, t.Indices)
+		} else {
+			gt.setIndices(t.Index)
+		}
+		return
+	case pixel.TrianglesIndexed:
+		vertices := t.Vertices()
+		gt.setVertexLen(vertices.Len())
+		gt.updateData(vertices)
+		gt.setIndices(t.Index)
+		return
+	}
+
+	// the properties not supported by t stay the same
+	corners := pixel.MakeTrianglesData(gt.Len())
+	corners.Update(gt)
+	corners.Update(t)
+	for i, v := range *corners {
+		gt.SetPosition(i, v.Position)
+		gt.SetColor(i, v.Color)
+		gt.SetPicture(i, v.Picture, v.Intensity)
+		gt.SetClipRect(i, v.ClipRect)
+	}
+}
+
+// setIndices sets the indices of indexed GLTriangles, converting them to the type of the element
+// buffer.
+func (gt *GLTriangles) setIndices(index func(i int) int) {
+	for i := range gt.indices16 {
+		gt.indices16[i] = uint16(index(i))
+	}
+	for i := range gt.indices32 {
+		gt.indices32[i] = uint32(index(i))
+	}
+}
+
+// vertexIndex returns the index of the vertex of the i-th corner of indexed GLTriangles.
+func (gt *GLTriangles) vertexIndex(i int) int {
+	if gt.elements.short {
+		return int(gt.indices16[i])
+	}
+	return int(gt.indices32[i])
+}
+
 // CopyVertices copies the GLTriangle data down to the vertex data.
 func (gt *GLTriangles) CopyVertices() {
 	// this code is supposed to copy the vertex data and CallNonBlock the update if
@@ -219,6 +397,43 @@ func (gt *GLTriangles) CopyVertices() {
 	}
 }
 
+// copyIndices copies the indices of indexed GLTriangles down to the element buffer.
+func (gt *GLTriangles) copyIndices() {
+	// the same heuristic as in CopyVertices
+	var indices interface{} = gt.indices32
+	if gt.elements.short {
+		indices = gt.indices16
+	}
+	count := gt.Len()
+	call := mainthread.Call
+	if count < 256 {
+		if gt.elements.short {
+			indices = append([]uint16{}, gt.indices16...)
+		} else {
+			indices = append([]uint32{}, gt.indices32...)
+		}
+		call = mainthread.CallNonBlock
+	}
+	call(func() {
+		gt.vs.Begin()
+		gt.elements.set(gt.offset, count, indices)
+		gt.vs.End()
+	})
+}
+
+// render draws the triangles with the currently bound shader.
+//
+// must be manually called inside mainthread
+func (gt *GLTriangles) render() {
+	gt.vs.Begin()
+	if gt.elements != nil {
+		gt.elements.draw(gt.offset, gt.Len())
+	} else {
+		gt.vs.Draw()
+	}
+	gt.vs.End()
+}
+
 // Copy returns an independent copy of this GLTriangles.
 //
 // The returned Triangles are *GLTriangles as the underlying type.
@@ -229,6 +444,9 @@ func (gt *GLTriangles) Copy() pixel.Triangles {
 // index is a helper function that returns the index in the data
 // slice given the i-th vertex and the item index.
 func (gt *GLTriangles) index(i, idx int) int {
+	if gt.elements != nil {
+		i = gt.vertexIndex(i)
+	}
 	return i*gt.vs.Stride() + idx
 }
 
@@ -508,6 +508,12 @@ func (w *Window) SetComposeMethod(cmp pixel.ComposeMethod) {
 	w.canvas.SetComposeMethod(cmp)
 }
 
+// SetBlendMode sets a blend mode to be used in the following draws onto this Window. See
+// Canvas.SetBlendMode for details.
+func (w *Window) SetBlendMode(bm pixel.BlendMode) {
+	w.canvas.SetBlendMode(bm)
+}
+
 // SetSmooth sets whether the stretched Pictures drawn onto this Window should be drawn smooth or
 // pixely.
 func (w *Window) SetSmooth(smooth bool) {
@@ -530,6 +536,11 @@ func (w *Window) Color(at pixel.Vec) pixel.RGBA {
 	return w.canvas.Color(at)
 }
 
+// Screenshot returns a copy of the content of the Window as PictureData, see Canvas.Screenshot.
+func (w *Window) Screenshot() *pixel.PictureData {
+	return w.canvas.Screenshot()
+}
+
 // Canvas returns the window's underlying Canvas
 func (w *Window) Canvas() *Canvas {
 	return w.canvas
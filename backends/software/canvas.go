@@ -0,0 +1,272 @@
+package software
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Canvas is an off-screen rectangular ComposeTarget and Picture at the same time, that you can draw
+// onto. All drawing happens on the CPU, no graphics device is needed.
+//
+// It supports TrianglesPosition, TrianglesColor, TrianglesPicture, TrianglesClipped and
+// PictureColor. The rasterization rules follow the ones of the opengl backend, so that the same
+// drawing code produces (nearly) the same pixels on both.
+type Canvas struct {
+	pd *pixel.PictureData
+
+	cmp    pixel.ComposeMethod
+	blend  pixel.BlendMode
+	mat    pixel.Matrix
+	col    pixel.RGBA
+	smooth bool
+
+	sprite *pixel.Sprite
+}
+
+var (
+	_ pixel.ComposeTarget = (*Canvas)(nil)
+	_ pixel.BlendTarget   = (*Canvas)(nil)
+	_ pixel.PictureColor  = (*Canvas)(nil)
+)
+
+// NewCanvas creates a new empty, fully transparent Canvas with given bounds.
+func NewCanvas(bounds pixel.Rect) *Canvas {
+	c := &Canvas{
+		mat: pixel.IM,
+		col: pixel.Alpha(1),
+	}
+	c.SetBounds(bounds)
+	return c
+}
+
+// MakeTriangles creates a specialized copy of the supplied Triangles that draws onto this Canvas.
+//
+// TrianglesPosition, TrianglesColor, TrianglesPicture and TrianglesClipped are supported.
+func (c *Canvas) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	td := pixel.MakeTrianglesData(t.Len())
+	td.Update(t)
+	return &canvasTriangles{
+		TrianglesData: td,
+		dst:           c,
+	}
+}
+
+// MakePicture creates a specialized copy of the supplied Picture that draws onto this Canvas.
+//
+// PictureColor is supported. Other Canvases are referenced directly, so drawing them always uses
+// their current content.
+func (c *Canvas) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	switch p := p.(type) {
+	case *canvasPicture:
+		return &canvasPicture{src: p.src, pd: p.pd, dst: c}
+	case *Canvas:
+		return &canvasPicture{src: p, dst: c}
+	}
+	return &canvasPicture{pd: pixel.PictureDataFromPicture(p), dst: c}
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (c *Canvas) SetMatrix(m pixel.Matrix) {
+	c.mat = m
+}
+
+// SetColorMask sets a color that every color in triangles or a picture will be multiplied by.
+func (c *Canvas) SetColorMask(col color.Color) {
+	if col == nil {
+		c.col = pixel.Alpha(1)
+		return
+	}
+	c.col = pixel.ToRGBA(col)
+}
+
+// SetComposeMethod sets a Porter-Duff composition method to be used in the following draws onto
+// this Canvas.
+func (c *Canvas) SetComposeMethod(cmp pixel.ComposeMethod) {
+	c.cmp = cmp
+}
+
+// SetBlendMode sets a blend mode to be used in the following draws onto this Canvas. BlendNormal
+// uses the compose method set by SetComposeMethod, other blend modes ignore it.
+func (c *Canvas) SetBlendMode(bm pixel.BlendMode) {
+	c.blend = bm
+}
+
+// SetBounds resizes the Canvas to the new bounds. Old content will be preserved where the old and
+// the new bounds overlap.
+func (c *Canvas) SetBounds(bounds pixel.Rect) {
+	if c.pd != nil && bounds == c.pd.Rect {
+		return
+	}
+
+	old := c.pd
+	c.pd = pixel.MakePictureData(bounds)
+
+	if old != nil {
+		ox, oy, ow, oh := intBounds(old.Rect)
+		nx, ny, nw, nh := intBounds(bounds)
+		for y := max(oy, ny); y < min(oy+oh, ny+nh); y++ {
+			for x := max(ox, nx); x < min(ox+ow, nx+nw); x++ {
+				c.pd.Pix[(y-ny)*c.pd.Stride+(x-nx)] = old.Pix[(y-oy)*old.Stride+(x-ox)]
+			}
+		}
+	}
+
+	if c.sprite == nil {
+		c.sprite = pixel.NewSprite(nil, pixel.Rect{})
+	}
+	c.sprite.Set(c, c.Bounds())
+}
+
+// Bounds returns the rectangular bounds of the Canvas.
+func (c *Canvas) Bounds() pixel.Rect {
+	return c.pd.Rect
+}
+
+// SetSmooth sets whether stretched Pictures drawn onto this Canvas should be drawn smooth or
+// pixely.
+func (c *Canvas) SetSmooth(smooth bool) {
+	c.smooth = smooth
+}
+
+// Smooth returns whether stretched Pictures drawn onto this Canvas are set to be drawn smooth or
+// pixely.
+func (c *Canvas) Smooth() bool {
+	return c.smooth
+}
+
+// Clear fills the whole Canvas with a single color.
+func (c *Canvas) Clear(color color.Color) {
+	rgba := toColorRGBA(pixel.ToRGBA(color).Mul(c.col))
+	for i := range c.pd.Pix {
+		c.pd.Pix[i] = rgba
+	}
+}
+
+// Color returns the color of the pixel over the given position inside the Canvas.
+func (c *Canvas) Color(at pixel.Vec) pixel.RGBA {
+	return c.pd.Color(at)
+}
+
+// SetPixels replaces the content of the Canvas with the provided pixels. The provided slice must be
+// an alpha-premultiplied RGBA sequence of correct length (4 * width * height), starting with the
+// bottom row.
+func (c *Canvas) SetPixels(pixels []uint8) {
+	if len(pixels) != 4*len(c.pd.Pix) {
+		panic(fmt.Errorf("(%T).SetPixels: invalid pixels length", c))
+	}
+	for i := range c.pd.Pix {
+		c.pd.Pix[i] = color.RGBA{
+			R: pixels[i*4+0],
+			G: pixels[i*4+1],
+			B: pixels[i*4+2],
+			A: pixels[i*4+3],
+		}
+	}
+}
+
+// Pixels returns an alpha-premultiplied RGBA sequence of the content of the Canvas, starting with
+// the bottom row.
+func (c *Canvas) Pixels() []uint8 {
+	pixels := make([]uint8, 4*len(c.pd.Pix))
+	for i, rgba := range c.pd.Pix {
+		pixels[i*4+0] = rgba.R
+		pixels[i*4+1] = rgba.G
+		pixels[i*4+2] = rgba.B
+		pixels[i*4+3] = rgba.A
+	}
+	return pixels
+}
+
+// PictureData returns a copy of the content of the Canvas.
+func (c *Canvas) PictureData() *pixel.PictureData {
+	pd := pixel.MakePictureData(c.pd.Rect)
+	copy(pd.Pix, c.pd.Pix)
+	return pd
+}
+
+// Image returns the content of the Canvas as an image.RGBA. The image is oriented top-down, as
+// usual for the image package.
+func (c *Canvas) Image() *image.RGBA {
+	return c.pd.Image()
+}
+
+// Draw draws the content of the Canvas onto another Target, transformed by the given Matrix, just
+// like if it was a Sprite containing the whole Canvas.
+func (c *Canvas) Draw(t pixel.Target, matrix pixel.Matrix) {
+	c.sprite.Draw(t, matrix)
+}
+
+// DrawColorMask draws the content of the Canvas onto another Target, transformed by the given
+// Matrix and multiplied by the given mask, just like if it was a Sprite containing the whole Canvas.
+//
+// If the color mask is nil, a fully opaque white mask will be used causing no effect.
+func (c *Canvas) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	c.sprite.DrawColorMask(t, matrix, mask)
+}
+
+type canvasTriangles struct {
+	*pixel.TrianglesData
+	dst *Canvas
+}
+
+func (ct *canvasTriangles) Draw() {
+	ct.dst.rasterize(ct.TrianglesData, nil)
+}
+
+type canvasPicture struct {
+	src *Canvas
+	pd  *pixel.PictureData
+	dst *Canvas
+}
+
+func (cp *canvasPicture) data() *pixel.PictureData {
+	if cp.src == nil {
+		return cp.pd
+	}
+	if cp.src == cp.dst {
+		// reading and writing the same pixels at once, take a snapshot first
+		return cp.src.PictureData()
+	}
+	return cp.src.pd
+}
+
+func (cp *canvasPicture) Bounds() pixel.Rect {
+	return cp.data().Bounds()
+}
+
+func (cp *canvasPicture) Color(at pixel.Vec) pixel.RGBA {
+	return cp.data().Color(at)
+}
+
+func (cp *canvasPicture) Draw(t pixel.TargetTriangles) {
+	ct := t.(*canvasTriangles)
+	if cp.dst != ct.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Canvas", cp))
+	}
+	ct.dst.rasterize(ct.TrianglesData, cp.data())
+}
+
+func intBounds(bounds pixel.Rect) (x, y, w, h int) {
+	x0 := int(math.Floor(bounds.Min.X))
+	y0 := int(math.Floor(bounds.Min.Y))
+	x1 := int(math.Ceil(bounds.Max.X))
+	y1 := int(math.Ceil(bounds.Max.Y))
+	return x0, y0, x1 - x0, y1 - y0
+}
+
+func toColorRGBA(c pixel.RGBA) color.RGBA {
+	return color.RGBA{
+		R: toByte(c.R),
+		G: toByte(c.G),
+		B: toByte(c.B),
+		A: toByte(c.A),
+	}
+}
+
+func toByte(x float64) uint8 {
+	return uint8(math.Round(pixel.Clamp(x, 0, 1) * 255))
+}
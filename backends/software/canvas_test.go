@@ -0,0 +1,224 @@
+package software_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func TestCanvas_Clear(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 4, 4))
+	c.Clear(pixel.RGB(1, 0, 0))
+
+	if got, want := c.Color(pixel.V(2, 2)), pixel.RGB(1, 0, 0); got != want {
+		t.Fatalf("Color() = %v, want %v", got, want)
+	}
+	if got, want := c.Color(pixel.V(10, 10)), pixel.Alpha(0); got != want {
+		t.Fatalf("Color() outside = %v, want %v", got, want)
+	}
+}
+
+func TestCanvas_Sprite(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255}) // top-left
+	img.Set(1, 0, color.RGBA{0, 255, 0, 255}) // top-right
+	img.Set(0, 1, color.RGBA{0, 0, 255, 255}) // bottom-left
+	img.Set(1, 1, color.RGBA{255, 255, 255, 255})
+	pic := pixel.PictureDataFromImage(img)
+
+	c := software.NewCanvas(pixel.R(0, 0, 4, 4))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	sprite.Draw(c, pixel.IM.Scaled(pixel.ZV, 2).Moved(c.Bounds().Center()))
+
+	tests := []struct {
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		{pixel.V(0.5, 3.5), pixel.RGB(1, 0, 0)},
+		{pixel.V(3.5, 3.5), pixel.RGB(0, 1, 0)},
+		{pixel.V(0.5, 0.5), pixel.RGB(0, 0, 1)},
+		{pixel.V(3.5, 0.5), pixel.RGB(1, 1, 1)},
+	}
+	for _, tt := range tests {
+		if got := c.Color(tt.at); got != tt.want {
+			t.Errorf("Color(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+
+	// Image is top-down, the red pixel is the top-left one
+	if got, want := c.Image().RGBAAt(0, 0), (color.RGBA{255, 0, 0, 255}); got != want {
+		t.Errorf("Image().At(0, 0) = %v, want %v", got, want)
+	}
+}
+
+func TestCanvas_SharedEdgesDrawnOnce(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 8, 8))
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.Alpha(0.5)
+	imd.Push(pixel.V(0, 0), pixel.V(8, 8))
+	imd.Rectangle(0)
+	imd.Draw(c)
+
+	for y := 0.5; y < 8; y++ {
+		for x := 0.5; x < 8; x++ {
+			if got := c.Color(pixel.V(x, y)); got.A < 0.49 || got.A > 0.51 {
+				t.Fatalf("Color(%v, %v).A = %v, want 0.5", x, y, got.A)
+			}
+		}
+	}
+}
+
+func TestCanvas_ComposeMethod(t *testing.T) {
+	src := pixel.RGB(1, 0, 0).Mul(pixel.Alpha(0.6))
+	dst := pixel.RGB(0, 0, 1).Mul(pixel.Alpha(0.4))
+
+	for _, cmp := range []pixel.ComposeMethod{
+		pixel.ComposeOver,
+		pixel.ComposeIn,
+		pixel.ComposeOut,
+		pixel.ComposeAtop,
+		pixel.ComposeRover,
+		pixel.ComposeRin,
+		pixel.ComposeRout,
+		pixel.ComposeRatop,
+		pixel.ComposeXor,
+		pixel.ComposePlus,
+		pixel.ComposeCopy,
+	} {
+		c := software.NewCanvas(pixel.R(0, 0, 2, 2))
+		c.Clear(dst)
+		c.SetComposeMethod(cmp)
+
+		imd := imdraw.New(nil)
+		imd.Color = src
+		imd.Push(pixel.V(0, 0), pixel.V(2, 2))
+		imd.Rectangle(0)
+		imd.Draw(c)
+
+		got := c.Color(pixel.V(1, 1))
+		want := cmp.Compose(src, dst)
+		if !nearlyEqual(got, want) {
+			t.Errorf("compose method %v: Color() = %v, want %v", cmp, got, want)
+		}
+	}
+}
+
+func TestCanvas_BlendMode(t *testing.T) {
+	src := pixel.RGB(1, 0.5, 0).Mul(pixel.Alpha(0.6))
+	dst := pixel.RGB(0.2, 0.4, 1).Mul(pixel.Alpha(0.8))
+
+	for _, bm := range []pixel.BlendMode{
+		pixel.BlendMultiply,
+		pixel.BlendScreen,
+		pixel.BlendOverlay,
+		pixel.BlendDarken,
+		pixel.BlendLighten,
+		pixel.BlendColorDodge,
+		pixel.BlendColorBurn,
+		pixel.BlendDifference,
+		pixel.BlendAdditive,
+	} {
+		c := software.NewCanvas(pixel.R(0, 0, 2, 2))
+		c.Clear(dst)
+		// the blend mode takes precedence over the compose method
+		c.SetComposeMethod(pixel.ComposeCopy)
+		c.SetBlendMode(bm)
+		// compare with the background as stored in the Canvas, with its 8-bit precision
+		want := bm.Compose(src, c.Color(pixel.V(1, 1)))
+
+		imd := imdraw.New(nil)
+		imd.Color = src
+		imd.Push(pixel.V(0, 0), pixel.V(2, 2))
+		imd.Rectangle(0)
+		imd.Draw(c)
+
+		got := c.Color(pixel.V(1, 1))
+		if !nearlyEqual(got, want) {
+			t.Errorf("blend mode %v: Color() = %v, want %v", bm, got, want)
+		}
+	}
+}
+
+func TestCanvas_ClipRect(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 4, 4))
+
+	tri := pixel.MakeTrianglesData(6)
+	for i, v := range []pixel.Vec{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 4}} {
+		(*tri)[i].Position = v
+		(*tri)[i].ClipRect = pixel.R(0, 0, 2, 4)
+		(*tri)[i].IsClipped = true
+	}
+	c.MakeTriangles(tri).Draw()
+
+	if got := c.Color(pixel.V(1, 1)); got != pixel.Alpha(1) {
+		t.Errorf("Color inside clip = %v, want %v", got, pixel.Alpha(1))
+	}
+	if got := c.Color(pixel.V(3, 1)); got != pixel.Alpha(0) {
+		t.Errorf("Color outside clip = %v, want %v", got, pixel.Alpha(0))
+	}
+}
+
+func TestCanvas_Text(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 64, 16))
+
+	txt := text.New(pixel.V(2, 4), text.Atlas7x13)
+	txt.WriteString("Hello")
+	txt.Draw(c, pixel.IM)
+
+	drawn := 0
+	for _, px := range c.PictureData().Pix {
+		if px.A > 0 {
+			drawn++
+		}
+	}
+	if drawn == 0 {
+		t.Fatal("no text pixels were drawn")
+	}
+}
+
+func TestCanvas_DrawCanvas(t *testing.T) {
+	src := software.NewCanvas(pixel.R(0, 0, 2, 2))
+	src.Clear(pixel.RGB(0, 1, 0))
+
+	dst := software.NewCanvas(pixel.R(0, 0, 4, 4))
+	src.Draw(dst, pixel.IM.Moved(pixel.V(1, 1)))
+
+	if got, want := dst.Color(pixel.V(0.5, 0.5)), pixel.RGB(0, 1, 0); got != want {
+		t.Errorf("Color() = %v, want %v", got, want)
+	}
+	if got, want := dst.Color(pixel.V(3.5, 3.5)), pixel.Alpha(0); got != want {
+		t.Errorf("Color() = %v, want %v", got, want)
+	}
+}
+
+func TestCanvas_SetBoundsPreservesContent(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 2, 2))
+	c.Clear(pixel.RGB(1, 1, 0))
+	c.SetBounds(pixel.R(-2, -2, 2, 2))
+
+	if got, want := c.Color(pixel.V(1, 1)), pixel.RGB(1, 1, 0); got != want {
+		t.Errorf("Color() = %v, want %v", got, want)
+	}
+	if got, want := c.Color(pixel.V(-1, -1)), pixel.Alpha(0); got != want {
+		t.Errorf("Color() = %v, want %v", got, want)
+	}
+}
+
+func nearlyEqual(a, b pixel.RGBA) bool {
+	const eps = 1.0 / 255
+	d := a.Sub(b)
+	return abs(d.R) <= eps && abs(d.G) <= eps && abs(d.B) <= eps && abs(d.A) <= eps
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,6 @@
+// Package software implements a pure-Go CPU rasterizing Target for the Pixel game development
+// library, specifically Canvas.
+//
+// It requires no GPU and no windowing system, which makes it useful for headless rendering, such as
+// server-side image generation or checking the output of drawing code in tests and CI.
+package software
@@ -0,0 +1,188 @@
+package software
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// vertex is a single vertex of a triangle in the coordinates of the Canvas's pixel grid, where the
+// pixel (x, y) covers the area [x, x+1) x [y, y+1).
+type vertex struct {
+	pos       pixel.Vec
+	col       pixel.RGBA
+	pic       pixel.Vec
+	intensity float64
+}
+
+// rasterize draws all triangles from td onto the Canvas using the current matrix, color mask and
+// compose method or blend mode. The pic is the texture of the triangles, it can be nil.
+func (c *Canvas) rasterize(td *pixel.TrianglesData, pic *pixel.PictureData) {
+	bounds := c.pd.Rect
+	if bounds.W() <= 0 || bounds.H() <= 0 {
+		return
+	}
+	_, _, fw, fh := intBounds(bounds)
+	scale := pixel.V(float64(fw)/bounds.W(), float64(fh)/bounds.H())
+
+	var tri [3]vertex
+	for i := 0; i+2 < len(*td); i += 3 {
+		for k := range tri {
+			v := &(*td)[i+k]
+			tri[k] = vertex{
+				pos:       c.mat.Project(v.Position).Sub(bounds.Min).ScaledXY(scale),
+				col:       v.Color,
+				pic:       v.Picture,
+				intensity: v.Intensity,
+			}
+		}
+
+		// like the opengl backend, any non-zero clipping rectangle clips the triangle
+		clip := (*td)[i].ClipRect
+		c.fillTriangle(tri, pic, fw, fh, clip, clip != pixel.Rect{})
+	}
+}
+
+func (c *Canvas) fillTriangle(tri [3]vertex, pic *pixel.PictureData, fw, fh int, clip pixel.Rect, clipped bool) {
+	area := edge(tri[0].pos, tri[1].pos, tri[2].pos)
+	if area == 0 || math.IsNaN(area) {
+		return
+	}
+	if area < 0 {
+		// make the triangle counter-clockwise, so that the interior is on the left of each edge
+		tri[1], tri[2] = tri[2], tri[1]
+		area = -area
+	}
+
+	minX := math.Min(tri[0].pos.X, math.Min(tri[1].pos.X, tri[2].pos.X))
+	minY := math.Min(tri[0].pos.Y, math.Min(tri[1].pos.Y, tri[2].pos.Y))
+	maxX := math.Max(tri[0].pos.X, math.Max(tri[1].pos.X, tri[2].pos.X))
+	maxY := math.Max(tri[0].pos.Y, math.Max(tri[1].pos.Y, tri[2].pos.Y))
+
+	x0, x1 := max(int(math.Floor(minX)), 0), min(int(math.Ceil(maxX)), fw)
+	y0, y1 := max(int(math.Floor(minY)), 0), min(int(math.Ceil(maxY)), fh)
+
+	topLeft := [3]bool{
+		isTopLeft(tri[1].pos, tri[2].pos),
+		isTopLeft(tri[2].pos, tri[0].pos),
+		isTopLeft(tri[0].pos, tri[1].pos),
+	}
+
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			p := pixel.V(float64(x)+0.5, float64(y)+0.5)
+
+			w := [3]float64{
+				edge(tri[1].pos, tri[2].pos, p),
+				edge(tri[2].pos, tri[0].pos, p),
+				edge(tri[0].pos, tri[1].pos, p),
+			}
+			if !covers(w[0], topLeft[0]) || !covers(w[1], topLeft[1]) || !covers(w[2], topLeft[2]) {
+				continue
+			}
+
+			if clipped && (p.X < clip.Min.X || p.Y < clip.Min.Y || p.X > clip.Max.X || p.Y > clip.Max.Y) {
+				continue
+			}
+
+			l0, l1, l2 := w[0]/area, w[1]/area, w[2]/area
+			frag := c.shade(
+				lerpRGBA(tri[0].col, tri[1].col, tri[2].col, l0, l1, l2),
+				tri[0].pic.Scaled(l0).Add(tri[1].pic.Scaled(l1)).Add(tri[2].pic.Scaled(l2)),
+				tri[0].intensity*l0+tri[1].intensity*l1+tri[2].intensity*l2,
+				pic,
+			)
+
+			idx := y*c.pd.Stride + x
+			c.pd.Pix[idx] = toColorRGBA(c.compose(frag, fromColorRGBA(c.pd.Pix[idx])))
+		}
+	}
+}
+
+// compose composes the fragment with the color in the Canvas using the blend mode, or the compose
+// method for BlendNormal.
+func (c *Canvas) compose(frag, dst pixel.RGBA) pixel.RGBA {
+	if c.blend != pixel.BlendNormal {
+		return c.blend.Compose(frag, dst)
+	}
+	return c.cmp.Compose(frag, dst)
+}
+
+// shade computes the color of a single fragment, the same way the default fragment shader of the
+// opengl backend does.
+func (c *Canvas) shade(col pixel.RGBA, at pixel.Vec, intensity float64, pic *pixel.PictureData) pixel.RGBA {
+	if intensity == 0 || pic == nil {
+		return clampRGBA(col.Mul(c.col))
+	}
+	frag := col.Scaled(1 - intensity)
+	frag = frag.Add(col.Mul(c.sample(pic, at)).Scaled(intensity))
+	return clampRGBA(frag.Mul(c.col))
+}
+
+// sample returns the color of the picture at the given position. Positions outside of the picture
+// are fully transparent. If the Canvas is smooth, the picture is sampled bilinearly.
+func (c *Canvas) sample(pic *pixel.PictureData, at pixel.Vec) pixel.RGBA {
+	bx, by, _, _ := intBounds(pic.Rect)
+	u, v := at.X-float64(bx), at.Y-float64(by)
+
+	if !c.smooth {
+		return texel(pic, int(math.Floor(u)), int(math.Floor(v)))
+	}
+
+	u, v = u-0.5, v-0.5
+	tx, ty := math.Floor(u), math.Floor(v)
+	fx, fy := u-tx, v-ty
+	x, y := int(tx), int(ty)
+
+	bottom := texel(pic, x, y).Scaled(1 - fx).Add(texel(pic, x+1, y).Scaled(fx))
+	top := texel(pic, x, y+1).Scaled(1 - fx).Add(texel(pic, x+1, y+1).Scaled(fx))
+	return bottom.Scaled(1 - fy).Add(top.Scaled(fy))
+}
+
+func texel(pic *pixel.PictureData, x, y int) pixel.RGBA {
+	if x < 0 || y < 0 || x >= pic.Stride || y*pic.Stride+x >= len(pic.Pix) {
+		return pixel.RGBA{}
+	}
+	return fromColorRGBA(pic.Pix[y*pic.Stride+x])
+}
+
+// edge returns the doubled signed area of the triangle (a, b, p). It is positive if p lies to the
+// left of the directed edge a->b.
+func edge(a, b, p pixel.Vec) float64 {
+	return (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+}
+
+// isTopLeft reports whether the directed edge a->b of a counter-clockwise triangle is a top or a
+// left edge. Pixels lying exactly on such edges belong to the triangle, so that pixels on edges
+// shared by two triangles are drawn exactly once.
+func isTopLeft(a, b pixel.Vec) bool {
+	d := a.To(b)
+	return d.Y < 0 || (d.Y == 0 && d.X < 0)
+}
+
+func covers(w float64, topLeft bool) bool {
+	return w > 0 || (w == 0 && topLeft)
+}
+
+func lerpRGBA(a, b, c pixel.RGBA, la, lb, lc float64) pixel.RGBA {
+	return a.Scaled(la).Add(b.Scaled(lb)).Add(c.Scaled(lc))
+}
+
+func clampRGBA(c pixel.RGBA) pixel.RGBA {
+	return pixel.RGBA{
+		R: pixel.Clamp(c.R, 0, 1),
+		G: pixel.Clamp(c.G, 0, 1),
+		B: pixel.Clamp(c.B, 0, 1),
+		A: pixel.Clamp(c.A, 0, 1),
+	}
+}
+
+func fromColorRGBA(c color.RGBA) pixel.RGBA {
+	return pixel.RGBA{
+		R: float64(c.R) / 255,
+		G: float64(c.G) / 255,
+		B: float64(c.B) / 255,
+		A: float64(c.A) / 255,
+	}
+}
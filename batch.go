@@ -25,6 +25,11 @@ var _ BasicTarget = (*Batch)(nil)
 // properties, that the supplied container supports. If you retain access to the container and
 // change it, call Dirty to notify Batch about the change.
 //
+// If the container is IndexedTrianglesData, the vertices of the objects are stored only once and
+// the triangles are formed by indices. Objects made of TrianglesIndexed keep their vertices shared,
+// so a quad takes four vertices instead of six. Drawing panics if the container has more vertices
+// than its indices can address.
+//
 // Note, that if the container does not support TrianglesColor, color masking will not work.
 func NewBatch(container Triangles, pic Picture) *Batch {
 	b := &Batch{cont: Drawer{Triangles: container, Picture: pic, Cached: true}}
@@ -44,9 +49,30 @@ func (b *Batch) Dirty() {
 	b.cont.Dirty()
 }
 
+// DirtyRange notifies Batch about an external modification of it's container in range [i, j). Only
+// this range is then updated in the Targets the Batch is drawn to, see Drawer.DirtyRange.
+//
+//	container := &pixel.TrianglesData{}
+//	batch := pixel.NewBatch(container, nil)
+//	// ... draw 10000 sprites onto the batch
+//	(*container)[42].Color = pixel.RGB(1, 0, 0) // container changed from outside of Batch
+//	batch.DirtyRange(42, 43)                   // notify Batch about the change
+//
+// Objects drawn onto the Batch only mark the Triangles they add as changed, so adding an object to
+// a large Batch doesn't update the objects already in it.
+//
+// If the container is IndexedTrianglesData, the range is ignored and all of the container is updated
+// on the next Draw, as after Dirty. This holds for the objects drawn onto the Batch too.
+func (b *Batch) DirtyRange(i, j int) {
+	b.cont.DirtyRange(i, j)
+}
+
 // Clear removes all objects from the Batch.
 func (b *Batch) Clear() {
 	b.cont.Triangles.SetLen(0)
+	if t, ok := b.cont.Triangles.(TrianglesIndexed); ok {
+		t.Vertices().SetLen(0)
+	}
 	b.cont.Dirty()
 }
 
@@ -127,6 +153,15 @@ func (bt *batchTriangles) Copy() Triangles {
 }
 
 func (bt *batchTriangles) draw(bp *batchPicture) {
+	switch cont := bt.dst.cont.Triangles.(type) {
+	case *IndexedTrianglesData[uint16]:
+		appendIndexed(cont, bt)
+		return
+	case *IndexedTrianglesData[uint32]:
+		appendIndexed(cont, bt)
+		return
+	}
+
 	bt.tmp.Update(bt.tri)
 
 	for i := range *bt.tmp {
@@ -139,6 +174,34 @@ func (bt *batchTriangles) draw(bp *batchPicture) {
 	added := cont.Slice(cont.Len()-bt.tri.Len(), cont.Len())
 	added.Update(bt.tri)
 	added.Update(bt.tmp)
+	bt.dst.cont.DirtyRange(cont.Len()-bt.tri.Len(), cont.Len())
+}
+
+// appendIndexed adds the vertices of the triangles to the vertices of the container and their
+// indices, moved after the existing vertices, to the indices of the container.
+func appendIndexed[I VertexIndex](cont *IndexedTrianglesData[I], bt *batchTriangles) {
+	var vertices Triangles = bt.tri
+	index := func(i int) int { return i }
+	if t, ok := bt.tri.(TrianglesIndexed); ok {
+		vertices, index = t.Vertices(), t.Index
+	}
+
+	base, n := cont.VertexData.Len(), vertices.Len()
+	if uint64(base+n) > uint64(^I(0))+1 {
+		panic(fmt.Errorf("(%T).Draw: too many vertices for %T indices", bt.dst, I(0)))
+	}
+	cont.VertexData.SetLen(base + n)
+	added := cont.VertexData.Slice(base, base+n).(*TrianglesData)
+	added.Update(vertices)
+	for i := range *added {
+		(*added)[i].Position = bt.dst.mat.Project((*added)[i].Position)
+		(*added)[i].Color = bt.dst.col.Mul((*added)[i].Color)
+	}
+
+	for i := 0; i < bt.tri.Len(); i++ {
+		cont.Indices = append(cont.Indices, I(base+index(i)))
+	}
+	// the indexed Triangles are always updated whole, see Drawer.DirtyRange
 	bt.dst.cont.Dirty()
 }
 
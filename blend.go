@@ -0,0 +1,149 @@
+package pixel
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// BlendTarget is a BasicTarget capable of blend modes.
+type BlendTarget interface {
+	BasicTarget
+
+	// SetBlendMode sets a blend mode to be used. BlendNormal uses the ComposeMethod of the Target,
+	// if it has one, other blend modes ignore it.
+	SetBlendMode(BlendMode)
+}
+
+// BlendMode is a blend mode, known from image editors, which combines the colors of the
+// foreground and the background.
+//
+// Except for BlendAdditive, the blend modes follow the W3C Compositing and Blending specification:
+// the blended colors are composed with the background like with ComposeOver, so the transparent
+// parts of the foreground leave the background unchanged, and the foreground is kept as it is where
+// the background is transparent.
+type BlendMode int
+
+// Here's the list of all available blend modes.
+const (
+	// BlendNormal draws the foreground over the background, see ComposeOver.
+	BlendNormal BlendMode = iota
+	// BlendMultiply multiplies the colors, which always darkens the background.
+	BlendMultiply
+	// BlendScreen multiplies the inverted colors, which always lightens the background.
+	BlendScreen
+	// BlendOverlay multiplies the dark parts and screens the light parts of the background.
+	BlendOverlay
+	// BlendDarken keeps the darker of the colors.
+	BlendDarken
+	// BlendLighten keeps the lighter of the colors.
+	BlendLighten
+	// BlendColorDodge brightens the background to reflect the foreground.
+	BlendColorDodge
+	// BlendColorBurn darkens the background to reflect the foreground.
+	BlendColorBurn
+	// BlendDifference subtracts the darker of the colors from the lighter one.
+	BlendDifference
+	// BlendAdditive adds the colors, while the alpha is composed like with ComposeOver, so that
+	// adding lights doesn't make the background more opaque than drawing over it does.
+	BlendAdditive
+)
+
+// String returns the name of the BlendMode.
+func (bm BlendMode) String() string {
+	switch bm {
+	case BlendNormal:
+		return "BlendNormal"
+	case BlendMultiply:
+		return "BlendMultiply"
+	case BlendScreen:
+		return "BlendScreen"
+	case BlendOverlay:
+		return "BlendOverlay"
+	case BlendDarken:
+		return "BlendDarken"
+	case BlendLighten:
+		return "BlendLighten"
+	case BlendColorDodge:
+		return "BlendColorDodge"
+	case BlendColorBurn:
+		return "BlendColorBurn"
+	case BlendDifference:
+		return "BlendDifference"
+	case BlendAdditive:
+		return "BlendAdditive"
+	}
+	return fmt.Sprintf("BlendMode(%d)", int(bm))
+}
+
+// Compose blends two colors together according to the BlendMode. A is the foreground, B is the
+// background. The components of the result are not clamped.
+func (bm BlendMode) Compose(a, b RGBA) RGBA {
+	var blend func(cb, cs float64) float64
+
+	switch bm {
+	case BlendNormal:
+		return ComposeOver.Compose(a, b)
+	case BlendMultiply:
+		blend = func(cb, cs float64) float64 { return cb * cs }
+	case BlendScreen:
+		blend = screen
+	case BlendOverlay:
+		blend = func(cb, cs float64) float64 {
+			if cb <= 0.5 {
+				return cs * 2 * cb
+			}
+			return screen(cs, 2*cb-1)
+		}
+	case BlendDarken:
+		blend = math.Min
+	case BlendLighten:
+		blend = math.Max
+	case BlendColorDodge:
+		blend = func(cb, cs float64) float64 {
+			switch {
+			case cb == 0:
+				return 0
+			case cs >= 1:
+				return 1
+			}
+			return math.Min(1, cb/(1-cs))
+		}
+	case BlendColorBurn:
+		blend = func(cb, cs float64) float64 {
+			switch {
+			case cb == 1:
+				return 1
+			case cs <= 0:
+				return 0
+			}
+			return 1 - math.Min(1, (1-cb)/cs)
+		}
+	case BlendDifference:
+		blend = func(cb, cs float64) float64 { return math.Abs(cb - cs) }
+	case BlendAdditive:
+		return RGBA{
+			R: a.R + b.R,
+			G: a.G + b.G,
+			B: a.B + b.B,
+			A: a.A + b.A*(1-a.A),
+		}
+	default:
+		panic(errors.New("Compose: invalid BlendMode"))
+	}
+
+	// the blend functions work with straight colors, while a and b are premultiplied
+	sr, sg, sb := a.straight()
+	br, bg, bb := b.straight()
+	both := a.A * b.A
+	return RGBA{
+		R: a.R*(1-b.A) + b.R*(1-a.A) + both*blend(br, sr),
+		G: a.G*(1-b.A) + b.G*(1-a.A) + both*blend(bg, sg),
+		B: a.B*(1-b.A) + b.B*(1-a.A) + both*blend(bb, sb),
+		A: a.A + b.A - both,
+	}
+}
+
+func screen(cb, cs float64) float64 {
+	return cb + cs - cb*cs
+}
@@ -0,0 +1,573 @@
+package pixel
+
+import (
+	"math"
+	"sort"
+)
+
+// ComplexPolygon is a polygon with holes. The Outline is in counter-clockwise order and the Holes
+// are in clockwise order. Holes lie inside of the Outline and don't overlap each other.
+type ComplexPolygon struct {
+	Outline Polygon
+	Holes   []Polygon
+}
+
+// Area returns the area of the ComplexPolygon, which is the area of the outline without the areas
+// of the holes.
+func (cp ComplexPolygon) Area() float64 {
+	area := cp.Outline.Area()
+	for _, hole := range cp.Holes {
+		area -= hole.Area()
+	}
+	return area
+}
+
+// Bounds returns the smallest Rect which contains the whole ComplexPolygon.
+func (cp ComplexPolygon) Bounds() Rect {
+	return cp.Outline.Bounds()
+}
+
+// Contains checks whether a vector u is contained within the outline and outside of all holes of
+// the ComplexPolygon. Points on the edges of the outline or the holes are contained.
+func (cp ComplexPolygon) Contains(u Vec) bool {
+	if !cp.Outline.Contains(u) {
+		return false
+	}
+	for _, hole := range cp.Holes {
+		if hole.Contains(u) && !onEdges(hole, u) {
+			return false
+		}
+	}
+	return true
+}
+
+// TrianglesData returns the triangles of the ComplexPolygon, see Polygon.TrianglesData.
+func (cp ComplexPolygon) TrianglesData() *TrianglesData {
+	return cp.Outline.TrianglesData(cp.Holes...)
+}
+
+// PolygonSet is a set of non-overlapping polygons with holes. It is the result of the boolean
+// operations on polygons: Union, Intersect, Difference and Xor.
+//
+// A PolygonSet can be created from simple polygons, such as rectangles or circles, like this:
+//
+//	terrain := pixel.PolygonSet{{Outline: pixel.R(0, 0, 800, 200).Polygon()}}
+//	crater := pixel.PolygonSet{{Outline: pixel.C(hit, 30).Polygon(32)}}
+//	terrain = terrain.Difference(crater)
+//
+// Outlines and holes of the operands can be in either clockwise or counter-clockwise order, the
+// results are always normalized as described for ComplexPolygon.
+type PolygonSet []ComplexPolygon
+
+// Area returns the total area of all polygons in the PolygonSet.
+func (ps PolygonSet) Area() float64 {
+	area := 0.0
+	for _, cp := range ps {
+		area += cp.Area()
+	}
+	return area
+}
+
+// Bounds returns the smallest Rect which contains all polygons of the PolygonSet. An empty
+// PolygonSet has bounds of ZR.
+func (ps PolygonSet) Bounds() Rect {
+	if len(ps) == 0 {
+		return ZR
+	}
+	r := ps[0].Bounds()
+	for _, cp := range ps[1:] {
+		r = r.Union(cp.Bounds())
+	}
+	return r
+}
+
+// Contains checks whether a vector u is contained within any of the polygons of the PolygonSet.
+func (ps PolygonSet) Contains(u Vec) bool {
+	for _, cp := range ps {
+		if cp.Contains(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrianglesData returns the triangles of all polygons of the PolygonSet, see
+// Polygon.TrianglesData.
+func (ps PolygonSet) TrianglesData() *TrianglesData {
+	td := &TrianglesData{}
+	for _, cp := range ps {
+		*td = append(*td, *cp.TrianglesData()...)
+	}
+	return td
+}
+
+// Union returns the area covered by either of the two PolygonSets.
+func (ps PolygonSet) Union(qs PolygonSet) PolygonSet {
+	return polygonBoolean(ps, qs, opUnion)
+}
+
+// Intersect returns the area covered by both of the PolygonSets.
+func (ps PolygonSet) Intersect(qs PolygonSet) PolygonSet {
+	return polygonBoolean(ps, qs, opIntersect)
+}
+
+// Difference returns the area covered by this PolygonSet, but not by the other one.
+func (ps PolygonSet) Difference(qs PolygonSet) PolygonSet {
+	return polygonBoolean(ps, qs, opDifference)
+}
+
+// Xor returns the area covered by exactly one of the PolygonSets.
+func (ps PolygonSet) Xor(qs PolygonSet) PolygonSet {
+	return polygonBoolean(ps, qs, opXor)
+}
+
+type booleanOp int
+
+const (
+	opUnion booleanOp = iota
+	opIntersect
+	opDifference
+	opXor
+)
+
+// boolSegment is a directed piece of an edge of one of the operands, such that the operand's area
+// lies on its left.
+type boolSegment struct {
+	a, b    int // nodes
+	operand int // 0 or 1
+}
+
+// polygonBoolean implements the boolean operations: all edges of both operands are split where they
+// intersect, the pieces are kept or dropped depending on whether they lie inside of the other
+// operand, and the kept pieces are linked into the resulting outlines and holes.
+func polygonBoolean(ps, qs PolygonSet, op booleanOp) PolygonSet {
+	operands := [2][]Polygon{orientedContours(ps), orientedContours(qs)}
+
+	bounds := ps.Bounds().Union(qs.Bounds())
+	eps := 1e-9 * math.Max(1, math.Max(bounds.W(), bounds.H()))
+
+	type edge struct {
+		a, b    Vec
+		operand int
+		splits  []float64
+	}
+	var edges []edge
+	for operand, contours := range operands {
+		for _, contour := range contours {
+			for _, l := range contour.Edges() {
+				if l.A.To(l.B).Len() > eps {
+					edges = append(edges, edge{a: l.A, b: l.B, operand: operand})
+				}
+			}
+		}
+	}
+
+	// find where the edges cross or touch each other, sweeping along the x axis, so that only the
+	// edges overlapping along it are tested
+	order := make([]int, len(edges))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		e, f := edges[order[i]], edges[order[j]]
+		return math.Min(e.a.X, e.b.X) < math.Min(f.a.X, f.b.X)
+	})
+	var active []int
+	for _, i := range order {
+		e := &edges[i]
+		minX := math.Min(e.a.X, e.b.X)
+		n := 0
+		for _, j := range active {
+			if f := &edges[j]; math.Max(f.a.X, f.b.X)+eps >= minX {
+				active[n] = j
+				n++
+			}
+		}
+		active = active[:n]
+
+		for _, j := range active {
+			f := &edges[j]
+			if !segmentBoundsOverlap(e.a, e.b, f.a, f.b, eps) {
+				continue
+			}
+			t, u := splitParams(e.a, e.b, f.a, f.b, eps)
+			e.splits = append(e.splits, t...)
+			f.splits = append(f.splits, u...)
+		}
+		active = append(active, i)
+	}
+
+	// cut the edges into segments between the splits, joining the nearly equal points into nodes,
+	// which are found by the grid cells of the size eps they lie in
+	var nodes []Vec
+	cells := make(map[[2]int][]int, 2*len(edges))
+	node := func(u Vec) int {
+		x, y := int(math.Floor(u.X/eps)), int(math.Floor(u.Y/eps))
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for _, i := range cells[[2]int{x + dx, y + dy}] {
+					if u.To(nodes[i]).Len() <= eps {
+						return i
+					}
+				}
+			}
+		}
+		nodes = append(nodes, u)
+		cells[[2]int{x, y}] = append(cells[[2]int{x, y}], len(nodes)-1)
+		return len(nodes) - 1
+	}
+	var segments []boolSegment
+	for _, e := range edges {
+		sort.Float64s(e.splits)
+		prev := node(e.a)
+		for _, t := range append(e.splits, 1) {
+			next := node(e.b)
+			if t < 1 {
+				next = node(Lerp(e.a, e.b, t))
+			}
+			if next != prev {
+				segments = append(segments, boolSegment{a: prev, b: next, operand: e.operand})
+			}
+			prev = next
+		}
+	}
+
+	// pair up the segments shared by both operands, by their nodes in either direction
+	shared := make([]int, len(segments))
+	unpaired := make(map[[2]int][]int)
+	for i, s := range segments {
+		shared[i] = -1
+		if s.operand == 1 {
+			key := [2]int{min(s.a, s.b), max(s.a, s.b)}
+			unpaired[key] = append(unpaired[key], i)
+		}
+	}
+	for i, s := range segments {
+		key := [2]int{min(s.a, s.b), max(s.a, s.b)}
+		if s.operand != 0 || len(unpaired[key]) == 0 {
+			continue
+		}
+		j := unpaired[key][0]
+		unpaired[key] = unpaired[key][1:]
+		shared[i], shared[j] = j, i
+	}
+
+	bands := [2]*edgeBands{newEdgeBands(operands[0]), newEdgeBands(operands[1])}
+
+	var kept []boolSegment
+	for i, s := range segments {
+		if shared[i] >= 0 {
+			if s.operand == 1 {
+				continue
+			}
+			sameDir := segments[shared[i]].a == s.a
+			if op == opUnion && sameDir || op == opIntersect && sameDir || op == opDifference && !sameDir {
+				kept = append(kept, s)
+			}
+			continue
+		}
+
+		mid := Lerp(nodes[s.a], nodes[s.b], 0.5)
+		inside := bands[1-s.operand].contains(mid)
+		reversed := s
+		reversed.a, reversed.b = s.b, s.a
+
+		switch op {
+		case opUnion:
+			if !inside {
+				kept = append(kept, s)
+			}
+		case opIntersect:
+			if inside {
+				kept = append(kept, s)
+			}
+		case opDifference:
+			if s.operand == 0 && !inside {
+				kept = append(kept, s)
+			}
+			if s.operand == 1 && inside {
+				kept = append(kept, reversed)
+			}
+		case opXor:
+			if inside {
+				kept = append(kept, reversed)
+			} else {
+				kept = append(kept, s)
+			}
+		}
+	}
+
+	return assembleContours(linkSegments(nodes, kept, eps))
+}
+
+// orientedContours returns all outlines of the PolygonSet in counter-clockwise order and all holes
+// in clockwise order, so that the area of the set always lies on the left of the edges.
+func orientedContours(ps PolygonSet) []Polygon {
+	var contours []Polygon
+	for _, cp := range ps {
+		if len(cp.Outline) < 3 {
+			continue
+		}
+		outline := cp.Outline
+		if outline.IsClockwise() {
+			outline = outline.Reversed()
+		}
+		contours = append(contours, outline)
+		for _, hole := range cp.Holes {
+			if len(hole) < 3 {
+				continue
+			}
+			if !hole.IsClockwise() {
+				hole = hole.Reversed()
+			}
+			contours = append(contours, hole)
+		}
+	}
+	return contours
+}
+
+func segmentBoundsOverlap(a1, b1, a2, b2 Vec, eps float64) bool {
+	return math.Min(a1.X, b1.X) <= math.Max(a2.X, b2.X)+eps &&
+		math.Min(a2.X, b2.X) <= math.Max(a1.X, b1.X)+eps &&
+		math.Min(a1.Y, b1.Y) <= math.Max(a2.Y, b2.Y)+eps &&
+		math.Min(a2.Y, b2.Y) <= math.Max(a1.Y, b1.Y)+eps
+}
+
+// splitParams returns the parameters along the segments a1b1 and a2b2, strictly between 0 and 1,
+// where they need to be split, because the other segment crosses or touches them there.
+func splitParams(a1, b1, a2, b2 Vec, eps float64) (t, u []float64) {
+	d1, d2 := a1.To(b1), a2.To(b2)
+	l1, l2 := d1.Len(), d2.Len()
+
+	// param returns the parameter of the point p projected onto the segment a->a+d, if the point
+	// lies on the segment, strictly between its end points
+	param := func(p, a, d Vec, l float64) (float64, bool) {
+		ap := a.To(p)
+		if math.Abs(d.Cross(ap))/l > eps {
+			return 0, false
+		}
+		s := d.Dot(ap) / (l * l)
+		return s, s*l > eps && (1-s)*l > eps
+	}
+
+	// end points touching the other segment, this also handles collinear overlaps
+	for _, p := range [...]Vec{a2, b2} {
+		if s, ok := param(p, a1, d1, l1); ok {
+			t = append(t, s)
+		}
+	}
+	for _, p := range [...]Vec{a1, b1} {
+		if s, ok := param(p, a2, d2, l2); ok {
+			u = append(u, s)
+		}
+	}
+
+	// proper crossing
+	denom := d1.Cross(d2)
+	if math.Abs(denom) <= eps*l1*l2 {
+		return t, u
+	}
+	a1a2 := a1.To(a2)
+	s1 := a1a2.Cross(d2) / denom
+	s2 := a1a2.Cross(d1) / denom
+	if s1*l1 > eps && (1-s1)*l1 > eps && s2*l2 > eps && (1-s2)*l2 > eps {
+		t = append(t, s1)
+		u = append(u, s2)
+	}
+	return t, u
+}
+
+// edgeBands holds the edges of contours sorted into horizontal bands, so that testing whether a
+// point lies inside of them only goes through the edges of the band of the point.
+type edgeBands struct {
+	minY, height float64
+	bands        [][]Line
+}
+
+func newEdgeBands(contours []Polygon) *edgeBands {
+	n, bounds := 0, Rect{}
+	for i, contour := range contours {
+		n += len(contour)
+		if i == 0 {
+			bounds = contour.Bounds()
+		} else {
+			bounds = bounds.Union(contour.Bounds())
+		}
+	}
+	eb := &edgeBands{minY: bounds.Min.Y, bands: make([][]Line, max(1, int(math.Sqrt(float64(n)))))}
+	eb.height = bounds.H() / float64(len(eb.bands))
+	for _, contour := range contours {
+		for _, l := range contour.Edges() {
+			first, last := eb.band(math.Min(l.A.Y, l.B.Y)), eb.band(math.Max(l.A.Y, l.B.Y))
+			for i := first; i <= last; i++ {
+				eb.bands[i] = append(eb.bands[i], l)
+			}
+		}
+	}
+	return eb
+}
+
+// band returns the index of the band containing the y coordinate, clamped to the bands.
+func (eb *edgeBands) band(y float64) int {
+	if eb.height == 0 {
+		return 0
+	}
+	return max(0, min(len(eb.bands)-1, int((y-eb.minY)/eb.height)))
+}
+
+// contains returns whether u lies inside of the area bounded by the edges, using the even-odd rule.
+func (eb *edgeBands) contains(u Vec) bool {
+	inside := false
+	for _, l := range eb.bands[eb.band(u.Y)] {
+		a, b := l.A, l.B
+		if (a.Y > u.Y) != (b.Y > u.Y) {
+			if x := a.X + (u.Y-a.Y)/(b.Y-a.Y)*(b.X-a.X); u.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// linkSegments connects the directed segments into closed loops. Where more segments continue from
+// a node, the one turning the most to the left is taken, so that loops touching in a single node
+// are kept apart.
+func linkSegments(nodes []Vec, segments []boolSegment, eps float64) []Polygon {
+	outgoing := make(map[int][]int)
+	for i, s := range segments {
+		outgoing[s.a] = append(outgoing[s.a], i)
+	}
+	used := make([]bool, len(segments))
+
+	var loops []Polygon
+	for start := range segments {
+		if used[start] {
+			continue
+		}
+		var loop Polygon
+		cur := start
+		for {
+			used[cur] = true
+			s := segments[cur]
+			loop = append(loop, nodes[s.a])
+			if s.b == segments[start].a {
+				break
+			}
+
+			dir := nodes[s.a].To(nodes[s.b])
+			next, bestTurn := -1, math.Inf(-1)
+			for _, j := range outgoing[s.b] {
+				if used[j] {
+					continue
+				}
+				d := nodes[segments[j].a].To(nodes[segments[j].b])
+				if turn := math.Atan2(dir.Cross(d), dir.Dot(d)); turn > bestTurn {
+					next, bestTurn = j, turn
+				}
+			}
+			if next < 0 {
+				// an open chain, which can only happen due to rounding errors
+				loop = nil
+				break
+			}
+			cur = next
+		}
+
+		if loop = removeCollinear(loop, eps); len(loop) >= 3 {
+			loops = append(loops, loop)
+		}
+	}
+	return loops
+}
+
+// removeCollinear removes the vertices lying on the straight line between their neighbours.
+func removeCollinear(p Polygon, eps float64) Polygon {
+	for changed := true; changed && len(p) >= 3; {
+		changed = false
+		for i := 0; i < len(p) && len(p) >= 3; i++ {
+			prev, cur, next := p[(i+len(p)-1)%len(p)], p[i], p[(i+1)%len(p)]
+			d := prev.To(next)
+			if l := d.Len(); l > 0 && math.Abs(d.Cross(prev.To(cur)))/l <= eps && d.Dot(prev.To(cur)) >= 0 &&
+				d.Dot(cur.To(next)) >= 0 {
+				p = append(p[:i], p[i+1:]...)
+				changed = true
+				i--
+			}
+		}
+	}
+	return p
+}
+
+// assembleContours sorts the loops into outlines (counter-clockwise) and holes (clockwise) and
+// assigns each hole to the smallest outline containing it.
+func assembleContours(loops []Polygon) PolygonSet {
+	var set PolygonSet
+	var holes []Polygon
+	for _, loop := range loops {
+		switch area := loop.SignedArea(); {
+		case area > 0:
+			set = append(set, ComplexPolygon{Outline: loop})
+		case area < 0:
+			holes = append(holes, loop)
+		}
+	}
+
+	bounds := make([]Rect, len(set))
+	areas := make([]float64, len(set))
+	for i, cp := range set {
+		bounds[i], areas[i] = cp.Outline.Bounds(), cp.Outline.Area()
+	}
+	var candidates []int
+	for _, hole := range holes {
+		holeBounds := hole.Bounds()
+		candidates = candidates[:0]
+		for i := range set {
+			if bounds[i].Contains(holeBounds.Min) && bounds[i].Contains(holeBounds.Max) {
+				candidates = append(candidates, i)
+			}
+		}
+		// each hole lies inside of an outline, so only the outlines around other outlines need the
+		// exact test
+		best := -1
+		if len(candidates) == 1 {
+			best = candidates[0]
+		} else {
+			for _, i := range candidates {
+				if containsPolygon(set[i].Outline, hole) && (best < 0 || areas[i] < areas[best]) {
+					best = i
+				}
+			}
+		}
+		if best >= 0 {
+			set[best].Holes = append(set[best].Holes, hole)
+		}
+	}
+	return set
+}
+
+// containsPolygon returns whether the outline contains the other polygon, which is known not to
+// cross it.
+func containsPolygon(outline, p Polygon) bool {
+	for _, v := range p {
+		if !onEdges(outline, v) {
+			return outline.Contains(v)
+		}
+	}
+	// all the vertices lie on the outline, test the middle of an edge instead
+	for _, l := range p.Edges() {
+		if c := l.Center(); !onEdges(outline, c) {
+			return outline.Contains(c)
+		}
+	}
+	return false
+}
+
+// onEdges returns whether u lies on any of the edges of the polygon.
+func onEdges(p Polygon, u Vec) bool {
+	for _, l := range p.Edges() {
+		if l.Contains(u) {
+			return true
+		}
+	}
+	return false
+}
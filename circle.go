@@ -63,6 +63,15 @@ func (c Circle) Moved(delta Vec) Circle {
 	}
 }
 
+// TransformedBounds returns the smallest Rect containing the Circle projected by the Matrix. A
+// Matrix which scales unevenly or shears turns a Circle into an ellipse, its exact bounds are
+// returned. Use Polygon and Polygon.Transformed to get the transformed outline.
+func (c Circle) TransformedBounds(m Matrix) Rect {
+	center := m.Project(c.Center)
+	half := V(math.Hypot(m[0], m[2]), math.Hypot(m[1], m[3])).Scaled(math.Abs(c.Radius))
+	return Rect{Min: center.Sub(half), Max: center.Add(half)}
+}
+
 // Resized returns the Circle resized by the given delta.  The Circles center is use as the anchor.
 //
 // c := pixel.C(pixel.ZV, 10)
@@ -339,3 +348,15 @@ func (c Circle) IntersectionPoints(l Line) []Vec {
 	}
 	return []Vec{second, first}
 }
+
+// Polygon returns a regular counter-clockwise Polygon with the given number of vertices
+// approximating the Circle. The vertices lie on the circumference, the first one at the angle 0.
+// Fewer than 3 segments are rounded up to 3.
+func (c Circle) Polygon(segments int) Polygon {
+	segments = max(segments, 3)
+	p := make(Polygon, segments)
+	for i := range p {
+		p[i] = c.Center.Add(Unit(2 * math.Pi * float64(i) / float64(segments)).Scaled(c.Radius))
+	}
+	return p
+}
@@ -1,6 +1,10 @@
 package pixel
 
-import "image/color"
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
 
 // RGBA represents an alpha-premultiplied RGBA color with components within range [0, 1].
 //
@@ -65,6 +69,28 @@ func (c RGBA) Scaled(scale float64) RGBA {
 	}
 }
 
+// Lerp interpolates linearly from color c to color d, component-wise in the premultiplied RGB space.
+// Returns c for t = 0 and d for t = 1.
+func (c RGBA) Lerp(d RGBA, t float64) RGBA {
+	return c.Add(d.Sub(c).Scaled(t))
+}
+
+// Hex returns the color in the hexadecimal "#rrggbb" notation, or "#rrggbbaa" if the color is not
+// fully opaque. The components are unpremultiplied and clamped to range [0, 1].
+//
+//	pixel.RGB(1, 0.5, 0).Hex()                       // returns "#ff8000"
+//	pixel.RGB(1, 0.5, 0).Mul(pixel.Alpha(0.5)).Hex() // returns "#ff800080"
+func (c RGBA) Hex() string {
+	r, g, b := c.straight()
+	to8 := func(x float64) uint8 {
+		return uint8(math.Round(Clamp(x, 0, 1) * 0xff))
+	}
+	if a := to8(c.A); a != 0xff {
+		return fmt.Sprintf("#%02x%02x%02x%02x", to8(r), to8(g), to8(b), a)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", to8(r), to8(g), to8(b))
+}
+
 // RGBA returns alpha-premultiplied red, green, blue and alpha components of the RGBA color.
 func (c RGBA) RGBA() (r, g, b, a uint32) {
 	r = uint32(0xffff * c.R)
@@ -0,0 +1,167 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// ParseColor parses a color in one of the CSS notations:
+//
+//	"#f80", "#f808", "#ff8800", "#ff880080"  // hexadecimal, optionally with alpha
+//	"rgb(255, 136, 0)", "rgba(100%, 53%, 0%, 0.5)", "rgb(255 136 0 / 50%)"
+//	"hsl(32, 100%, 50%)", "hsla(32deg, 100%, 50%, 0.5)", "hsl(0.09turn 100% 50% / 0.5)"
+//	"orange", "transparent"                  // named colors, see golang.org/x/image/colornames
+//
+// The parsing is case-insensitive and the components out of their ranges are clamped.
+func ParseColor(s string) (RGBA, error) {
+	c, ok := parseColor(strings.ToLower(strings.TrimSpace(s)))
+	if !ok {
+		return RGBA{}, fmt.Errorf("pixel.ParseColor: invalid color %q", s)
+	}
+	return c, nil
+}
+
+func parseColor(s string) (RGBA, bool) {
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		return parseHexColor(hex)
+	}
+	if name, args, ok := strings.Cut(s, "("); ok {
+		args, ok := strings.CutSuffix(args, ")")
+		if !ok {
+			return RGBA{}, false
+		}
+		return parseColorFunc(strings.TrimSpace(name), args)
+	}
+	if s == "transparent" {
+		return RGBA{}, true
+	}
+	if c, ok := colornames.Map[s]; ok {
+		return ToRGBA(c), true
+	}
+	return RGBA{}, false
+}
+
+func parseHexColor(hex string) (RGBA, bool) {
+	switch len(hex) {
+	case 3, 4:
+		// each digit is repeated: "f80" is "ff8800"
+		var long strings.Builder
+		for i := range hex {
+			long.WriteByte(hex[i])
+			long.WriteByte(hex[i])
+		}
+		hex = long.String()
+	case 6, 8:
+	default:
+		return RGBA{}, false
+	}
+
+	var x [4]float64
+	x[3] = 1
+	for i := 0; i < len(hex)/2; i++ {
+		n, err := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+		if err != nil {
+			return RGBA{}, false
+		}
+		x[i] = float64(n) / 0xff
+	}
+	return premultiplied(x[0], x[1], x[2], x[3]), true
+}
+
+// parseColorFunc parses the arguments of the rgb, rgba, hsl and hsla color functions, either
+// separated by commas, or by spaces with the alpha separated by a slash.
+func parseColorFunc(name, args string) (RGBA, bool) {
+	var parts []string
+	if strings.Contains(args, ",") {
+		parts = strings.Split(args, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+	} else {
+		color, alpha, hasAlpha := strings.Cut(args, "/")
+		parts = strings.Fields(color)
+		if hasAlpha {
+			if len(parts) != 3 {
+				return RGBA{}, false
+			}
+			parts = append(parts, strings.TrimSpace(alpha))
+		}
+	}
+	if len(parts) != 3 && len(parts) != 4 {
+		return RGBA{}, false
+	}
+
+	a := 1.0
+	if len(parts) == 4 {
+		var ok bool
+		if a, ok = parseColorComponent(parts[3], 1); !ok {
+			return RGBA{}, false
+		}
+	}
+
+	var c RGBA
+	switch name {
+	case "rgb", "rgba":
+		var x [3]float64
+		for i := range x {
+			var ok bool
+			if x[i], ok = parseColorComponent(parts[i], 255); !ok {
+				return RGBA{}, false
+			}
+		}
+		c = RGB(x[0], x[1], x[2])
+	case "hsl", "hsla":
+		h, ok1 := parseHue(parts[0])
+		s, ok2 := parseColorComponent(parts[1], 100)
+		l, ok3 := parseColorComponent(parts[2], 100)
+		if !ok1 || !ok2 || !ok3 {
+			return RGBA{}, false
+		}
+		c = HSL(h, s, l)
+	default:
+		return RGBA{}, false
+	}
+	return c.Scaled(a), true
+}
+
+// parseColorComponent parses a number within range [0, max] or a percentage and returns it clamped
+// to range [0, 1].
+func parseColorComponent(s string, max float64) (float64, bool) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		s, max = p, 100
+	}
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+		return 0, false
+	}
+	return Clamp(x/max, 0, 1), true
+}
+
+// parseHue parses a hue in degrees, or with one of the deg, rad, grad and turn units.
+func parseHue(s string) (float64, bool) {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"grad", 360.0 / 400},
+		{"deg", 1},
+		{"rad", 180 / math.Pi},
+		{"turn", 360},
+	}
+	scale := 1.0
+	for _, u := range units {
+		if n, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, scale = n, u.scale
+			break
+		}
+	}
+	h, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(h) || math.IsInf(h, 0) {
+		return 0, false
+	}
+	return h * scale, true
+}
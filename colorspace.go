@@ -0,0 +1,255 @@
+package pixel
+
+import "math"
+
+// The conversions in this file work with straight (not alpha-premultiplied) components. Colors are
+// unpremultiplied before converting them to another color space and premultiplied after converting
+// them back. The RGB components of RGBA are assumed to be in the sRGB color space.
+
+// HSV returns a fully opaque RGBA color with the given hue, saturation and value. The hue is in
+// degrees, saturation and value are within range [0, 1].
+func HSV(h, s, v float64) RGBA {
+	h = hue(h) / 60
+	c := v * s
+	r, g, b := hueRGB(h, c)
+	m := v - c
+	return RGB(r+m, g+m, b+m)
+}
+
+// HSL returns a fully opaque RGBA color with the given hue, saturation and lightness. The hue is in
+// degrees, saturation and lightness are within range [0, 1].
+func HSL(h, s, l float64) RGBA {
+	h = hue(h) / 60
+	c := (1 - math.Abs(2*l-1)) * s
+	r, g, b := hueRGB(h, c)
+	m := l - c/2
+	return RGB(r+m, g+m, b+m)
+}
+
+// Lab returns a fully opaque RGBA color with the given CIELAB components, using the D65 white point
+// of sRGB. The lightness l is within range [0, 100]. Colors outside of the sRGB gamut are clamped.
+func Lab(l, a, b float64) RGBA {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	x := labWhite[0] * labFInv(fx)
+	y := labWhite[1] * labFInv(fy)
+	z := labWhite[2] * labFInv(fz)
+	return clampedLinearRGB(
+		3.2404542*x-1.5371385*y-0.4985314*z,
+		-0.9692660*x+1.8760108*y+0.0415560*z,
+		0.0556434*x-0.2040259*y+1.0572252*z,
+	)
+}
+
+// OKLab returns a fully opaque RGBA color with the given Oklab components. The lightness l is within
+// range [0, 1]. Colors outside of the sRGB gamut are clamped.
+//
+// Oklab is a perceptual color space: the same distance between two colors looks like the same
+// difference to the eye, regardless of their hue or lightness.
+func OKLab(l, a, b float64) RGBA {
+	l, m, s := oklabToLMS(l, a, b)
+	return clampedLinearRGB(
+		+4.0767416621*l-3.3077115913*m+0.2309699292*s,
+		-1.2684380046*l+2.6097574011*m-0.3413193965*s,
+		-0.0041960863*l-0.7034186147*m+1.7076147010*s,
+	)
+}
+
+// HSV returns the hue, saturation and value of the color. The hue is in degrees within range
+// [0, 360), saturation and value are within range [0, 1]. The hue of a gray color is 0.
+func (c RGBA) HSV() (h, s, v float64) {
+	r, g, b := c.straight()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	v = max
+	if max > 0 {
+		s = (max - min) / max
+	}
+	return rgbHue(r, g, b, max, min), s, v
+}
+
+// HSL returns the hue, saturation and lightness of the color. The hue is in degrees within range
+// [0, 360), saturation and lightness are within range [0, 1]. The hue of a gray color is 0.
+func (c RGBA) HSL() (h, s, l float64) {
+	r, g, b := c.straight()
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	if d := 1 - math.Abs(2*l-1); d > 0 {
+		s = (max - min) / d
+	}
+	return rgbHue(r, g, b, max, min), s, l
+}
+
+// Lab returns the CIELAB components of the color, using the D65 white point of sRGB. The lightness
+// l is within range [0, 100].
+func (c RGBA) Lab() (l, a, b float64) {
+	r, g, bl := c.straight()
+	r, g, bl = srgbToLinear(r), srgbToLinear(g), srgbToLinear(bl)
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*bl) / labWhite[0]
+	y := (0.2126729*r + 0.7151522*g + 0.0721750*bl) / labWhite[1]
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*bl) / labWhite[2]
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// OKLab returns the Oklab components of the color. The lightness l is within range [0, 1].
+func (c RGBA) OKLab() (l, a, b float64) {
+	r, g, bl := c.straight()
+	r, g, bl = srgbToLinear(r), srgbToLinear(g), srgbToLinear(bl)
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+	return 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc,
+		1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc,
+		0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+}
+
+// Linear converts the color from sRGB to linear RGB, in which the components are proportional to
+// the intensity of the light. Blending and lighting computations are physically correct in linear
+// RGB.
+func (c RGBA) Linear() RGBA {
+	r, g, b := c.straight()
+	return premultiplied(srgbToLinear(r), srgbToLinear(g), srgbToLinear(b), c.A)
+}
+
+// SRGB converts the color from linear RGB back to sRGB. It is the inverse of Linear.
+func (c RGBA) SRGB() RGBA {
+	r, g, b := c.straight()
+	return premultiplied(linearToSRGB(r), linearToSRGB(g), linearToSRGB(b), c.A)
+}
+
+// LerpOKLab interpolates from color c to color d in the Oklab color space. Unlike Lerp, the
+// lightness of the intermediate colors changes evenly, and the intermediate colors don't get
+// muddy, which makes LerpOKLab a good choice for gradients and color transitions.
+//
+// The alpha is interpolated linearly. The components of a fully transparent color are ignored, so
+// fading a color in or out doesn't change its hue.
+func (c RGBA) LerpOKLab(d RGBA, t float64) RGBA {
+	a := c.A + (d.A-c.A)*t
+	switch {
+	case c.A == 0 && d.A == 0:
+		return RGBA{}
+	case c.A == 0:
+		c = d
+	case d.A == 0:
+		d = c
+	}
+	l1, a1, b1 := c.OKLab()
+	l2, a2, b2 := d.OKLab()
+	col := OKLab(l1+(l2-l1)*t, a1+(a2-a1)*t, b1+(b2-b1)*t)
+	return col.Scaled(a)
+}
+
+// straight returns the RGB components of the color divided by its alpha. A fully transparent color
+// is black.
+func (c RGBA) straight() (r, g, b float64) {
+	if c.A == 0 {
+		return 0, 0, 0
+	}
+	return c.R / c.A, c.G / c.A, c.B / c.A
+}
+
+func premultiplied(r, g, b, a float64) RGBA {
+	return RGBA{r * a, g * a, b * a, a}
+}
+
+// hue returns h wrapped to range [0, 360).
+func hue(h float64) float64 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// hueRGB returns the RGB components of a color with the hue h in sextants and the chroma c, without
+// the lightness.
+func hueRGB(h, c float64) (r, g, b float64) {
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	switch {
+	case h < 1:
+		return c, x, 0
+	case h < 2:
+		return x, c, 0
+	case h < 3:
+		return 0, c, x
+	case h < 4:
+		return 0, x, c
+	case h < 5:
+		return x, 0, c
+	default:
+		return c, 0, x
+	}
+}
+
+// rgbHue returns the hue in degrees of the color with the given components, their maximum and
+// minimum.
+func rgbHue(r, g, b, max, min float64) float64 {
+	d := max - min
+	if d == 0 {
+		return 0
+	}
+	var h float64
+	switch max {
+	case r:
+		h = (g - b) / d
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return hue(h * 60)
+}
+
+func srgbToLinear(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(x float64) float64 {
+	if x <= 0.0031308 {
+		return x * 12.92
+	}
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
+
+// clampedLinearRGB returns an opaque color from the linear RGB components clamped to the sRGB
+// gamut.
+func clampedLinearRGB(r, g, b float64) RGBA {
+	return RGB(
+		linearToSRGB(Clamp(r, 0, 1)),
+		linearToSRGB(Clamp(g, 0, 1)),
+		linearToSRGB(Clamp(b, 0, 1)),
+	)
+}
+
+// labWhite is the D65 reference white in the CIE XYZ color space.
+var labWhite = [3]float64{0.95047, 1, 1.08883}
+
+const (
+	labDelta  = 6.0 / 29
+	labDelta3 = labDelta * labDelta * labDelta
+)
+
+func labF(t float64) float64 {
+	if t > labDelta3 {
+		return math.Cbrt(t)
+	}
+	return t/(3*labDelta*labDelta) + 4.0/29
+}
+
+func labFInv(t float64) float64 {
+	if t > labDelta {
+		return t * t * t
+	}
+	return 3 * labDelta * labDelta * (t - 4.0/29)
+}
+
+func oklabToLMS(l, a, b float64) (float64, float64, float64) {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	return lc * lc * lc, mc * mc * mc, sc * sc * sc
+}
@@ -13,8 +13,9 @@ package pixel
 // If Triangles is nil, nothing will be drawn. If Picture is nil, Triangles will be drawn without a
 // Picture.
 //
-// Whenever you change the Triangles, call Dirty to notify Drawer that Triangles changed. You don't
-// need to notify Drawer about a change of the Picture.
+// Whenever you change the Triangles, call Dirty to notify Drawer that Triangles changed. If only a
+// part of the Triangles changed, call DirtyRange instead, so that only that part gets updated in the
+// Targets. You don't need to notify Drawer about a change of the Picture.
 //
 // Note, that Drawer caches the results of MakePicture from Targets it's drawn to for each Picture
 // it's set to. What it means is that using a Drawer with an unbounded number of Pictures leads to a
@@ -34,6 +35,11 @@ type drawerTarget struct {
 	tris  TargetTriangles
 	pics  map[Picture]TargetPicture
 	clean bool
+
+	// the changed range of the Triangles, if not all of them changed
+	ranged     bool
+	rangeStart int
+	rangeEnd   int
 }
 
 func (d *Drawer) lazyInit() {
@@ -50,6 +56,34 @@ func (d *Drawer) Dirty() {
 
 	for _, t := range d.allTargets {
 		t.clean = false
+		t.ranged = false
+	}
+}
+
+// DirtyRange marks the Triangles of this Drawer in range [i, j) as changed. Only this range is
+// updated in the Targets on the next Draw, which is much faster than updating all the Triangles
+// when a small part of many Triangles changes. The Triangles may have been resized, as long as the
+// new and changed ones are in the range.
+//
+// Changes of several calls are merged into a single range covering all of them. TrianglesIndexed
+// are always updated whole, because their indices may refer to any of the vertices, so for them
+// DirtyRange is no cheaper than Dirty.
+func (d *Drawer) DirtyRange(i, j int) {
+	d.lazyInit()
+
+	if i >= j {
+		return
+	}
+	for _, t := range d.allTargets {
+		switch {
+		case t.clean:
+			t.clean = false
+			t.ranged = true
+			t.rangeStart, t.rangeEnd = i, j
+		case t.ranged:
+			t.rangeStart = min(t.rangeStart, i)
+			t.rangeEnd = max(t.rangeEnd, j)
+		}
 	}
 }
 
@@ -79,9 +113,7 @@ func (d *Drawer) Draw(t Target) {
 	}
 
 	if !dt.clean {
-		dt.tris.SetLen(d.Triangles.Len())
-		dt.tris.Update(d.Triangles)
-		dt.clean = true
+		dt.update(d.Triangles)
 	}
 
 	if d.Picture == nil {
@@ -100,3 +132,19 @@ func (d *Drawer) Draw(t Target) {
 
 	pic.Draw(dt.tris)
 }
+
+// update updates the TargetTriangles with the changed Triangles.
+func (dt *drawerTarget) update(t Triangles) {
+	ranged := dt.ranged
+	dt.clean, dt.ranged = true, false
+
+	dt.tris.SetLen(t.Len())
+	if _, ok := t.(TrianglesIndexed); !ranged || ok {
+		dt.tris.Update(t)
+		return
+	}
+	i, j := max(dt.rangeStart, 0), min(dt.rangeEnd, t.Len())
+	if i < j {
+		dt.tris.Slice(i, j).Update(t.Slice(i, j))
+	}
+}
@@ -0,0 +1,106 @@
+package pixel
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+)
+
+// ImageFormat is a file format PictureData can be encoded to.
+type ImageFormat int
+
+// Here's the list of all available image formats.
+const (
+	// ImageFormatPNG is the lossless PNG format, which keeps the transparency.
+	ImageFormatPNG ImageFormat = iota
+	// ImageFormatJPEG is the lossy JPEG format with the quality of 90. JPEG has no transparency, the
+	// transparent parts turn black.
+	ImageFormatJPEG
+	// ImageFormatGIF is the GIF format with at most 256 colors, see PictureData.Paletted.
+	ImageFormatGIF
+)
+
+// String returns the name of the ImageFormat.
+func (f ImageFormat) String() string {
+	switch f {
+	case ImageFormatPNG:
+		return "ImageFormatPNG"
+	case ImageFormatJPEG:
+		return "ImageFormatJPEG"
+	case ImageFormatGIF:
+		return "ImageFormatGIF"
+	}
+	return fmt.Sprintf("ImageFormat(%d)", int(f))
+}
+
+// Encode writes the PictureData to the writer in the given format. The image is oriented top-down,
+// as usual for image files, see Image.
+//
+// Unlike the decoding functions, which leave registering the formats to you, Encode calls the
+// encoders of the standard library directly, so no image format needs to be imported.
+func (pd *PictureData) Encode(w io.Writer, format ImageFormat) error {
+	switch format {
+	case ImageFormatPNG:
+		return png.Encode(w, pd.Image())
+	case ImageFormatJPEG:
+		return jpeg.Encode(w, pd.Image(), &jpeg.Options{Quality: 90})
+	case ImageFormatGIF:
+		return gif.Encode(w, pd.Paletted(256, true), nil)
+	}
+	return fmt.Errorf("(%T).Encode: invalid image format %v", pd, format)
+}
+
+// Save writes the PictureData to a file in the given format, see Encode.
+//
+//	err := win.Screenshot().Save("screenshot.png", pixel.ImageFormatPNG)
+func (pd *PictureData) Save(path string, format ImageFormat) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pd.Encode(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Paletted converts PictureData into an image.Paletted with at most n colors, chosen by Palette.
+// If dither is true, the colors are dithered, see Quantized.
+//
+// The resulting image.Paletted's Bounds will be equivalent of the PictureData's Bounds, and it's
+// oriented top-down, like the result of Image. Paletted panics if n is not within range [1, 256].
+func (pd *PictureData) Paletted(n int, dither bool) *image.Paletted {
+	if n < 1 || n > 256 {
+		panic(fmt.Errorf("(%T).Paletted: invalid number of colors %d", pd, n))
+	}
+	x0, y0, w, h := pd.grid()
+
+	palette := pd.Palette(n)
+	if len(palette) == 0 {
+		palette = []RGBA{{}}
+	}
+	colors := make(color.Palette, len(palette))
+	index := make(map[color.RGBA]uint8, len(palette))
+	for i := len(palette) - 1; i >= 0; i-- {
+		c := toColorRGBA(palette[i])
+		colors[i] = c
+		index[c] = uint8(i)
+	}
+
+	quantized := pd.Quantized(palette, dither)
+	img := image.NewPaletted(image.Rect(x0, y0, x0+w, y0+h), colors)
+	for y := 0; y < h; y++ {
+		// the rows of the image go top-down
+		row := img.Pix[(h-1-y)*img.Stride:]
+		for x := 0; x < w; x++ {
+			row[x] = index[quantized.Pix[y*w+x]]
+		}
+	}
+	return img
+}
@@ -0,0 +1,338 @@
+// Package collide implements collision detection between convex shapes for the Pixel game
+// development library, using the Separating Axis Theorem (SAT).
+//
+// Shapes are created from the geometry types of the pixel package:
+//
+//	player := collide.Circle(pixel.C(pos, 8))
+//	crate := collide.RotatedRect(pixel.R(-16, -16, 16, 16), pixel.IM.Rotated(pixel.ZV, angle).Moved(cratePos))
+//
+//	if m, ok := collide.Collide(player, crate); ok {
+//		// move the crate out of the player
+//		crate = crate.Moved(m.Normal.Scaled(m.Depth))
+//	}
+//
+// Moving shapes can be tested with Sweep, which finds the time of their first contact, so that fast
+// shapes don't pass through each other between two frames.
+package collide
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Shape is a convex shape that can be tested for collisions. Create shapes with Circle, Rect,
+// RotatedRect or Polygon.
+type Shape interface {
+	// Bounds returns the smallest Rect containing the whole Shape.
+	Bounds() pixel.Rect
+
+	// Moved returns the Shape moved by the given vector delta.
+	Moved(delta pixel.Vec) Shape
+
+	// Contains checks whether a vector u is contained within the Shape, including its boundary.
+	Contains(u pixel.Vec) bool
+
+	// project returns the interval the Shape occupies on the given unit axis.
+	project(axis pixel.Vec) (min, max float64)
+}
+
+// Manifold describes how two overlapping shapes collide.
+type Manifold struct {
+	// Normal is the unit direction pointing from the first shape to the second one.
+	Normal pixel.Vec
+
+	// Depth is the penetration depth. Moving the second shape by Normal scaled by Depth (or the
+	// first one by the opposite vector) separates the shapes.
+	Depth float64
+
+	// Contacts are the points where the shapes touch, one or two of them.
+	Contacts []pixel.Vec
+}
+
+// Hit describes the first contact of two moving shapes, as found by Sweep.
+type Hit struct {
+	// Time is the fraction of the movement, in the range [0, 1], after which the shapes touch.
+	Time float64
+
+	// Normal is the unit direction pointing from the first shape to the second one at the time of
+	// the contact.
+	Normal pixel.Vec
+
+	// Contacts are the points where the shapes touch at the time of the contact, one or two of them.
+	Contacts []pixel.Vec
+}
+
+type circle struct {
+	c pixel.Circle
+}
+
+// Circle creates a Shape from a Circle.
+func Circle(c pixel.Circle) Shape {
+	return &circle{c: c.Norm()}
+}
+
+func (c *circle) Bounds() pixel.Rect {
+	return c.c.Bounds()
+}
+
+func (c *circle) Moved(delta pixel.Vec) Shape {
+	return &circle{c: c.c.Moved(delta)}
+}
+
+func (c *circle) Contains(u pixel.Vec) bool {
+	return c.c.Contains(u)
+}
+
+func (c *circle) project(axis pixel.Vec) (min, max float64) {
+	center := c.c.Center.Dot(axis)
+	return center - c.c.Radius, center + c.c.Radius
+}
+
+type polygon struct {
+	// vertices are in counter-clockwise order
+	vertices pixel.Polygon
+	// normals[i] is the outward unit normal of the edge from vertices[i] to vertices[i+1]
+	normals []pixel.Vec
+}
+
+// Rect creates a Shape from a Rect.
+func Rect(r pixel.Rect) Shape {
+	return RotatedRect(r, pixel.IM)
+}
+
+// RotatedRect creates a Shape from a Rect transformed by the Matrix, typically a rotated and moved
+// rectangle. The Matrix must not be degenerate, such as scaling by zero.
+func RotatedRect(r pixel.Rect, m pixel.Matrix) Shape {
+	v := r.Norm().Vertices()
+	return Polygon(pixel.Polygon(v[:]).Transformed(m))
+}
+
+// Polygon creates a Shape from a convex Polygon, in either clockwise or counter-clockwise order.
+//
+// Polygon panics if the Polygon is not convex. Concave polygons can be split into convex parts,
+// each one being a separate Shape.
+func Polygon(p pixel.Polygon) Shape {
+	if !p.IsConvex() {
+		panic(fmt.Errorf("collide.Polygon: %v is not convex", p))
+	}
+	if p.IsClockwise() {
+		p = p.Reversed()
+	}
+	return newPolygon(p)
+}
+
+func newPolygon(vertices pixel.Polygon) *polygon {
+	p := &polygon{vertices: vertices}
+	for _, edge := range vertices.Edges() {
+		d := edge.A.To(edge.B)
+		if d == pixel.ZV {
+			continue
+		}
+		p.normals = append(p.normals, pixel.V(d.Y, -d.X).Unit())
+	}
+	return p
+}
+
+func (p *polygon) Bounds() pixel.Rect {
+	return p.vertices.Bounds()
+}
+
+func (p *polygon) Moved(delta pixel.Vec) Shape {
+	return &polygon{vertices: p.vertices.Moved(delta), normals: p.normals}
+}
+
+func (p *polygon) Contains(u pixel.Vec) bool {
+	return p.vertices.Contains(u)
+}
+
+func (p *polygon) project(axis pixel.Vec) (min, max float64) {
+	min, max = math.Inf(+1), math.Inf(-1)
+	for _, v := range p.vertices {
+		d := v.Dot(axis)
+		min = math.Min(min, d)
+		max = math.Max(max, d)
+	}
+	return min, max
+}
+
+// Collide tests whether the two shapes overlap. If they do, it returns a Manifold describing the
+// collision and true. Shapes which only touch each other do not collide.
+func Collide(a, b Shape) (Manifold, bool) {
+	switch a := a.(type) {
+	case *circle:
+		switch b := b.(type) {
+		case *circle:
+			return circleCircle(a, b)
+		case *polygon:
+			return flipped(polygonCircle(b, a))
+		}
+	case *polygon:
+		switch b := b.(type) {
+		case *circle:
+			return polygonCircle(a, b)
+		case *polygon:
+			return polygonPolygon(a, b)
+		}
+	}
+	panic(fmt.Errorf("collide.Collide: unsupported shapes %T and %T", a, b))
+}
+
+func flipped(m Manifold, ok bool) (Manifold, bool) {
+	m.Normal = m.Normal.Scaled(-1)
+	return m, ok
+}
+
+// overlap returns how far b must be moved along axis (or against it, if the returned sign is
+// negative) to stop overlapping with a on that axis. Non-positive depth means no overlap.
+func overlap(a, b Shape, axis pixel.Vec) (depth, sign float64) {
+	aMin, aMax := a.project(axis)
+	bMin, bMax := b.project(axis)
+	forward, backward := aMax-bMin, bMax-aMin
+	if forward < backward {
+		return forward, +1
+	}
+	return backward, -1
+}
+
+// separation finds the axis of the minimum penetration among the given axes. It returns false if
+// any of the axes separates the shapes.
+func separation(a, b Shape, axes []pixel.Vec, m *Manifold) bool {
+	for _, axis := range axes {
+		depth, sign := overlap(a, b, axis)
+		if depth <= 0 {
+			return false
+		}
+		if depth < m.Depth {
+			m.Depth = depth
+			m.Normal = axis.Scaled(sign)
+		}
+	}
+	return true
+}
+
+func circleCircle(a, b *circle) (Manifold, bool) {
+	d := a.c.Center.To(b.c.Center)
+	depth := a.c.Radius + b.c.Radius - d.Len()
+	if depth <= 0 {
+		return Manifold{}, false
+	}
+
+	normal := pixel.V(0, 1)
+	if d != pixel.ZV {
+		normal = d.Unit()
+	}
+	return Manifold{
+		Normal:   normal,
+		Depth:    depth,
+		Contacts: []pixel.Vec{a.c.Center.Add(normal.Scaled(a.c.Radius - depth/2))},
+	}, true
+}
+
+func polygonCircle(p *polygon, c *circle) (Manifold, bool) {
+	m := Manifold{Depth: math.Inf(+1)}
+	if !separation(p, c, p.normals, &m) {
+		return Manifold{}, false
+	}
+
+	// the axis from the closest vertex to the center separates the circle from the corners
+	closest := p.vertices[0]
+	for _, v := range p.vertices[1:] {
+		if v.To(c.c.Center).SqLen() < closest.To(c.c.Center).SqLen() {
+			closest = v
+		}
+	}
+	if axis := closest.To(c.c.Center); axis != pixel.ZV {
+		if !separation(p, c, []pixel.Vec{axis.Unit()}, &m) {
+			return Manifold{}, false
+		}
+	}
+
+	m.Contacts = []pixel.Vec{c.c.Center.Sub(m.Normal.Scaled(c.c.Radius))}
+	return m, true
+}
+
+func polygonPolygon(a, b *polygon) (Manifold, bool) {
+	m := Manifold{Depth: math.Inf(+1)}
+	if !separation(a, b, a.normals, &m) || !separation(a, b, b.normals, &m) {
+		return Manifold{}, false
+	}
+	m.Contacts = contacts(a, b, m.Normal)
+	return m, true
+}
+
+// contacts finds the contact points of two touching or overlapping polygons, given the collision
+// normal pointing from a to b. It clips the edge of one polygon facing the other (the incident
+// edge) by the edge of the other polygon most perpendicular to the normal (the reference edge).
+func contacts(a, b *polygon, normal pixel.Vec) []pixel.Vec {
+	i, alignA := a.facing(normal)
+	j, alignB := b.facing(normal.Scaled(-1))
+
+	ref, inc := a.edge(i), b.edge(j)
+	refNormal := a.normals[i]
+	if alignB > alignA {
+		ref, inc = b.edge(j), a.edge(i)
+		refNormal = b.normals[j]
+	}
+
+	dir := ref.A.To(ref.B).Unit()
+	points := clip([]pixel.Vec{inc.A, inc.B}, dir, dir.Dot(ref.A))
+	points = clip(points, dir.Scaled(-1), -dir.Dot(ref.B))
+
+	// only keep the points that got behind the reference edge
+	const epsilon = 1e-9
+	face := refNormal.Dot(ref.A)
+	kept := points[:0]
+	for _, p := range points {
+		if refNormal.Dot(p) <= face+epsilon*math.Max(1, math.Abs(face)) {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		// can only happen due to rounding errors, use the deepest point of the incident edge
+		if refNormal.Dot(inc.A) < refNormal.Dot(inc.B) {
+			return []pixel.Vec{inc.A}
+		}
+		return []pixel.Vec{inc.B}
+	}
+	return kept
+}
+
+// facing returns the index of the edge whose normal is the most aligned with the direction, and the
+// dot product of the two.
+func (p *polygon) facing(dir pixel.Vec) (index int, alignment float64) {
+	alignment = math.Inf(-1)
+	for i, n := range p.normals {
+		if d := n.Dot(dir); d > alignment {
+			index, alignment = i, d
+		}
+	}
+	return index, alignment
+}
+
+func (p *polygon) edge(i int) pixel.Line {
+	return pixel.L(p.vertices[i], p.vertices[(i+1)%len(p.vertices)])
+}
+
+// clip returns the part of the segment given by two points, which lies on the side of the line
+// dot(axis, p) = offset that axis points to.
+func clip(points []pixel.Vec, axis pixel.Vec, offset float64) []pixel.Vec {
+	if len(points) < 2 {
+		return points
+	}
+	p1, p2 := points[0], points[1]
+	d1, d2 := axis.Dot(p1)-offset, axis.Dot(p2)-offset
+
+	clipped := make([]pixel.Vec, 0, 2)
+	if d1 >= 0 {
+		clipped = append(clipped, p1)
+	}
+	if d2 >= 0 {
+		clipped = append(clipped, p2)
+	}
+	if d1*d2 < 0 {
+		clipped = append(clipped, pixel.Lerp(p1, p2, d1/(d1-d2)))
+	}
+	return clipped
+}
@@ -0,0 +1,331 @@
+package collide_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/collide"
+)
+
+const epsilon = 1e-9
+
+func eqVec(a, b pixel.Vec) bool {
+	return math.Abs(a.X-b.X) < epsilon && math.Abs(a.Y-b.Y) < epsilon
+}
+
+func eqContacts(got, want []pixel.Vec) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			found = found || eqVec(g, w)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCollide(t *testing.T) {
+	diamond := collide.RotatedRect(pixel.R(-1, -1, 1, 1), pixel.IM.Rotated(pixel.ZV, math.Pi/4))
+
+	tests := []struct {
+		name     string
+		a, b     collide.Shape
+		collides bool
+		want     collide.Manifold
+	}{
+		{
+			name:     "circles apart",
+			a:        collide.Circle(pixel.C(pixel.V(0, 0), 1)),
+			b:        collide.Circle(pixel.C(pixel.V(3, 0), 1)),
+			collides: false,
+		},
+		{
+			name:     "circles touching",
+			a:        collide.Circle(pixel.C(pixel.V(0, 0), 1)),
+			b:        collide.Circle(pixel.C(pixel.V(2, 0), 1)),
+			collides: false,
+		},
+		{
+			name:     "circles overlapping",
+			a:        collide.Circle(pixel.C(pixel.V(0, 0), 2)),
+			b:        collide.Circle(pixel.C(pixel.V(0, 3), 2)),
+			collides: true,
+			want:     collide.Manifold{Normal: pixel.V(0, 1), Depth: 1, Contacts: []pixel.Vec{pixel.V(0, 1.5)}},
+		},
+		{
+			name:     "rects apart",
+			a:        collide.Rect(pixel.R(0, 0, 2, 2)),
+			b:        collide.Rect(pixel.R(3, 0, 5, 2)),
+			collides: false,
+		},
+		{
+			name:     "rects touching",
+			a:        collide.Rect(pixel.R(0, 0, 2, 2)),
+			b:        collide.Rect(pixel.R(2, 0, 4, 2)),
+			collides: false,
+		},
+		{
+			name:     "rects overlapping",
+			a:        collide.Rect(pixel.R(0, 0, 4, 4)),
+			b:        collide.Rect(pixel.R(3, 1, 7, 3)),
+			collides: true,
+			want: collide.Manifold{
+				Normal:   pixel.V(1, 0),
+				Depth:    1,
+				Contacts: []pixel.Vec{pixel.V(3, 1), pixel.V(3, 3)},
+			},
+		},
+		{
+			name:     "rect inside rect",
+			a:        collide.Rect(pixel.R(0, 0, 10, 10)),
+			b:        collide.Rect(pixel.R(1, 4, 3, 6)),
+			collides: true,
+			want: collide.Manifold{
+				Normal:   pixel.V(-1, 0),
+				Depth:    3,
+				Contacts: []pixel.Vec{pixel.V(3, 4), pixel.V(3, 6)},
+			},
+		},
+		{
+			name:     "diamond corner in rect",
+			a:        collide.Rect(pixel.R(-2, 1, 2, 3)),
+			b:        diamond,
+			collides: true,
+			want: collide.Manifold{
+				Normal:   pixel.V(0, -1),
+				Depth:    math.Sqrt2 - 1,
+				Contacts: []pixel.Vec{pixel.V(0, math.Sqrt2)},
+			},
+		},
+		{
+			name:     "diamond next to rect",
+			a:        collide.Rect(pixel.R(-2, 1.5, 2, 3)),
+			b:        diamond,
+			collides: false,
+		},
+		{
+			name:     "circle and rect side",
+			a:        collide.Circle(pixel.C(pixel.V(-1, 1), 2)),
+			b:        collide.Rect(pixel.R(0, 0, 2, 2)),
+			collides: true,
+			want:     collide.Manifold{Normal: pixel.V(1, 0), Depth: 1, Contacts: []pixel.Vec{pixel.V(1, 1)}},
+		},
+		{
+			name:     "rect and circle side",
+			a:        collide.Rect(pixel.R(0, 0, 2, 2)),
+			b:        collide.Circle(pixel.C(pixel.V(-1, 1), 2)),
+			collides: true,
+			want:     collide.Manifold{Normal: pixel.V(-1, 0), Depth: 1, Contacts: []pixel.Vec{pixel.V(1, 1)}},
+		},
+		{
+			name:     "circle near rect corner",
+			a:        collide.Circle(pixel.C(pixel.V(3, 3), 1.2)),
+			b:        collide.Rect(pixel.R(0, 0, 2, 2)),
+			collides: false,
+		},
+		{
+			name:     "circle over rect corner",
+			a:        collide.Circle(pixel.C(pixel.V(3, 3), 2)),
+			b:        collide.Rect(pixel.R(0, 0, 2, 2)),
+			collides: true,
+			want: collide.Manifold{
+				Normal:   pixel.V(-1, -1).Unit(),
+				Depth:    2 - math.Sqrt2,
+				Contacts: []pixel.Vec{pixel.V(3, 3).Add(pixel.V(-1, -1).Unit().Scaled(2))},
+			},
+		},
+		{
+			name:     "triangle and rect",
+			a:        collide.Polygon(pixel.Polygon{pixel.V(0, 0), pixel.V(2, 3), pixel.V(4, 0)}),
+			b:        collide.Rect(pixel.R(1, 2, 3, 5)),
+			collides: true,
+			want: collide.Manifold{
+				Normal:   pixel.V(0, 1),
+				Depth:    1,
+				Contacts: []pixel.Vec{pixel.V(2, 3)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := collide.Collide(tt.a, tt.b)
+			if ok != tt.collides {
+				t.Fatalf("Collide() = %v, %v; want collision: %v", got, ok, tt.collides)
+			}
+			if !ok {
+				return
+			}
+			if !eqVec(got.Normal, tt.want.Normal) {
+				t.Errorf("Collide().Normal = %v, want %v", got.Normal, tt.want.Normal)
+			}
+			if math.Abs(got.Depth-tt.want.Depth) > epsilon {
+				t.Errorf("Collide().Depth = %v, want %v", got.Depth, tt.want.Depth)
+			}
+			if !eqContacts(got.Contacts, tt.want.Contacts) {
+				t.Errorf("Collide().Contacts = %v, want %v", got.Contacts, tt.want.Contacts)
+			}
+
+			// moving b out of a resolves the collision
+			if m, ok := collide.Collide(tt.a, tt.b.Moved(got.Normal.Scaled(got.Depth+epsilon))); ok {
+				t.Errorf("still colliding after resolution: %v", m)
+			}
+		})
+	}
+}
+
+func TestPolygon_Concave(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Polygon() with a concave polygon did not panic")
+		}
+	}()
+	collide.Polygon(pixel.Polygon{pixel.V(0, 0), pixel.V(4, 0), pixel.V(2, 1), pixel.V(2, 4)})
+}
+
+func TestSweep(t *testing.T) {
+	tests := []struct {
+		name string
+		a    collide.Shape
+		va   pixel.Vec
+		b    collide.Shape
+		vb   pixel.Vec
+		hits bool
+		want collide.Hit
+	}{
+		{
+			name: "circle misses circle",
+			a:    collide.Circle(pixel.C(pixel.V(0, 0), 1)),
+			va:   pixel.V(10, 0),
+			b:    collide.Circle(pixel.C(pixel.V(5, 3), 1)),
+			hits: false,
+		},
+		{
+			name: "circle through circle",
+			a:    collide.Circle(pixel.C(pixel.V(0, 0), 1)),
+			va:   pixel.V(10, 0),
+			b:    collide.Circle(pixel.C(pixel.V(5, 0), 1)),
+			hits: true,
+			want: collide.Hit{Time: 0.3, Normal: pixel.V(1, 0), Contacts: []pixel.Vec{pixel.V(4, 0)}},
+		},
+		{
+			name: "both circles moving",
+			a:    collide.Circle(pixel.C(pixel.V(0, 0), 1)),
+			va:   pixel.V(4, 0),
+			b:    collide.Circle(pixel.C(pixel.V(10, 0), 1)),
+			vb:   pixel.V(-4, 0),
+			hits: true,
+			want: collide.Hit{Time: 1, Normal: pixel.V(1, 0), Contacts: []pixel.Vec{pixel.V(5, 0)}},
+		},
+		{
+			name: "circle too slow",
+			a:    collide.Circle(pixel.C(pixel.V(0, 0), 1)),
+			va:   pixel.V(2, 0),
+			b:    collide.Circle(pixel.C(pixel.V(5, 0), 1)),
+			hits: false,
+		},
+		{
+			name: "circle through thin wall",
+			a:    collide.Circle(pixel.C(pixel.V(0, 5), 1)),
+			va:   pixel.V(20, 0),
+			b:    collide.Rect(pixel.R(9, 0, 10, 10)),
+			hits: true,
+			want: collide.Hit{Time: 0.4, Normal: pixel.V(1, 0), Contacts: []pixel.Vec{pixel.V(9, 5)}},
+		},
+		{
+			name: "circle against wall corner",
+			a:    collide.Circle(pixel.C(pixel.V(0, 10.6), 1)),
+			va:   pixel.V(20, 0),
+			b:    collide.Rect(pixel.R(9, 0, 10, 10)),
+			hits: true,
+			want: collide.Hit{Time: 0.41, Normal: pixel.V(0.8, -0.6), Contacts: []pixel.Vec{pixel.V(9, 10)}},
+		},
+		{
+			name: "wall against circle",
+			a:    collide.Rect(pixel.R(9, 0, 10, 10)),
+			va:   pixel.V(-20, 0),
+			b:    collide.Circle(pixel.C(pixel.V(0, 5), 1)),
+			hits: true,
+			want: collide.Hit{Time: 0.4, Normal: pixel.V(-1, 0), Contacts: []pixel.Vec{pixel.V(1, 5)}},
+		},
+		{
+			name: "rect through thin wall",
+			a:    collide.Rect(pixel.R(0, 4, 2, 6)),
+			va:   pixel.V(20, 0),
+			b:    collide.Rect(pixel.R(9, 0, 10, 10)),
+			hits: true,
+			want: collide.Hit{Time: 0.35, Normal: pixel.V(1, 0), Contacts: []pixel.Vec{pixel.V(9, 4), pixel.V(9, 6)}},
+		},
+		{
+			name: "rect passes wall",
+			a:    collide.Rect(pixel.R(0, 11, 2, 13)),
+			va:   pixel.V(20, 0),
+			b:    collide.Rect(pixel.R(9, 0, 10, 10)),
+			hits: false,
+		},
+		{
+			name: "diamond onto moving floor",
+			a:    collide.RotatedRect(pixel.R(-1, -1, 1, 1), pixel.IM.Rotated(pixel.ZV, math.Pi/4).Moved(pixel.V(0, 10))),
+			va:   pixel.V(0, -10),
+			b:    collide.Rect(pixel.R(-5, -1, 5, 0)),
+			vb:   pixel.V(0, 10),
+			hits: true,
+			want: collide.Hit{
+				Time:     (10 - math.Sqrt2) / 20,
+				Normal:   pixel.V(0, -1),
+				Contacts: []pixel.Vec{pixel.V(0, (10-math.Sqrt2)/2)},
+			},
+		},
+		{
+			name: "already overlapping",
+			a:    collide.Rect(pixel.R(0, 0, 4, 4)),
+			va:   pixel.V(20, 0),
+			b:    collide.Rect(pixel.R(3, 1, 7, 3)),
+			hits: true,
+			want: collide.Hit{Time: 0, Normal: pixel.V(1, 0), Contacts: []pixel.Vec{pixel.V(3, 1), pixel.V(3, 3)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := collide.Sweep(tt.a, tt.va, tt.b, tt.vb)
+			if ok != tt.hits {
+				t.Fatalf("Sweep() = %v, %v; want hit: %v", got, ok, tt.hits)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(got.Time-tt.want.Time) > epsilon {
+				t.Errorf("Sweep().Time = %v, want %v", got.Time, tt.want.Time)
+			}
+			if !eqVec(got.Normal, tt.want.Normal) {
+				t.Errorf("Sweep().Normal = %v, want %v", got.Normal, tt.want.Normal)
+			}
+			if !eqContacts(got.Contacts, tt.want.Contacts) {
+				t.Errorf("Sweep().Contacts = %v, want %v", got.Contacts, tt.want.Contacts)
+			}
+		})
+	}
+}
+
+func BenchmarkCollide(b *testing.B) {
+	shapes := []struct {
+		name string
+		a, b collide.Shape
+	}{
+		{"circles", collide.Circle(pixel.C(pixel.V(0, 0), 2)), collide.Circle(pixel.C(pixel.V(0, 3), 2))},
+		{"rects", collide.Rect(pixel.R(0, 0, 4, 4)), collide.Rect(pixel.R(3, 1, 7, 3))},
+		{"circle and rect", collide.Circle(pixel.C(pixel.V(-1, 1), 2)), collide.Rect(pixel.R(0, 0, 2, 2))},
+	}
+	for _, s := range shapes {
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				collide.Collide(s.a, s.b)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package collide
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Sweep tests whether the shape a moving by the vector va and the shape b moving by the vector vb
+// collide during their movement. If they do, it returns the Hit describing their first contact and
+// true.
+//
+// If the shapes already overlap, the returned Hit has zero Time and the Normal and Contacts of the
+// Manifold returned by Collide.
+//
+//	hit, ok := collide.Sweep(bullet, bulletVel.Scaled(dt), wall, pixel.ZV)
+//	if ok {
+//		bulletPos = bulletPos.Add(bulletVel.Scaled(dt * hit.Time))
+//	}
+func Sweep(a Shape, va pixel.Vec, b Shape, vb pixel.Vec) (Hit, bool) {
+	if m, ok := Collide(a, b); ok {
+		return Hit{Normal: m.Normal, Contacts: m.Contacts}, true
+	}
+
+	// move a relative to b, which stays still
+	v := va.Sub(vb)
+
+	var (
+		hit Hit
+		ok  bool
+		// the velocity of the shape which stayed still while computing the hit
+		still = vb
+	)
+	switch a := a.(type) {
+	case *circle:
+		switch b := b.(type) {
+		case *circle:
+			hit, ok = sweepCircleCircle(a, b, v)
+		case *polygon:
+			hit, ok = sweepCirclePolygon(a, b, v)
+		}
+	case *polygon:
+		switch b := b.(type) {
+		case *circle:
+			hit, ok = sweepCirclePolygon(b, a, v.Scaled(-1))
+			hit.Normal = hit.Normal.Scaled(-1)
+			still = va
+		case *polygon:
+			hit, ok = sweepPolygonPolygon(a, b, v)
+		}
+	default:
+		panic(fmt.Errorf("collide.Sweep: unsupported shapes %T and %T", a, b))
+	}
+	if !ok {
+		return Hit{}, false
+	}
+
+	// the contacts were found relative to the shape which stayed still, move them along with it
+	for i := range hit.Contacts {
+		hit.Contacts[i] = hit.Contacts[i].Add(still.Scaled(hit.Time))
+	}
+	return hit, true
+}
+
+func sweepCircleCircle(a, b *circle, v pixel.Vec) (Hit, bool) {
+	t, ok := rayCircle(a.c.Center, v, pixel.C(b.c.Center, a.c.Radius+b.c.Radius))
+	if !ok {
+		return Hit{}, false
+	}
+	center := a.c.Center.Add(v.Scaled(t))
+	normal := center.To(b.c.Center).Unit()
+	return Hit{
+		Time:     t,
+		Normal:   normal,
+		Contacts: []pixel.Vec{center.Add(normal.Scaled(a.c.Radius))},
+	}, true
+}
+
+// sweepCirclePolygon casts the center of the circle against the polygon grown by the radius, which
+// has the edges moved outwards and rounded corners.
+func sweepCirclePolygon(c *circle, p *polygon, v pixel.Vec) (Hit, bool) {
+	r := c.c.Radius
+	hit := Hit{Time: math.Inf(+1)}
+
+	for i, n := range p.normals {
+		speed := n.Dot(v)
+		if speed >= 0 {
+			continue
+		}
+		edge := p.edge(i)
+		t := (n.Dot(edge.A) + r - n.Dot(c.c.Center)) / speed
+		if t < 0 || t > 1 || t >= hit.Time {
+			continue
+		}
+		contact := c.c.Center.Add(v.Scaled(t)).Sub(n.Scaled(r))
+		dir := edge.A.To(edge.B)
+		if along := edge.A.To(contact).Dot(dir); along < 0 || along > dir.SqLen() {
+			continue
+		}
+		hit = Hit{Time: t, Normal: n.Scaled(-1), Contacts: []pixel.Vec{contact}}
+	}
+
+	for _, vertex := range p.vertices {
+		t, ok := rayCircle(c.c.Center, v, pixel.C(vertex, r))
+		if !ok || t >= hit.Time {
+			continue
+		}
+		center := c.c.Center.Add(v.Scaled(t))
+		hit = Hit{Time: t, Normal: center.To(vertex).Unit(), Contacts: []pixel.Vec{vertex}}
+	}
+
+	return hit, !math.IsInf(hit.Time, +1)
+}
+
+// sweepPolygonPolygon finds the first time when the projections of the polygons overlap on all the
+// separating axes.
+func sweepPolygonPolygon(a, b *polygon, v pixel.Vec) (Hit, bool) {
+	first, last := math.Inf(-1), 1.0
+	var normal pixel.Vec
+
+	axes := append(append([]pixel.Vec{}, a.normals...), b.normals...)
+	for _, axis := range axes {
+		aMin, aMax := a.project(axis)
+		bMin, bMax := b.project(axis)
+		speed := axis.Dot(v)
+
+		switch {
+		case aMax <= bMin:
+			// a is behind b on this axis
+			if speed <= 0 {
+				return Hit{}, false
+			}
+			if t := (bMin - aMax) / speed; t > first {
+				first, normal = t, axis
+			}
+			last = math.Min(last, (bMax-aMin)/speed)
+		case bMax <= aMin:
+			// a is in front of b on this axis
+			if speed >= 0 {
+				return Hit{}, false
+			}
+			if t := (bMax - aMin) / speed; t > first {
+				first, normal = t, axis.Scaled(-1)
+			}
+			last = math.Min(last, (bMin-aMax)/speed)
+		case speed > 0:
+			last = math.Min(last, (bMax-aMin)/speed)
+		case speed < 0:
+			last = math.Min(last, (bMin-aMax)/speed)
+		}
+
+		if first > last {
+			return Hit{}, false
+		}
+	}
+
+	if first < 0 || first > 1 {
+		return Hit{}, false
+	}
+
+	moved := a.Moved(v.Scaled(first)).(*polygon)
+	return Hit{
+		Time:     first,
+		Normal:   normal,
+		Contacts: contacts(moved, b, normal),
+	}, true
+}
+
+// rayCircle returns the time in [0, 1] when the point moving from origin by the vector v enters
+// the circle.
+func rayCircle(origin, v pixel.Vec, c pixel.Circle) (float64, bool) {
+	d := c.Center.To(origin)
+	a := v.SqLen()
+	b := d.Dot(v)
+	k := d.SqLen() - c.Radius*c.Radius
+	if a == 0 || b >= 0 {
+		// not moving, or moving away from the circle
+		return 0, false
+	}
+	discriminant := b*b - a*k
+	if discriminant < 0 {
+		return 0, false
+	}
+	t := (-b - math.Sqrt(discriminant)) / a
+	if t < 0 || t > 1 {
+		return 0, false
+	}
+	return t, true
+}
@@ -52,6 +52,7 @@ type IMDraw struct {
 	EndShape  EndShape
 
 	points []point
+	holes  []int
 	pool   [][]point
 	matrix pixel.Matrix
 	mask   pixel.RGBA
@@ -111,6 +112,7 @@ func (imd *IMDraw) Clear() {
 // This does not affect matrix and color mask set by SetMatrix and SetColorMask.
 func (imd *IMDraw) Reset() {
 	imd.points = imd.points[:0]
+	imd.holes = imd.holes[:0]
 	imd.Color = pixel.Alpha(1)
 	imd.Picture = pixel.ZV
 	imd.Intensity = 0
@@ -191,18 +193,44 @@ func (imd *IMDraw) Rectangle(thickness float64) {
 	}
 }
 
-// Polygon draws a polygon from the Pushed points. If the thickness is 0, the convex polygon will be
-// filled. Otherwise, an outline of the specified thickness will be drawn. The outline does not have
-// to be convex.
+// Hole starts a hole of a polygon. The points Pushed after calling Hole, until the next call to
+// Hole, make up the outline of the hole. Holes only apply to the next Polygon, other shapes ignore
+// them. The outline of the polygon must be Pushed first: if Hole is called before Pushing any
+// points, the Polygon has no outline and draws nothing.
 //
-// Note, that the filled polygon does not have to be strictly convex. The way it's drawn is that a
-// triangle is drawn between each two adjacent points and the first Pushed point. You can use this
-// property to draw certain kinds of concave polygons.
+//	imd.Push(pixel.V(0, 0), pixel.V(100, 0), pixel.V(100, 100), pixel.V(0, 100))
+//	imd.Hole()
+//	imd.Push(pixel.V(40, 40), pixel.V(60, 40), pixel.V(60, 60), pixel.V(40, 60))
+//	imd.Polygon(0) // draws a square with a square hole in the middle
+func (imd *IMDraw) Hole() {
+	imd.holes = append(imd.holes, len(imd.points))
+}
+
+// Polygon draws a polygon from the Pushed points, with holes started by calling Hole. If the
+// thickness is 0, the polygon will be filled. Otherwise, outlines of the polygon and of its holes of
+// the specified thickness will be drawn.
+//
+// The polygon does not have to be convex, but it must not intersect itself. Holes must lie inside of
+// the polygon and must not overlap.
 func (imd *IMDraw) Polygon(thickness float64) {
 	if thickness == 0 {
-		imd.fillPolygon()
+		imd.fillTriangulatedPolygon()
 	} else {
-		imd.polyline(thickness, true)
+		imd.outlinePolygon(thickness)
+	}
+}
+
+// PolygonSet draws all polygons of the PolygonSet, such as a result of a boolean operation on
+// polygons, like Polygon does. The points of the polygons are Pushed with the current point
+// properties, previously Pushed points are drawn as a part of the first polygon.
+func (imd *IMDraw) PolygonSet(set pixel.PolygonSet, thickness float64) {
+	for _, cp := range set {
+		imd.Push(cp.Outline...)
+		for _, hole := range cp.Holes {
+			imd.Hole()
+			imd.Push(hole...)
+		}
+		imd.Polygon(thickness)
 	}
 }
 
@@ -261,6 +289,7 @@ func (imd *IMDraw) EllipseArc(radius pixel.Vec, low, high, thickness float64) {
 
 func (imd *IMDraw) getAndClearPoints() []point {
 	points := imd.points
+	imd.holes = imd.holes[:0]
 	// use one of the existing pools so we don't reallocate as often
 	if len(imd.pool) > 0 {
 		pos := len(imd.pool) - 1
@@ -375,6 +404,75 @@ func (imd *IMDraw) fillPolygon() {
 	imd.restorePoints(points)
 }
 
+// contours returns the Pushed points split into the outline and the holes of a polygon.
+func (imd *IMDraw) contours() [][]point {
+	if len(imd.holes) > 0 && imd.holes[0] == 0 {
+		// Hole was called before Pushing the outline
+		return nil
+	}
+	var contours [][]point
+	start := 0
+	// copy the holes, appending to them could write to their spare capacity
+	ends := append(append([]int(nil), imd.holes...), len(imd.points))
+	for _, end := range ends {
+		if end > start {
+			contours = append(contours, imd.points[start:end])
+		}
+		start = end
+	}
+	return contours
+}
+
+func (imd *IMDraw) fillTriangulatedPolygon() {
+	contours := imd.contours()
+	points := imd.getAndClearPoints()
+
+	if len(contours) == 0 || len(contours[0]) < 3 {
+		imd.restorePoints(points)
+		return
+	}
+
+	polygons := make([]pixel.Polygon, len(contours))
+	for i, contour := range contours {
+		polygons[i] = make(pixel.Polygon, len(contour))
+		for j, pt := range contour {
+			polygons[i][j] = pt.pos
+		}
+	}
+	// the contours are consecutive in points, so the indices of the triangulation match them
+	indices := pixel.Triangulate(polygons[0], polygons[1:]...)
+
+	off := imd.tri.Len()
+	imd.tri.SetLen(imd.tri.Len() + len(indices))
+
+	for i, p := range indices {
+		tri := &(*imd.tri)[off+i]
+		tri.Position = points[p].pos
+		tri.Color = points[p].col
+		tri.Picture = points[p].pic
+		tri.Intensity = points[p].in
+	}
+
+	imd.applyMatrixAndMask(off)
+	imd.batch.Dirty()
+
+	imd.restorePoints(points)
+}
+
+func (imd *IMDraw) outlinePolygon(thickness float64) {
+	contours := imd.contours()
+	points := imd.getAndClearPoints()
+
+	for _, contour := range contours {
+		for _, pt := range contour {
+			imd.pushPt(pt.pos, pt)
+		}
+		imd.polyline(thickness, true)
+	}
+
+	imd.restorePoints(points)
+}
+
 func (imd *IMDraw) fillEllipseArc(radius pixel.Vec, low, high float64) {
 	points := imd.getAndClearPoints()
 
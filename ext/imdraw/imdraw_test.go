@@ -7,6 +7,7 @@ import (
 
 	"github.com/gopxl/pixel/v2"
 	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/gopxl/pixel/v2/pixeltest"
 )
 
 func BenchmarkPush(b *testing.B) {
@@ -94,3 +95,92 @@ func BenchmarkEllipseOutline(b *testing.B) {
 		})
 	}
 }
+
+func TestDrawGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		draw func(imd *imdraw.IMDraw)
+	}{
+		{"rectangle", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(1, 0, 0)
+			imd.Push(pixel.V(8, 8), pixel.V(40, 24))
+			imd.Rectangle(0)
+			imd.Color = pixel.RGB(0, 0, 1)
+			imd.Push(pixel.V(24, 16), pixel.V(56, 56))
+			imd.Rectangle(3)
+		}},
+		{"circle", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(0, 1, 0)
+			imd.Push(pixel.V(32, 32))
+			imd.Circle(24, 0)
+			imd.Color = pixel.RGB(1, 1, 1)
+			imd.Push(pixel.V(32, 32))
+			imd.Circle(16, 2)
+		}},
+		{"line", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(1, 1, 0)
+			imd.EndShape = imdraw.RoundEndShape
+			imd.Push(pixel.V(8, 8), pixel.V(32, 56), pixel.V(56, 8))
+			imd.Line(6)
+		}},
+		{"polygon", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(1, 0, 0)
+			imd.Push(pixel.V(8, 8))
+			imd.Color = pixel.RGB(0, 1, 0)
+			imd.Push(pixel.V(56, 16))
+			imd.Color = pixel.RGB(0, 0, 1)
+			imd.Push(pixel.V(32, 56))
+			imd.Polygon(0)
+		}},
+		{"concave_polygon_with_hole", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(1, 0.5, 0)
+			imd.Push(
+				pixel.V(4, 4), pixel.V(60, 4), pixel.V(60, 60), pixel.V(32, 36), pixel.V(4, 60),
+			)
+			imd.Hole()
+			imd.Push(pixel.V(24, 12), pixel.V(40, 12), pixel.V(40, 24), pixel.V(24, 24))
+			imd.Polygon(0)
+		}},
+		{"polygon_outline_with_hole", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(0, 1, 1)
+			imd.Push(pixel.V(8, 8), pixel.V(56, 8), pixel.V(56, 56), pixel.V(8, 56))
+			imd.Hole()
+			imd.Push(pixel.V(24, 24), pixel.V(40, 24), pixel.V(40, 40), pixel.V(24, 40))
+			imd.Polygon(2)
+		}},
+		{"polygon_set", func(imd *imdraw.IMDraw) {
+			imd.Color = pixel.RGB(1, 0, 1)
+			square := pixel.PolygonSet{{Outline: pixel.R(6, 6, 42, 42).Polygon()}}
+			circle := pixel.PolygonSet{{Outline: pixel.C(pixel.V(40, 40), 18).Polygon(32)}}
+			imd.PolygonSet(square.Xor(circle), 0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pixeltest.AssertGolden(t, tt.name, pixel.R(0, 0, 64, 64), func(target pixel.Target) {
+				imd := imdraw.New(nil)
+				tt.draw(imd)
+				imd.Draw(target)
+			}, pixeltest.Options{})
+		})
+	}
+}
+
+func TestIMDraw_Hole(t *testing.T) {
+	empty := pixeltest.Render(pixel.R(0, 0, 64, 64), func(pixel.Target) {})
+	for _, thickness := range []float64{0, 2} {
+		// a Hole before the outline leaves the polygon without an outline, instead of turning the
+		// first hole into the outline
+		got := pixeltest.Render(pixel.R(0, 0, 64, 64), func(target pixel.Target) {
+			imd := imdraw.New(nil)
+			imd.Hole()
+			imd.Push(pixel.V(24, 24), pixel.V(40, 24), pixel.V(40, 40), pixel.V(24, 40))
+			imd.Polygon(thickness)
+			imd.Draw(target)
+		})
+		if diff, _ := pixeltest.Compare(got, empty, 0); diff != 0 {
+			t.Errorf("Polygon(%v) with a Hole before the outline drew %d pixels", thickness, diff)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package recorder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// pngSignature starts every PNG file.
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// encodeAPNG writes the frames as an APNG, which is a PNG with the first frame as its image and
+// the other frames in additional chunks, see https://wiki.mozilla.org/APNG_Specification.
+//
+// The image/png package has no control over the color type of the encoded images, which has to be
+// the same for all the frames, so the chunks are written here directly. All the frames are 8-bit
+// RGBA images.
+func (r *Recorder) encodeAPNG(w io.Writer) error {
+	size := frameSize(r.frames[0])
+	enc := &apngEncoder{w: w}
+
+	enc.write([]byte(pngSignature))
+
+	var ihdr [13]byte
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(size.X))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(size.Y))
+	ihdr[8] = 8  // bit depth
+	ihdr[9] = 6  // color type: RGBA
+	ihdr[10] = 0 // compression method: deflate
+	ihdr[11] = 0 // filter method: adaptive
+	ihdr[12] = 0 // interlace method: none
+	enc.chunk("IHDR", ihdr[:])
+
+	var actl [8]byte
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(r.frames)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	enc.chunk("acTL", actl[:])
+
+	// the delays are fractions of a second with at most 16-bit numerators and denominators
+	delay := uint16(math.Min(math.Round(r.delay.Seconds()*1000), math.MaxUint16))
+
+	for i, frame := range r.frames {
+		var fctl [26]byte
+		binary.BigEndian.PutUint32(fctl[0:], enc.seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+		binary.BigEndian.PutUint32(fctl[12:], 0) // x offset
+		binary.BigEndian.PutUint32(fctl[16:], 0) // y offset
+		binary.BigEndian.PutUint16(fctl[20:], delay)
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		fctl[24] = 0 // dispose: none
+		fctl[25] = 0 // blend: replace the previous frame, including the transparent pixels
+		enc.seq++
+		enc.chunk("fcTL", fctl[:])
+
+		data, err := compressFrame(frame.Unpremultiplied().Image().Pix, size.X, size.Y)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			enc.chunk("IDAT", data)
+			continue
+		}
+		fdat := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, enc.seq)
+		copy(fdat[4:], data)
+		enc.seq++
+		enc.chunk("fdAT", fdat)
+	}
+
+	enc.chunk("IEND", nil)
+	return enc.err
+}
+
+// compressFrame returns the zlib-compressed scanlines of the straight RGBA pixels, top row first.
+func compressFrame(pix []byte, w, h int) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	stride := 4 * w
+	line := make([]byte, 1+stride)
+	for y := 0; y < h; y++ {
+		row := pix[y*stride : (y+1)*stride]
+		// the Sub filter stores the differences from the pixels on the left, which compresses
+		// the smooth areas common in games well
+		line[0] = 1
+		for i := range row {
+			if i < 4 {
+				line[1+i] = row[i]
+			} else {
+				line[1+i] = row[i] - row[i-4]
+			}
+		}
+		if _, err := zw.Write(line); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// apngEncoder writes PNG chunks, keeping the first error and the sequence number of the animation
+// chunks.
+type apngEncoder struct {
+	w   io.Writer
+	seq uint32
+	err error
+}
+
+func (e *apngEncoder) write(b []byte) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(b)
+}
+
+// chunk writes a PNG chunk: the length of the data, the type, the data and the CRC of the type and
+// the data.
+func (e *apngEncoder) chunk(typ string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], typ)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	e.write(header[:])
+	e.write(data)
+	e.write(footer[:])
+}
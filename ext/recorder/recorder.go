@@ -0,0 +1,192 @@
+// Package recorder captures frames drawn by Pixel and saves them as an animated GIF or APNG, for
+// example to attach a short recording to a bug report.
+package recorder
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Format is a file format of the animation.
+type Format int
+
+// Here's the list of all available formats.
+const (
+	// GIF is the animated GIF format. Each frame has its own palette of at most 256 colors and
+	// only fully transparent pixels stay transparent. The delays are rounded to hundredths of a
+	// second.
+	GIF Format = iota
+	// APNG is the animated PNG format, which keeps all the colors and the transparency, at the
+	// cost of larger files.
+	APNG
+)
+
+// String returns the name of the Format.
+func (f Format) String() string {
+	switch f {
+	case GIF:
+		return "GIF"
+	case APNG:
+		return "APNG"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// Recorder collects up to a given number of frames and encodes them into an animation. The frames
+// are kept in memory until the animation is encoded.
+//
+//	rec := recorder.New(120, time.Second/60)
+//	for !win.Closed() {
+//		// draw the frame ...
+//		if rec.CaptureFrom(win.Canvas()) {
+//			rec.Save("bug.gif", recorder.GIF)
+//		}
+//		win.Update()
+//	}
+//
+// All the frames must have the same size, like the frames of a Window which isn't resized during
+// the recording.
+type Recorder struct {
+	frames []*pixel.PictureData
+	limit  int
+	delay  time.Duration
+}
+
+// New creates a new Recorder which captures at most n frames, shown for the delay each in the
+// animation. If n is not positive, the number of frames is not limited.
+func New(n int, delay time.Duration) *Recorder {
+	return &Recorder{limit: n, delay: delay}
+}
+
+// Capture adds the frame to the animation, unless the Recorder already has all the frames it
+// should capture. The Recorder keeps the frame, so don't modify it afterwards. Screenshot methods
+// of the Targets return a new PictureData every time, so their results can be passed directly.
+//
+// Capture returns true when the frame completed the animation, so that it can be saved. It only
+// returns true once, later frames are ignored.
+func (r *Recorder) Capture(frame *pixel.PictureData) bool {
+	if r.Done() {
+		return false
+	}
+	r.frames = append(r.frames, frame)
+	return r.Done()
+}
+
+// Source is a Target whose content can be read back, such as the Canvases of the opengl and
+// software backends. The Canvas of a Window is its Canvas method.
+type Source interface {
+	pixel.Target
+	Bounds() pixel.Rect
+
+	// Pixels returns an alpha-premultiplied RGBA sequence of the content, starting with the
+	// bottom row.
+	Pixels() []uint8
+}
+
+// CaptureFrom reads the content of the Source and adds it to the animation like Capture does. The
+// content isn't read if the Recorder already has all the frames it should capture, so there's no
+// cost of calling CaptureFrom every frame after the recording is done.
+//
+// CaptureFrom panics if the Source returns fewer pixels than its Bounds cover.
+func (r *Recorder) CaptureFrom(src Source) bool {
+	if r.Done() {
+		return false
+	}
+	pixels := src.Pixels()
+	frame := pixel.MakePictureData(src.Bounds())
+	if len(pixels) < 4*len(frame.Pix) {
+		panic(fmt.Errorf("(%T).CaptureFrom: %d pixels don't cover the bounds %v", r, len(pixels)/4, src.Bounds()))
+	}
+	for i := range frame.Pix {
+		frame.Pix[i] = color.RGBA{
+			R: pixels[i*4+0],
+			G: pixels[i*4+1],
+			B: pixels[i*4+2],
+			A: pixels[i*4+3],
+		}
+	}
+	return r.Capture(frame)
+}
+
+// Done returns whether the Recorder has captured all the frames it should capture.
+func (r *Recorder) Done() bool {
+	return r.limit > 0 && len(r.frames) >= r.limit
+}
+
+// Len returns the number of captured frames.
+func (r *Recorder) Len() int {
+	return len(r.frames)
+}
+
+// Reset removes all the captured frames, so that the Recorder can capture a new animation.
+func (r *Recorder) Reset() {
+	r.frames = nil
+}
+
+// Encode writes the captured frames to the writer as an animation in the given format, which loops
+// forever. Encode returns an error if there are no frames or the frames differ in size.
+func (r *Recorder) Encode(w io.Writer, format Format) error {
+	if len(r.frames) == 0 {
+		return fmt.Errorf("(%T).Encode: no frames captured", r)
+	}
+	size := frameSize(r.frames[0])
+	for i, frame := range r.frames {
+		if frameSize(frame) != size {
+			return fmt.Errorf("(%T).Encode: frame %d is %v, want %v like the first frame", r, i, frameSize(frame), size)
+		}
+	}
+
+	switch format {
+	case GIF:
+		return r.encodeGIF(w)
+	case APNG:
+		return r.encodeAPNG(w)
+	}
+	return fmt.Errorf("(%T).Encode: invalid format %v", r, format)
+}
+
+// Save writes the captured frames to a file as an animation in the given format, see Encode.
+func (r *Recorder) Save(path string, format Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Encode(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r *Recorder) encodeGIF(w io.Writer) error {
+	// the delays are in hundredths of a second
+	delay := int(math.Round(r.delay.Seconds() * 100))
+	anim := &gif.GIF{}
+	for _, frame := range r.frames {
+		// dithering changes between the frames would flicker, so the frames are not dithered
+		img := frame.Paletted(256, false)
+		// all the frames cover the whole animation, which starts at the origin
+		img.Rect = img.Rect.Sub(img.Rect.Min)
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+		// the transparent parts of a frame show the background, not the previous frame
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// frameSize returns the size of the frame in pixels.
+func frameSize(frame *pixel.PictureData) image.Point {
+	if frame.Stride == 0 {
+		return image.Point{}
+	}
+	return image.Pt(frame.Stride, len(frame.Pix)/frame.Stride)
+}
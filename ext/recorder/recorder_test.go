@@ -0,0 +1,234 @@
+package recorder_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/recorder"
+)
+
+// frame returns a 4x3 frame, filled with the color, except for its transparent bottom-left pixel.
+func frame(c color.RGBA) *pixel.PictureData {
+	pd := pixel.MakePictureData(pixel.R(0, 0, 4, 3))
+	for i := range pd.Pix {
+		pd.Pix[i] = c
+	}
+	pd.Pix[0] = color.RGBA{}
+	return pd
+}
+
+var colors = []color.RGBA{
+	{255, 0, 0, 255},
+	{0, 128, 0, 255},
+	{0, 0, 64, 128},
+}
+
+func record(t *testing.T) *recorder.Recorder {
+	rec := recorder.New(len(colors), 50*time.Millisecond)
+	for i, c := range colors {
+		if done := rec.Capture(frame(c)); done != (i == len(colors)-1) {
+			t.Fatalf("Capture() of frame %d = %v", i, done)
+		}
+	}
+	if rec.Capture(frame(colors[0])) {
+		t.Errorf("Capture() after the last frame = true")
+	}
+	if rec.Len() != len(colors) || !rec.Done() {
+		t.Fatalf("Len() = %d, Done() = %v, want %d frames captured", rec.Len(), rec.Done(), len(colors))
+	}
+	return rec
+}
+
+func TestRecorder_GIF(t *testing.T) {
+	rec := record(t)
+	var buf bytes.Buffer
+	if err := rec.Encode(&buf, recorder.GIF); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll() error = %v", err)
+	}
+	if len(anim.Image) != len(colors) {
+		t.Fatalf("got %d frames, want %d", len(anim.Image), len(colors))
+	}
+	for i, img := range anim.Image {
+		if anim.Delay[i] != 5 {
+			t.Errorf("frame %d delay = %d, want 5", i, anim.Delay[i])
+		}
+		if img.Bounds() != image.Rect(0, 0, 4, 3) {
+			t.Errorf("frame %d bounds = %v", i, img.Bounds())
+		}
+		// the top-left pixel has the color, the bottom-left one is transparent
+		if r, g, b, _ := img.At(0, 0).RGBA(); r>>8 != uint32(colors[i].R) || g>>8 != uint32(colors[i].G) || b>>8 != uint32(colors[i].B) {
+			t.Errorf("frame %d color = %v, want %v", i, img.At(0, 0), colors[i])
+		}
+		if _, _, _, a := img.At(0, 2).RGBA(); a != 0 {
+			t.Errorf("frame %d bottom-left pixel = %v, want transparent", i, img.At(0, 2))
+		}
+	}
+}
+
+func TestRecorder_APNG(t *testing.T) {
+	rec := record(t)
+	var buf bytes.Buffer
+	if err := rec.Encode(&buf, recorder.APNG); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	// decoders without APNG support show the first frame
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if c := color.NRGBAModel.Convert(img.At(1, 0)); c != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("first frame color = %v, want red", c)
+	}
+
+	// go through the chunks and turn each frame into a separate PNG
+	var ihdr []byte
+	var frames [][]byte
+	var seq []uint32
+	for pos := 8; pos < len(data); {
+		n := int(binary.BigEndian.Uint32(data[pos:]))
+		typ, body := string(data[pos+4:pos+8]), data[pos+8:pos+8+n]
+		pos += 12 + n
+		switch typ {
+		case "IHDR":
+			ihdr = body
+		case "acTL":
+			if got := binary.BigEndian.Uint32(body); got != uint32(len(colors)) {
+				t.Errorf("acTL frames = %d, want %d", got, len(colors))
+			}
+		case "fcTL":
+			seq = append(seq, binary.BigEndian.Uint32(body))
+			if num, den := binary.BigEndian.Uint16(body[20:]), binary.BigEndian.Uint16(body[22:]); float64(num)/float64(den) != 0.05 {
+				t.Errorf("fcTL delay = %d/%d, want 0.05", num, den)
+			}
+		case "IDAT":
+			frames = append(frames, body)
+		case "fdAT":
+			seq = append(seq, binary.BigEndian.Uint32(body))
+			frames = append(frames, body[4:])
+		}
+	}
+	for i, s := range seq {
+		if s != uint32(i) {
+			t.Errorf("sequence numbers = %v, want consecutive from 0", seq)
+			break
+		}
+	}
+	if len(frames) != len(colors) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(colors))
+	}
+
+	for i, idat := range frames {
+		var single bytes.Buffer
+		enc := pngWriter{&single}
+		single.WriteString("\x89PNG\r\n\x1a\n")
+		enc.chunk("IHDR", ihdr)
+		enc.chunk("IDAT", idat)
+		enc.chunk("IEND", nil)
+
+		img, err := png.Decode(&single)
+		if err != nil {
+			t.Fatalf("frame %d: png.Decode() error = %v", i, err)
+		}
+		// the colors are unpremultiplied with rounding, while NRGBAModel truncates
+		want := color.NRGBAModel.Convert(colors[i]).(color.NRGBA)
+		if c := color.NRGBAModel.Convert(img.At(3, 0)).(color.NRGBA); !near(c, want) {
+			t.Errorf("frame %d color = %v, want %v", i, c, want)
+		}
+		if _, _, _, a := img.At(0, 2).RGBA(); a != 0 {
+			t.Errorf("frame %d bottom-left pixel = %v, want transparent", i, img.At(0, 2))
+		}
+	}
+}
+
+// countingCanvas counts how many times its content was read.
+type countingCanvas struct {
+	*software.Canvas
+	reads int
+}
+
+func (c *countingCanvas) Pixels() []uint8 {
+	c.reads++
+	return c.Canvas.Pixels()
+}
+
+func TestRecorder_CaptureFrom(t *testing.T) {
+	canvas := &countingCanvas{Canvas: software.NewCanvas(pixel.R(0, 0, 4, 3))}
+	rec := recorder.New(len(colors), 50*time.Millisecond)
+	want := recorder.New(len(colors), 50*time.Millisecond)
+	for i, c := range colors {
+		canvas.Clear(c)
+		if done := rec.CaptureFrom(canvas); done != (i == len(colors)-1) {
+			t.Fatalf("CaptureFrom() of frame %d = %v", i, done)
+		}
+		want.Capture(canvas.PictureData())
+	}
+	if rec.CaptureFrom(canvas) || canvas.reads != len(colors) {
+		t.Errorf("CaptureFrom() after the last frame read the Canvas, %d reads", canvas.reads)
+	}
+
+	// the frames read from the Canvas are the same as its PictureData
+	var got, wantBuf bytes.Buffer
+	if err := rec.Encode(&got, recorder.APNG); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := want.Encode(&wantBuf, recorder.APNG); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !bytes.Equal(got.Bytes(), wantBuf.Bytes()) {
+		t.Errorf("animation captured from the Canvas differs from its PictureData")
+	}
+}
+
+func TestRecorder_Encode_errors(t *testing.T) {
+	var buf bytes.Buffer
+	rec := recorder.New(0, time.Second)
+	if err := rec.Encode(&buf, recorder.GIF); err == nil {
+		t.Errorf("Encode() without frames: want error")
+	}
+	rec.Capture(frame(colors[0]))
+	rec.Capture(pixel.MakePictureData(pixel.R(0, 0, 2, 2)))
+	if rec.Done() {
+		t.Errorf("Done() of an unlimited Recorder = true")
+	}
+	if err := rec.Encode(&buf, recorder.APNG); err == nil {
+		t.Errorf("Encode() of frames of different sizes: want error")
+	}
+	rec.Reset()
+	rec.Capture(frame(colors[0]))
+	if err := rec.Encode(&buf, recorder.Format(-1)); err == nil {
+		t.Errorf("Encode() with an invalid format: want error")
+	}
+}
+
+// pngWriter writes the chunks of a PNG, for reassembling the frames of an APNG.
+type pngWriter struct {
+	buf *bytes.Buffer
+}
+
+func (w pngWriter) chunk(typ string, data []byte) {
+	binary.Write(w.buf, binary.BigEndian, uint32(len(data)))
+	w.buf.WriteString(typ)
+	w.buf.Write(data)
+	binary.Write(w.buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+}
+
+func near(c, d color.NRGBA) bool {
+	diff := func(a, b uint8) bool { return a-b <= 1 || b-a <= 1 }
+	return diff(c.R, d.R) && diff(c.G, d.G) && diff(c.B, d.B) && c.A == d.A
+}
@@ -0,0 +1,280 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Hash is an Index which divides the unbounded space into a uniform grid of square cells. Each item
+// is stored in all the cells its bounds overlap.
+//
+// The Hash is fast to update and works best with evenly spread items of similar size. The cell
+// size should be about the size of a typical item: with smaller cells, each item is stored in many
+// cells, with larger cells, each query goes through many items.
+//
+// The items spanning more than maxHashCells cells, including the ones with infinite bounds, are not
+// stored in the cells, but in a list which all the queries go through.
+//
+// The queries don't modify the Hash, so they can run concurrently with each other, but not with
+// the updates.
+type Hash[T comparable] struct {
+	cellSize float64
+	cells    map[cell][]*entry[T]
+	entries  map[T]*entry[T]
+	large    []*entry[T]
+
+	// the range of cells which have ever held an entry
+	extentMin, extentMax cell
+	hasExtent            bool
+}
+
+type cell struct {
+	X, Y int
+}
+
+// maxHashCells is the maximum number of cells an item of a Hash is stored in.
+const maxHashCells = 1024
+
+var _ Index[int] = (*Hash[int])(nil)
+
+// NewHash creates a new empty Hash with cells of the given size, which must be positive.
+func NewHash[T comparable](cellSize float64) *Hash[T] {
+	if cellSize <= 0 {
+		panic(fmt.Errorf("spatial.NewHash: cell size %v is not positive", cellSize))
+	}
+	return &Hash[T]{
+		cellSize: cellSize,
+		cells:    make(map[cell][]*entry[T]),
+		entries:  make(map[T]*entry[T]),
+	}
+}
+
+// Insert adds the item with the given bounds. Inserting an item which is already stored updates its
+// bounds.
+func (h *Hash[T]) Insert(item T, bounds pixel.Rect) {
+	h.Update(item, bounds)
+}
+
+// Remove removes the item. Removing an item which is not stored does nothing.
+func (h *Hash[T]) Remove(item T) {
+	e, ok := h.entries[item]
+	if !ok {
+		return
+	}
+	delete(h.entries, item)
+	h.detach(e)
+}
+
+// Update changes the bounds of a stored item, or inserts it if it's not stored.
+func (h *Hash[T]) Update(item T, bounds pixel.Rect) {
+	bounds = bounds.Norm()
+	minCell, maxCell := h.cellOf(bounds.Min), h.cellOf(bounds.Max)
+
+	e, ok := h.entries[item]
+	if !ok {
+		e = &entry[T]{item: item}
+		h.entries[item] = e
+	} else {
+		if e.minCell == minCell && e.maxCell == maxCell {
+			e.bounds = bounds
+			return
+		}
+		h.detach(e)
+	}
+
+	e.bounds, e.minCell, e.maxCell = bounds, minCell, maxCell
+	if isLarge(minCell, maxCell) {
+		h.large = append(h.large, e)
+		return
+	}
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			c := cell{x, y}
+			h.cells[c] = append(h.cells[c], e)
+		}
+	}
+
+	if !h.hasExtent {
+		h.extentMin, h.extentMax, h.hasExtent = minCell, maxCell, true
+	} else {
+		h.extentMin = cell{min(h.extentMin.X, minCell.X), min(h.extentMin.Y, minCell.Y)}
+		h.extentMax = cell{max(h.extentMax.X, maxCell.X), max(h.extentMax.Y, maxCell.Y)}
+	}
+}
+
+// Bounds returns the bounds of the item and whether the item is stored.
+func (h *Hash[T]) Bounds(item T) (pixel.Rect, bool) {
+	if e, ok := h.entries[item]; ok {
+		return e.bounds, true
+	}
+	return pixel.ZR, false
+}
+
+// Len returns the number of stored items.
+func (h *Hash[T]) Len() int {
+	return len(h.entries)
+}
+
+// Clear removes all items.
+func (h *Hash[T]) Clear() {
+	h.cells = make(map[cell][]*entry[T])
+	h.entries = make(map[T]*entry[T])
+	h.large = nil
+	h.hasExtent = false
+}
+
+// QueryRect returns the items with bounds intersecting or touching the Rect.
+func (h *Hash[T]) QueryRect(r pixel.Rect) []T {
+	return queryRect[T](h, r)
+}
+
+// QueryCircle returns the items with bounds intersecting or touching the Circle.
+func (h *Hash[T]) QueryCircle(c pixel.Circle) []T {
+	return queryCircle[T](h, c)
+}
+
+// QueryPoint returns the items with bounds containing the point, including their edges.
+func (h *Hash[T]) QueryPoint(u pixel.Vec) []T {
+	return queryRect[T](h, pixel.Rect{Min: u, Max: u})
+}
+
+// Raycast returns the items with bounds hit by the line segment going from l.A to l.B, ordered by
+// the distance from l.A.
+func (h *Hash[T]) Raycast(l pixel.Line) []Hit[T] {
+	return raycast[T](h, l)
+}
+
+// Nearest returns at most k items closest to the point, ordered by distance. The distance of an
+// item is the distance of the point from its bounds, zero if they contain the point.
+func (h *Hash[T]) Nearest(u pixel.Vec, k int) []T {
+	if k <= 0 || len(h.entries) == 0 {
+		return nil
+	}
+	// search the rings of cells around the point, until the k-th closest item found so far is
+	// closer than anything in the cells which haven't been searched yet
+	center := h.cellOf(u)
+	lastRing := max(
+		abs(center.X-h.extentMin.X), abs(h.extentMax.X-center.X),
+		abs(center.Y-h.extentMin.Y), abs(h.extentMax.Y-center.Y),
+	)
+
+	best := nearest[T]{u: u, k: k}
+	for _, e := range h.large {
+		best.offer(e)
+	}
+	if !h.hasExtent {
+		return best.items()
+	}
+	seen := make(map[*entry[T]]struct{})
+	for ring := 0; ring <= lastRing; ring++ {
+		h.visitRing(center, ring, seen, best.offer)
+		if limit := float64(ring) * h.cellSize; best.full() && best.worst() <= limit*limit {
+			break
+		}
+	}
+	return best.items()
+}
+
+func (h *Hash[T]) visit(bounds pixel.Rect, region func(pixel.Rect) bool, fn func(e *entry[T])) {
+	for _, e := range h.large {
+		fn(e)
+	}
+	if !h.hasExtent {
+		return
+	}
+	minCell, maxCell := h.cellOf(bounds.Min), h.cellOf(bounds.Max)
+	// don't go through the empty cells of huge queries
+	minCell = cell{max(minCell.X, h.extentMin.X), max(minCell.Y, h.extentMin.Y)}
+	maxCell = cell{min(maxCell.X, h.extentMax.X), min(maxCell.Y, h.extentMax.Y)}
+
+	seen := make(map[*entry[T]]struct{})
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			c := cell{x, y}
+			entries, ok := h.cells[c]
+			if !ok || !region(h.cellRect(c)) {
+				continue
+			}
+			h.visitCell(entries, seen, fn)
+		}
+	}
+}
+
+// visitRing visits the cells at the given Chebyshev distance from the center cell.
+func (h *Hash[T]) visitRing(center cell, ring int, seen map[*entry[T]]struct{}, fn func(e *entry[T])) {
+	for x := center.X - ring; x <= center.X+ring; x++ {
+		step := 2 * ring
+		if x == center.X-ring || x == center.X+ring || ring == 0 {
+			step = 1
+		}
+		for y := center.Y - ring; y <= center.Y+ring; y += step {
+			if entries, ok := h.cells[cell{x, y}]; ok {
+				h.visitCell(entries, seen, fn)
+			}
+		}
+	}
+}
+
+// visitCell calls fn for the entries of a cell which haven't been seen yet.
+func (h *Hash[T]) visitCell(entries []*entry[T], seen map[*entry[T]]struct{}, fn func(e *entry[T])) {
+	for _, e := range entries {
+		if _, ok := seen[e]; !ok {
+			seen[e] = struct{}{}
+			fn(e)
+		}
+	}
+}
+
+func (h *Hash[T]) detach(e *entry[T]) {
+	if isLarge(e.minCell, e.maxCell) {
+		h.large = removeEntry(h.large, e)
+		return
+	}
+	for x := e.minCell.X; x <= e.maxCell.X; x++ {
+		for y := e.minCell.Y; y <= e.maxCell.Y; y++ {
+			c := cell{x, y}
+			if entries := removeEntry(h.cells[c], e); len(entries) > 0 {
+				h.cells[c] = entries
+			} else {
+				delete(h.cells, c)
+			}
+		}
+	}
+}
+
+func (h *Hash[T]) cellOf(u pixel.Vec) cell {
+	return cell{cellIndex(u.X / h.cellSize), cellIndex(u.Y / h.cellSize)}
+}
+
+// cellIndex returns the index of the cell containing the coordinate in units of cells. It saturates
+// instead of overflowing, so that infinite bounds cover all the cells, and the differences of the
+// indices fit an int.
+func cellIndex(x float64) int {
+	const limit = float64(math.MaxInt >> 2)
+	return int(math.Max(-limit, math.Min(limit, math.Floor(x))))
+}
+
+func (h *Hash[T]) cellRect(c cell) pixel.Rect {
+	return pixel.R(
+		float64(c.X)*h.cellSize,
+		float64(c.Y)*h.cellSize,
+		float64(c.X+1)*h.cellSize,
+		float64(c.Y+1)*h.cellSize,
+	)
+}
+
+// isLarge returns whether the range of cells is too large to store an item in each of its cells.
+func isLarge(minCell, maxCell cell) bool {
+	// compared one by one, because the area can overflow
+	w, h := maxCell.X-minCell.X+1, maxCell.Y-minCell.Y+1
+	return w > maxHashCells || h > maxHashCells || w*h > maxHashCells
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,327 @@
+package spatial
+
+import (
+	"fmt"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+const (
+	quadMaxItems = 8
+	quadMaxDepth = 10
+)
+
+// Quadtree is an Index which recursively splits its bounds into four quadrants once they hold too
+// many items. Each item is stored in the smallest quadrant containing its whole bounds, so the
+// Quadtree adapts to items clustered in some places and to items of very different sizes.
+//
+// Items which lie outside of the bounds of the Quadtree are supported, but they are all kept in a
+// single list, which is searched by every query.
+type Quadtree[T comparable] struct {
+	root    *quadNode[T]
+	outside *quadNode[T]
+	entries map[T]*entry[T]
+}
+
+type quadNode[T comparable] struct {
+	bounds   pixel.Rect
+	depth    int
+	parent   *quadNode[T]
+	children *[4]quadNode[T]
+	items    []*entry[T]
+	// count is the number of items in this node and all its descendants
+	count int
+}
+
+var _ Index[int] = (*Quadtree[int])(nil)
+
+// NewQuadtree creates a new empty Quadtree covering the bounds, which are usually the bounds of the
+// game world. The bounds must have a positive area.
+func NewQuadtree[T comparable](bounds pixel.Rect) *Quadtree[T] {
+	bounds = bounds.Norm()
+	if bounds.Area() <= 0 {
+		panic(fmt.Errorf("spatial.NewQuadtree: bounds %v have zero area", bounds))
+	}
+	return &Quadtree[T]{
+		root:    &quadNode[T]{bounds: bounds},
+		outside: &quadNode[T]{},
+		entries: make(map[T]*entry[T]),
+	}
+}
+
+// Insert adds the item with the given bounds. Inserting an item which is already stored updates its
+// bounds.
+func (q *Quadtree[T]) Insert(item T, bounds pixel.Rect) {
+	q.Update(item, bounds)
+}
+
+// Remove removes the item. Removing an item which is not stored does nothing.
+func (q *Quadtree[T]) Remove(item T) {
+	e, ok := q.entries[item]
+	if !ok {
+		return
+	}
+	delete(q.entries, item)
+	q.detach(e)
+}
+
+// Update changes the bounds of a stored item, or inserts it if it's not stored.
+func (q *Quadtree[T]) Update(item T, bounds pixel.Rect) {
+	bounds = bounds.Norm()
+	e, ok := q.entries[item]
+	if !ok {
+		e = &entry[T]{item: item}
+		q.entries[item] = e
+	} else {
+		// an item moving within a leaf doesn't need to move in the tree
+		if n := e.node; n != q.outside && n.children == nil && contains(n.bounds, bounds) {
+			e.bounds = bounds
+			return
+		}
+		q.detach(e)
+	}
+	e.bounds = bounds
+
+	if !contains(q.root.bounds, bounds) {
+		q.outside.items = append(q.outside.items, e)
+		e.node = q.outside
+		return
+	}
+	q.root.insert(e)
+}
+
+// Bounds returns the bounds of the item and whether the item is stored.
+func (q *Quadtree[T]) Bounds(item T) (pixel.Rect, bool) {
+	if e, ok := q.entries[item]; ok {
+		return e.bounds, true
+	}
+	return pixel.ZR, false
+}
+
+// Len returns the number of stored items.
+func (q *Quadtree[T]) Len() int {
+	return len(q.entries)
+}
+
+// Clear removes all items.
+func (q *Quadtree[T]) Clear() {
+	q.root = &quadNode[T]{bounds: q.root.bounds}
+	q.outside = &quadNode[T]{}
+	q.entries = make(map[T]*entry[T])
+}
+
+// QueryRect returns the items with bounds intersecting or touching the Rect.
+func (q *Quadtree[T]) QueryRect(r pixel.Rect) []T {
+	return queryRect[T](q, r)
+}
+
+// QueryCircle returns the items with bounds intersecting or touching the Circle.
+func (q *Quadtree[T]) QueryCircle(c pixel.Circle) []T {
+	return queryCircle[T](q, c)
+}
+
+// QueryPoint returns the items with bounds containing the point, including their edges.
+func (q *Quadtree[T]) QueryPoint(u pixel.Vec) []T {
+	return queryRect[T](q, pixel.Rect{Min: u, Max: u})
+}
+
+// Raycast returns the items with bounds hit by the line segment going from l.A to l.B, ordered by
+// the distance from l.A.
+func (q *Quadtree[T]) Raycast(l pixel.Line) []Hit[T] {
+	return raycast[T](q, l)
+}
+
+// Nearest returns at most k items closest to the point, ordered by distance. The distance of an
+// item is the distance of the point from its bounds, zero if they contain the point.
+func (q *Quadtree[T]) Nearest(u pixel.Vec, k int) []T {
+	if k <= 0 {
+		return nil
+	}
+
+	best := nearest[T]{u: u, k: k}
+	for _, e := range q.outside.items {
+		best.offer(e)
+	}
+
+	// best-first search through the nodes, a node is never further than anything inside of it
+	queue := &candidates[T]{}
+	queue.push(candidate[T]{dist: rectSqDist(q.root.bounds, u), node: q.root})
+	for len(*queue) > 0 {
+		c := queue.pop()
+		if best.full() && c.dist > best.worst() {
+			break
+		}
+		for _, e := range c.node.items {
+			best.offer(e)
+		}
+		if c.node.children != nil {
+			for i := range c.node.children {
+				child := &c.node.children[i]
+				if child.count > 0 {
+					queue.push(candidate[T]{dist: rectSqDist(child.bounds, u), node: child})
+				}
+			}
+		}
+	}
+	return best.items()
+}
+
+func (q *Quadtree[T]) visit(bounds pixel.Rect, region func(pixel.Rect) bool, fn func(e *entry[T])) {
+	for _, e := range q.outside.items {
+		fn(e)
+	}
+	q.root.visit(bounds, region, fn)
+}
+
+// detach removes the entry from its node and merges the nodes which don't hold enough items
+// anymore.
+func (q *Quadtree[T]) detach(e *entry[T]) {
+	n := e.node
+	e.node = nil
+	n.items = removeEntry(n.items, e)
+	if n == q.outside {
+		return
+	}
+
+	var merge *quadNode[T]
+	for ; n != nil; n = n.parent {
+		n.count--
+		if n.children != nil && n.count <= quadMaxItems {
+			merge = n
+		}
+	}
+	if merge != nil {
+		merge.collapse()
+	}
+}
+
+func (n *quadNode[T]) insert(e *entry[T]) {
+	n.count++
+	if n.children != nil {
+		for i := range n.children {
+			if contains(n.children[i].bounds, e.bounds) {
+				n.children[i].insert(e)
+				return
+			}
+		}
+	}
+
+	e.node = n
+	n.items = append(n.items, e)
+	if n.children == nil && len(n.items) > quadMaxItems && n.depth < quadMaxDepth {
+		n.split()
+	}
+}
+
+func (n *quadNode[T]) split() {
+	c := n.bounds.Center()
+	min, max := n.bounds.Min, n.bounds.Max
+	n.children = &[4]quadNode[T]{
+		{bounds: pixel.R(min.X, min.Y, c.X, c.Y)},
+		{bounds: pixel.R(c.X, min.Y, max.X, c.Y)},
+		{bounds: pixel.R(min.X, c.Y, c.X, max.Y)},
+		{bounds: pixel.R(c.X, c.Y, max.X, max.Y)},
+	}
+	for i := range n.children {
+		n.children[i].parent = n
+		n.children[i].depth = n.depth + 1
+	}
+
+	items := n.items
+	n.items = nil
+	n.count -= len(items)
+	for _, e := range items {
+		n.insert(e)
+	}
+}
+
+// collapse moves all items of the descendants into the node and removes the descendants.
+func (n *quadNode[T]) collapse() {
+	var gather func(m *quadNode[T])
+	gather = func(m *quadNode[T]) {
+		if m.children == nil {
+			return
+		}
+		for i := range m.children {
+			child := &m.children[i]
+			n.items = append(n.items, child.items...)
+			gather(child)
+		}
+	}
+	gather(n)
+	n.children = nil
+	for _, e := range n.items {
+		e.node = n
+	}
+}
+
+func (n *quadNode[T]) visit(bounds pixel.Rect, region func(pixel.Rect) bool, fn func(e *entry[T])) {
+	if n.count == 0 || !overlaps(n.bounds, bounds) || !region(n.bounds) {
+		return
+	}
+	for _, e := range n.items {
+		fn(e)
+	}
+	if n.children != nil {
+		for i := range n.children {
+			n.children[i].visit(bounds, region, fn)
+		}
+	}
+}
+
+func removeEntry[T comparable](entries []*entry[T], e *entry[T]) []*entry[T] {
+	for i := range entries {
+		if entries[i] == e {
+			last := len(entries) - 1
+			entries[i] = entries[last]
+			entries[last] = nil
+			return entries[:last]
+		}
+	}
+	return entries
+}
+
+// candidate is a node of the Quadtree with its squared distance from the searched point.
+type candidate[T comparable] struct {
+	dist float64
+	node *quadNode[T]
+}
+
+// candidates is a binary min-heap of candidates ordered by distance.
+type candidates[T comparable] []candidate[T]
+
+func (c *candidates[T]) push(x candidate[T]) {
+	h := append(*c, x)
+	for i := len(h) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if h[parent].dist <= h[i].dist {
+			break
+		}
+		h[parent], h[i] = h[i], h[parent]
+		i = parent
+	}
+	*c = h
+}
+
+func (c *candidates[T]) pop() candidate[T] {
+	h := *c
+	top := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+	for i := 0; ; {
+		smallest := i
+		for _, child := range [...]int{2*i + 1, 2*i + 2} {
+			if child < len(h) && h[child].dist < h[smallest].dist {
+				smallest = child
+			}
+		}
+		if smallest == i {
+			break
+		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+	*c = h
+	return top
+}
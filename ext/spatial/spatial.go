@@ -0,0 +1,210 @@
+// Package spatial implements spatial indexes for fast broad-phase queries, such as finding the
+// objects under the mouse, or the enemies near the player.
+//
+// Items are stored by their bounding Rect. Two implementations of the common Index interface are
+// provided: a Quadtree, which adapts to unevenly distributed items, and a Hash, a uniform grid of
+// cells, which is faster to update when the items are evenly spread and of similar size.
+package spatial
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Index stores items by their bounding Rect and finds them by location.
+//
+// The queries are broad-phase: they test the bounds of the items, not their exact shapes. The order
+// of the items returned by QueryRect, QueryCircle and QueryPoint is unspecified.
+type Index[T comparable] interface {
+	// Insert adds the item with the given bounds. Inserting an item which is already stored
+	// updates its bounds.
+	Insert(item T, bounds pixel.Rect)
+	// Remove removes the item. Removing an item which is not stored does nothing.
+	Remove(item T)
+	// Update changes the bounds of a stored item, or inserts it if it's not stored.
+	Update(item T, bounds pixel.Rect)
+	// Bounds returns the bounds of the item and whether the item is stored.
+	Bounds(item T) (pixel.Rect, bool)
+	// Len returns the number of stored items.
+	Len() int
+	// Clear removes all items.
+	Clear()
+
+	// QueryRect returns the items with bounds intersecting or touching the Rect.
+	QueryRect(r pixel.Rect) []T
+	// QueryCircle returns the items with bounds intersecting or touching the Circle.
+	QueryCircle(c pixel.Circle) []T
+	// QueryPoint returns the items with bounds containing the point, including their edges.
+	QueryPoint(u pixel.Vec) []T
+	// Raycast returns the items with bounds hit by the line segment going from l.A to l.B, ordered
+	// by the distance from l.A.
+	Raycast(l pixel.Line) []Hit[T]
+	// Nearest returns at most k items closest to the point, ordered by distance. The distance of
+	// an item is the distance of the point from its bounds, zero if they contain the point.
+	Nearest(u pixel.Vec, k int) []T
+}
+
+// Hit is an item hit by Index.Raycast.
+type Hit[T comparable] struct {
+	Item T
+	// Point is where the line enters the bounds of the item, or the start of the line if it
+	// starts inside of them.
+	Point pixel.Vec
+	// Dist is the distance of the Point from the start of the line.
+	Dist float64
+}
+
+// entry is a stored item. Each index uses only the fields it needs.
+type entry[T comparable] struct {
+	item   T
+	bounds pixel.Rect
+
+	// the node of the Quadtree holding the entry
+	node *quadNode[T]
+
+	// the range of cells of the Hash holding the entry
+	minCell, maxCell cell
+}
+
+// visitor calls fn for all entries with bounds possibly overlapping the bounds, at least once. The
+// region tests whether a part of the space can contain entries the caller is interested in.
+type visitor[T comparable] interface {
+	visit(bounds pixel.Rect, region func(pixel.Rect) bool, fn func(e *entry[T]))
+}
+
+func queryRect[T comparable](v visitor[T], r pixel.Rect) []T {
+	r = r.Norm()
+	var items []T
+	v.visit(r, func(region pixel.Rect) bool { return overlaps(region, r) }, func(e *entry[T]) {
+		if overlaps(e.bounds, r) {
+			items = append(items, e.item)
+		}
+	})
+	return items
+}
+
+func queryCircle[T comparable](v visitor[T], c pixel.Circle) []T {
+	c = c.Norm()
+	sqRadius := c.Radius * c.Radius
+	var items []T
+	v.visit(c.Bounds(), func(region pixel.Rect) bool {
+		return rectSqDist(region, c.Center) <= sqRadius
+	}, func(e *entry[T]) {
+		if rectSqDist(e.bounds, c.Center) <= sqRadius {
+			items = append(items, e.item)
+		}
+	})
+	return items
+}
+
+func raycast[T comparable](v visitor[T], l pixel.Line) []Hit[T] {
+	dir := l.A.To(l.B)
+	length := dir.Len()
+	var hits []Hit[T]
+	v.visit(l.Bounds(), func(region pixel.Rect) bool {
+		_, ok := rayRect(l.A, dir, region)
+		return ok
+	}, func(e *entry[T]) {
+		if t, ok := rayRect(l.A, dir, e.bounds); ok {
+			hits = append(hits, Hit[T]{Item: e.item, Point: l.A.Add(dir.Scaled(t)), Dist: t * length})
+		}
+	})
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Dist < hits[j].Dist
+	})
+	return hits
+}
+
+// overlaps returns whether the Rects intersect or touch.
+func overlaps(a, b pixel.Rect) bool {
+	return a.Min.X <= b.Max.X && b.Min.X <= a.Max.X && a.Min.Y <= b.Max.Y && b.Min.Y <= a.Max.Y
+}
+
+// contains returns whether the Rect a contains the Rect b, including the edges.
+func contains(a, b pixel.Rect) bool {
+	return a.Min.X <= b.Min.X && b.Max.X <= a.Max.X && a.Min.Y <= b.Min.Y && b.Max.Y <= a.Max.Y
+}
+
+// rectSqDist returns the squared distance of the point from the Rect, zero if the Rect contains it.
+func rectSqDist(r pixel.Rect, u pixel.Vec) float64 {
+	var dx, dy float64
+	if u.X < r.Min.X {
+		dx = r.Min.X - u.X
+	} else if u.X > r.Max.X {
+		dx = u.X - r.Max.X
+	}
+	if u.Y < r.Min.Y {
+		dy = r.Min.Y - u.Y
+	} else if u.Y > r.Max.Y {
+		dy = u.Y - r.Max.Y
+	}
+	return dx*dx + dy*dy
+}
+
+// nearest collects the k entries closest to the point u.
+type nearest[T comparable] struct {
+	u pixel.Vec
+	k int
+	// the closest entries found so far with their squared distances, ordered by distance
+	entries []*entry[T]
+	dists   []float64
+}
+
+func (n *nearest[T]) full() bool {
+	return len(n.entries) == n.k
+}
+
+// worst returns the squared distance of the furthest of the collected entries.
+func (n *nearest[T]) worst() float64 {
+	return n.dists[len(n.dists)-1]
+}
+
+func (n *nearest[T]) offer(e *entry[T]) {
+	d := rectSqDist(e.bounds, n.u)
+	if n.full() {
+		if d >= n.worst() {
+			return
+		}
+		n.entries, n.dists = n.entries[:n.k-1], n.dists[:n.k-1]
+	}
+	i := sort.SearchFloat64s(n.dists, d)
+	for i < len(n.dists) && n.dists[i] == d {
+		i++
+	}
+	n.entries = append(n.entries, nil)
+	n.dists = append(n.dists, 0)
+	copy(n.entries[i+1:], n.entries[i:])
+	copy(n.dists[i+1:], n.dists[i:])
+	n.entries[i], n.dists[i] = e, d
+}
+
+func (n *nearest[T]) items() []T {
+	items := make([]T, len(n.entries))
+	for i, e := range n.entries {
+		items[i] = e.item
+	}
+	return items
+}
+
+// rayRect returns the fraction of the vector dir from the origin at which the segment enters the
+// Rect, using the slab method.
+func rayRect(origin, dir pixel.Vec, r pixel.Rect) (float64, bool) {
+	tMin, tMax := 0.0, 1.0
+	slab := func(o, d, min, max float64) bool {
+		if d == 0 {
+			return o >= min && o <= max
+		}
+		t1, t2 := (min-o)/d, (max-o)/d
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tMin, tMax = math.Max(tMin, t1), math.Min(tMax, t2)
+		return tMin <= tMax
+	}
+	if !slab(origin.X, dir.X, r.Min.X, r.Max.X) || !slab(origin.Y, dir.Y, r.Min.Y, r.Max.Y) {
+		return 0, false
+	}
+	return tMin, true
+}
@@ -0,0 +1,308 @@
+package spatial_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/spatial"
+)
+
+var indexes = []struct {
+	name string
+	new  func() spatial.Index[int]
+}{
+	{"Quadtree", func() spatial.Index[int] { return spatial.NewQuadtree[int](pixel.R(0, 0, 1000, 1000)) }},
+	{"Hash", func() spatial.Index[int] { return spatial.NewHash[int](50) }},
+}
+
+// bruteForce is the reference the indexes are compared against.
+type bruteForce map[int]pixel.Rect
+
+func (b bruteForce) filter(keep func(r pixel.Rect) bool) []int {
+	var items []int
+	for item, r := range b {
+		if keep(r) {
+			items = append(items, item)
+		}
+	}
+	sort.Ints(items)
+	return items
+}
+
+func dist(r pixel.Rect, u pixel.Vec) float64 {
+	dx := math.Max(0, math.Max(r.Min.X-u.X, u.X-r.Max.X))
+	dy := math.Max(0, math.Max(r.Min.Y-u.Y, u.Y-r.Max.Y))
+	return math.Hypot(dx, dy)
+}
+
+func overlaps(a, b pixel.Rect) bool {
+	return a.Min.X <= b.Max.X && b.Min.X <= a.Max.X && a.Min.Y <= b.Max.Y && b.Min.Y <= a.Max.Y
+}
+
+func sorted(items []int) []int {
+	items = append([]int(nil), items...)
+	sort.Ints(items)
+	return items
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func randomRect(rnd *rand.Rand) pixel.Rect {
+	// some of the items stick out of the bounds of the quadtree
+	min := pixel.V(rnd.Float64()*1100-50, rnd.Float64()*1100-50)
+	size := pixel.V(rnd.Float64()*60, rnd.Float64()*60)
+	if rnd.Intn(20) == 0 {
+		size = size.Scaled(10)
+	}
+	return pixel.Rect{Min: min, Max: min.Add(size)}
+}
+
+func TestIndex(t *testing.T) {
+	for _, idx := range indexes {
+		t.Run(idx.name, func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(1))
+			index := idx.new()
+			ref := bruteForce{}
+
+			for round := 0; round < 20; round++ {
+				// insert, move and remove some items
+				for i := 0; i < 100; i++ {
+					item := rnd.Intn(300)
+					switch rnd.Intn(4) {
+					case 0:
+						index.Remove(item)
+						delete(ref, item)
+					case 1:
+						// small moves keep the item in the same node or cells
+						r, ok := ref[item]
+						if !ok {
+							r = randomRect(rnd)
+						}
+						r = r.Moved(pixel.V(rnd.Float64()-0.5, rnd.Float64()-0.5))
+						index.Update(item, r)
+						ref[item] = r
+					default:
+						r := randomRect(rnd)
+						index.Insert(item, r)
+						ref[item] = r
+					}
+				}
+
+				if index.Len() != len(ref) {
+					t.Fatalf("Len() = %d, want %d", index.Len(), len(ref))
+				}
+				for item, want := range ref {
+					if got, ok := index.Bounds(item); !ok || got != want {
+						t.Fatalf("Bounds(%d) = %v, %v, want %v, true", item, got, ok, want)
+					}
+				}
+
+				for i := 0; i < 20; i++ {
+					r := randomRect(rnd)
+					if got, want := sorted(index.QueryRect(r)), ref.filter(func(b pixel.Rect) bool {
+						return overlaps(b, r)
+					}); !equal(got, want) {
+						t.Errorf("QueryRect(%v) = %v, want %v", r, got, want)
+					}
+
+					c := pixel.C(r.Min, rnd.Float64()*100)
+					if got, want := sorted(index.QueryCircle(c)), ref.filter(func(b pixel.Rect) bool {
+						return dist(b, c.Center) <= c.Radius
+					}); !equal(got, want) {
+						t.Errorf("QueryCircle(%v) = %v, want %v", c, got, want)
+					}
+
+					if got, want := sorted(index.QueryPoint(r.Max)), ref.filter(func(b pixel.Rect) bool {
+						return b.Contains(r.Max)
+					}); !equal(got, want) {
+						t.Errorf("QueryPoint(%v) = %v, want %v", r.Max, got, want)
+					}
+
+					k := 1 + rnd.Intn(10)
+					got := index.Nearest(r.Min, k)
+					want := ref.filter(func(pixel.Rect) bool { return true })
+					sort.SliceStable(want, func(i, j int) bool {
+						return dist(ref[want[i]], r.Min) < dist(ref[want[j]], r.Min)
+					})
+					want = want[:min(k, len(want))]
+					if len(got) != len(want) {
+						t.Fatalf("Nearest(%v, %d) = %v, want %v", r.Min, k, got, want)
+					}
+					for j := range got {
+						// items at the same distance may come in any order
+						if dist(ref[got[j]], r.Min) != dist(ref[want[j]], r.Min) {
+							t.Errorf("Nearest(%v, %d) = %v, want %v", r.Min, k, got, want)
+							break
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_Raycast(t *testing.T) {
+	for _, idx := range indexes {
+		t.Run(idx.name, func(t *testing.T) {
+			index := idx.new()
+			index.Insert(1, pixel.R(100, 100, 200, 200))
+			index.Insert(2, pixel.R(300, 120, 320, 180))
+			index.Insert(3, pixel.R(500, 0, 600, 100))
+			index.Insert(4, pixel.R(-50, 140, 0, 160))
+			index.Insert(5, pixel.R(150, 140, 160, 160))
+
+			hits := index.Raycast(pixel.L(pixel.V(0, 150), pixel.V(400, 150)))
+			want := []spatial.Hit[int]{
+				{Item: 4, Point: pixel.V(0, 150), Dist: 0},
+				{Item: 1, Point: pixel.V(100, 150), Dist: 100},
+				{Item: 5, Point: pixel.V(150, 150), Dist: 150},
+				{Item: 2, Point: pixel.V(300, 150), Dist: 300},
+			}
+			if len(hits) != len(want) {
+				t.Fatalf("Raycast() = %v, want %v", hits, want)
+			}
+			for i := range hits {
+				if hits[i] != want[i] {
+					t.Errorf("Raycast()[%d] = %v, want %v", i, hits[i], want[i])
+				}
+			}
+
+			if hits := index.Raycast(pixel.L(pixel.V(0, 0), pixel.V(450, 450))); len(hits) != 2 || hits[0].Item != 1 || hits[1].Item != 5 {
+				t.Errorf("diagonal Raycast() = %v, want items 1 and 5", hits)
+			}
+		})
+	}
+}
+
+func TestIndex_Clear(t *testing.T) {
+	for _, idx := range indexes {
+		t.Run(idx.name, func(t *testing.T) {
+			index := idx.new()
+			for i := 0; i < 100; i++ {
+				index.Insert(i, pixel.R(float64(i), 0, float64(i)+5, 5))
+			}
+			index.Clear()
+			if index.Len() != 0 || len(index.QueryRect(pixel.R(0, 0, 1000, 1000))) != 0 || len(index.Nearest(pixel.ZV, 1)) != 0 {
+				t.Errorf("index is not empty after Clear()")
+			}
+		})
+	}
+}
+
+func TestIndex_infinite(t *testing.T) {
+	inf := math.Inf(1)
+	for _, idx := range indexes {
+		t.Run(idx.name, func(t *testing.T) {
+			index := idx.new()
+			for i := 0; i < 10; i++ {
+				index.Insert(i, pixel.R(float64(i)*100, -50, float64(i)*100+5, -45))
+			}
+			if got := sorted(index.QueryRect(pixel.R(-inf, -inf, inf, inf))); len(got) != 10 {
+				t.Errorf("QueryRect() of the whole plane = %v, want all the items", got)
+			}
+			if got := sorted(index.QueryRect(pixel.R(450, -inf, inf, 0))); !equal(got, []int{5, 6, 7, 8, 9}) {
+				t.Errorf("QueryRect() of a half-plane = %v, want items 5 to 9", got)
+			}
+
+			// items with infinite and huge bounds
+			index.Insert(10, pixel.R(-inf, 0, inf, 1))
+			index.Insert(11, pixel.R(-1e300, -1e300, 1e300, 1e300))
+			if got := sorted(index.QueryPoint(pixel.V(1e6, 0.5))); !equal(got, []int{10, 11}) {
+				t.Errorf("QueryPoint() = %v, want items 10 and 11", got)
+			}
+			if got := sorted(index.Nearest(pixel.V(200, 100), 2)); !equal(got, []int{10, 11}) {
+				t.Errorf("Nearest() = %v, want items 10 and 11", got)
+			}
+			if got := sorted(index.QueryRect(pixel.R(0, -50, 10, -45))); !equal(got, []int{0, 11}) {
+				t.Errorf("QueryRect() = %v, want items 0 and 11", got)
+			}
+			index.Update(11, pixel.R(0, 0, 1, 1))
+			index.Remove(10)
+			if got := sorted(index.QueryRect(pixel.R(-inf, 0, inf, 1))); !equal(got, []int{11}) {
+				t.Errorf("QueryRect() after Remove() = %v, want item 11", got)
+			}
+			if index.Len() != 11 {
+				t.Errorf("Len() = %d, want 11", index.Len())
+			}
+		})
+	}
+}
+
+func TestIndex_concurrentQueries(t *testing.T) {
+	for _, idx := range indexes {
+		t.Run(idx.name, func(t *testing.T) {
+			index := idx.new()
+			for i := 0; i < 100; i++ {
+				index.Insert(i, pixel.R(float64(i)*10, 0, float64(i)*10+15, 15))
+			}
+			var wg sync.WaitGroup
+			for g := 0; g < 4; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 100; i++ {
+						if got := index.QueryRect(pixel.R(0, 0, 1000, 20)); len(got) != 100 {
+							t.Errorf("QueryRect() found %d items, want 100", len(got))
+							return
+						}
+						index.Nearest(pixel.V(float64(i)*10, 30), 3)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func BenchmarkIndex(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	rects := make([]pixel.Rect, 10000)
+	for i := range rects {
+		min := pixel.V(rnd.Float64()*1000, rnd.Float64()*1000)
+		rects[i] = pixel.Rect{Min: min, Max: min.Add(pixel.V(5+rnd.Float64()*10, 5+rnd.Float64()*10))}
+	}
+
+	for _, idx := range indexes {
+		index := idx.new()
+		for i, r := range rects {
+			index.Insert(i, r)
+		}
+		b.Run(fmt.Sprintf("%s/Update", idx.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				item := i % len(rects)
+				rects[item] = rects[item].Moved(pixel.V(1, -1).Scaled(float64(i%3 - 1)))
+				index.Update(item, rects[item])
+			}
+		})
+		b.Run(fmt.Sprintf("%s/QueryRect", idx.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				index.QueryRect(pixel.R(0, 0, 50, 50).Moved(rects[i%len(rects)].Min))
+			}
+		})
+		b.Run(fmt.Sprintf("%s/Raycast", idx.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				index.Raycast(pixel.L(rects[i%len(rects)].Min, pixel.V(500, 500)))
+			}
+		})
+		b.Run(fmt.Sprintf("%s/Nearest", idx.name), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				index.Nearest(rects[i%len(rects)].Min, 5)
+			}
+		})
+	}
+}
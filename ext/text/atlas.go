@@ -3,6 +3,7 @@ package text
 import (
 	"image"
 	"image/draw"
+	"math"
 	"sort"
 	"unicode"
 
@@ -29,6 +30,17 @@ type Atlas struct {
 	ascent     float64
 	descent    float64
 	lineHeight float64
+
+	// pad is the horizontal padding of the frames of the glyphs, and distRange the range of the
+	// distances of an Atlas of signed distance fields, see NewSDFAtlas
+	pad       float64
+	distRange float64
+
+	// kerning is the kerning of the bitmap fonts, which don't have a face
+	kerning map[[2]rune]float64
+
+	// shaping is the font of an Atlas for ShapedText, see NewShapingAtlas
+	shaping *shapingFont
 }
 
 // NewAtlas creates a new Atlas containing glyphs of the union of the given sets of runes (plus
@@ -118,6 +130,9 @@ func (a *Atlas) Glyph(r rune) Glyph {
 // Kern returns the kerning distance between runes r0 and r1. Positive distance means that the
 // glyphs should be further apart.
 func (a *Atlas) Kern(r0, r1 rune) float64 {
+	if a.face == nil {
+		return a.kerning[[2]rune{r0, r1}]
+	}
 	return i2f(a.face.Kern(r0, r1))
 }
 
@@ -136,6 +151,12 @@ func (a *Atlas) LineHeight() float64 {
 	return a.lineHeight
 }
 
+// DistanceRange returns the range of the signed distances stored in the Atlas's Picture, in pixels
+// of the Picture, or 0 if the Atlas doesn't contain signed distance fields. See NewSDFAtlas.
+func (a *Atlas) DistanceRange() float64 {
+	return a.distRange
+}
+
 // DrawRune returns parameters necessary for drawing a rune glyph.
 //
 // Rect is a rectangle where the glyph should be positioned. Frame is the glyph frame inside the
@@ -162,9 +183,9 @@ func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixe
 
 	if bounds.W()*bounds.H() != 0 {
 		bounds = pixel.R(
-			bounds.Min.X,
+			bounds.Min.X+a.pad,
 			dot.Y-a.Descent(),
-			bounds.Max.X,
+			bounds.Max.X-a.pad,
 			dot.Y+a.Ascent(),
 		)
 	}
@@ -174,6 +195,19 @@ func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixe
 	return rect, glyph.Frame, bounds, dot
 }
 
+// addReplacement maps unicode.ReplacementChar to the glyph of '?', or to an empty glyph as wide as
+// a space, if the mapping of a bitmap font lacks it. DrawRune doesn't draw anything without it.
+func addReplacement(mapping map[rune]Glyph) {
+	if _, ok := mapping[unicode.ReplacementChar]; ok {
+		return
+	}
+	if g, ok := mapping['?']; ok {
+		mapping[unicode.ReplacementChar] = g
+		return
+	}
+	mapping[unicode.ReplacementChar] = Glyph{Advance: mapping[' '].Advance}
+}
+
 type fixedGlyph struct {
 	dot     fixed.Point26_6
 	frame   fixed.Rectangle26_6
@@ -242,6 +276,44 @@ func makeMapping(face font.Face, runes []rune, padding, width fixed.Int26_6) (ma
 	return mapping, bounds
 }
 
+// packRects places rectangles of the sizes in rows of a roughly square area and returns their
+// positions and the size of the area. The rectangles are a pixel apart, so that they don't bleed
+// into each other when drawn smooth, and the empty ones are left at the origin.
+func packRects(sizes []image.Point) (pos []image.Point, size image.Point) {
+	area, widest := 0, 0
+	for _, s := range sizes {
+		area += (s.X + 1) * (s.Y + 1)
+		widest = max(widest, s.X+1)
+	}
+	width := max(widest, int(math.Ceil(math.Sqrt(float64(area)))))
+
+	// the tallest rectangles first, so that the rows are filled evenly
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sizes[order[i]].Y > sizes[order[j]].Y
+	})
+
+	pos = make([]image.Point, len(sizes))
+	x, y, rowHeight := 0, 0, 0
+	for _, i := range order {
+		s := sizes[i]
+		if s.X == 0 || s.Y == 0 {
+			continue
+		}
+		if x+s.X+1 > width {
+			x, y = 0, y+rowHeight
+			rowHeight = 0
+		}
+		pos[i] = image.Pt(x, y)
+		x += s.X + 1
+		rowHeight = max(rowHeight, s.Y+1)
+	}
+	return pos, image.Pt(max(1, width), max(1, y+rowHeight))
+}
+
 func i2f(i fixed.Int26_6) float64 {
 	return float64(i) / (1 << 6)
 }
@@ -0,0 +1,424 @@
+package text
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// LoadBMFont loads an Atlas from an AngelCode BMFont file and its pages. The file can be in the
+// text, the XML or the binary format, the pages are decoded by the decoder and combined into the
+// Atlas's Picture, and the kerning pairs are used by Kern.
+//
+// The file system can be an embed.FS or os.DirFS, as long as it contains the font and its pages.
+// If the decoder is nil, pixel.DefaultDecoderFunc is used, in which case the image formats must be
+// registered, for example by importing image/png. Grayscale pages are used as the alpha channel of
+// white glyphs, the channels of packed pages aren't supported.
+//
+// The runes missing in the Atlas are drawn as '?', unless the font has unicode.ReplacementChar.
+func LoadBMFont(fsys fs.FS, name string, decoder pixel.DecoderFunc) (*Atlas, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseBMFont(data)
+	if err != nil {
+		return nil, fmt.Errorf("LoadBMFont: %s: %w", name, err)
+	}
+
+	if decoder == nil {
+		decoder = pixel.DefaultDecoderFunc
+	}
+	pages := make([]image.Image, len(f.pages))
+	for i, page := range f.pages {
+		file, err := fsys.Open(path.Join(path.Dir(name), page))
+		if err != nil {
+			return nil, fmt.Errorf("LoadBMFont: %s: %w", name, err)
+		}
+		pages[i], err = decoder(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("LoadBMFont: %s: page %s: %w", name, page, err)
+		}
+	}
+	return newBMFontAtlas(f, pages), nil
+}
+
+// bmFont is a font in the BMFont format, the fields not needed by an Atlas are skipped.
+type bmFont struct {
+	lineHeight, base int
+	pages            []string
+	chars            []bmChar
+	kernings         []bmKerning
+	hasCommon        bool
+}
+
+type bmChar struct {
+	id               rune
+	x, y, w, h       int
+	xoffset, yoffset int
+	xadvance, page   int
+}
+
+type bmKerning struct {
+	first, second rune
+	amount        int
+}
+
+// bmAttrs are the attributes of a tag of the text and the XML formats. The first invalid number
+// is remembered in err.
+type bmAttrs struct {
+	m   map[string]string
+	err error
+}
+
+func (a *bmAttrs) int(key string) int {
+	v, ok := a.m[key]
+	if !ok {
+		return 0
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil && a.err == nil {
+		a.err = fmt.Errorf("invalid %s=%q", key, v)
+	}
+	return i
+}
+
+// set sets the values of a tag of the text and the XML formats.
+func (f *bmFont) set(tag string, attrs *bmAttrs) error {
+	switch tag {
+	case "common":
+		f.lineHeight = attrs.int("lineHeight")
+		f.base = attrs.int("base")
+		f.hasCommon = true
+		if attrs.int("packed") != 0 {
+			return errors.New("packed pages aren't supported")
+		}
+	case "page":
+		id := attrs.int("id")
+		if id < 0 || id > 255 {
+			return fmt.Errorf("invalid page id %d", id)
+		}
+		for len(f.pages) <= id {
+			f.pages = append(f.pages, "")
+		}
+		f.pages[id] = attrs.m["file"]
+	case "char":
+		f.chars = append(f.chars, bmChar{
+			id:       rune(attrs.int("id")),
+			x:        attrs.int("x"),
+			y:        attrs.int("y"),
+			w:        attrs.int("width"),
+			h:        attrs.int("height"),
+			xoffset:  attrs.int("xoffset"),
+			yoffset:  attrs.int("yoffset"),
+			xadvance: attrs.int("xadvance"),
+			page:     attrs.int("page"),
+		})
+	case "kerning":
+		f.kernings = append(f.kernings, bmKerning{
+			first:  rune(attrs.int("first")),
+			second: rune(attrs.int("second")),
+			amount: attrs.int("amount"),
+		})
+	}
+	return attrs.err
+}
+
+// validate checks that the font has the common tag and that the pages of the chars exist.
+func (f *bmFont) validate() error {
+	if !f.hasCommon {
+		return errors.New("missing common block")
+	}
+	for i, page := range f.pages {
+		if page == "" {
+			return fmt.Errorf("missing page %d", i)
+		}
+	}
+	for _, c := range f.chars {
+		if c.page < 0 || c.page >= len(f.pages) {
+			return fmt.Errorf("char %d on missing page %d", c.id, c.page)
+		}
+		if c.w < 0 || c.h < 0 {
+			return fmt.Errorf("char %d has a negative size", c.id)
+		}
+	}
+	return nil
+}
+
+// parseBMFont parses a font in any of the BMFont formats.
+func parseBMFont(data []byte) (*bmFont, error) {
+	var (
+		f   *bmFont
+		err error
+	)
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(data, []byte("BMF")):
+		f, err = parseBMFontBinary(data)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		f, err = parseBMFontXML(trimmed)
+	default:
+		f, err = parseBMFontText(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, f.validate()
+}
+
+// parseBMFontText parses the text format, lines of a tag followed by key=value pairs, the values
+// with spaces are quoted.
+func parseBMFontText(data []byte) (*bmFont, error) {
+	f := &bmFont{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		tag, rest, _ := strings.Cut(line, " ")
+		attrs := &bmAttrs{m: make(map[string]string)}
+		for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+			key, value, ok := strings.Cut(rest, "=")
+			if !ok || key == "" || strings.ContainsAny(key, " \t") {
+				return nil, fmt.Errorf("line %d: invalid attribute %q", n, rest)
+			}
+			if strings.HasPrefix(value, `"`) {
+				end := strings.IndexByte(value[1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("line %d: unterminated value of %s", n, key)
+				}
+				attrs.m[key], rest = value[1:end+1], value[end+2:]
+			} else {
+				attrs.m[key], rest, _ = strings.Cut(value, " ")
+			}
+		}
+		if err := f.set(tag, attrs); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return f, scanner.Err()
+}
+
+// parseBMFontXML parses the XML format, the elements are the tags of the text format.
+func parseBMFontXML(data []byte) (*bmFont, error) {
+	f := &bmFont{}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return f, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		attrs := &bmAttrs{m: make(map[string]string)}
+		for _, a := range start.Attr {
+			attrs.m[a.Name.Local] = a.Value
+		}
+		if err := f.set(start.Name.Local, attrs); err != nil {
+			line, _ := d.InputPos()
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// parseBMFontBinary parses version 3 of the binary format, blocks of little-endian structs.
+func parseBMFontBinary(data []byte) (*bmFont, error) {
+	if len(data) < 4 || data[3] != 3 {
+		return nil, errors.New("unsupported binary version")
+	}
+	var (
+		f  = &bmFont{}
+		le = binary.LittleEndian
+	)
+	for data = data[4:]; len(data) > 0; {
+		if len(data) < 5 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		typ, size := data[0], int(le.Uint32(data[1:]))
+		if size < 0 || len(data)-5 < size {
+			return nil, io.ErrUnexpectedEOF
+		}
+		block := data[5 : 5+size]
+		data = data[5+size:]
+
+		switch typ {
+		case 2:
+			if len(block) < 15 {
+				return nil, errors.New("invalid common block")
+			}
+			f.lineHeight = int(le.Uint16(block[0:]))
+			f.base = int(le.Uint16(block[2:]))
+			f.hasCommon = true
+			if block[10]&0x80 != 0 {
+				return nil, errors.New("packed pages aren't supported")
+			}
+		case 3:
+			for _, name := range strings.Split(strings.TrimSuffix(string(block), "\x00"), "\x00") {
+				f.pages = append(f.pages, name)
+			}
+		case 4:
+			for ; len(block) >= 20; block = block[20:] {
+				f.chars = append(f.chars, bmChar{
+					id:       rune(le.Uint32(block[0:])),
+					x:        int(le.Uint16(block[4:])),
+					y:        int(le.Uint16(block[6:])),
+					w:        int(le.Uint16(block[8:])),
+					h:        int(le.Uint16(block[10:])),
+					xoffset:  int(int16(le.Uint16(block[12:]))),
+					yoffset:  int(int16(le.Uint16(block[14:]))),
+					xadvance: int(int16(le.Uint16(block[16:]))),
+					page:     int(block[18]),
+				})
+			}
+		case 5:
+			for ; len(block) >= 10; block = block[10:] {
+				f.kernings = append(f.kernings, bmKerning{
+					first:  rune(le.Uint32(block[0:])),
+					second: rune(le.Uint32(block[4:])),
+					amount: int(int16(le.Uint16(block[8:]))),
+				})
+			}
+		}
+	}
+	return f, nil
+}
+
+// newBMFontAtlas creates an Atlas of the font, with the pages stacked from the top of its Picture.
+func newBMFontAtlas(f *bmFont, pages []image.Image) *Atlas {
+	width, pageHeight := 0, 0
+	for _, page := range pages {
+		width = max(width, page.Bounds().Dx())
+		pageHeight = max(pageHeight, page.Bounds().Dy())
+	}
+	height := pageHeight * len(pages)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, page := range pages {
+		r := page.Bounds().Sub(page.Bounds().Min).Add(image.Pt(0, i*pageHeight))
+		if gray, ok := page.(*image.Gray); ok {
+			mask := &image.Alpha{Pix: gray.Pix, Stride: gray.Stride, Rect: gray.Rect}
+			draw.DrawMask(img, r, image.White, image.Point{}, mask, mask.Rect.Min, draw.Src)
+		} else {
+			draw.Draw(img, r, page, page.Bounds().Min, draw.Src)
+		}
+	}
+
+	base := float64(f.base)
+	mapping := make(map[rune]Glyph)
+	for _, c := range f.chars {
+		// the y axis of the pages points down, from the top of the line
+		top := float64(height - c.y - c.page*pageHeight)
+		frame := pixel.R(float64(c.x), top-float64(c.h), float64(c.x+c.w), top)
+		mapping[c.id] = Glyph{
+			Dot:     pixel.V(float64(c.x-c.xoffset), top-(base-float64(c.yoffset))),
+			Frame:   frame,
+			Advance: float64(c.xadvance),
+		}
+	}
+	addReplacement(mapping)
+
+	kerning := make(map[[2]rune]float64)
+	for _, k := range f.kernings {
+		kerning[[2]rune{k.first, k.second}] += float64(k.amount)
+	}
+
+	return &Atlas{
+		pic:        pixel.PictureDataFromImage(img),
+		mapping:    mapping,
+		ascent:     base,
+		descent:    float64(f.lineHeight) - base,
+		lineHeight: float64(f.lineHeight),
+		kerning:    kerning,
+	}
+}
+
+// EncodeBMFont writes the Atlas in the text format of AngelCode BMFont, with a single page named
+// pageName, and returns the image of the page, which is the Atlas's Picture. The page is usually
+// encoded as PNG next to the font:
+//
+//	page, err := atlas.EncodeBMFont(fnt, "font_0.png")
+//	if err != nil {
+//	    return err
+//	}
+//	err = png.Encode(pageFile, page)
+//
+// The positions and the advances of the glyphs and the kerning are rounded to whole pixels, and
+// only the kerning between the runes of the Atlas is written.
+func (a *Atlas) EncodeBMFont(w io.Writer, pageName string) (page image.Image, err error) {
+	pd, ok := a.pic.(*pixel.PictureData)
+	if !ok {
+		pd = pixel.PictureDataFromPicture(a.pic)
+	}
+	bounds := pd.Bounds()
+	base := math.Round(a.ascent)
+
+	runes := make([]rune, 0, len(a.mapping))
+	for r, g := range a.mapping {
+		// the replacement added by addReplacement would be added again when loading
+		if r == unicode.ReplacementChar && a.face == nil && g == a.mapping['?'] {
+			continue
+		}
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	var kernings []bmKerning
+	for _, r0 := range runes {
+		for _, r1 := range runes {
+			if amount := int(math.Round(a.Kern(r0, r1))); amount != 0 {
+				kernings = append(kernings, bmKerning{r0, r1, amount})
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "info face=\"\" size=%d bold=0 italic=0 charset=\"\" unicode=1 stretchH=100 smooth=0 aa=1 padding=0,0,0,0 spacing=0,0\n",
+		int(math.Round(a.ascent+a.descent)))
+	fmt.Fprintf(bw, "common lineHeight=%d base=%d scaleW=%d scaleH=%d pages=1 packed=0\n",
+		int(math.Round(a.lineHeight)), int(base), int(bounds.W()), int(bounds.H()))
+	fmt.Fprintf(bw, "page id=0 file=%q\n", pageName)
+	fmt.Fprintf(bw, "chars count=%d\n", len(runes))
+	for _, r := range runes {
+		g := a.mapping[r]
+		fmt.Fprintf(bw, "char id=%d x=%d y=%d width=%d height=%d xoffset=%d yoffset=%d xadvance=%d page=0 chnl=15\n",
+			r,
+			int(math.Round(g.Frame.Min.X-bounds.Min.X)),
+			int(math.Round(bounds.Max.Y-g.Frame.Max.Y)),
+			int(math.Round(g.Frame.W())),
+			int(math.Round(g.Frame.H())),
+			int(math.Round(g.Frame.Min.X-g.Dot.X)),
+			int(math.Round(base-(g.Frame.Max.Y-g.Dot.Y))),
+			int(math.Round(g.Advance)),
+		)
+	}
+	fmt.Fprintf(bw, "kernings count=%d\n", len(kernings))
+	for _, k := range kernings {
+		fmt.Fprintf(bw, "kerning first=%d second=%d amount=%d\n", k.first, k.second, k.amount)
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, fmt.Errorf("(%T).EncodeBMFont: %w", a, err)
+	}
+	return pd.Image(), nil
+}
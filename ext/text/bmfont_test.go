@@ -0,0 +1,242 @@
+package text_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+// The test font has 'A' in red on the first page and 'B' in a grayscale second page. Its lines
+// are 10 pixels high, with the baseline 8 pixels below the top.
+
+const bmfontText = `info face="Test Font" size=10 bold=0 italic=0 charset="" unicode=1 stretchH=100 smooth=0 aa=1 padding=0,0,0,0 spacing=1,1
+common lineHeight=10 base=8 scaleW=8 scaleH=8 pages=2 packed=0
+page id=0 file="test_0.png"
+page id=1 file="test_1.png"
+chars count=3
+char id=65   x=0 y=0 width=3 height=4 xoffset=1 yoffset=4 xadvance=5 page=0 chnl=15
+char id=66   x=2 y=1 width=3 height=4 xoffset=0 yoffset=2 xadvance=4 page=1 chnl=15
+char id=32   x=0 y=0 width=0 height=0 xoffset=0 yoffset=0 xadvance=3 page=0 chnl=15
+kernings count=1
+kerning first=65 second=66 amount=-1
+`
+
+const bmfontXML = `<?xml version="1.0"?>
+<font>
+  <info face="Test Font" size="10"/>
+  <common lineHeight="10" base="8" scaleW="8" scaleH="8" pages="2" packed="0"/>
+  <pages>
+    <page id="0" file="test_0.png"/>
+    <page id="1" file="test_1.png"/>
+  </pages>
+  <chars count="3">
+    <char id="65" x="0" y="0" width="3" height="4" xoffset="1" yoffset="4" xadvance="5" page="0" chnl="15"/>
+    <char id="66" x="2" y="1" width="3" height="4" xoffset="0" yoffset="2" xadvance="4" page="1" chnl="15"/>
+    <char id="32" x="0" y="0" width="0" height="0" xoffset="0" yoffset="0" xadvance="3" page="0" chnl="15"/>
+  </chars>
+  <kernings count="1">
+    <kerning first="65" second="66" amount="-1"/>
+  </kernings>
+</font>
+`
+
+// bmfontBinary returns the test font in the binary format.
+func bmfontBinary() []byte {
+	var b bytes.Buffer
+	block := func(typ byte, data ...any) {
+		var blk bytes.Buffer
+		for _, d := range data {
+			binary.Write(&blk, binary.LittleEndian, d)
+		}
+		b.WriteByte(typ)
+		binary.Write(&b, binary.LittleEndian, uint32(blk.Len()))
+		b.Write(blk.Bytes())
+	}
+	type char struct {
+		ID                        uint32
+		X, Y, W, H                uint16
+		XOffset, YOffset, Advance int16
+		Page, Channel             uint8
+	}
+	b.WriteString("BMF\x03")
+	block(1, int16(10), uint8(0), uint8(0), uint16(100), uint8(1), [4]uint8{}, [2]uint8{1, 1}, uint8(0), []byte("Test Font\x00"))
+	block(2, uint16(10), uint16(8), uint16(8), uint16(8), uint16(2), uint8(0), [4]uint8{})
+	block(3, []byte("test_0.png\x00test_1.png\x00"))
+	block(4,
+		char{65, 0, 0, 3, 4, 1, 4, 5, 0, 15},
+		char{66, 2, 1, 3, 4, 0, 2, 4, 1, 15},
+		char{32, 0, 0, 0, 0, 0, 0, 3, 0, 15},
+	)
+	block(5, uint32(65), uint32(66), int16(-1))
+	return b.Bytes()
+}
+
+// bmfontPages returns the PNG encoded pages of the test font.
+func bmfontPages(t *testing.T) fstest.MapFS {
+	red := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 3; x++ {
+			red.Set(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	gray := image.NewGray(image.Rect(0, 0, 8, 8))
+	for y := 1; y < 5; y++ {
+		for x := 2; x < 5; x++ {
+			gray.Set(x, y, color.Gray{255})
+		}
+	}
+	fsys := fstest.MapFS{}
+	for name, img := range map[string]image.Image{"fonts/test_0.png": red, "fonts/test_1.png": gray} {
+		var b bytes.Buffer
+		if err := png.Encode(&b, img); err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: b.Bytes()}
+	}
+	return fsys
+}
+
+// checkBMFont checks the metrics and the drawing of "AB" of the test font.
+func checkBMFont(t *testing.T, atlas *text.Atlas) {
+	t.Helper()
+	if atlas.LineHeight() != 10 || atlas.Ascent() != 8 || atlas.Descent() != 2 {
+		t.Errorf("line height, ascent, descent = %v, %v, %v, want 10, 8, 2", atlas.LineHeight(), atlas.Ascent(), atlas.Descent())
+	}
+	if got := atlas.Kern('A', 'B'); got != -1 {
+		t.Errorf("Kern('A', 'B') = %v, want -1", got)
+	}
+	if got := atlas.Glyph(' ').Advance; got != 3 {
+		t.Errorf("space advance = %v, want 3", got)
+	}
+
+	txt := text.New(pixel.ZV, atlas)
+	fmt.Fprint(txt, "AB")
+	if got, want := txt.Dot, pixel.V(8, 0); got != want {
+		t.Errorf("Dot = %v, want %v", got, want)
+	}
+	pd := pixeltest.Render(pixel.R(0, -2, 8, 8), func(target pixel.Target) {
+		txt.Draw(target, pixel.IM)
+	})
+	// 'A' covers (1, 0)-(4, 4) and the kerned 'B' covers (4, 2)-(7, 6)
+	tests := []struct {
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		{pixel.V(1, 0), pixel.RGB(1, 0, 0)},
+		{pixel.V(3, 3), pixel.RGB(1, 0, 0)},
+		{pixel.V(0, 0), pixel.Alpha(0)},
+		{pixel.V(4, 5), pixel.RGB(1, 1, 1)},
+		{pixel.V(6, 2), pixel.RGB(1, 1, 1)},
+		{pixel.V(5, 6), pixel.Alpha(0)},
+		{pixel.V(4, 1), pixel.Alpha(0)},
+	}
+	for _, tt := range tests {
+		if got := pd.Color(tt.at); got != tt.want {
+			t.Errorf("color at %v = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestLoadBMFont(t *testing.T) {
+	formats := map[string][]byte{
+		"text":   []byte(bmfontText),
+		"xml":    []byte(bmfontXML),
+		"binary": bmfontBinary(),
+	}
+	for name, data := range formats {
+		t.Run(name, func(t *testing.T) {
+			fsys := bmfontPages(t)
+			fsys["fonts/test.fnt"] = &fstest.MapFile{Data: data}
+			atlas, err := text.LoadBMFont(fsys, "fonts/test.fnt", png.Decode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkBMFont(t, atlas)
+
+			// the missing runes are drawn as a space, without '?' in the font
+			if _, _, _, dot := atlas.DrawRune(-1, 'x', pixel.ZV); dot != pixel.V(3, 0) {
+				t.Errorf("DrawRune('x') dot = %v, want (3, 0)", dot)
+			}
+		})
+	}
+}
+
+func TestLoadBMFont_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		fnt  string
+	}{
+		{"missing common", "page id=0 file=\"test_0.png\"\n"},
+		{"missing page", strings.Replace(bmfontText, "test_1.png", "nope.png", 1)},
+		{"char on missing page", strings.Replace(bmfontText, "page=1", "page=2", 1)},
+		{"invalid number", strings.Replace(bmfontText, "x=2", "x=two", 1)},
+		{"unterminated value", "info face=\"Test\n"},
+		{"packed", strings.Replace(bmfontText, "packed=0", "packed=1", 1)},
+		{"invalid xml", "<font><common lineHeight=\"10\"></font>"},
+		{"truncated binary", string(bmfontBinary()[:20])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := bmfontPages(t)
+			fsys["fonts/test.fnt"] = &fstest.MapFile{Data: []byte(tt.fnt)}
+			if _, err := text.LoadBMFont(fsys, "fonts/test.fnt", png.Decode); err == nil {
+				t.Error("LoadBMFont() error = nil")
+			}
+		})
+	}
+}
+
+func TestAtlas_EncodeBMFont(t *testing.T) {
+	fsys := bmfontPages(t)
+	fsys["fonts/test.fnt"] = &fstest.MapFile{Data: []byte(bmfontText)}
+	bmfont, err := text.LoadBMFont(fsys, "fonts/test.fnt", png.Decode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reencode re-encodes the Atlas and loads it again
+	reencode := func(atlas *text.Atlas) *text.Atlas {
+		var fnt, page bytes.Buffer
+		img, err := atlas.EncodeBMFont(&fnt, "page.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := png.Encode(&page, img); err != nil {
+			t.Fatal(err)
+		}
+		reloaded, err := text.LoadBMFont(fstest.MapFS{
+			"font.fnt": &fstest.MapFile{Data: fnt.Bytes()},
+			"page.png": &fstest.MapFile{Data: page.Bytes()},
+		}, "font.fnt", png.Decode)
+		if err != nil {
+			t.Fatalf("%v\n%s", err, fnt.String())
+		}
+		return reloaded
+	}
+
+	// the pages are combined into one
+	checkBMFont(t, reencode(bmfont))
+
+	// an Atlas of a font.Face draws the same text after the round trip
+	reloaded := reencode(text.Atlas7x13)
+	render := func(atlas *text.Atlas) *pixel.PictureData {
+		return pixeltest.Render(pixel.R(0, 0, 128, 32), func(target pixel.Target) {
+			txt := text.New(pixel.V(2, 18), atlas)
+			fmt.Fprint(txt, "Hello, BMFont!\n~{}")
+			txt.Draw(target, pixel.IM)
+		})
+	}
+	if diff, _ := pixeltest.Compare(render(text.Atlas7x13), render(reloaded), 0); diff != 0 {
+		t.Errorf("%d pixels differ after the round trip", diff)
+	}
+}
@@ -0,0 +1,51 @@
+package text
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// NewGridAtlas creates a new Atlas from a bitmap font drawn on a grid of equally sized cells, such
+// as a sprite sheet of a pixel-art font. The runes of chars are assigned to the cells in reading
+// order, from the top-left cell of the Picture, and the runes beyond the last cell are ignored.
+//
+// Each glyph is as wide as its cell, with the baseline at the bottom of the cell, and the lines
+// are as high as the cells. The runes missing in the Atlas are drawn as '?', if it's in chars.
+//
+//	pic := pixel.PictureDataFromImage(img)
+//	atlas := text.NewGridAtlas(pic, pixel.V(8, 8), " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+func NewGridAtlas(pic pixel.Picture, cellSize pixel.Vec, chars string) *Atlas {
+	bounds := pic.Bounds()
+	cols := int(math.Floor(bounds.W() / cellSize.X))
+	rows := int(math.Floor(bounds.H() / cellSize.Y))
+
+	mapping := make(map[rune]Glyph)
+	i := 0
+	for _, r := range chars {
+		if i >= cols*rows {
+			break
+		}
+		cell := pixel.V(
+			bounds.Min.X+float64(i%cols)*cellSize.X,
+			bounds.Max.Y-float64(i/cols+1)*cellSize.Y,
+		)
+		i++
+		if _, ok := mapping[r]; ok {
+			continue
+		}
+		mapping[r] = Glyph{
+			Dot:     cell,
+			Frame:   pixel.Rect{Min: cell, Max: cell.Add(cellSize)},
+			Advance: cellSize.X,
+		}
+	}
+	addReplacement(mapping)
+
+	return &Atlas{
+		pic:        pic,
+		mapping:    mapping,
+		ascent:     cellSize.Y,
+		lineHeight: cellSize.Y,
+	}
+}
@@ -0,0 +1,42 @@
+package text_test
+
+import (
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func TestNewGridAtlas(t *testing.T) {
+	// 4 columns and 2 rows of 4x4 cells, 'h' doesn't fit and the second 'a' is ignored
+	pd := pixel.MakePictureData(pixel.R(0, 0, 16, 8))
+	atlas := text.NewGridAtlas(pd, pixel.V(4, 4), "ab?adefgh")
+
+	tests := []struct {
+		r     rune
+		frame pixel.Rect
+	}{
+		{'a', pixel.R(0, 4, 4, 8)},
+		{'?', pixel.R(8, 4, 12, 8)},
+		{'d', pixel.R(0, 0, 4, 4)},
+		{'g', pixel.R(12, 0, 16, 4)},
+	}
+	for _, tt := range tests {
+		g := atlas.Glyph(tt.r)
+		if g.Frame != tt.frame || g.Dot != tt.frame.Min || g.Advance != 4 {
+			t.Errorf("Glyph(%q) = %v, want the frame %v", tt.r, g, tt.frame)
+		}
+	}
+	if atlas.Contains('h') {
+		t.Error("Contains('h') = true, want false")
+	}
+	if atlas.LineHeight() != 4 || atlas.Ascent() != 4 || atlas.Descent() != 0 {
+		t.Errorf("line height, ascent, descent = %v, %v, %v, want 4, 4, 0", atlas.LineHeight(), atlas.Ascent(), atlas.Descent())
+	}
+
+	// the missing runes are drawn as '?'
+	rect, frame, _, dot := atlas.DrawRune(-1, 'x', pixel.V(10, 20))
+	if rect != pixel.R(10, 20, 14, 24) || frame != atlas.Glyph('?').Frame || dot != pixel.V(14, 20) {
+		t.Errorf("DrawRune('x') = %v, %v, %v", rect, frame, dot)
+	}
+}
@@ -0,0 +1,449 @@
+package text
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// WrapMode is the way a Text breaks the lines longer than its WrapWidth.
+type WrapMode int
+
+const (
+	// WrapWord breaks the lines at whitespace. The words longer than a line are broken between
+	// characters.
+	WrapWord WrapMode = iota
+
+	// WrapChar breaks the lines between any characters.
+	WrapChar
+
+	// WrapTruncate doesn't break the lines. The lines longer than the WrapWidth are cut and end
+	// with the Ellipsis.
+	WrapTruncate
+)
+
+func (wm WrapMode) String() string {
+	switch wm {
+	case WrapWord:
+		return "WrapWord"
+	case WrapChar:
+		return "WrapChar"
+	case WrapTruncate:
+		return "WrapTruncate"
+	default:
+		return "InvalidWrapMode"
+	}
+}
+
+// Align is the horizontal alignment of the lines of a Text.
+type Align int
+
+const (
+	// AlignLeft aligns the lines to Orig.
+	AlignLeft Align = iota
+
+	// AlignCenter centers the lines within the WrapWidth, or around Orig if there's no WrapWidth.
+	AlignCenter
+
+	// AlignRight aligns the lines to the right edge of the WrapWidth, or to Orig if there's no
+	// WrapWidth.
+	AlignRight
+
+	// AlignJustify widens the spaces of the wrapped lines to fill the WrapWidth. The lines ended
+	// by a newline, the last line and the truncated lines are aligned to the left.
+	AlignJustify
+)
+
+func (a Align) String() string {
+	switch a {
+	case AlignLeft:
+		return "AlignLeft"
+	case AlignCenter:
+		return "AlignCenter"
+	case AlignRight:
+		return "AlignRight"
+	case AlignJustify:
+		return "AlignJustify"
+	default:
+		return "InvalidAlign"
+	}
+}
+
+// Line describes a line of text laid out by a Text.
+type Line struct {
+	// Start and End are the byte offsets of the text of the line. End excludes the whitespace at
+	// the end of the line and the newline or the whitespace where the line was broken.
+	Start, End int
+
+	// Dot is the position of the dot at the start of the line, after the alignment.
+	Dot pixel.Vec
+
+	// Width is the width of the line from Dot, excluding the whitespace at the end of the line and
+	// including the Ellipsis.
+	Width float64
+
+	// Bounds is the bounding box of the glyphs of the line, after the alignment. The bounds of an
+	// empty line have a zero width.
+	Bounds pixel.Rect
+
+	// Truncated reports whether the line was cut and ends with the Ellipsis.
+	Truncated bool
+}
+
+// layoutRune is a rune of the line being laid out.
+type layoutRune struct {
+	r         rune
+	col       pixel.RGBA
+	off, size int
+
+	// atlas overrides the Atlas of the layout, img replaces the glyph by an inline image and run
+	// is the index of the styled run of a RichText the rune belongs to
+	atlas *Atlas
+	img   *pixel.Sprite
+	run   int
+
+	// the dot before and after the rune and the parameters for drawing it, before the alignment
+	x0, x1              float64
+	rect, frame, bounds pixel.Rect
+
+	// shift is the horizontal offset of the rune by the alignment
+	shift    float64
+	ellipsis bool
+}
+
+// layout breaks, aligns and truncates lines of text. The runes are added one by one and only the
+// current line is kept, the finished lines are passed to emit along with their runes.
+type layout struct {
+	atlas      *Atlas
+	orig       pixel.Vec
+	lineHeight float64
+	tabWidth   float64
+	wrapWidth  float64
+	wrap       WrapMode
+	align      Align
+	maxLines   int
+	ellipsis   string
+
+	emit func(line Line, runes []layoutRune)
+
+	start pixel.Vec
+	off   int
+	runes []layoutRune
+	lines int
+
+	// ended is set when the last line allowed by maxLines was ended by a newline, skip when the
+	// rest of a truncated line is dropped and full when all of the following text is dropped
+	ended, skip, full bool
+}
+
+// reset starts the layout of a new text at the dot.
+func (l *layout) reset(dot pixel.Vec) {
+	l.start = dot
+	l.off = 0
+	l.runes = l.runes[:0]
+	l.lines = 0
+	l.ended, l.skip, l.full = false, false, false
+}
+
+// moveTo ends the current line, if it isn't empty, and starts a new line at the dot.
+func (l *layout) moveTo(dot pixel.Vec) {
+	if l.ended || l.full {
+		return
+	}
+	if len(l.runes) > 0 {
+		l.newLine(len(l.runes), false, l.off)
+	}
+	l.start = dot
+	l.skip = false
+}
+
+// lastLine reports whether the current line is the last line allowed by maxLines.
+func (l *layout) lastLine() bool {
+	return l.maxLines > 0 && l.lines+1 >= l.maxLines
+}
+
+// add lays out the rune r of size bytes at the byte offset off of the text.
+func (l *layout) add(r rune, size int, col pixel.RGBA, off int) {
+	l.addRune(layoutRune{r: r, col: col, off: off, size: size})
+}
+
+// addRune lays out the rune, which has its r, col, off and size set, and possibly its atlas, img
+// and run.
+func (l *layout) addRune(lr layoutRune) {
+	r, col, off, size := lr.r, lr.col, lr.off, lr.size
+	switch {
+	case l.full || r == '\r':
+		return
+	case r == '\n':
+		l.skip = false
+		if l.lastLine() {
+			// the line gets the ellipsis if any text follows
+			l.ended = true
+			return
+		}
+		l.newLine(len(l.runes), false, off+size)
+		return
+	case l.ended:
+		l.truncate(len(l.runes), col)
+		l.full = true
+		return
+	case l.skip:
+		return
+	}
+
+	l.runes = append(l.runes, lr)
+	l.position(len(l.runes) - 1)
+
+	// everything before the last rune fits, breaking the line may carry a word longer than a line
+	// to the next one, so the new line is checked again
+	for l.overflows(len(l.runes) - 1) {
+		i := len(l.runes) - 1
+		if l.wrap == WrapTruncate || l.lastLine() {
+			l.truncate(i, col)
+			if l.lastLine() {
+				l.full = true
+			} else {
+				l.skip = true
+			}
+			return
+		}
+		b := l.breakAt(i)
+		if b == 0 {
+			// the only glyph of the line doesn't fit, it stays anyway
+			return
+		}
+		l.newLine(b, true, l.runes[b].off)
+	}
+}
+
+// overflows reports whether the rune i reaches past the wrap width.
+func (l *layout) overflows(i int) bool {
+	const eps = 1e-9
+	return l.wrapWidth > 0 && !isSpace(l.runes[i].r) && l.runes[i].x1 > l.orig.X+l.wrapWidth+eps
+}
+
+// breakAt returns the index of the rune the next line starts with when the rune i doesn't fit, or
+// zero if the line can't be broken.
+func (l *layout) breakAt(i int) int {
+	if l.content(i) == 0 {
+		return 0
+	}
+	if l.wrap == WrapWord {
+		for b := i; b > 0; b-- {
+			if isSpace(l.runes[b-1].r) && !isSpace(l.runes[b].r) && l.content(b) > 0 {
+				return b
+			}
+		}
+	}
+	return i
+}
+
+// content returns the number of the first n runes without the whitespace at their end.
+func (l *layout) content(n int) int {
+	for n > 0 && isSpace(l.runes[n-1].r) {
+		n--
+	}
+	return n
+}
+
+// newLine emits the first n runes as a line and starts the next line with the rest of the runes,
+// at the byte offset off.
+func (l *layout) newLine(n int, wrapped bool, off int) {
+	line := l.place(n, wrapped && l.align == AlignJustify)
+	if l.emit != nil {
+		l.emit(line, l.runes[:n])
+	}
+	l.lines++
+
+	l.runes = l.runes[:copy(l.runes, l.runes[n:])]
+	l.start = pixel.V(l.orig.X, l.start.Y-l.lineHeight)
+	l.off = off
+	for i := range l.runes {
+		l.position(i)
+	}
+}
+
+// truncate cuts the current line to the first n runes and as many of them as fit with the
+// ellipsis, which is added after them.
+func (l *layout) truncate(n int, col pixel.RGBA) {
+	limit := math.Inf(1)
+	if l.wrapWidth > 0 {
+		limit = l.orig.X + l.wrapWidth
+	}
+	// the ellipsis has the style of the last rune
+	last := layoutRune{col: col}
+	if n > 0 {
+		last = l.runes[n-1]
+	}
+	last.atlas = l.atlasOf(&last)
+
+	l.runes = l.runes[:n]
+	for {
+		c := l.content(len(l.runes))
+		l.runes = l.runes[:c]
+
+		off := l.off
+		if c > 0 {
+			off = l.runes[c-1].off + l.runes[c-1].size
+		}
+		for _, r := range l.ellipsis {
+			l.runes = append(l.runes, layoutRune{r: r, col: last.col, off: off, atlas: last.atlas, run: last.run, ellipsis: true})
+			l.position(len(l.runes) - 1)
+		}
+		if c == 0 || l.runes[len(l.runes)-1].x1 <= limit {
+			return
+		}
+		l.runes = l.runes[:c-1]
+	}
+}
+
+// position computes the position of the rune i from the previous rune.
+func (l *layout) position(i int) {
+	lr := &l.runes[i]
+	atlas := l.atlasOf(lr)
+	dot, prevR := l.start, rune(-1)
+	if i > 0 {
+		prev := &l.runes[i-1]
+		dot.X = prev.x1
+		// only the glyphs of the same Atlas are kerned
+		if prev.r != '\t' && prev.img == nil && l.atlasOf(prev) == atlas {
+			prevR = prev.r
+		}
+	}
+	lr.x0 = dot.X
+
+	if lr.r == '\t' {
+		// tabs align to the multiples of the tab width from Orig
+		lr.rect, lr.frame, lr.bounds = pixel.Rect{}, pixel.Rect{}, pixel.Rect{}
+		lr.x1 = l.orig.X + (math.Floor((dot.X-l.orig.X)/l.tabWidth)+1)*l.tabWidth
+		return
+	}
+	if lr.img != nil {
+		// inline images are centered between the ascent and the descent
+		frame := lr.img.Frame()
+		y := dot.Y + (atlas.Ascent()-atlas.Descent()-frame.H())/2
+		lr.rect = pixel.R(dot.X, y, dot.X+frame.W(), y+frame.H())
+		lr.frame, lr.bounds = frame, lr.rect
+		lr.x1 = dot.X + frame.W()
+		return
+	}
+	lr.rect, lr.frame, lr.bounds, dot = atlas.DrawRune(prevR, lr.r, dot)
+	lr.x1 = dot.X
+}
+
+// atlasOf returns the Atlas of the rune.
+func (l *layout) atlasOf(lr *layoutRune) *Atlas {
+	if lr.atlas != nil {
+		return lr.atlas
+	}
+	return l.atlas
+}
+
+// place aligns the first n runes of the current line and returns the Line they form.
+func (l *layout) place(n int, justify bool) Line {
+	line := Line{Start: l.off, End: l.off, Dot: l.start}
+
+	c := l.content(n)
+	if c > 0 {
+		line.Width = l.runes[c-1].x1 - l.start.X
+	}
+	for i := c - 1; i >= 0; i-- {
+		if !l.runes[i].ellipsis {
+			line.End = l.runes[i].off + l.runes[i].size
+			break
+		}
+	}
+
+	box := 0.0
+	if l.wrapWidth > 0 {
+		box = l.orig.X + l.wrapWidth - l.start.X
+	}
+	var shift, gap float64
+	switch l.align {
+	case AlignCenter:
+		shift = (box - line.Width) / 2
+	case AlignRight:
+		shift = box - line.Width
+	case AlignJustify:
+		if justify && box > line.Width {
+			// the spaces between the words are widened, not the ones at the start of the line
+			first, spaces := 0, 0
+			for first < c && isSpace(l.runes[first].r) {
+				first++
+			}
+			for i := first; i < c; i++ {
+				if isSpace(l.runes[i].r) && l.runes[i].r != '\t' {
+					spaces++
+				}
+			}
+			if spaces > 0 {
+				gap = (box - line.Width) / float64(spaces)
+				line.Width = box
+			}
+		}
+	}
+	line.Dot.X += shift
+
+	for i := range l.runes[:n] {
+		lr := &l.runes[i]
+		lr.shift = shift
+		if gap > 0 && i < c && isSpace(lr.r) && lr.r != '\t' && l.content(i) > 0 {
+			shift += gap
+		}
+		if lr.bounds.W()*lr.bounds.H() == 0 {
+			continue
+		}
+		if b := lr.bounds.Moved(pixel.V(lr.shift, 0)); line.Bounds.W()*line.Bounds.H() == 0 {
+			line.Bounds = b
+		} else {
+			line.Bounds = line.Bounds.Union(b)
+		}
+	}
+	for _, lr := range l.runes[:n] {
+		line.Truncated = line.Truncated || lr.ellipsis
+	}
+
+	if line.Bounds.W()*line.Bounds.H() == 0 {
+		line.Bounds = pixel.R(line.Dot.X, line.Dot.Y-l.atlas.Descent(), line.Dot.X, line.Dot.Y+l.atlas.Ascent())
+	}
+	return line
+}
+
+// current returns the current line, aligned as the last line.
+func (l *layout) current() Line {
+	return l.place(len(l.runes), false)
+}
+
+// dot returns the position where the next rune would be drawn.
+func (l *layout) dot() pixel.Vec {
+	if l.ended {
+		return pixel.V(l.orig.X, l.start.Y-l.lineHeight)
+	}
+	if len(l.runes) == 0 {
+		return l.start
+	}
+	last := l.runes[len(l.runes)-1]
+	return pixel.V(last.x1+last.shift, l.start.Y)
+}
+
+// isSpace reports whether the line may be broken at r.
+func isSpace(r rune) bool {
+	switch r {
+	case '\u00a0', '\u2007', '\u202f':
+		// non-breaking spaces
+		return false
+	}
+	return unicode.IsSpace(r)
+}
+
+// unionBounds returns the union of the bounds, ignoring a zero Rect.
+func unionBounds(a, b pixel.Rect) pixel.Rect {
+	if a == (pixel.Rect{}) {
+		return b
+	}
+	if b == (pixel.Rect{}) {
+		return a
+	}
+	return a.Union(b)
+}
@@ -0,0 +1,297 @@
+package text_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+// The glyphs of Atlas7x13 are 7 units wide and its lines are 13 units high.
+
+func TestText_LayoutOf(t *testing.T) {
+	type line struct {
+		start, end int
+		dot        pixel.Vec
+		width      float64
+		truncated  bool
+	}
+	tests := []struct {
+		name  string
+		setup func(txt *text.Text)
+		s     string
+		want  []line
+	}{
+		{
+			name:  "newlines",
+			setup: func(txt *text.Text) {},
+			s:     "ab\n\nc",
+			want: []line{
+				{0, 2, pixel.V(0, 0), 14, false},
+				{3, 3, pixel.V(0, -13), 0, false},
+				{4, 5, pixel.V(0, -26), 7, false},
+			},
+		},
+		{
+			name:  "word wrap",
+			setup: func(txt *text.Text) { txt.WrapWidth = 35 },
+			s:     "hello world foo",
+			want: []line{
+				{0, 5, pixel.V(0, 0), 35, false},
+				{6, 11, pixel.V(0, -13), 35, false},
+				{12, 15, pixel.V(0, -26), 21, false},
+			},
+		},
+		{
+			name:  "word longer than a line",
+			setup: func(txt *text.Text) { txt.WrapWidth = 21 },
+			s:     "abcdefg hi",
+			want: []line{
+				{0, 3, pixel.V(0, 0), 21, false},
+				{3, 6, pixel.V(0, -13), 21, false},
+				{6, 7, pixel.V(0, -26), 7, false},
+				{8, 10, pixel.V(0, -39), 14, false},
+			},
+		},
+		{
+			name: "char wrap",
+			setup: func(txt *text.Text) {
+				txt.WrapWidth = 21
+				txt.Wrap = text.WrapChar
+			},
+			s: "hello world",
+			want: []line{
+				{0, 3, pixel.V(0, 0), 21, false},
+				{3, 5, pixel.V(0, -13), 14, false},
+				{6, 9, pixel.V(0, -26), 21, false},
+				{9, 11, pixel.V(0, -39), 14, false},
+			},
+		},
+		{
+			name: "truncate",
+			setup: func(txt *text.Text) {
+				txt.WrapWidth = 35
+				txt.Wrap = text.WrapTruncate
+			},
+			s: "hello world\nhi",
+			want: []line{
+				{0, 2, pixel.V(0, 0), 35, true},
+				{12, 14, pixel.V(0, -13), 14, false},
+			},
+		},
+		{
+			name: "max lines",
+			setup: func(txt *text.Text) {
+				txt.WrapWidth = 35
+				txt.MaxLines = 2
+			},
+			s: "hello world foo",
+			want: []line{
+				{0, 5, pixel.V(0, 0), 35, false},
+				{6, 8, pixel.V(0, -13), 35, true},
+			},
+		},
+		{
+			name:  "max lines ended by newline",
+			setup: func(txt *text.Text) { txt.MaxLines = 1 },
+			s:     "ab\ncd",
+			want: []line{
+				{0, 2, pixel.V(0, 0), 35, true},
+			},
+		},
+		{
+			name:  "max lines with trailing newline",
+			setup: func(txt *text.Text) { txt.MaxLines = 1 },
+			s:     "ab\n",
+			want: []line{
+				{0, 2, pixel.V(0, 0), 14, false},
+			},
+		},
+		{
+			name: "custom ellipsis",
+			setup: func(txt *text.Text) {
+				txt.WrapWidth = 35
+				txt.Wrap = text.WrapTruncate
+				txt.Ellipsis = "~"
+			},
+			s: "hello world",
+			want: []line{
+				{0, 4, pixel.V(0, 0), 35, true},
+			},
+		},
+		{
+			name: "center",
+			setup: func(txt *text.Text) {
+				txt.WrapWidth = 35
+				txt.Align = text.AlignCenter
+			},
+			s: "ab",
+			want: []line{
+				{0, 2, pixel.V(10.5, 0), 14, false},
+			},
+		},
+		{
+			name:  "right without wrap width",
+			setup: func(txt *text.Text) { txt.Align = text.AlignRight },
+			s:     "ab\nabc",
+			want: []line{
+				{0, 2, pixel.V(-14, 0), 14, false},
+				{3, 6, pixel.V(-21, -13), 21, false},
+			},
+		},
+		{
+			name: "justify",
+			setup: func(txt *text.Text) {
+				txt.WrapWidth = 49
+				txt.Align = text.AlignJustify
+			},
+			s: "a b c dd\nx y",
+			want: []line{
+				{0, 5, pixel.V(0, 0), 49, false},
+				{6, 8, pixel.V(0, -13), 14, false},
+				{9, 12, pixel.V(0, -26), 21, false},
+			},
+		},
+		{
+			name: "orig",
+			setup: func(txt *text.Text) {
+				txt.Orig = pixel.V(100, 50)
+				txt.WrapWidth = 14
+			},
+			s: "ab cd",
+			want: []line{
+				{0, 2, pixel.V(100, 50), 14, false},
+				{3, 5, pixel.V(100, 37), 14, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txt := text.New(pixel.ZV, text.Atlas7x13)
+			tt.setup(txt)
+
+			var got []line
+			for _, l := range txt.LayoutOf(tt.s) {
+				got = append(got, line{l.Start, l.End, l.Dot, l.Width, l.Truncated})
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("LayoutOf(%q) =\n%v\nwant\n%v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_wrapWrites(t *testing.T) {
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.WrapWidth = 35
+	txt.Align = text.AlignCenter
+
+	// writing byte by byte wraps the same as writing everything at once
+	const s = "hello world foo"
+	for i := 0; i < len(s); i++ {
+		txt.WriteByte(s[i])
+	}
+
+	var want pixel.Rect
+	lines := txt.LayoutOf(s)
+	for i, l := range lines {
+		if i == 0 {
+			want = l.Bounds
+		} else {
+			want = want.Union(l.Bounds)
+		}
+	}
+	if got := txt.Bounds(); got != want {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+	if got, want := txt.Dot, pixel.V(28, -26); !eqVectors(got, want) {
+		t.Errorf("Dot = %v, want %v", got, want)
+	}
+	// the last line "fooab" fills the wrap width, so it is not shifted anymore
+	if got, want := txt.BoundsOf("ab"), pixel.R(21, -28, 34, -15); got != want {
+		t.Errorf("BoundsOf() = %v, want %v", got, want)
+	}
+
+	// the Dot can be moved to start a new line
+	txt.Dot = pixel.V(0, -52)
+	fmt.Fprint(txt, "x")
+	if got, want := txt.Dot, pixel.V(21, -52); !eqVectors(got, want) {
+		t.Errorf("Dot = %v, want %v", got, want)
+	}
+}
+
+func TestText_layoutAfterPlainWrites(t *testing.T) {
+	// countGlyphs counts the glyphs drawn by the Text in the columns [x0, x1)
+	countGlyphs := func(txt *text.Text, x0, x1 float64) int {
+		pd := pixeltest.Render(pixel.R(-10, -20, 110, 20), func(target pixel.Target) {
+			txt.Draw(target, pixel.IM)
+		})
+		count := 0
+		for x := x0; x < x1; x += 7 {
+			for y := -20.0; y < 20; y++ {
+				if pd.Color(pixel.V(x+3, y)).A > 0 {
+					count++
+					break
+				}
+			}
+		}
+		return count
+	}
+
+	// the text written before the lines are laid out stays, the layout continues after it
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	fmt.Fprint(txt, "abc")
+	txt.WrapWidth = 100
+	txt.Align = text.AlignRight
+	fmt.Fprint(txt, "def")
+	if got := countGlyphs(txt, 0, 21); got != 3 {
+		t.Errorf("%d glyphs of \"abc\" drawn, want 3", got)
+	}
+	if got := countGlyphs(txt, 79, 100); got != 3 {
+		t.Errorf("%d glyphs of \"def\" drawn, want 3", got)
+	}
+	if got := txt.Bounds(); got.Min.X != 0 || got.Max.X < 98 {
+		t.Errorf("Bounds() = %v, want from x 0 to about 100", got)
+	}
+
+	// switching back and forth keeps the glyphs laid out before the plain writes
+	txt.Align = text.AlignLeft
+	txt.WrapWidth = 0
+	fmt.Fprint(txt, "\ngh")
+	txt.Align = text.AlignRight
+	txt.WrapWidth = 100
+	fmt.Fprint(txt, "i")
+	if got := countGlyphs(txt, 79, 100); got != 3 {
+		t.Errorf("%d glyphs of \"def\" drawn, want 3", got)
+	}
+	if got := txt.Bounds(); got.Min.Y > -13 || got.Max.X < 98 {
+		t.Errorf("Bounds() = %v, want the first and the second line", got)
+	}
+}
+
+func TestWrapGolden(t *testing.T) {
+	pixeltest.AssertGolden(t, "wrap", pixel.R(0, 0, 96, 72), func(target pixel.Target) {
+		txt := text.New(pixel.V(4, 58), text.Atlas7x13)
+		txt.WrapWidth = 88
+		txt.Align = text.AlignJustify
+		txt.MaxLines = 4
+		txt.Color = pixel.RGB(1, 1, 0)
+		fmt.Fprint(txt, "The quick brown fox ")
+		txt.Color = pixel.RGB(0, 1, 1)
+		fmt.Fprint(txt, "jumps over the lazy dog, twice.")
+		txt.Draw(target, pixel.IM)
+	}, pixeltest.Options{})
+}
+
+func BenchmarkTextWrite_wrap(b *testing.B) {
+	const s = "The quick brown fox jumps over the lazy dog. "
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.WrapWidth = 300
+	txt.Align = text.AlignJustify
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		txt.WriteString(s)
+	}
+}
@@ -0,0 +1,175 @@
+package text
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Span is a part of the text of a RichText enclosed in a markup tag.
+type Span struct {
+	// Tag and Value are the name and the value of the tag, for example "color" and "#f00" for
+	// [color=#f00].
+	Tag, Value string
+
+	// Start and End are the byte offsets of the span in the plain text, see RichText.Text.
+	Start, End int
+
+	// Bounds are the bounding boxes of the glyphs of the span, one for each line the span is on.
+	Bounds []pixel.Rect
+}
+
+// Contains reports whether the point is within the bounds of the span.
+func (s Span) Contains(pos pixel.Vec) bool {
+	for _, b := range s.Bounds {
+		if b.Contains(pos) {
+			return true
+		}
+	}
+	return false
+}
+
+// richStyle is the style of a run of the text of a RichText.
+type richStyle struct {
+	col          pixel.RGBA
+	hasCol       bool
+	bold, italic bool
+	img          string
+
+	// the effects are enabled by a non-zero amplitude, a negative one is the default amplitude
+	wave, shake float64
+}
+
+// richRun is a run of the plain text of a RichText with the same style.
+type richRun struct {
+	start int
+	text  string
+	style richStyle
+}
+
+// objectReplacement is the rune of the inline images in the plain text.
+const objectReplacement = '\uFFFC'
+
+// parseMarkup parses the markup into the plain text, the runs of the text with the same style and
+// the spans of the tags.
+func parseMarkup(markup string) (plain string, runs []richRun, spans []Span, err error) {
+	var (
+		b     strings.Builder
+		open  []int
+		style richStyle
+	)
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(runs); n > 0 && runs[n-1].style == style && style.img == "" {
+			runs[n-1].text += s
+		} else {
+			runs = append(runs, richRun{start: b.Len(), text: s, style: style})
+		}
+		b.WriteString(s)
+	}
+
+	for i := 0; i < len(markup); {
+		j := strings.IndexByte(markup[i:], '[')
+		if j < 0 {
+			write(markup[i:])
+			break
+		}
+		write(markup[i : i+j])
+		i += j
+
+		if strings.HasPrefix(markup[i:], "[[") {
+			write("[")
+			i += 2
+			continue
+		}
+		k := strings.IndexByte(markup[i:], ']')
+		if k < 0 {
+			return "", nil, nil, fmt.Errorf("unclosed tag at %d", i)
+		}
+		tag := markup[i+1 : i+k]
+		at := i
+		i += k + 1
+
+		if name, ok := strings.CutPrefix(tag, "/"); ok {
+			if len(open) == 0 || spans[open[len(open)-1]].Tag != name {
+				return "", nil, nil, fmt.Errorf("unexpected [/%s] at %d", name, at)
+			}
+			spans[open[len(open)-1]].End = b.Len()
+			open = open[:len(open)-1]
+			style = spanStyle(spans, open)
+			continue
+		}
+
+		name, value, _ := strings.Cut(tag, "=")
+		span := Span{Tag: name, Value: value, Start: b.Len()}
+		switch name {
+		case "img":
+			if value == "" {
+				return "", nil, nil, fmt.Errorf("[img] without an image at %d", at)
+			}
+			style.img = value
+			write(string(objectReplacement))
+			style.img = ""
+			span.End = b.Len()
+			spans = append(spans, span)
+			continue
+		case "color":
+			if _, err := pixel.ParseColor(value); err != nil {
+				return "", nil, nil, fmt.Errorf("[%s] at %d: %w", tag, at, err)
+			}
+		case "wave", "shake":
+			if value != "" {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					return "", nil, nil, fmt.Errorf("[%s] at %d: invalid amplitude", tag, at)
+				}
+			}
+		case "b", "i", "link":
+		default:
+			return "", nil, nil, fmt.Errorf("unknown tag [%s] at %d", tag, at)
+		}
+		spans = append(spans, span)
+		open = append(open, len(spans)-1)
+		style = spanStyle(spans, open)
+	}
+
+	// the tags left open end with the text
+	for _, i := range open {
+		spans[i].End = b.Len()
+	}
+	return b.String(), runs, spans, nil
+}
+
+// spanStyle returns the style of the text in the open spans.
+func spanStyle(spans []Span, open []int) richStyle {
+	var style richStyle
+	for _, i := range open {
+		s := spans[i]
+		switch s.Tag {
+		case "color":
+			style.col, _ = pixel.ParseColor(s.Value)
+			style.hasCol = true
+		case "b":
+			style.bold = true
+		case "i":
+			style.italic = true
+		case "wave":
+			style.wave = amplitude(s.Value)
+		case "shake":
+			style.shake = amplitude(s.Value)
+		}
+	}
+	return style
+}
+
+// amplitude returns the amplitude of an effect, or -1 for the default one.
+func amplitude(value string) float64 {
+	a, err := strconv.ParseFloat(value, 64)
+	if err != nil || a == 0 {
+		return -1
+	}
+	return a
+}
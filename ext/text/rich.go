@@ -0,0 +1,434 @@
+package text
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Fonts is a set of Atlases for the styles of a RichText. Only Regular is required, the text of
+// the missing styles is drawn with the Bold, Italic or Regular Atlas instead.
+type Fonts struct {
+	Regular    *Atlas
+	Bold       *Atlas
+	Italic     *Atlas
+	BoldItalic *Atlas
+}
+
+// atlas returns the Atlas of the style.
+func (f Fonts) atlas(bold, italic bool) *Atlas {
+	switch {
+	case bold && italic && f.BoldItalic != nil:
+		return f.BoldItalic
+	case bold && f.Bold != nil:
+		return f.Bold
+	case italic && f.Italic != nil:
+		return f.Italic
+	}
+	return f.Regular
+}
+
+// The parameters of the effects.
+const (
+	waveFrequency = 1.5 // Hz
+	wavePhase     = 0.5 // radians per glyph
+	shakeInterval = 50 * time.Millisecond
+)
+
+// RichText draws text styled by a markup. The markup consists of text and tags in square
+// brackets, most of which style the text until they are closed:
+//
+//	[color=#f00]red[/color]      a color in a CSS notation, see pixel.ParseColor
+//	[b]bold[/b]                  the Bold Atlas of the Fonts
+//	[i]italic[/i]                the Italic Atlas of the Fonts
+//	[img=coin]                   the Sprite named coin in Images, inline
+//	[wave]wavy[/wave]            glyphs moving up and down, [wave=4] sets the amplitude
+//	[shake]scared[/shake]        glyphs jittering randomly, [shake=2] sets the amplitude
+//	[link=shop]Shop[/link]       no style, but a Span with bounds for hit testing
+//
+// Tags left open end with the text and [[ writes a literal [. For example:
+//
+//	rt := text.NewRichText(pixel.V(10, 100), text.Fonts{Regular: regular, Bold: bold})
+//	rt.Images = map[string]*pixel.Sprite{"coin": coin}
+//	rt.WrapWidth = 200
+//	err := rt.SetMarkup("Get [color=gold]100[/color] [img=coin] for [b]free[/b] in the [link=shop][wave]shop[/wave][/link]!")
+//	// handle error
+//
+//	rt.Update(dt) // moves the glyphs with effects
+//	rt.Draw(win, pixel.IM)
+//
+// The lines are laid out like the lines of a Text, see WrapWidth, Align and MaxLines, with the
+// glyphs of the same triangles. The glyphs of each Atlas and the images of each Picture are drawn
+// together, in the order of the first glyph or image drawn with them.
+//
+// Spans returns the spans of the tags with their bounds, which allows to find the link under the
+// mouse:
+//
+//	mouse := matrix.Unproject(win.MousePosition())
+//	if span, ok := rt.SpanAt("link", mouse); ok && win.JustPressed(pixel.MouseButtonLeft) {
+//		open(span.Value)
+//	}
+type RichText struct {
+	// Orig is the position of the dot at the start of the first line.
+	Orig pixel.Vec
+
+	// Color is the color of the text outside of color tags. Defaults to white. Inline images are
+	// drawn with their own colors.
+	Color color.Color
+
+	// LineHeight is the vertical distance between two lines of text. Defaults to the line height of
+	// the Regular Atlas.
+	LineHeight float64
+
+	// TabWidth is the horizontal tab width, tabs align to the multiples of it from Orig.
+	TabWidth float64
+
+	// WrapWidth, Wrap, Align, MaxLines and Ellipsis lay out the lines like the fields of a Text.
+	WrapWidth float64
+	Wrap      WrapMode
+	Align     Align
+	MaxLines  int
+	Ellipsis  string
+
+	// Images are the inline images of the img tags by their names.
+	Images map[string]*pixel.Sprite
+
+	// The fields above are applied by SetMarkup.
+
+	fonts   Fonts
+	plain   string
+	runs    []richRun
+	spans   []Span
+	bounds  pixel.Rect
+	parts   []*richPart
+	glyphs  int
+	effects bool
+
+	spanLines []int
+	lines     int
+
+	elapsed time.Duration
+	mat     pixel.Matrix
+	col     pixel.RGBA
+	dirty   bool
+}
+
+// richPart is the triangles of the glyphs or images of a RichText drawn with a Picture.
+type richPart struct {
+	pic     pixel.Picture
+	tris    pixel.TrianglesData
+	trans   pixel.TrianglesData
+	transD  pixel.Drawer
+	effects []richEffect
+}
+
+// richEffect moves the glyph of the part with the 6 vertices from i.
+type richEffect struct {
+	i           int
+	glyph       int
+	wave, shake float64
+}
+
+// NewRichText creates a new RichText drawing text with the Fonts, starting at orig.
+func NewRichText(orig pixel.Vec, fonts Fonts) *RichText {
+	rt := &RichText{
+		Orig:       orig,
+		Color:      pixel.Alpha(1),
+		LineHeight: fonts.Regular.LineHeight(),
+		TabWidth:   fonts.Regular.Glyph(' ').Advance * 4,
+		Ellipsis:   "...",
+		fonts:      fonts,
+		mat:        pixel.IM,
+		col:        pixel.Alpha(1),
+	}
+	if fonts.Regular.Contains('…') {
+		rt.Ellipsis = "…"
+	}
+	return rt
+}
+
+// Fonts returns the Fonts of the RichText.
+func (rt *RichText) Fonts() Fonts {
+	return rt.fonts
+}
+
+// SetMarkup replaces the text of the RichText by the text of the markup and lays it out using the
+// current values of the fields of the RichText. It fails if the markup is invalid or uses an image
+// missing from Images, in which case the text isn't changed.
+func (rt *RichText) SetMarkup(markup string) error {
+	plain, runs, spans, err := parseMarkup(markup)
+	if err != nil {
+		return fmt.Errorf("(%T).SetMarkup: %w", rt, err)
+	}
+	for _, run := range runs {
+		if run.style.img != "" && rt.Images[run.style.img] == nil {
+			return fmt.Errorf("(%T).SetMarkup: unknown image %q", rt, run.style.img)
+		}
+	}
+	rt.plain, rt.runs, rt.spans = plain, runs, spans
+	rt.layout()
+	return nil
+}
+
+// Text returns the text of the RichText without the markup. Each inline image is a U+FFFC object
+// replacement character.
+func (rt *RichText) Text() string {
+	return rt.plain
+}
+
+// Bounds returns the bounding box of the text of the RichText, without the effects.
+func (rt *RichText) Bounds() pixel.Rect {
+	return rt.bounds
+}
+
+// Spans returns the spans of the tags of the markup, in the order of their opening tags. The bounds
+// of the spans are in the coordinates of the RichText, before the matrix it's drawn with.
+func (rt *RichText) Spans() []Span {
+	return rt.spans
+}
+
+// SpanAt returns the innermost span of the tag with its bounds containing the point, for example
+// the link under the mouse.
+func (rt *RichText) SpanAt(tag string, pos pixel.Vec) (Span, bool) {
+	for i := len(rt.spans) - 1; i >= 0; i-- {
+		if s := rt.spans[i]; s.Tag == tag && s.Contains(pos) {
+			return s, true
+		}
+	}
+	return Span{}, false
+}
+
+// Update advances the time of the effects.
+func (rt *RichText) Update(dt time.Duration) {
+	rt.elapsed += dt
+	if rt.effects {
+		rt.dirty = true
+	}
+}
+
+// layout lays out the runs of the text into the triangles of the parts.
+func (rt *RichText) layout() {
+	for _, p := range rt.parts {
+		p.tris.SetLen(0)
+		p.effects = p.effects[:0]
+	}
+	rt.parts = rt.parts[:0]
+	rt.bounds = pixel.Rect{}
+	rt.glyphs = 0
+	rt.effects = false
+	rt.lines = 0
+	rt.spanLines = make([]int, len(rt.spans))
+	for i := range rt.spans {
+		rt.spans[i].Bounds = nil
+	}
+	rt.dirty = true
+
+	l := layout{
+		atlas:      rt.fonts.Regular,
+		orig:       rt.Orig,
+		lineHeight: rt.LineHeight,
+		tabWidth:   rt.TabWidth,
+		wrapWidth:  rt.WrapWidth,
+		wrap:       rt.Wrap,
+		align:      rt.Align,
+		maxLines:   rt.MaxLines,
+		ellipsis:   rt.Ellipsis,
+		emit:       rt.addLine,
+	}
+	l.reset(rt.Orig)
+
+	defaultCol := pixel.ToRGBA(rt.Color)
+	for i, run := range rt.runs {
+		lr := layoutRune{
+			col:   defaultCol,
+			atlas: rt.fonts.atlas(run.style.bold, run.style.italic),
+			img:   rt.Images[run.style.img],
+			run:   i,
+		}
+		if run.style.hasCol {
+			lr.col = run.style.col
+		}
+		for off := 0; off < len(run.text); {
+			lr.r, lr.size = utf8.DecodeRuneInString(run.text[off:])
+			lr.off = run.start + off
+			l.addRune(lr)
+			off += lr.size
+		}
+	}
+	rt.addLine(l.current(), l.runes)
+}
+
+// addLine adds the glyphs of a line to the parts and their bounds to the spans.
+func (rt *RichText) addLine(line Line, runes []layoutRune) {
+	rt.bounds = unionBounds(rt.bounds, line.Bounds)
+	rt.lines++
+
+	for _, lr := range runes {
+		if lr.rect.W()*lr.rect.H() == 0 {
+			continue
+		}
+		style := rt.runs[lr.run].style
+
+		pic := lr.atlas.Picture()
+		col := lr.col
+		if lr.img != nil {
+			pic = lr.img.Picture()
+			col = pixel.Alpha(1)
+		}
+		part := rt.part(pic)
+
+		if style.wave != 0 || style.shake != 0 {
+			part.effects = append(part.effects, richEffect{
+				i:     part.tris.Len(),
+				glyph: rt.glyphs,
+				wave:  effectAmplitude(style.wave, 0.15*rt.LineHeight),
+				shake: effectAmplitude(style.shake, 0.1*rt.LineHeight),
+			})
+			rt.effects = true
+		}
+		rt.glyphs++
+
+		rect := lr.rect.Moved(pixel.V(lr.shift, 0))
+		rv := [...]pixel.Vec{
+			{X: rect.Min.X, Y: rect.Min.Y},
+			{X: rect.Max.X, Y: rect.Min.Y},
+			{X: rect.Max.X, Y: rect.Max.Y},
+			{X: rect.Min.X, Y: rect.Max.Y},
+		}
+		fv := [...]pixel.Vec{
+			{X: lr.frame.Min.X, Y: lr.frame.Min.Y},
+			{X: lr.frame.Max.X, Y: lr.frame.Min.Y},
+			{X: lr.frame.Max.X, Y: lr.frame.Max.Y},
+			{X: lr.frame.Min.X, Y: lr.frame.Max.Y},
+		}
+		n := part.tris.Len()
+		part.tris.SetLen(n + 6)
+		for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+			part.tris[n+i].Position = rv[j]
+			part.tris[n+i].Picture = fv[j]
+			part.tris[n+i].Color = col
+			part.tris[n+i].Intensity = 1
+		}
+	}
+
+	// the bounds of the spans are the bounds of their glyphs on each line
+	for _, lr := range runes {
+		if lr.ellipsis || lr.bounds.W()*lr.bounds.H() == 0 {
+			continue
+		}
+		b := lr.bounds.Moved(pixel.V(lr.shift, 0))
+		for i := range rt.spans {
+			s := &rt.spans[i]
+			if lr.off < s.Start || lr.off >= s.End {
+				continue
+			}
+			if len(s.Bounds) == 0 || rt.spanLines[i] != rt.lines {
+				s.Bounds = append(s.Bounds, b)
+				rt.spanLines[i] = rt.lines
+			} else {
+				s.Bounds[len(s.Bounds)-1] = s.Bounds[len(s.Bounds)-1].Union(b)
+			}
+		}
+	}
+}
+
+// part returns the part drawn with the Picture.
+func (rt *RichText) part(pic pixel.Picture) *richPart {
+	for _, p := range rt.parts {
+		if p.pic == pic {
+			return p
+		}
+	}
+	// the parts of the previous layouts are reused
+	if n := len(rt.parts); n < cap(rt.parts) {
+		rt.parts = rt.parts[:n+1]
+		p := rt.parts[n]
+		p.pic = pic
+		p.transD.Picture = pic
+		return p
+	}
+	p := &richPart{pic: pic}
+	p.transD.Picture = pic
+	p.transD.Triangles = &p.trans
+	p.transD.Cached = true
+	rt.parts = append(rt.parts, p)
+	return p
+}
+
+// effectAmplitude returns the amplitude of an effect, which is negative for the default amplitude.
+func effectAmplitude(a, def float64) float64 {
+	if a < 0 {
+		return def
+	}
+	return a
+}
+
+// Draw draws the text of the RichText onto the Target, transformed by the Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (rt *RichText) Draw(t pixel.Target, matrix pixel.Matrix) {
+	rt.DrawColorMask(t, matrix, nil)
+}
+
+// DrawColorMask draws the text of the RichText onto the Target, transformed by the Matrix and
+// masked by the color mask.
+func (rt *RichText) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	if matrix != rt.mat {
+		rt.mat = matrix
+		rt.dirty = true
+	}
+	if mask == nil {
+		mask = pixel.Alpha(1)
+	}
+	if rgba := pixel.ToRGBA(mask); rgba != rt.col {
+		rt.col = rgba
+		rt.dirty = true
+	}
+
+	if rt.dirty {
+		for _, p := range rt.parts {
+			p.trans.SetLen(p.tris.Len())
+			p.trans.Update(&p.tris)
+
+			for _, e := range p.effects {
+				offset := rt.effectOffset(e)
+				for i := e.i; i < e.i+6; i++ {
+					p.trans[i].Position = p.trans[i].Position.Add(offset)
+				}
+			}
+			for i := range p.trans {
+				p.trans[i].Position = rt.mat.Project(p.trans[i].Position)
+				p.trans[i].Color = p.trans[i].Color.Mul(rt.col)
+			}
+			p.transD.Dirty()
+		}
+		rt.dirty = false
+	}
+
+	for _, p := range rt.parts {
+		p.transD.Draw(t)
+	}
+}
+
+// effectOffset returns the offset of a glyph by its effects at the current time.
+func (rt *RichText) effectOffset(e richEffect) pixel.Vec {
+	var offset pixel.Vec
+	if e.wave != 0 {
+		angle := 2*math.Pi*waveFrequency*rt.elapsed.Seconds() - wavePhase*float64(e.glyph)
+		offset.Y += e.wave * math.Sin(angle)
+	}
+	if e.shake != 0 {
+		// the glyphs jump to a new random offset every interval
+		h := uint64(e.glyph)*0x9e3779b97f4a7c15 ^ uint64(rt.elapsed/shakeInterval)*0xbf58476d1ce4e5b9
+		h ^= h >> 31
+		h *= 0x94d049bb133111eb
+		h ^= h >> 29
+		offset.X += e.shake * (float64(h&0xffff)/0xffff*2 - 1)
+		offset.Y += e.shake * (float64(h>>16&0xffff)/0xffff*2 - 1)
+	}
+	return offset
+}
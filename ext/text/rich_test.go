@@ -0,0 +1,238 @@
+package text_test
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font/gofont/gobold"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+// coin returns a 4x4 yellow Sprite.
+func coin() *pixel.Sprite {
+	pd := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	for i := range pd.Pix {
+		pd.Pix[i] = color.RGBA{255, 255, 0, 255}
+	}
+	return pixel.NewSprite(pd, pd.Bounds())
+}
+
+func TestRichText_SetMarkup(t *testing.T) {
+	type span struct {
+		tag, value string
+		start, end int
+	}
+	tests := []struct {
+		markup  string
+		want    string
+		spans   []span
+		wantErr bool
+	}{
+		{markup: "plain", want: "plain"},
+		{markup: "a [color=#f00]b[/color] c", want: "a b c", spans: []span{{"color", "#f00", 2, 3}}},
+		{markup: "[link=x][b]ab[/b]c[/link]", want: "abc", spans: []span{{"link", "x", 0, 3}, {"b", "", 0, 2}}},
+		{markup: "[[b]", want: "[b]"},
+		{markup: "a]b", want: "a]b"},
+		{markup: "x[img=coin]y", want: "x\uFFFCy", spans: []span{{"img", "coin", 1, 4}}},
+		{markup: "[wave=3]ab", want: "ab", spans: []span{{"wave", "3", 0, 2}}},
+		{markup: "[color=gold][shake]a[/shake][/color]", want: "a", spans: []span{{"color", "gold", 0, 1}, {"shake", "", 0, 1}}},
+		{markup: "[color=rgb(0, 255, 0)]a[color=#0f08]b", want: "ab", spans: []span{{"color", "rgb(0, 255, 0)", 0, 2}, {"color", "#0f08", 1, 2}}},
+		{markup: "[color=hsl(120, 100%, 50%)]a[color=transparent]b", want: "ab", spans: []span{{"color", "hsl(120, 100%, 50%)", 0, 2}, {"color", "transparent", 1, 2}}},
+		{markup: "[b]a[/i]", wantErr: true},
+		{markup: "a[/b]", wantErr: true},
+		{markup: "[foo]a", wantErr: true},
+		{markup: "[color=nope]a", wantErr: true},
+		{markup: "[color=#12345]a", wantErr: true},
+		{markup: "[b", wantErr: true},
+		{markup: "[img]", wantErr: true},
+		{markup: "[img=missing]", wantErr: true},
+		{markup: "[shake=x]a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.markup, func(t *testing.T) {
+			rt := text.NewRichText(pixel.ZV, text.Fonts{Regular: text.Atlas7x13})
+			rt.Images = map[string]*pixel.Sprite{"coin": coin()}
+			if err := rt.SetMarkup("unchanged"); err != nil {
+				t.Fatal(err)
+			}
+
+			err := rt.SetMarkup(tt.markup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetMarkup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if got := rt.Text(); got != "unchanged" {
+					t.Errorf("Text() = %q after an error, want the previous text", got)
+				}
+				return
+			}
+			if got := rt.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+			var spans []span
+			for _, s := range rt.Spans() {
+				spans = append(spans, span{s.Tag, s.Value, s.Start, s.End})
+			}
+			if fmt.Sprint(spans) != fmt.Sprint(tt.spans) {
+				t.Errorf("Spans() = %v, want %v", spans, tt.spans)
+			}
+		})
+	}
+}
+
+func TestRichText_spanBounds(t *testing.T) {
+	rt := text.NewRichText(pixel.ZV, text.Fonts{Regular: text.Atlas7x13})
+	rt.WrapWidth = 35
+	if err := rt.SetMarkup("go [link=shop]to the[/link] shop"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the lines are laid out like the lines of a Text
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.WrapWidth = 35
+	lines := txt.LayoutOf(rt.Text())
+	if len(lines) != 3 {
+		t.Fatalf("%d lines, want 3", len(lines))
+	}
+	if got, want := rt.Bounds(), lines[0].Bounds.Union(lines[1].Bounds).Union(lines[2].Bounds); got != want {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+
+	// the link is at the end of the first line and at the start of the second one
+	link := rt.Spans()[0]
+	if len(link.Bounds) != 2 {
+		t.Fatalf("link bounds = %v, want 2 rects", link.Bounds)
+	}
+	if link.Bounds[0].Max != lines[0].Bounds.Max || link.Bounds[1].Min != lines[1].Bounds.Min {
+		t.Errorf("link bounds = %v, lines = %v, %v", link.Bounds, lines[0].Bounds, lines[1].Bounds)
+	}
+
+	tests := []struct {
+		pos  pixel.Vec
+		want bool
+	}{
+		{link.Bounds[0].Center(), true},
+		{link.Bounds[1].Center(), true},
+		{lines[0].Bounds.Min.Add(pixel.V(1, 1)), false},
+		{lines[2].Bounds.Center(), false},
+	}
+	for _, tt := range tests {
+		s, ok := rt.SpanAt("link", tt.pos)
+		if ok != tt.want || ok && s.Value != "shop" {
+			t.Errorf("SpanAt(%v) = %v, %v, want %v", tt.pos, s, ok, tt.want)
+		}
+	}
+}
+
+// pictureTarget records the Pictures and the numbers of vertices drawn onto it.
+type pictureTarget struct {
+	draws []pictureDraw
+}
+
+type pictureDraw struct {
+	pic pixel.Picture
+	len int
+}
+
+func (pt *pictureTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return &pictureTriangles{TrianglesData: pixel.MakeTrianglesData(t.Len())}
+}
+
+func (pt *pictureTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return &targetPicture{Picture: p, dst: pt}
+}
+
+type pictureTriangles struct {
+	*pixel.TrianglesData
+}
+
+func (pt *pictureTriangles) Draw() {}
+
+type targetPicture struct {
+	pixel.Picture
+	dst *pictureTarget
+}
+
+func (tp *targetPicture) Draw(t pixel.TargetTriangles) {
+	tp.dst.draws = append(tp.dst.draws, pictureDraw{tp.Picture, t.Len()})
+}
+
+func TestRichText_pictures(t *testing.T) {
+	ttf, err := truetype.Parse(gobold.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bold := text.NewAtlas(truetype.NewFace(ttf, &truetype.Options{Size: 13}), text.ASCII)
+	c := coin()
+
+	rt := text.NewRichText(pixel.ZV, text.Fonts{Regular: text.Atlas7x13, Bold: bold})
+	rt.Images = map[string]*pixel.Sprite{"coin": c}
+	if err := rt.SetMarkup("ab [b]c[i]d[/i][/b] [img=coin] e"); err != nil {
+		t.Fatal(err)
+	}
+
+	target := &pictureTarget{}
+	rt.Draw(target, pixel.IM)
+	// the spaces of Atlas7x13 have glyphs too
+	want := []pictureDraw{
+		{text.Atlas7x13.Picture(), 6 * 6},
+		{bold.Picture(), 2 * 6},
+		{c.Picture(), 6},
+	}
+	if fmt.Sprint(target.draws) != fmt.Sprint(want) {
+		t.Errorf("draws = %v, want %v", target.draws, want)
+	}
+
+	// the image is as wide as its frame
+	img := rt.Spans()[2]
+	if img.Tag != "img" || len(img.Bounds) != 1 || img.Bounds[0].W() != 4 || img.Bounds[0].H() != 4 {
+		t.Errorf("image span = %+v", img)
+	}
+}
+
+func TestRichText_effects(t *testing.T) {
+	render := func(markup string, elapsed time.Duration) *pixel.PictureData {
+		rt := text.NewRichText(pixel.V(4, 14), text.Fonts{Regular: text.Atlas7x13})
+		if err := rt.SetMarkup(markup); err != nil {
+			t.Fatal(err)
+		}
+		rt.Update(elapsed)
+		return pixeltest.Render(pixel.R(0, 0, 64, 24), func(target pixel.Target) {
+			rt.Draw(target, pixel.IM)
+		})
+	}
+
+	tests := []struct {
+		markup  string
+		changes bool
+	}{
+		{"[wave=3]moving[/wave]", true},
+		{"[shake=2]moving[/shake]", true},
+		{"still", false},
+	}
+	for _, tt := range tests {
+		diff, _ := pixeltest.Compare(render(tt.markup, 0), render(tt.markup, 130*time.Millisecond), 0)
+		if changes := diff > 0; changes != tt.changes {
+			t.Errorf("%s: %d pixels changed", tt.markup, diff)
+		}
+	}
+}
+
+func TestRichTextGolden(t *testing.T) {
+	pixeltest.AssertGolden(t, "rich", pixel.R(0, 0, 96, 40), func(target pixel.Target) {
+		rt := text.NewRichText(pixel.V(4, 24), text.Fonts{Regular: text.Atlas7x13})
+		rt.Images = map[string]*pixel.Sprite{"coin": coin()}
+		rt.WrapWidth = 88
+		rt.Align = text.AlignCenter
+		if err := rt.SetMarkup("[color=#f00]Red[/color] [img=coin] [color=cyan][wave=2]wavy[/wave][/color] and plain"); err != nil {
+			t.Fatal(err)
+		}
+		rt.Draw(target, pixel.IM)
+	}, pixeltest.Options{})
+}
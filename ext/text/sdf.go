@@ -0,0 +1,458 @@
+package text
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"sync"
+	"unicode"
+
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// SDFOptions are the options of an Atlas of signed distance fields, see NewSDFAtlas.
+type SDFOptions struct {
+	// Size is the size of the font in the Atlas, in pixels per em. The text is drawn at this size
+	// when it isn't scaled. Defaults to 32.
+	Size float64
+
+	// Spread is the distance from the outlines of the glyphs covered by the distance fields, in
+	// pixels of the Atlas. It limits the width of the outlines, glows and shadows drawn by
+	// SDFFragmentShader. Defaults to Size / 8.
+	Spread float64
+
+	// MultiChannel generates multi-channel signed distance fields, which keep the corners of the
+	// glyphs sharp when scaling the text up a lot.
+	MultiChannel bool
+}
+
+// NewSDFAtlas creates a new Atlas containing the signed distance fields of the glyphs of the union
+// of the given sets of runes (plus unicode.ReplacementChar) of the font. The distance fields are
+// computed from the outlines of the glyphs, so the text stays crisp at any scale when it's drawn
+// with SDFFragmentShader.
+//
+// Each pixel of the Atlas's Picture stores the signed distance to the outline of its glyph in its
+// alpha channel and in its red, green and blue channels, which hold a multi-channel distance field
+// if MultiChannel is set. The distances are mapped from [-Spread, Spread] to [0, 1], positive
+// inside the glyphs. Drawn without the shader, the glyphs look like blurry boxes.
+//
+//	f, err := opentype.Parse(goregular.TTF)
+//	if err != nil {
+//	    panic(err)
+//	}
+//	atlas, err := text.NewSDFAtlas(f, text.SDFOptions{Size: 48}, text.ASCII)
+//	if err != nil {
+//	    panic(err)
+//	}
+//
+// Generating the distance fields is expensive, do not create a new Atlas each frame.
+func NewSDFAtlas(f *sfnt.Font, opts SDFOptions, runeSets ...[]rune) (*Atlas, error) {
+	if opts.Size <= 0 {
+		opts.Size = 32
+	}
+	if opts.Spread <= 0 {
+		opts.Spread = opts.Size / 8
+	}
+
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: opts.Size, DPI: 72})
+	if err != nil {
+		return nil, fmt.Errorf("NewSDFAtlas: %w", err)
+	}
+
+	seen := make(map[rune]bool)
+	runes := []rune{unicode.ReplacementChar}
+	for _, set := range runeSets {
+		for _, r := range set {
+			if !seen[r] {
+				runes = append(runes, r)
+				seen[r] = true
+			}
+		}
+	}
+
+	// the outlines are loaded first, the Buffer can't be used concurrently
+	var (
+		buf    sfnt.Buffer
+		ppem   = fixed.Int26_6(opts.Size * 64)
+		pad    = int(math.Ceil(opts.Spread))
+		glyphs []*sdfGlyph
+	)
+	for _, r := range runes {
+		index, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			return nil, fmt.Errorf("NewSDFAtlas: %w", err)
+		}
+		// the missing runes are replaced by unicode.ReplacementChar, or .notdef for that rune
+		if index == 0 && r != unicode.ReplacementChar {
+			continue
+		}
+		segments, err := f.LoadGlyph(&buf, index, ppem, nil)
+		if err != nil {
+			return nil, fmt.Errorf("NewSDFAtlas: rune %q: %w", r, err)
+		}
+		advance, err := f.GlyphAdvance(&buf, index, ppem, font.HintingNone)
+		if err != nil {
+			return nil, fmt.Errorf("NewSDFAtlas: rune %q: %w", r, err)
+		}
+		g := newSDFGlyph(r, segments, i2f(advance))
+		if len(g.contours) > 0 {
+			g.w = int(math.Ceil(g.bounds.Max.X)-math.Floor(g.bounds.Min.X)) + 2*pad
+			g.h = int(math.Ceil(g.bounds.Max.Y)-math.Floor(g.bounds.Min.Y)) + 2*pad
+		}
+		glyphs = append(glyphs, g)
+	}
+
+	sizes := make([]image.Point, len(glyphs))
+	for i, g := range glyphs {
+		sizes[i] = image.Pt(g.w, g.h)
+	}
+	pos, size := packRects(sizes)
+	for i, g := range glyphs {
+		g.x, g.y = pos[i].X, pos[i].Y
+	}
+	pd := pixel.MakePictureData(pixel.R(0, 0, float64(size.X), float64(size.Y)))
+
+	// the glyphs don't overlap, so they are computed concurrently
+	var wg sync.WaitGroup
+	next := make(chan *sdfGlyph)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range next {
+				g.draw(pd, pad, opts.Spread, opts.MultiChannel)
+			}
+		}()
+	}
+	for _, g := range glyphs {
+		if len(g.contours) > 0 {
+			next <- g
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	mapping := make(map[rune]Glyph)
+	for _, g := range glyphs {
+		glyph := Glyph{Advance: g.advance}
+		if len(g.contours) > 0 {
+			glyph.Frame = pixel.R(float64(g.x), float64(g.y), float64(g.x+g.w), float64(g.y+g.h))
+			glyph.Dot = pixel.V(
+				float64(g.x+pad)-math.Floor(g.bounds.Min.X),
+				float64(g.y+pad)-math.Floor(g.bounds.Min.Y),
+			)
+		}
+		mapping[g.r] = glyph
+	}
+
+	return &Atlas{
+		face:       face,
+		pic:        pd,
+		mapping:    mapping,
+		ascent:     i2f(face.Metrics().Ascent),
+		descent:    i2f(face.Metrics().Descent),
+		lineHeight: i2f(face.Metrics().Height),
+		pad:        float64(pad),
+		distRange:  2 * opts.Spread,
+	}, nil
+}
+
+// The colors of the edges of multi-channel distance fields, the channels they contribute to.
+const (
+	sdfRed   = 1
+	sdfGreen = 2
+	sdfBlue  = 4
+
+	sdfCyan    = sdfGreen | sdfBlue
+	sdfMagenta = sdfRed | sdfBlue
+	sdfYellow  = sdfRed | sdfGreen
+	sdfWhite   = sdfRed | sdfGreen | sdfBlue
+)
+
+// sdfEdge is an edge of the outline of a glyph, flattened to a polyline.
+type sdfEdge struct {
+	pts   []pixel.Vec
+	color int
+}
+
+// sdfGlyph is the outline of a glyph and the place of its distance field in the Atlas.
+type sdfGlyph struct {
+	r        rune
+	advance  float64
+	contours [][]sdfEdge
+	bounds   pixel.Rect
+
+	// orient is 1 if the outer contours go counterclockwise, -1 otherwise
+	orient float64
+
+	x, y, w, h int
+}
+
+// newSDFGlyph flattens the outline of a glyph, with the y axis pointing up.
+func newSDFGlyph(r rune, segments sfnt.Segments, advance float64) *sdfGlyph {
+	g := &sdfGlyph{r: r, advance: advance}
+
+	pt := func(p fixed.Point26_6) pixel.Vec {
+		return pixel.V(i2f(p.X), -i2f(p.Y))
+	}
+	var (
+		contour  []sdfEdge
+		start, p pixel.Vec
+	)
+	closeContour := func() {
+		if p != start {
+			contour = append(contour, sdfEdge{pts: []pixel.Vec{p, start}})
+		}
+		if len(contour) > 0 {
+			g.contours = append(g.contours, contour)
+		}
+		contour = nil
+	}
+	for _, s := range segments {
+		switch s.Op {
+		case sfnt.SegmentOpMoveTo:
+			closeContour()
+			start, p = pt(s.Args[0]), pt(s.Args[0])
+			continue
+		case sfnt.SegmentOpLineTo:
+			if q := pt(s.Args[0]); q != p {
+				contour = append(contour, sdfEdge{pts: []pixel.Vec{p, q}})
+				p = q
+			}
+		case sfnt.SegmentOpQuadTo:
+			c, q := pt(s.Args[0]), pt(s.Args[1])
+			n := flattenSteps(p.To(c).Len() + c.To(q).Len())
+			pts := make([]pixel.Vec, n+1)
+			for i := range pts {
+				t := float64(i) / float64(n)
+				pts[i] = p.Scaled((1 - t) * (1 - t)).Add(c.Scaled(2 * t * (1 - t))).Add(q.Scaled(t * t))
+			}
+			contour = append(contour, sdfEdge{pts: pts})
+			p = q
+		case sfnt.SegmentOpCubeTo:
+			c1, c2, q := pt(s.Args[0]), pt(s.Args[1]), pt(s.Args[2])
+			n := flattenSteps(p.To(c1).Len() + c1.To(c2).Len() + c2.To(q).Len())
+			pts := make([]pixel.Vec, n+1)
+			for i := range pts {
+				t := float64(i) / float64(n)
+				u := 1 - t
+				pts[i] = p.Scaled(u * u * u).Add(c1.Scaled(3 * u * u * t)).Add(c2.Scaled(3 * u * t * t)).Add(q.Scaled(t * t * t))
+			}
+			contour = append(contour, sdfEdge{pts: pts})
+			p = q
+		}
+	}
+	closeContour()
+
+	area := 0.0
+	if len(g.contours) > 0 {
+		first := g.contours[0][0].pts[0]
+		g.bounds = pixel.Rect{Min: first, Max: first}
+	}
+	for _, contour := range g.contours {
+		for _, e := range contour {
+			for j := 1; j < len(e.pts); j++ {
+				a, b := e.pts[j-1], e.pts[j]
+				area += a.Cross(b)
+				g.bounds.Min = pixel.V(math.Min(g.bounds.Min.X, b.X), math.Min(g.bounds.Min.Y, b.Y))
+				g.bounds.Max = pixel.V(math.Max(g.bounds.Max.X, b.X), math.Max(g.bounds.Max.Y, b.Y))
+			}
+		}
+	}
+	g.orient = 1
+	if area < 0 {
+		g.orient = -1
+	}
+	for i := range g.contours {
+		colorEdges(g.contours[i])
+	}
+	return g
+}
+
+// flattenSteps returns the number of lines a curve with the control polygon of the length is
+// flattened to.
+func flattenSteps(length float64) int {
+	return max(2, min(32, int(math.Ceil(length/2))))
+}
+
+// colorEdges assigns the colors to the edges of a contour so that the edges meeting at a corner
+// have different colors which share a channel.
+func colorEdges(contour []sdfEdge) {
+	var corners []int
+	for i := range contour {
+		prev := contour[(i+len(contour)-1)%len(contour)]
+		a := prev.pts[len(prev.pts)-2].To(prev.pts[len(prev.pts)-1]).Unit()
+		b := contour[i].pts[0].To(contour[i].pts[1]).Unit()
+		if a.Dot(b) <= 0 || math.Abs(a.Cross(b)) > 0.14 {
+			corners = append(corners, i)
+		}
+	}
+
+	switch len(corners) {
+	case 0:
+		// a smooth contour
+		for i := range contour {
+			contour[i].color = sdfWhite
+		}
+	case 1:
+		// a teardrop is split into three parts by the position of the edges, the first and the last
+		// edges meeting at the corner
+		colors := [3]int{sdfMagenta, sdfWhite, sdfYellow}
+		for i := range contour {
+			j := (corners[0] + i) % len(contour)
+			contour[j].color = colors[min(2, 3*i/len(contour))]
+		}
+		if len(contour) < 3 {
+			// too few edges to split, but they still differ at the corner
+			contour[corners[0]].color = sdfMagenta
+			contour[(corners[0]+len(contour)-1)%len(contour)].color = sdfYellow
+		}
+	default:
+		colors := [3]int{sdfCyan, sdfMagenta, sdfYellow}
+		for k, c := range corners {
+			color := colors[k%3]
+			if k == len(corners)-1 && k%3 == 0 {
+				// the last spline meets the first one
+				color = colors[1]
+			}
+			end := len(contour)
+			if k+1 < len(corners) {
+				end = corners[k+1]
+			} else {
+				end = corners[0] + len(contour)
+			}
+			for i := c; i < end; i++ {
+				contour[i%len(contour)].color = color
+			}
+		}
+	}
+}
+
+// sdfClosest is the closest point of an edge.
+type sdfClosest struct {
+	d2    float64
+	ortho float64
+	edge  *sdfEdge
+	seg   int
+	t     float64
+}
+
+// better reports whether the closest point c is closer than o, or as close and more orthogonal to
+// its edge.
+func (c sdfClosest) better(o sdfClosest) bool {
+	const eps = 1e-9
+	if o.edge == nil || c.d2 < o.d2-eps {
+		return true
+	}
+	return c.d2 <= o.d2+eps && c.ortho > o.ortho
+}
+
+// draw computes the distance field of the glyph into its place in the Picture.
+func (g *sdfGlyph) draw(pd *pixel.PictureData, pad int, spread float64, multiChannel bool) {
+	origin := pixel.V(float64(g.x+pad)-math.Floor(g.bounds.Min.X), float64(g.y+pad)-math.Floor(g.bounds.Min.Y))
+	encode := func(d float64) uint8 {
+		return uint8(math.Round(pixel.Clamp(0.5+d/(2*spread), 0, 1) * 255))
+	}
+
+	for y := g.y; y < g.y+g.h; y++ {
+		for x := g.x; x < g.x+g.w; x++ {
+			p := pixel.V(float64(x)+0.5, float64(y)+0.5).Sub(origin)
+
+			var (
+				closest  sdfClosest
+				channels [3]sdfClosest
+				winding  int
+			)
+			for ci := range g.contours {
+				for ei := range g.contours[ci] {
+					e := &g.contours[ci][ei]
+					var best sdfClosest
+					for j := 1; j < len(e.pts); j++ {
+						a, b := e.pts[j-1], e.pts[j]
+						ab := a.To(b)
+						ap := a.To(p)
+						t := pixel.Clamp(ap.Dot(ab)/ab.Dot(ab), 0, 1)
+						qp := a.Add(ab.Scaled(t)).To(p)
+						c := sdfClosest{d2: qp.Dot(qp), edge: e, seg: j - 1, t: t}
+						if qp.Len() > 0 {
+							c.ortho = math.Abs(ab.Unit().Cross(qp.Unit()))
+						}
+						if c.better(best) {
+							best = c
+						}
+
+						// the nonzero winding rule
+						if a.Y <= p.Y && b.Y > p.Y && ab.Cross(ap) > 0 {
+							winding++
+						} else if b.Y <= p.Y && a.Y > p.Y && ab.Cross(ap) < 0 {
+							winding--
+						}
+					}
+					if best.better(closest) {
+						closest = best
+					}
+					for k := range channels {
+						if e.color&(1<<k) != 0 && best.better(channels[k]) {
+							channels[k] = best
+						}
+					}
+				}
+			}
+
+			dist := math.Sqrt(closest.d2)
+			if winding == 0 {
+				dist = -dist
+			}
+			col := color.RGBA{encode(dist), encode(dist), encode(dist), encode(dist)}
+			if multiChannel {
+				var d [3]float64
+				for k := range channels {
+					d[k] = g.pseudoDistance(channels[k], p)
+				}
+				// the channels disagreeing with the true distance would produce artifacts
+				if m := median(d[0], d[1], d[2]); (m > 0) == (dist > 0) {
+					col.R, col.G, col.B = encode(d[0]), encode(d[1]), encode(d[2])
+				}
+			}
+			pd.Pix[pd.Index(pixel.V(float64(x), float64(y)))] = col
+		}
+	}
+}
+
+// pseudoDistance returns the signed distance of the point to the edge of the closest point,
+// extended past its ends along their tangents.
+func (g *sdfGlyph) pseudoDistance(c sdfClosest, p pixel.Vec) float64 {
+	if c.edge == nil {
+		return math.Inf(-1)
+	}
+	pts := c.edge.pts
+	a, b := pts[c.seg], pts[c.seg+1]
+	ab := a.To(b)
+
+	dist := math.Sqrt(c.d2)
+	switch {
+	case c.seg == 0 && c.t == 0:
+		// before the start of the edge, the distance to its tangent
+		if a.To(p).Dot(ab) < 0 {
+			dist = math.Abs(ab.Unit().Cross(a.To(p)))
+		}
+	case c.seg == len(pts)-2 && c.t == 1:
+		if b.To(p).Dot(ab) > 0 {
+			dist = math.Abs(ab.Unit().Cross(b.To(p)))
+		}
+	}
+	if ab.Cross(a.To(p))*g.orient < 0 {
+		dist = -dist
+	}
+	return dist
+}
+
+func median(a, b, c float64) float64 {
+	return math.Max(math.Min(a, b), math.Min(math.Max(a, b), c))
+}
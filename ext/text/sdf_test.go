@@ -0,0 +1,100 @@
+package text_test
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func TestNewSDFAtlas(t *testing.T) {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: 32, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := text.NewAtlas(face, text.ASCII)
+	plainPic := plain.Picture().(*pixel.PictureData)
+
+	for _, multiChannel := range []bool{false, true} {
+		atlas, err := text.NewSDFAtlas(f, text.SDFOptions{MultiChannel: multiChannel}, text.ASCII)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := atlas.DistanceRange(), 8.0; got != want {
+			t.Errorf("DistanceRange() = %v, want %v", got, want)
+		}
+		if got, want := atlas.LineHeight(), plain.LineHeight(); got != want {
+			t.Errorf("LineHeight() = %v, want %v", got, want)
+		}
+		if plain.DistanceRange() != 0 {
+			t.Errorf("DistanceRange() = %v for a plain Atlas", plain.DistanceRange())
+		}
+
+		// the text is laid out like the text of a plain Atlas of the same size
+		const s = "Hello, SDF world!"
+		txt, plainTxt := text.New(pixel.ZV, atlas), text.New(pixel.ZV, plain)
+		if got, want := txt.BoundsOf(s), plainTxt.BoundsOf(s); math.Abs(got.Min.X-want.Min.X) > 1 ||
+			math.Abs(got.Max.X-want.Max.X) > 1 || got.Min.Y != want.Min.Y || got.Max.Y != want.Max.Y {
+			t.Errorf("BoundsOf(%q) = %v, want %v", s, got, want)
+		}
+
+		// the signs of the distances match the coverage of the rasterized glyphs
+		pic := atlas.Picture().(*pixel.PictureData)
+		checked, wrong := 0, 0
+		for _, r := range text.ASCII {
+			g, pg := atlas.Glyph(r), plain.Glyph(r)
+			if !atlas.Contains(r) || g.Advance != pg.Advance {
+				t.Errorf("Glyph(%q) = %v, want the advance %v", r, g, pg.Advance)
+				continue
+			}
+			for y := g.Frame.Min.Y; y < g.Frame.Max.Y; y++ {
+				for x := g.Frame.Min.X; x < g.Frame.Max.X; x++ {
+					at := pixel.V(x, y)
+					coverage := 0.0
+					if p := at.Sub(g.Dot).Add(pg.Dot); pg.Frame.Contains(p) {
+						coverage = plainPic.Color(p).A
+					}
+					if coverage > 0.1 && coverage < 0.9 {
+						continue
+					}
+					c := pic.Color(at)
+					inside := c.A > 0.5
+					if multiChannel {
+						inside = median(c.R, c.G, c.B) > 0.5
+					}
+					checked++
+					if inside != (coverage >= 0.9) {
+						wrong++
+					}
+				}
+			}
+		}
+		if checked == 0 || wrong > checked/200 {
+			t.Errorf("MultiChannel %v: %d of %d pixels on the wrong side", multiChannel, wrong, checked)
+		}
+	}
+}
+
+func median(a, b, c float64) float64 {
+	return math.Max(math.Min(a, b), math.Min(math.Max(a, b), c))
+}
+
+func BenchmarkNewSDFAtlas(b *testing.B) {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := text.NewSDFAtlas(f, text.SDFOptions{MultiChannel: true}, text.ASCII); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
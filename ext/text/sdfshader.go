@@ -0,0 +1,137 @@
+package text
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/gopxl/pixel/v2"
+)
+
+// SDFFragmentShader is a fragment shader for the opengl backend drawing the text of an Atlas
+// created by NewSDFAtlas, crisp at any scale. It draws everything else like the default shader.
+//
+// The shader needs the uniforms of SDFEffects, which must be set before the shader:
+//
+//	canvas := opengl.NewCanvas(win.Bounds())
+//	canvas.SetSmooth(true)
+//	text.SDFEffects{OutlineWidth: 2, OutlineColor: pixel.RGB(0, 0, 0)}.SetUniforms(canvas, atlas)
+//	canvas.SetFragmentShader(text.SDFFragmentShader)
+//
+// The Canvas must be smooth. The effects can be changed later by calling SetUniforms again.
+// Pictures other than the Atlas's Picture would be drawn as distance fields too, so the text is
+// best drawn onto a dedicated Canvas.
+const SDFFragmentShader = `
+#version 330 core
+
+in vec4  vColor;
+in vec2  vTexCoords;
+in float vIntensity;
+in vec4  vClipRect;
+
+out vec4 fragColor;
+
+uniform vec4 uColorMask;
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+uniform float uSDFRange;
+uniform float uSDFWeight;
+uniform float uSDFOutlineWidth;
+uniform vec4  uSDFOutlineColor;
+uniform float uSDFGlowWidth;
+uniform vec4  uSDFGlowColor;
+uniform vec2  uSDFShadowOffset;
+uniform float uSDFShadowSoftness;
+uniform vec4  uSDFShadowColor;
+
+float median(float r, float g, float b) {
+	return max(min(r, g), min(max(r, g), b));
+}
+
+// over composites the premultiplied color src over dst
+vec4 over(vec4 src, vec4 dst) {
+	return src + dst * (1 - src.a);
+}
+
+void main() {
+	if ((vClipRect != vec4(0,0,0,0)) && (gl_FragCoord.x < vClipRect.x || gl_FragCoord.y < vClipRect.y || gl_FragCoord.x > vClipRect.z || gl_FragCoord.y > vClipRect.w))
+		discard;
+
+	if (vIntensity == 0) {
+		fragColor = uColorMask * vColor;
+		return;
+	}
+
+	vec2 size = vec2(textureSize(uTexture, 0));
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+
+	// the number of pixels on the screen per pixel of the atlas, for antialiasing
+	vec2 scale = 1 / (fwidth(t) * size);
+	float px = max(0.5 * (scale.x + scale.y), 0.001);
+
+	// the distances are in pixels of the atlas, positive inside the glyphs
+	vec4 s = texture(uTexture, t);
+	float d = (median(s.r, s.g, s.b) - 0.5) * uSDFRange + uSDFWeight;
+	float sd = (s.a - 0.5) * uSDFRange + uSDFWeight;
+
+	vec4 col = vec4(0, 0, 0, 0);
+	if (uSDFShadowColor.a > 0) {
+		float shadow = (texture(uTexture, t - uSDFShadowOffset / size).a - 0.5) * uSDFRange + uSDFWeight;
+		col = smoothstep(-uSDFShadowSoftness - 0.5 / px, 0.5 / px, shadow) * uSDFShadowColor;
+	}
+	if (uSDFGlowWidth > 0) {
+		col = over(smoothstep(-uSDFGlowWidth, 0, sd) * uSDFGlowColor, col);
+	}
+	if (uSDFOutlineWidth > 0) {
+		col = over(clamp((sd + uSDFOutlineWidth) * px + 0.5, 0, 1) * uSDFOutlineColor, col);
+	}
+	col *= vColor.a;
+	col = over(clamp(d * px + 0.5, 0, 1) * vColor, col);
+
+	fragColor = (1 - vIntensity) * vColor + vIntensity * col;
+	fragColor *= uColorMask;
+}
+`
+
+// SDFEffects are the parameters of SDFFragmentShader. The distances are in pixels of the Atlas, so
+// they scale with the text, and they are limited by its spread, see SDFOptions.
+//
+// The zero value draws the plain text.
+type SDFEffects struct {
+	// Weight makes the glyphs bolder if positive, or thinner if negative.
+	Weight float64
+
+	// OutlineWidth and OutlineColor are the width and the color of the outline around the glyphs.
+	OutlineWidth float64
+	OutlineColor pixel.RGBA
+
+	// GlowWidth and GlowColor are the width and the color of the glow fading out around the glyphs.
+	GlowWidth float64
+	GlowColor pixel.RGBA
+
+	// ShadowOffset, ShadowSoftness and ShadowColor are the offset, the width of the blurred edge
+	// and the color of the drop shadow of the glyphs.
+	ShadowOffset   pixel.Vec
+	ShadowSoftness float64
+	ShadowColor    pixel.RGBA
+}
+
+// UniformSetter is a target of the uniforms of a shader, like *opengl.Canvas.
+type UniformSetter interface {
+	SetUniform(name string, value interface{})
+}
+
+// SetUniforms sets the uniforms of SDFFragmentShader for drawing the text of the Atlas with the
+// effects.
+func (e SDFEffects) SetUniforms(target UniformSetter, atlas *Atlas) {
+	vec4 := func(c pixel.RGBA) mgl32.Vec4 {
+		return mgl32.Vec4{float32(c.R), float32(c.G), float32(c.B), float32(c.A)}
+	}
+	target.SetUniform("uSDFRange", float32(atlas.DistanceRange()))
+	target.SetUniform("uSDFWeight", float32(e.Weight))
+	target.SetUniform("uSDFOutlineWidth", float32(e.OutlineWidth))
+	target.SetUniform("uSDFOutlineColor", vec4(e.OutlineColor))
+	target.SetUniform("uSDFGlowWidth", float32(e.GlowWidth))
+	target.SetUniform("uSDFGlowColor", vec4(e.GlowColor))
+	target.SetUniform("uSDFShadowOffset", mgl32.Vec2{float32(e.ShadowOffset.X), float32(e.ShadowOffset.Y)})
+	target.SetUniform("uSDFShadowSoftness", float32(e.ShadowSoftness))
+	target.SetUniform("uSDFShadowColor", vec4(e.ShadowColor))
+}
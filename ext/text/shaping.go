@@ -0,0 +1,531 @@
+package text
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-text/typesetting/di"
+	gotext "github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/language"
+	"github.com/go-text/typesetting/shaping"
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// shapingFont is the font of an Atlas created by NewShapingAtlas.
+type shapingFont struct {
+	font   *gotext.Font
+	size   fixed.Int26_6
+	glyphs map[gotext.GID]Glyph
+}
+
+// NewShapingAtlas creates a new Atlas containing all the glyphs of an OpenType or TrueType font of
+// the size in pixels per em, rounded up to whole pixels. Unlike the other Atlases, it can be used by
+// ShapedText, which draws the glyphs chosen by a text shaper, and it can be used by Text too.
+//
+// All the glyphs are drawn, because the glyphs of the ligatures and of the contextual forms of the
+// complex scripts can't be known in advance. Subset the large fonts to the scripts in use.
+func NewShapingAtlas(ttf []byte, size float64) (*Atlas, error) {
+	size = math.Ceil(size)
+	f, err := sfnt.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("NewShapingAtlas: %w", err)
+	}
+	shaped, err := gotext.ParseTTF(bytes.NewReader(ttf))
+	if err != nil {
+		return nil, fmt.Errorf("NewShapingAtlas: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72})
+	if err != nil {
+		return nil, fmt.Errorf("NewShapingAtlas: %w", err)
+	}
+
+	var (
+		buf     sfnt.Buffer
+		ppem    = fixed.Int26_6(size * 64)
+		n       = f.NumGlyphs()
+		rects   = make([]image.Rectangle, n)
+		sizes   = make([]image.Point, n)
+		advance = make([]float64, n)
+	)
+	for i := 0; i < n; i++ {
+		bounds, adv, err := f.GlyphBounds(&buf, sfnt.GlyphIndex(i), ppem, font.HintingNone)
+		if err != nil {
+			return nil, fmt.Errorf("NewShapingAtlas: glyph %d: %w", i, err)
+		}
+		rects[i] = image.Rect(bounds.Min.X.Floor(), bounds.Min.Y.Floor(), bounds.Max.X.Ceil(), bounds.Max.Y.Ceil())
+		sizes[i] = rects[i].Size()
+		advance[i] = i2f(adv)
+	}
+	pos, picSize := packRects(sizes)
+
+	// the glyphs are drawn white, with the y axis pointing down like in the outlines
+	img := image.NewRGBA(image.Rectangle{Max: picSize})
+	var z vector.Rasterizer
+	for i, r := range rects {
+		if r.Empty() {
+			continue
+		}
+		segments, err := f.LoadGlyph(&buf, sfnt.GlyphIndex(i), ppem, nil)
+		if err != nil {
+			return nil, fmt.Errorf("NewShapingAtlas: glyph %d: %w", i, err)
+		}
+		z.Reset(r.Dx(), r.Dy())
+		pt := func(p fixed.Point26_6) (float32, float32) {
+			return float32(p.X)/64 - float32(r.Min.X), float32(p.Y)/64 - float32(r.Min.Y)
+		}
+		for _, s := range segments {
+			switch s.Op {
+			case sfnt.SegmentOpMoveTo:
+				z.MoveTo(pt(s.Args[0]))
+			case sfnt.SegmentOpLineTo:
+				z.LineTo(pt(s.Args[0]))
+			case sfnt.SegmentOpQuadTo:
+				x1, y1 := pt(s.Args[0])
+				x2, y2 := pt(s.Args[1])
+				z.QuadTo(x1, y1, x2, y2)
+			case sfnt.SegmentOpCubeTo:
+				x1, y1 := pt(s.Args[0])
+				x2, y2 := pt(s.Args[1])
+				x3, y3 := pt(s.Args[2])
+				z.CubeTo(x1, y1, x2, y2, x3, y3)
+			}
+		}
+		z.ClosePath()
+		z.Draw(img, image.Rectangle{Min: pos[i], Max: pos[i].Add(sizes[i])}, image.White, image.Point{})
+	}
+
+	height := float64(picSize.Y)
+	glyphs := make(map[gotext.GID]Glyph, n)
+	for i, r := range rects {
+		g := Glyph{Advance: advance[i]}
+		if !r.Empty() {
+			at := pos[i]
+			g.Frame = pixel.R(float64(at.X), height-float64(at.Y+r.Dy()), float64(at.X+r.Dx()), height-float64(at.Y))
+			g.Dot = pixel.V(float64(at.X-r.Min.X), height-float64(at.Y-r.Min.Y))
+		}
+		glyphs[gotext.GID(i)] = g
+	}
+
+	mapping := make(map[rune]Glyph)
+	for it := shaped.Cmap.Iter(); it.Next(); {
+		r, gid := it.Char()
+		if g, ok := glyphs[gid]; ok {
+			mapping[r] = g
+		}
+	}
+	if _, ok := mapping[unicode.ReplacementChar]; !ok {
+		mapping[unicode.ReplacementChar] = glyphs[0]
+	}
+
+	return &Atlas{
+		face:       face,
+		pic:        pixel.PictureDataFromImage(img),
+		mapping:    mapping,
+		ascent:     i2f(face.Metrics().Ascent),
+		descent:    i2f(face.Metrics().Descent),
+		lineHeight: i2f(face.Metrics().Height),
+		shaping: &shapingFont{
+			font:   shaped.Font,
+			size:   ppem,
+			glyphs: glyphs,
+		},
+	}, nil
+}
+
+// Direction is the base direction of the paragraphs of a ShapedText.
+type Direction int
+
+const (
+	// DirectionAuto takes the direction of each paragraph from its first letter with a strong
+	// direction, or left-to-right without one.
+	DirectionAuto Direction = iota
+	// DirectionLTR is the left-to-right direction of the Latin scripts.
+	DirectionLTR
+	// DirectionRTL is the right-to-left direction of the Arabic and Hebrew scripts.
+	DirectionRTL
+)
+
+// String returns the name of the direction.
+func (d Direction) String() string {
+	switch d {
+	case DirectionAuto:
+		return "DirectionAuto"
+	case DirectionLTR:
+		return "DirectionLTR"
+	case DirectionRTL:
+		return "DirectionRTL"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// ShapedGlyph is a glyph of a ShapedText positioned by the shaper.
+type ShapedGlyph struct {
+	// Start and End are the byte offsets of the cluster of the glyph in the text. A cluster is the
+	// smallest part of the text which can't be split, like a ligature or a letter with its marks,
+	// and all its glyphs have the same offsets.
+	Start, End int
+
+	// Line is the index of the line of the glyph.
+	Line int
+
+	// Dot is the position of the glyph on the baseline, and Advance the distance to the next glyph.
+	Dot     pixel.Vec
+	Advance float64
+
+	// Rect is the rectangle the glyph is drawn in, which is empty for the glyphs without an image,
+	// like spaces.
+	Rect pixel.Rect
+
+	// RTL reports whether the glyph is in a right-to-left run of the text.
+	RTL bool
+}
+
+// ShapedText draws text of the complex scripts, like Arabic, Hebrew or Devanagari, with the glyphs
+// chosen and positioned by a text shaper. The shaper applies the ligatures, the contextual forms
+// and the positioning of the marks of the font, and the Unicode bidirectional algorithm orders the
+// runs of mixed-direction lines:
+//
+//	atlas, err := text.NewShapingAtlas(ttf, 24)
+//	if err != nil {
+//		panic(err)
+//	}
+//	st := text.NewShapedText(pixel.V(100, 100), atlas)
+//	st.WrapWidth = 300
+//	st.SetText("Hello, שלום, مرحبا!")
+//	st.Draw(win, pixel.IM)
+//
+// Shaping is slower than the simple layout of Text, which maps each rune to a glyph of its Atlas
+// and only applies kerning, so Text remains the way to draw text of the simple scripts.
+type ShapedText struct {
+	// Orig is the position of the dot at the start of the first line, on its baseline.
+	Orig pixel.Vec
+
+	// Color is the color of the text. Defaults to white.
+	Color color.Color
+
+	// LineHeight is the vertical distance between two lines of text. Defaults to the line height of
+	// the Atlas.
+	LineHeight float64
+
+	// WrapWidth wraps the lines at the line breaking opportunities of the text to fit in the width
+	// from Orig, if positive.
+	WrapWidth float64
+
+	// Align aligns the lines within the wrap width, or around Orig without one. AlignLeft aligns
+	// them to the start of their paragraph and AlignRight to its end, which are the right and the
+	// left of the right-to-left paragraphs. AlignJustify is the same as AlignLeft.
+	Align Align
+
+	// Direction is the base direction of the paragraphs.
+	Direction Direction
+
+	// Language is the BCP 47 language tag of the text, like "ar" or "hi", which selects the
+	// language-specific forms of the font. Defaults to the language of the script of the text.
+	Language string
+
+	// The fields above are applied by SetText.
+
+	atlas   *Atlas
+	face    *gotext.Face
+	shaper  shaping.HarfbuzzShaper
+	seg     shaping.Segmenter
+	wrapper shaping.LineWrapper
+
+	text   string
+	glyphs []ShapedGlyph
+	bounds pixel.Rect
+
+	tris   pixel.TrianglesData
+	trans  pixel.TrianglesData
+	transD pixel.Drawer
+	mat    pixel.Matrix
+	col    pixel.RGBA
+	dirty  bool
+}
+
+// NewShapedText creates a new ShapedText drawing text with the Atlas, starting at orig. The Atlas
+// must be created by NewShapingAtlas.
+func NewShapedText(orig pixel.Vec, atlas *Atlas) *ShapedText {
+	if atlas.shaping == nil {
+		panic(fmt.Errorf("text.NewShapedText: the Atlas isn't created by NewShapingAtlas"))
+	}
+	st := &ShapedText{
+		Orig:       orig,
+		Color:      pixel.Alpha(1),
+		LineHeight: atlas.LineHeight(),
+		atlas:      atlas,
+		// the faces cache the extents of the glyphs, each ShapedText has its own
+		face: gotext.NewFace(atlas.shaping.font),
+		mat:  pixel.IM,
+		col:  pixel.Alpha(1),
+	}
+	st.transD.Picture = atlas.Picture()
+	st.transD.Triangles = &st.trans
+	st.transD.Cached = true
+	return st
+}
+
+// Atlas returns the Atlas of the ShapedText.
+func (st *ShapedText) Atlas() *Atlas {
+	return st.atlas
+}
+
+// SetText replaces the text of the ShapedText and shapes and lays it out using the current values
+// of the fields of the ShapedText. Each paragraph of the text ends with a newline.
+func (st *ShapedText) SetText(s string) {
+	st.text = s
+	st.glyphs = st.glyphs[:0]
+	st.tris.SetLen(0)
+	st.bounds = pixel.Rect{}
+	st.dirty = true
+
+	dot := st.Orig
+	line := 0
+	for start := 0; start <= len(s); {
+		end := strings.IndexByte(s[start:], '\n')
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += start
+		}
+		line = st.shapeParagraph(s[start:end], start, line, &dot)
+		start = end + 1
+	}
+}
+
+// singleFace is a shaping.Fontmap of a single face.
+type singleFace struct {
+	face *gotext.Face
+}
+
+func (sf singleFace) ResolveFace(rune) *gotext.Face {
+	return sf.face
+}
+
+// shapeParagraph shapes and lays out a paragraph starting at the byte offset of the text, from
+// the line at the dot, and returns the index of the next line.
+func (st *ShapedText) shapeParagraph(s string, offset, line int, dot *pixel.Vec) int {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		dot.Y -= st.LineHeight
+		return line + 1
+	}
+
+	// offsets maps the indices of the runes to their byte offsets in the text
+	offsets := make([]int, 0, len(runes)+1)
+	for i := range s {
+		offsets = append(offsets, offset+i)
+	}
+	offsets = append(offsets, offset+len(s))
+
+	dir := di.DirectionLTR
+	if st.Direction == DirectionRTL || st.Direction == DirectionAuto && isRTL(runes) {
+		dir = di.DirectionRTL
+	}
+	input := shaping.Input{
+		Text:      runes,
+		RunStart:  0,
+		RunEnd:    len(runes),
+		Direction: dir,
+		Face:      st.face,
+		Size:      st.atlas.shaping.size,
+		Language:  language.NewLanguage(st.Language),
+	}
+	runs := st.seg.Split(input, singleFace{st.face})
+	outputs := make([]shaping.Output, len(runs))
+	for i, run := range runs {
+		outputs[i] = st.shaper.Shape(run)
+	}
+
+	maxWidth := fixed.Int26_6(math.MaxInt32)
+	if st.WrapWidth > 0 {
+		maxWidth = fixed.Int26_6(st.WrapWidth * 64)
+	}
+	lines, _ := st.wrapper.WrapParagraphF(shaping.WrapConfig{Direction: dir}, maxWidth, runes, shaping.NewSliceIterator(outputs))
+
+	for _, l := range lines {
+		sort.SliceStable(l, func(i, j int) bool {
+			return l[i].VisualIndex < l[j].VisualIndex
+		})
+		width := 0.0
+		for _, run := range l {
+			width += i2f(run.Advance)
+		}
+		dot.X = st.Orig.X + st.lineShift(width, dir)
+
+		lineStart := dot.X
+		for _, run := range l {
+			rtl := run.Direction.Progression() == di.TowardTopLeft
+			for _, g := range run.Glyphs {
+				st.addGlyph(ShapedGlyph{
+					Start:   offsets[g.TextIndex()],
+					End:     offsets[g.TextIndex()+g.RunesCount()],
+					Line:    line,
+					Dot:     *dot,
+					Advance: i2f(g.Advance),
+					RTL:     rtl,
+				}, g)
+				dot.X += i2f(g.Advance)
+			}
+		}
+
+		st.bounds = unionBounds(st.bounds, pixel.R(
+			lineStart,
+			dot.Y-st.atlas.Descent(),
+			lineStart+width,
+			dot.Y+st.atlas.Ascent(),
+		))
+		dot.Y -= st.LineHeight
+		line++
+	}
+	return line
+}
+
+// lineShift returns the distance of the start of a line of the width from Orig.
+func (st *ShapedText) lineShift(width float64, dir di.Direction) float64 {
+	align := st.Align
+	if align == AlignJustify {
+		align = AlignLeft
+	}
+	if dir == di.DirectionRTL {
+		switch align {
+		case AlignLeft:
+			align = AlignRight
+		case AlignRight:
+			align = AlignLeft
+		}
+	}
+	switch align {
+	case AlignCenter:
+		return (st.WrapWidth - width) / 2
+	case AlignRight:
+		return st.WrapWidth - width
+	default:
+		return 0
+	}
+}
+
+// addGlyph adds the shaped glyph and its triangles.
+func (st *ShapedText) addGlyph(sg ShapedGlyph, g shaping.Glyph) {
+	glyph := st.atlas.shaping.glyphs[g.GlyphID]
+	if glyph.Frame.W()*glyph.Frame.H() == 0 {
+		st.glyphs = append(st.glyphs, sg)
+		return
+	}
+	origin := sg.Dot.Add(pixel.V(i2f(g.XOffset), i2f(g.YOffset)))
+	sg.Rect = glyph.Frame.Moved(origin.Sub(glyph.Dot))
+	st.glyphs = append(st.glyphs, sg)
+
+	rv := [...]pixel.Vec{
+		{X: sg.Rect.Min.X, Y: sg.Rect.Min.Y},
+		{X: sg.Rect.Max.X, Y: sg.Rect.Min.Y},
+		{X: sg.Rect.Max.X, Y: sg.Rect.Max.Y},
+		{X: sg.Rect.Min.X, Y: sg.Rect.Max.Y},
+	}
+	fv := [...]pixel.Vec{
+		{X: glyph.Frame.Min.X, Y: glyph.Frame.Min.Y},
+		{X: glyph.Frame.Max.X, Y: glyph.Frame.Min.Y},
+		{X: glyph.Frame.Max.X, Y: glyph.Frame.Max.Y},
+		{X: glyph.Frame.Min.X, Y: glyph.Frame.Max.Y},
+	}
+	col := pixel.ToRGBA(st.Color)
+	n := st.tris.Len()
+	st.tris.SetLen(n + 6)
+	for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+		st.tris[n+i].Position = rv[j]
+		st.tris[n+i].Picture = fv[j]
+		st.tris[n+i].Color = col
+		st.tris[n+i].Intensity = 1
+	}
+}
+
+// isRTL reports whether the first letter with a strong direction is right-to-left.
+func isRTL(runes []rune) bool {
+	for _, r := range runes {
+		props, _ := bidi.LookupRune(r)
+		switch props.Class() {
+		case bidi.L:
+			return false
+		case bidi.R, bidi.AL:
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the text of the ShapedText.
+func (st *ShapedText) Text() string {
+	return st.text
+}
+
+// Glyphs returns the glyphs of the text in the visual order of each line, from the left. The
+// glyphs are in the coordinates of the ShapedText, before the matrix it's drawn with.
+func (st *ShapedText) Glyphs() []ShapedGlyph {
+	return st.glyphs
+}
+
+// GlyphAt returns the glyph at the point, for example the glyph under the mouse, using the
+// advances of the glyphs and the lines of the text.
+func (st *ShapedText) GlyphAt(pos pixel.Vec) (ShapedGlyph, bool) {
+	for _, g := range st.glyphs {
+		r := pixel.R(g.Dot.X, g.Dot.Y-st.atlas.Descent(), g.Dot.X+g.Advance, g.Dot.Y+st.atlas.Ascent())
+		if r.Contains(pos) {
+			return g, true
+		}
+	}
+	return ShapedGlyph{}, false
+}
+
+// Bounds returns the bounding box of the lines of the text of the ShapedText.
+func (st *ShapedText) Bounds() pixel.Rect {
+	return st.bounds
+}
+
+// Draw draws the text of the ShapedText onto the Target, transformed by the Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (st *ShapedText) Draw(t pixel.Target, matrix pixel.Matrix) {
+	st.DrawColorMask(t, matrix, nil)
+}
+
+// DrawColorMask draws the text of the ShapedText onto the Target, transformed by the Matrix and
+// masked by the color mask.
+func (st *ShapedText) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	if matrix != st.mat {
+		st.mat = matrix
+		st.dirty = true
+	}
+	if mask == nil {
+		mask = pixel.Alpha(1)
+	}
+	if rgba := pixel.ToRGBA(mask); rgba != st.col {
+		st.col = rgba
+		st.dirty = true
+	}
+
+	if st.dirty {
+		st.trans.SetLen(st.tris.Len())
+		st.trans.Update(&st.tris)
+		for i := range st.trans {
+			st.trans[i].Position = st.mat.Project(st.trans[i].Position)
+			st.trans[i].Color = st.trans[i].Color.Mul(st.col)
+		}
+		st.transD.Dirty()
+		st.dirty = false
+	}
+
+	st.transD.Draw(t)
+}
@@ -0,0 +1,187 @@
+package text_test
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+// The Go fonts don't have Hebrew glyphs, but the missing glyphs are still ordered by the
+// bidirectional algorithm.
+
+func shapingAtlas(t testing.TB) *text.Atlas {
+	atlas, err := text.NewShapingAtlas(goregular.TTF, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return atlas
+}
+
+func TestNewShapingAtlas(t *testing.T) {
+	atlas := shapingAtlas(t)
+
+	// Text draws the same glyphs as with an Atlas of the same face
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: 16, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func(atlas *text.Atlas) *pixel.PictureData {
+		return pixeltest.Render(pixel.R(0, 0, 128, 24), func(target pixel.Target) {
+			txt := text.New(pixel.V(2, 6), atlas)
+			fmt.Fprint(txt, "Hello, world! Ωé")
+			txt.Draw(target, pixel.IM)
+		})
+	}
+	if diff, _ := pixeltest.Compare(render(atlas), render(text.NewAtlas(face, text.ASCII, []rune("Ωé"))), 1); diff != 0 {
+		t.Errorf("%d pixels differ from an Atlas of the face", diff)
+	}
+
+	if _, err := text.NewShapingAtlas([]byte("not a font"), 16); err == nil {
+		t.Error("NewShapingAtlas() error = nil for an invalid font")
+	}
+}
+
+func TestShapedText(t *testing.T) {
+	atlas := shapingAtlas(t)
+
+	type glyph struct {
+		start, end, line int
+		rtl              bool
+	}
+	tests := []struct {
+		name  string
+		setup func(st *text.ShapedText)
+		s     string
+		want  []glyph
+	}{
+		{
+			name:  "composed mark",
+			setup: func(st *text.ShapedText) {},
+			s:     "é",
+			want:  []glyph{{0, 3, 0, false}},
+		},
+		{
+			name:  "mixed directions",
+			setup: func(st *text.ShapedText) {},
+			s:     "ab אב",
+			want:  []glyph{{0, 1, 0, false}, {1, 2, 0, false}, {2, 3, 0, false}, {5, 7, 0, true}, {3, 5, 0, true}},
+		},
+		{
+			name:  "right-to-left paragraph",
+			setup: func(st *text.ShapedText) {},
+			s:     "אב ab",
+			want:  []glyph{{5, 6, 0, false}, {6, 7, 0, false}, {4, 5, 0, true}, {2, 4, 0, true}, {0, 2, 0, true}},
+		},
+		{
+			name:  "forced left-to-right",
+			setup: func(st *text.ShapedText) { st.Direction = text.DirectionLTR },
+			s:     "אב ab",
+			want:  []glyph{{2, 4, 0, true}, {0, 2, 0, true}, {4, 5, 0, false}, {5, 6, 0, false}, {6, 7, 0, false}},
+		},
+		{
+			name:  "paragraphs",
+			setup: func(st *text.ShapedText) {},
+			s:     "a\n\nb",
+			want:  []glyph{{0, 1, 0, false}, {3, 4, 2, false}},
+		},
+		{
+			name:  "wrap",
+			setup: func(st *text.ShapedText) { st.WrapWidth = 30 },
+			s:     "ab cd",
+			want:  []glyph{{0, 1, 0, false}, {1, 2, 0, false}, {2, 3, 0, false}, {3, 4, 1, false}, {4, 5, 1, false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := text.NewShapedText(pixel.ZV, atlas)
+			tt.setup(st)
+			st.SetText(tt.s)
+
+			var got []glyph
+			for _, g := range st.Glyphs() {
+				got = append(got, glyph{g.Start, g.End, g.Line, g.RTL})
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Glyphs() =\n%v\nwant\n%v", got, tt.want)
+			}
+
+			// the glyphs follow each other on their lines
+			glyphs := st.Glyphs()
+			for i := 1; i < len(glyphs); i++ {
+				prev, g := glyphs[i-1], glyphs[i]
+				if g.Line == prev.Line && !eqVectors(g.Dot, prev.Dot.Add(pixel.V(prev.Advance, 0))) {
+					t.Errorf("glyph %d at %v, previous at %v advancing %v", i, g.Dot, prev.Dot, prev.Advance)
+				}
+				if want := -float64(g.Line) * st.LineHeight; g.Dot.Y != want {
+					t.Errorf("glyph %d on the baseline %v, want %v", i, g.Dot.Y, want)
+				}
+			}
+		})
+	}
+}
+
+func TestShapedText_align(t *testing.T) {
+	atlas := shapingAtlas(t)
+	tests := []struct {
+		s     string
+		align text.Align
+		left  bool
+	}{
+		{"ab", text.AlignLeft, true},
+		{"ab", text.AlignRight, false},
+		{"אב", text.AlignLeft, false},
+		{"אב", text.AlignRight, true},
+	}
+	for _, tt := range tests {
+		st := text.NewShapedText(pixel.V(10, 0), atlas)
+		st.WrapWidth = 100
+		st.Align = tt.align
+		st.SetText(tt.s)
+
+		b := st.Bounds()
+		if left := b.Min.X == 10; left != tt.left || !left && b.Max.X != 110 {
+			t.Errorf("%q aligned %v: bounds %v", tt.s, tt.align, b)
+		}
+	}
+
+	st := text.NewShapedText(pixel.V(10, 0), atlas)
+	st.WrapWidth = 100
+	st.Align = text.AlignCenter
+	st.SetText("ab")
+	if b := st.Bounds(); !eqVectors(b.Center(), pixel.V(60, b.Center().Y)) {
+		t.Errorf("centered bounds %v", b)
+	}
+	if g, ok := st.GlyphAt(st.Glyphs()[1].Dot.Add(pixel.V(1, 1))); !ok || g.Start != 1 {
+		t.Errorf("GlyphAt() = %v, %v, want the glyph of 'b'", g, ok)
+	}
+}
+
+func TestShapedTextGolden(t *testing.T) {
+	atlas := shapingAtlas(t)
+	pixeltest.AssertGolden(t, "shaped", pixel.R(0, 0, 128, 48), func(target pixel.Target) {
+		st := text.NewShapedText(pixel.V(4, 30), atlas)
+		st.WrapWidth = 120
+		st.Align = text.AlignCenter
+		st.Color = pixel.RGB(1, 1, 0)
+		st.SetText("Shaped ffi é AV\nab אב cd")
+		st.Draw(target, pixel.IM)
+	}, pixeltest.Options{})
+}
+
+func BenchmarkShapedText_SetText(b *testing.B) {
+	st := text.NewShapedText(pixel.ZV, shapingAtlas(b))
+	st.WrapWidth = 300
+	for i := 0; i < b.N; i++ {
+		st.SetText("The quick brown fox jumps over the lazy dog, אב גד, twice.")
+	}
+}
@@ -59,6 +59,17 @@ func RangeTable(table *unicode.RangeTable) []rune {
 //
 // Newlines, tabs and carriage returns are supported.
 //
+// By default, lines are only broken by newlines. Set WrapWidth to break the lines longer than it,
+// at whitespace or between any characters, or to cut them with an ellipsis, and Align to align
+// each line:
+//
+//	txt.WrapWidth = 200
+//	txt.Align = text.AlignCenter
+//	txt.MaxLines = 3
+//
+// LayoutOf returns the lines a string would be laid out in, without drawing it, for example to
+// size a box around the text.
+//
 // Finally, if we want the written text to show up on some other Target, we can draw it:
 //
 //	txt.Draw(target)
@@ -92,6 +103,24 @@ type Text struct {
 	//   txt.TabWidth = 8 * txt.Atlas().Glyph(' ').Advance
 	TabWidth float64
 
+	// WrapWidth is the width of the lines from Orig. If it's positive, the lines longer than
+	// WrapWidth are broken or cut according to Wrap.
+	WrapWidth float64
+
+	// Wrap is the way the lines longer than WrapWidth are broken. Defaults to WrapWord.
+	Wrap WrapMode
+
+	// Align is the horizontal alignment of each line, within WrapWidth or relative to Orig if
+	// WrapWidth is zero. Defaults to AlignLeft.
+	Align Align
+
+	// MaxLines is the maximum number of lines, if positive. The last line ends with the Ellipsis if
+	// any text follows and the rest of the text is dropped until Clear.
+	MaxLines int
+
+	// Ellipsis ends the truncated lines. Defaults to "…" if the Atlas contains it, "..." otherwise.
+	Ellipsis string
+
 	atlas *Atlas
 
 	buf    []byte
@@ -107,6 +136,16 @@ type Text struct {
 	dirty      bool
 	anchor     pixel.Anchor
 	isAnchored bool
+
+	// the layout of the lines when wrapping, aligning or limiting them, only the glyphs of the
+	// current line are laid out again when writing
+	lay         layout
+	layDot      pixel.Vec
+	lineTris    int
+	linesBounds pixel.Rect
+	written     int
+	// whether glyphs were written without the layout since it was last used
+	unlaid bool
 }
 
 // New creates a new Text capable of drawing runes contained in the provided Atlas. Orig and Dot
@@ -129,10 +168,15 @@ func New(orig pixel.Vec, atlas *Atlas) *Text {
 		Color:      pixel.Alpha(1),
 		LineHeight: atlas.LineHeight(),
 		TabWidth:   atlas.Glyph(' ').Advance * 4,
+		Ellipsis:   "...",
 		atlas:      atlas,
 		mat:        pixel.IM,
 		col:        pixel.Alpha(1),
 	}
+	if atlas.Contains('…') {
+		txt.Ellipsis = "…"
+	}
+	txt.lay.emit = txt.addLine
 
 	txt.glyph.SetLen(6)
 	for i := range txt.glyph {
@@ -164,6 +208,10 @@ func (txt *Text) Bounds() pixel.Rect {
 
 // BoundsOf returns the bounding box of s if it was to be written to the Text right now.
 func (txt *Text) BoundsOf(s string) pixel.Rect {
+	if txt.laidOut() {
+		return txt.layoutBoundsOf(s)
+	}
+
 	dot := txt.Dot
 	prevR := txt.prevR
 	bounds := pixel.Rect{}
@@ -246,6 +294,13 @@ func (txt *Text) Clear() {
 	txt.tris.SetLen(0)
 	txt.dirty = true
 	txt.Dot = txt.Orig
+
+	txt.lay.reset(txt.Orig)
+	txt.layDot = txt.Orig
+	txt.lineTris = 0
+	txt.linesBounds = pixel.Rect{}
+	txt.written = 0
+	txt.unlaid = false
 }
 
 // Write writes a slice of bytes to the Text. This method never fails, always returns len(p), nil.
@@ -370,7 +425,12 @@ func (txt *Text) drawBuf() {
 	if !utf8.FullRune(txt.buf) {
 		return
 	}
+	if txt.laidOut() {
+		txt.layoutBuf()
+		return
+	}
 
+	txt.unlaid = true
 	rgba := pixel.ToRGBA(txt.Color)
 	for i := range txt.glyph {
 		txt.glyph[i].Color = rgba
@@ -432,3 +492,151 @@ func (txt *Text) drawBuf() {
 		}
 	}
 }
+
+// laidOut reports whether the lines are wrapped, aligned or limited, which requires laying them out.
+func (txt *Text) laidOut() bool {
+	return txt.WrapWidth > 0 || txt.Align != AlignLeft || txt.MaxLines > 0
+}
+
+// updateLayout sets up the layout with the current settings of the Text.
+func (txt *Text) updateLayout(l *layout) {
+	l.atlas = txt.atlas
+	l.orig = txt.Orig
+	l.lineHeight = txt.LineHeight
+	l.tabWidth = txt.TabWidth
+	l.wrapWidth = txt.WrapWidth
+	l.wrap = txt.Wrap
+	l.align = txt.Align
+	l.maxLines = txt.MaxLines
+	l.ellipsis = txt.Ellipsis
+}
+
+// layoutBuf lays out the buffered runes and replaces the glyphs of the current line.
+func (txt *Text) layoutBuf() {
+	txt.updateLayout(&txt.lay)
+	if txt.unlaid {
+		// the glyphs written without the layout stay as they are, including the ones of the line
+		// laid out before them, and the layout continues after them
+		txt.lay.runes = txt.lay.runes[:0]
+		txt.lineTris = txt.tris.Len()
+		txt.linesBounds = txt.bounds
+		txt.lay.moveTo(txt.Dot)
+		txt.unlaid = false
+	}
+	txt.tris = txt.tris[:txt.lineTris]
+	if txt.Dot != txt.layDot {
+		// the Dot was moved since the last write
+		txt.lay.moveTo(txt.Dot)
+	}
+
+	rgba := pixel.ToRGBA(txt.Color)
+	for utf8.FullRune(txt.buf) {
+		r, size := utf8.DecodeRune(txt.buf)
+		txt.buf = txt.buf[size:]
+		txt.lay.add(r, size, rgba, txt.written)
+		txt.written += size
+	}
+
+	line := txt.lay.current()
+	txt.appendGlyphs(txt.lay.runes)
+	txt.bounds = unionBounds(txt.linesBounds, line.Bounds)
+	txt.Dot = txt.lay.dot()
+	txt.layDot = txt.Dot
+	txt.dirty = true
+}
+
+// addLine adds the glyphs of a finished line.
+func (txt *Text) addLine(line Line, runes []layoutRune) {
+	txt.appendGlyphs(runes)
+	txt.lineTris = txt.tris.Len()
+	txt.linesBounds = unionBounds(txt.linesBounds, line.Bounds)
+}
+
+// appendGlyphs appends the triangles of the glyphs of the laid out runes.
+func (txt *Text) appendGlyphs(runes []layoutRune) {
+	for _, lr := range runes {
+		if lr.rect.W()*lr.rect.H() == 0 {
+			continue
+		}
+		rect := lr.rect.Moved(pixel.V(lr.shift, 0))
+
+		rv := [...]pixel.Vec{
+			{X: rect.Min.X, Y: rect.Min.Y},
+			{X: rect.Max.X, Y: rect.Min.Y},
+			{X: rect.Max.X, Y: rect.Max.Y},
+			{X: rect.Min.X, Y: rect.Max.Y},
+		}
+
+		fv := [...]pixel.Vec{
+			{X: lr.frame.Min.X, Y: lr.frame.Min.Y},
+			{X: lr.frame.Max.X, Y: lr.frame.Min.Y},
+			{X: lr.frame.Max.X, Y: lr.frame.Max.Y},
+			{X: lr.frame.Min.X, Y: lr.frame.Max.Y},
+		}
+
+		for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+			txt.glyph[i].Position = rv[j]
+			txt.glyph[i].Picture = fv[j]
+			txt.glyph[i].Color = lr.col
+		}
+
+		txt.tris = append(txt.tris, txt.glyph...)
+	}
+}
+
+// layoutBoundsOf returns the bounding box of the glyphs of s if it was to be written to the Text
+// right now, when the lines are laid out.
+func (txt *Text) layoutBoundsOf(s string) pixel.Rect {
+	l := txt.lay
+	l.runes = append([]layoutRune(nil), txt.lay.runes...)
+
+	var bounds pixel.Rect
+	add := func(runes []layoutRune) {
+		for _, lr := range runes {
+			if lr.off < txt.written || lr.bounds.W()*lr.bounds.H() == 0 {
+				continue
+			}
+			bounds = unionBounds(bounds, lr.bounds.Moved(pixel.V(lr.shift, 0)))
+		}
+	}
+	l.emit = func(_ Line, runes []layoutRune) {
+		add(runes)
+	}
+	txt.updateLayout(&l)
+	if txt.Dot != txt.layDot {
+		l.moveTo(txt.Dot)
+	}
+
+	for off := 0; off < len(s); {
+		r, size := utf8.DecodeRuneInString(s[off:])
+		l.add(r, size, pixel.RGBA{}, txt.written+off)
+		off += size
+	}
+	l.current()
+	add(l.runes)
+	return bounds
+}
+
+// LayoutOf returns the lines s would be laid out in if it was written to the Text after Clear,
+// without drawing it. The lines are broken, aligned and cut according to the WrapWidth, Wrap,
+// Align, MaxLines and Ellipsis of the Text.
+//
+// Unlike writing to the Text, the lines are laid out even if the Text doesn't wrap, align or limit
+// them. In that case, carriage returns are ignored and tabs align to the multiples of TabWidth.
+func (txt *Text) LayoutOf(s string) []Line {
+	var lines []Line
+	l := layout{
+		emit: func(line Line, _ []layoutRune) {
+			lines = append(lines, line)
+		},
+	}
+	l.reset(txt.Orig)
+	txt.updateLayout(&l)
+
+	for off := 0; off < len(s); {
+		r, size := utf8.DecodeRuneInString(s[off:])
+		l.add(r, size, pixel.RGBA{}, off)
+		off += size
+	}
+	return append(lines, l.current())
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/golang/freetype/truetype"
 	"github.com/gopxl/pixel/v2"
 	"github.com/gopxl/pixel/v2/ext/text"
+	"github.com/gopxl/pixel/v2/pixeltest"
 )
 
 func TestClear(t *testing.T) {
@@ -34,6 +35,17 @@ func TestClear(t *testing.T) {
 	}
 }
 
+func TestDrawGolden(t *testing.T) {
+	pixeltest.AssertGolden(t, "hello", pixel.R(0, 0, 96, 40), func(target pixel.Target) {
+		txt := text.New(pixel.V(4, 24), text.Atlas7x13)
+		txt.Color = pixel.RGB(1, 1, 0)
+		fmt.Fprintln(txt, "Hello,")
+		txt.Color = pixel.RGB(0, 1, 1)
+		fmt.Fprint(txt, "\tPixel!")
+		txt.Draw(target, pixel.IM)
+	}, pixeltest.Options{})
+}
+
 func BenchmarkNewAtlas(b *testing.B) {
 	runeSets := []struct {
 		name string
@@ -0,0 +1,72 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodeTiles decodes the tiles of a layer or a chunk stored in the given encoding (csv or base64)
+// and compression (none, zlib or gzip).
+func decodeTiles(data, encoding, compression string, n int) ([]GID, error) {
+	switch encoding {
+	case "csv":
+		return decodeCSV(data, n)
+	case "base64":
+		return decodeBase64(data, compression, n)
+	}
+	return nil, fmt.Errorf("unsupported encoding %q", encoding)
+}
+
+func decodeCSV(data string, n int) ([]GID, error) {
+	tiles := make([]GID, 0, n)
+	for _, field := range strings.Split(data, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tile %q", field)
+		}
+		tiles = append(tiles, GID(gid))
+	}
+	if len(tiles) != n {
+		return nil, fmt.Errorf("%d tiles, want %d", len(tiles), n)
+	}
+	return tiles, nil
+}
+
+func decodeBase64(data, compression string, n int) ([]GID, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+	switch compression {
+	case "":
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+
+	tiles := make([]GID, n)
+	if err := binary.Read(r, binary.LittleEndian, tiles); err != nil {
+		return nil, fmt.Errorf("reading %d tiles: %w", n, err)
+	}
+	return tiles, nil
+}
@@ -0,0 +1,130 @@
+package tilemap
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Load loads a map from the file system, in the TMX format if the name ends with .tmx, or in the
+// TMJ format if it ends with .tmj or .json. The external tilesets are loaded too, from TSX (.tsx)
+// or TSJ (.tsj or .json) files, while the images are only loaded by LoadPictures.
+//
+// The file system can be an embed.FS or os.DirFS, as long as it contains the map and all the files
+// it references. Use LoadFile to load a map from a path of the operating system.
+//
+// Object templates and Wang sets aren't supported. Zstandard compressed layers aren't supported
+// either, use zlib or gzip compression.
+func Load(fsys fs.FS, name string) (*Map, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	external := func(source string) (*Tileset, error) {
+		return loadTileset(fsys, source)
+	}
+	var m *Map
+	switch ext := path.Ext(name); ext {
+	case ".tmx":
+		m, err = decodeTMX(data, path.Dir(name), external)
+	case ".tmj", ".json":
+		m, err = decodeTMJ(data, path.Dir(name), external)
+	default:
+		return nil, fmt.Errorf("Load: %s: unknown map format %q", name, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Load: %s: %w", name, err)
+	}
+	return m, nil
+}
+
+// loadTileset loads an external tileset from the file system.
+func loadTileset(fsys fs.FS, name string) (*Tileset, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	switch ext := path.Ext(name); ext {
+	case ".tsx":
+		return decodeTSX(data, path.Dir(name))
+	case ".tsj", ".json":
+		return decodeTSJ(data, path.Dir(name))
+	default:
+		return nil, fmt.Errorf("unknown tileset format %q", ext)
+	}
+}
+
+// LoadFile loads a map from a file of the operating system, see Load, and loads the Pictures of its
+// tilesets using the decoder, see LoadPictures. The paths of the files referenced by the map may
+// lead anywhere, including the parent directories of the map.
+func LoadFile(name string, decoder pixel.DecoderFunc) (*Map, error) {
+	m, err := Load(osFS{}, filepath.ToSlash(name))
+	if err != nil {
+		return nil, err
+	}
+	if err := m.LoadPictures(osFS{}, decoder); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// osFS is a file system of the operating system, which accepts any path, unlike os.DirFS, so
+// that the maps can reference files in the parent directories.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
+
+// LoadPictures loads the images of the tilesets of the map from the file system using the decoder
+// and sets the Pictures of the tilesets and of the tiles of image collection tilesets. Each image
+// is only loaded once, even if it's used by several tilesets.
+//
+// If the decoder is nil, pixel.DefaultDecoderFunc is used, in which case the image formats must
+// be registered, for example by importing image/png.
+func (m *Map) LoadPictures(fsys fs.FS, decoder pixel.DecoderFunc) error {
+	if decoder == nil {
+		decoder = pixel.DefaultDecoderFunc
+	}
+	pics := make(map[string]*pixel.PictureData)
+	load := func(img *Image) (pixel.Picture, error) {
+		if pd, ok := pics[img.Source]; ok {
+			return pd, nil
+		}
+		f, err := fsys.Open(img.Source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		decoded, err := decoder(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", img.Source, err)
+		}
+		pd := pixel.PictureDataFromImage(decoded)
+		pics[img.Source] = pd
+		return pd, nil
+	}
+
+	for _, ts := range m.Tilesets {
+		var err error
+		if ts.Image != nil {
+			if ts.Picture, err = load(ts.Image); err != nil {
+				return fmt.Errorf("(%T).LoadPictures: tileset %s: %w", m, ts.Name, err)
+			}
+		}
+		for _, t := range ts.Tiles {
+			if t.Image == nil {
+				continue
+			}
+			if t.Picture, err = load(t.Image); err != nil {
+				return fmt.Errorf("(%T).LoadPictures: tileset %s: %w", m, ts.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package tilemap
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Renderer draws the tile layers of a Map. The tiles of each Picture are drawn with a single
+// pixel.Batch and only the tiles in the view are drawn, so even large maps are drawn fast.
+//
+//	m, err := tilemap.LoadFile("level.tmx", nil)
+//	// handle error
+//	r, err := tilemap.NewRenderer(m)
+//	// handle error
+//	for !win.Closed() {
+//		r.Update(dt)
+//		cam := pixel.IM.Moved(win.Bounds().Center().Sub(camPos))
+//		win.SetMatrix(cam)
+//		// the part of the map visible in the window
+//		view := pixel.Rect{Min: cam.Unproject(win.Bounds().Min), Max: cam.Unproject(win.Bounds().Max)}
+//		r.Draw(win, view)
+//		win.Update()
+//	}
+//
+// The tiles are drawn at their cells in Pixel's coordinates, see Map.ToWorld, aligned with the
+// bottom left corners of the cells like in Tiled. Within a layer, the tiles of each Picture are
+// drawn together, so the tiles of different tilesets overlapping each other may be drawn in a
+// different order than in Tiled.
+//
+// The opacity, the tint color and the offset of the layers and their groups are applied, the
+// parallax factors are not. Object groups and image layers aren't drawn, their content is only
+// available in the Map.
+type Renderer struct {
+	m       *Map
+	margin  pixel.Vec
+	elapsed time.Duration
+
+	batches map[pixel.Picture]*tileBatch
+	used    []*tileBatch
+}
+
+// tileBatch is a Batch for the tiles of a Picture with the container the tiles are added to.
+type tileBatch struct {
+	batch *pixel.Batch
+	tris  *pixel.TrianglesData
+}
+
+// NewRenderer creates a Renderer of the Map. The Map must be orthogonal and all of its tilesets
+// must have their Pictures set, see Map.LoadPictures.
+func NewRenderer(m *Map) (*Renderer, error) {
+	if m.Orientation != "orthogonal" && m.Orientation != "" {
+		return nil, fmt.Errorf("NewRenderer: %s maps are not supported", m.Orientation)
+	}
+	r := &Renderer{
+		m:       m,
+		batches: make(map[pixel.Picture]*tileBatch),
+	}
+	for _, ts := range m.Tilesets {
+		if ts.Image != nil && ts.Picture == nil {
+			return nil, fmt.Errorf("NewRenderer: tileset %s has no Picture", ts.Name)
+		}
+		w, h := float64(ts.TileWidth), float64(ts.TileHeight)
+		for _, t := range ts.Tiles {
+			if ts.Image == nil && t.Image != nil && t.Picture == nil {
+				return nil, fmt.Errorf("NewRenderer: tile %d of tileset %s has no Picture", t.ID, ts.Name)
+			}
+			if t.Picture != nil {
+				w = math.Max(w, t.Picture.Bounds().W())
+				h = math.Max(h, t.Picture.Bounds().H())
+			}
+		}
+		// the tiles larger than the cells or offset may reach into the view from the cells outside
+		r.margin.X = math.Max(r.margin.X, w+math.Abs(ts.Offset.X))
+		r.margin.Y = math.Max(r.margin.Y, h+math.Abs(ts.Offset.Y))
+	}
+	return r, nil
+}
+
+// Update advances the time of the animated tiles.
+func (r *Renderer) Update(dt time.Duration) {
+	r.elapsed += dt
+}
+
+// Draw draws all the visible tile layers of the Map onto the Target, in the order of the layers.
+// Only the tiles which can be seen in the view, a rectangle in Pixel's coordinates, are drawn.
+func (r *Renderer) Draw(t pixel.Target, view pixel.Rect) {
+	r.drawLayers(t, r.m.Layers, view, pixel.Alpha(1), pixel.ZV)
+}
+
+// DrawLayer draws a tile layer, or all the visible tile layers of a group, of the Map onto the
+// Target. It draws the layer even if it isn't visible, which allows drawing other things between
+// the layers. Only the tiles which can be seen in the view, a rectangle in Pixel's coordinates, are
+// drawn.
+func (r *Renderer) DrawLayer(t pixel.Target, l *Layer, view pixel.Rect) {
+	r.drawLayer(t, l, view, pixel.Alpha(1), pixel.ZV)
+}
+
+func (r *Renderer) drawLayers(t pixel.Target, layers []*Layer, view pixel.Rect, mask pixel.RGBA, offset pixel.Vec) {
+	for _, l := range layers {
+		if l.Visible {
+			r.drawLayer(t, l, view, mask, offset)
+		}
+	}
+}
+
+func (r *Renderer) drawLayer(t pixel.Target, l *Layer, view pixel.Rect, mask pixel.RGBA, offset pixel.Vec) {
+	mask = mask.Mul(l.Tint).Scaled(l.Opacity)
+	// the offsets are in Tiled's coordinates
+	offset = offset.Add(pixel.V(l.Offset.X, -l.Offset.Y))
+
+	switch l.Kind {
+	case GroupLayer:
+		r.drawLayers(t, l.Layers, view, mask, offset)
+	case TileLayer:
+		r.drawTiles(l, view.Moved(offset.Scaled(-1)), mask, offset)
+		r.flush(t)
+	}
+}
+
+// drawTiles adds the tiles of the layer in the view to the Batches of their Pictures.
+func (r *Renderer) drawTiles(l *Layer, view pixel.Rect, mask pixel.RGBA, offset pixel.Vec) {
+	chunks := l.Chunks
+	if chunks == nil {
+		chunks = []Chunk{{Width: l.Width, Height: l.Height, Tiles: l.Tiles}}
+	}
+
+	// the rows are from the top, so the top of the view gives the first row
+	view = view.Norm()
+	x0, y0 := r.m.TileAt(pixel.V(view.Min.X-r.margin.X, view.Max.Y+r.margin.Y))
+	x1, y1 := r.m.TileAt(pixel.V(view.Max.X+r.margin.X, view.Min.Y-r.margin.Y))
+
+	// the tiles outside of the layer are skipped
+	minX, minY, maxX, maxY := math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+	for _, c := range chunks {
+		minX, minY = min(minX, c.X), min(minY, c.Y)
+		maxX, maxY = max(maxX, c.X+c.Width-1), max(maxY, c.Y+c.Height-1)
+	}
+	x0, y0 = max(x0, minX), max(y0, minY)
+	x1, y1 = min(x1, maxX), min(y1, maxY)
+	if x0 > x1 || y0 > y1 {
+		return
+	}
+
+	// the tiles are drawn in the render order of the map, by rows
+	dx, dy := 1, 1
+	switch r.m.RenderOrder {
+	case "right-up":
+		y0, y1, dy = y1, y0, -1
+	case "left-down":
+		x0, x1, dx = x1, x0, -1
+	case "left-up":
+		x0, x1, dx = x1, x0, -1
+		y0, y1, dy = y1, y0, -1
+	}
+
+	for y := y0; y != y1+dy; y += dy {
+		for _, c := range chunks {
+			if y < c.Y || y >= c.Y+c.Height {
+				continue
+			}
+			for x := x0; x != x1+dx; x += dx {
+				if x < c.X || x >= c.X+c.Width {
+					continue
+				}
+				if gid := c.Tiles[(y-c.Y)*c.Width+x-c.X]; gid.ID() != 0 {
+					r.addTile(gid, r.m.TileRect(x, y).Min.Add(offset), mask)
+				}
+			}
+		}
+	}
+}
+
+// addTile adds the tile with the bottom left corner of its cell at the position to the Batch of
+// its Picture.
+func (r *Renderer) addTile(gid GID, pos pixel.Vec, mask pixel.RGBA) {
+	ts, id := r.m.Tileset(gid)
+	if ts == nil {
+		return
+	}
+	if t := ts.Tile(id); t != nil && len(t.Animation) > 0 {
+		id = r.frame(t.Animation)
+	}
+
+	pic := ts.Picture
+	if ts.Image == nil {
+		t := ts.Tile(id)
+		if t == nil || t.Picture == nil {
+			return
+		}
+		pic = t.Picture
+	}
+	frame := ts.Frame(id)
+
+	tb := r.batches[pic]
+	if tb == nil {
+		tb = &tileBatch{tris: &pixel.TrianglesData{}}
+		tb.batch = pixel.NewBatch(tb.tris, pic)
+		r.batches[pic] = tb
+	}
+	if tb.tris.Len() == 0 {
+		r.used = append(r.used, tb)
+	}
+
+	// the tile offsets are in Tiled's coordinates
+	pos = pos.Add(pixel.V(ts.Offset.X, -ts.Offset.Y))
+	n := tb.tris.Len()
+	tb.tris.SetLen(n + 6)
+	for i, corner := range [6]pixel.Vec{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}} {
+		src := flipCorner(gid, corner)
+		(*tb.tris)[n+i].Position = pos.Add(pixel.V(corner.X*frame.W(), corner.Y*frame.H()))
+		(*tb.tris)[n+i].Picture = frame.Min.Add(pixel.V(src.X*frame.W(), src.Y*frame.H()))
+		(*tb.tris)[n+i].Color = mask
+		(*tb.tris)[n+i].Intensity = 1
+	}
+}
+
+// flipCorner returns the corner of the tile's frame, which is drawn at the corner of the tile,
+// both in the unit square with the y axis pointing up.
+func flipCorner(gid GID, corner pixel.Vec) pixel.Vec {
+	// the flips are defined with the y axis pointing down, the diagonal flip is applied first
+	u, v := corner.X, 1-corner.Y
+	if gid.FlippedVertically() {
+		v = 1 - v
+	}
+	if gid.FlippedHorizontally() {
+		u = 1 - u
+	}
+	if gid.FlippedDiagonally() {
+		u, v = v, u
+	}
+	return pixel.V(u, 1-v)
+}
+
+// frame returns the ID of the tile shown by the animation at the current time.
+func (r *Renderer) frame(animation []Frame) int {
+	total := 0
+	for _, f := range animation {
+		total += f.Duration
+	}
+	if total <= 0 {
+		return animation[0].TileID
+	}
+	ms := int(r.elapsed.Milliseconds() % int64(total))
+	for _, f := range animation {
+		if ms < f.Duration {
+			return f.TileID
+		}
+		ms -= f.Duration
+	}
+	return animation[len(animation)-1].TileID
+}
+
+// flush draws the Batches with tiles onto the Target and clears them.
+func (r *Renderer) flush(t pixel.Target) {
+	for _, tb := range r.used {
+		tb.batch.Dirty()
+		tb.batch.Draw(t)
+		tb.tris.SetLen(0)
+	}
+	r.used = r.used[:0]
+}
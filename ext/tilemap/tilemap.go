@@ -0,0 +1,409 @@
+// Package tilemap loads maps made in the Tiled map editor (https://www.mapeditor.org) from the TMX
+// (XML) and TMJ (JSON) formats and draws their tile layers.
+//
+// The structure of a Map follows the structure of the Tiled formats, with all the positions and
+// sizes in pixels, as they are in the files. Tiled's y axis points down, while Pixel's points up, so
+// the positions of the objects are converted using Map.ToWorld before drawing anything at them.
+package tilemap
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Map is a map made in Tiled.
+type Map struct {
+	Version      string
+	Class        string
+	Orientation  string // orthogonal, isometric, staggered or hexagonal
+	RenderOrder  string // right-down, right-up, left-down or left-up
+	Width        int    // in tiles
+	Height       int    // in tiles
+	TileWidth    int
+	TileHeight   int
+	Infinite     bool
+	Background   pixel.RGBA
+	NextObjectID int
+	Properties   Properties
+	Tilesets     []*Tileset
+	Layers       []*Layer
+}
+
+// Tileset returns the Tileset of the tile with the given GID and the ID of the tile in the
+// Tileset. It returns nil if the GID is empty or not in any of the Tilesets.
+func (m *Map) Tileset(gid GID) (ts *Tileset, id int) {
+	if gid.ID() == 0 {
+		return nil, 0
+	}
+	// the Tilesets are ordered by their first GIDs
+	for i := len(m.Tilesets) - 1; i >= 0; i-- {
+		if ts := m.Tilesets[i]; gid.ID() >= ts.FirstGID {
+			return ts, int(gid.ID() - ts.FirstGID)
+		}
+	}
+	return nil, 0
+}
+
+// ToWorld converts a position in the map from Tiled's coordinates, with the y axis pointing down
+// from the top edge of the map, to Pixel's coordinates, with the y axis pointing up from the bottom
+// edge of the map. The map's tiles then cover the rectangle from (0, 0) to the size of the map in
+// pixels. Infinite maps use the top edge of their initial size.
+func (m *Map) ToWorld(pos pixel.Vec) pixel.Vec {
+	return pixel.V(pos.X, float64(m.Height*m.TileHeight)-pos.Y)
+}
+
+// TileRect returns the rectangle of the cell of the tile at the given column and row of the map in
+// Pixel's coordinates, see ToWorld.
+func (m *Map) TileRect(x, y int) pixel.Rect {
+	min := m.ToWorld(pixel.V(float64(x*m.TileWidth), float64((y+1)*m.TileHeight)))
+	return pixel.R(min.X, min.Y, min.X+float64(m.TileWidth), min.Y+float64(m.TileHeight))
+}
+
+// TileAt returns the column and the row of the cell of the map containing the position in Pixel's
+// coordinates, see ToWorld.
+func (m *Map) TileAt(pos pixel.Vec) (x, y int) {
+	pos.Y = float64(m.Height*m.TileHeight) - pos.Y
+	return floorDiv(pos.X, m.TileWidth), floorDiv(pos.Y, m.TileHeight)
+}
+
+// LayerByName returns the first layer with the given name, searching the groups too, or nil if
+// there's no such layer.
+func (m *Map) LayerByName(name string) *Layer {
+	return layerByName(m.Layers, name)
+}
+
+func layerByName(layers []*Layer, name string) *Layer {
+	for _, l := range layers {
+		if l.Name == name {
+			return l
+		}
+		if found := layerByName(l.Layers, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// GID is a global tile ID, which identifies a tile among all the Tilesets of a Map. The highest
+// bits of a GID are the flags of the flips of the tile. GID 0 is an empty tile.
+type GID uint32
+
+// Here's the list of the flip flags of a GID.
+const (
+	FlippedHorizontally GID = 0x80000000
+	FlippedVertically   GID = 0x40000000
+	FlippedDiagonally   GID = 0x20000000
+	// RotatedHexagonal120 is only used by hexagonal maps, which can't be drawn by Renderer.
+	RotatedHexagonal120 GID = 0x10000000
+
+	flipFlags = FlippedHorizontally | FlippedVertically | FlippedDiagonally | RotatedHexagonal120
+)
+
+// ID returns the GID without the flip flags.
+func (gid GID) ID() GID {
+	return gid &^ flipFlags
+}
+
+// FlippedHorizontally returns whether the tile is flipped horizontally.
+func (gid GID) FlippedHorizontally() bool {
+	return gid&FlippedHorizontally != 0
+}
+
+// FlippedVertically returns whether the tile is flipped vertically.
+func (gid GID) FlippedVertically() bool {
+	return gid&FlippedVertically != 0
+}
+
+// FlippedDiagonally returns whether the tile is flipped diagonally, which swaps its x and y axes.
+// Together with the horizontal and the vertical flips, it's used to rotate the tile by 90 degrees.
+func (gid GID) FlippedDiagonally() bool {
+	return gid&FlippedDiagonally != 0
+}
+
+// Tileset is a set of tiles, either cut from a single image or each from its own image.
+type Tileset struct {
+	FirstGID   GID
+	Source     string // the path of an external tileset, empty for embedded tilesets
+	Name       string
+	Class      string
+	TileWidth  int
+	TileHeight int
+	Spacing    int
+	Margin     int
+	TileCount  int
+	Columns    int
+	Offset     pixel.Vec // in Tiled's coordinates, see Map.ToWorld
+	Image      *Image    // nil for tilesets made of a collection of images
+	Properties Properties
+	Tiles      []*Tile // only the tiles with additional data, like properties or animations
+
+	// Picture is the Picture of the Image, which is loaded by Map.LoadPictures or can be set
+	// manually.
+	Picture pixel.Picture
+
+	tiles map[int]*Tile
+}
+
+// Tile returns the tile with the given ID, or nil if the tile has no additional data.
+func (ts *Tileset) Tile(id int) *Tile {
+	if ts.tiles == nil {
+		ts.tiles = make(map[int]*Tile, len(ts.Tiles))
+		for _, t := range ts.Tiles {
+			ts.tiles[t.ID] = t
+		}
+	}
+	return ts.tiles[id]
+}
+
+// Frame returns the rectangle of the tile with the given ID in the Tileset's Picture. The tiles
+// of tilesets made of a collection of images cover their whole images.
+func (ts *Tileset) Frame(id int) pixel.Rect {
+	if ts.Image == nil {
+		if t := ts.Tile(id); t != nil && t.Picture != nil {
+			return t.Picture.Bounds()
+		}
+		return pixel.Rect{}
+	}
+
+	var bounds pixel.Rect
+	if ts.Picture != nil {
+		bounds = ts.Picture.Bounds()
+	} else {
+		bounds = pixel.R(0, 0, float64(ts.Image.Width), float64(ts.Image.Height))
+	}
+	columns := ts.Columns
+	if columns <= 0 {
+		columns = 1
+	}
+	var (
+		x = ts.Margin + id%columns*(ts.TileWidth+ts.Spacing)
+		y = ts.Margin + id/columns*(ts.TileHeight+ts.Spacing)
+	)
+	// the image's y axis points down
+	min := pixel.V(bounds.Min.X+float64(x), bounds.Max.Y-float64(y+ts.TileHeight))
+	return pixel.R(min.X, min.Y, min.X+float64(ts.TileWidth), min.Y+float64(ts.TileHeight))
+}
+
+// Tile is a tile of a Tileset with additional data.
+type Tile struct {
+	ID          int
+	Class       string
+	Probability float64
+	Properties  Properties
+	Animation   []Frame
+	Image       *Image    // only for tilesets made of a collection of images
+	Objects     []*Object // collision shapes, relative to the tile
+
+	// Picture is the Picture of the Image, which is loaded by Map.LoadPictures or can be set
+	// manually.
+	Picture pixel.Picture
+}
+
+// Frame is a frame of an animated tile.
+type Frame struct {
+	TileID   int
+	Duration int // in milliseconds
+}
+
+// Image is an image referenced by a Map.
+type Image struct {
+	// Source is the path of the image in the file system the Map was loaded from.
+	Source string
+	Width  int
+	Height int
+}
+
+// LayerKind is the kind of a Layer.
+type LayerKind int
+
+// Here's the list of all the kinds of layers.
+const (
+	TileLayer LayerKind = iota
+	ObjectGroup
+	ImageLayer
+	GroupLayer
+)
+
+// String returns the name of the LayerKind.
+func (k LayerKind) String() string {
+	switch k {
+	case TileLayer:
+		return "TileLayer"
+	case ObjectGroup:
+		return "ObjectGroup"
+	case ImageLayer:
+		return "ImageLayer"
+	case GroupLayer:
+		return "GroupLayer"
+	}
+	return fmt.Sprintf("LayerKind(%d)", int(k))
+}
+
+// Layer is a layer of a Map. Depending on its Kind, it's a layer of tiles, a group of objects, an
+// image or a group of other layers.
+type Layer struct {
+	Kind       LayerKind
+	ID         int
+	Name       string
+	Class      string
+	Visible    bool
+	Opacity    float64
+	Tint       pixel.RGBA
+	Offset     pixel.Vec // in Tiled's coordinates, see Map.ToWorld
+	Parallax   pixel.Vec
+	Properties Properties
+
+	// Width and Height are the size of a tile layer in tiles. Tiles are the tiles of a finite
+	// tile layer, row by row from the top. Chunks are the tiles of an infinite tile layer.
+	Width  int
+	Height int
+	Tiles  []GID
+	Chunks []Chunk
+
+	// Objects are the objects of an object group, drawn in the DrawOrder (topdown or index).
+	Objects   []*Object
+	DrawOrder string
+	Color     pixel.RGBA
+
+	// Image is the image of an image layer.
+	Image *Image
+
+	// Layers are the layers of a group.
+	Layers []*Layer
+}
+
+// Tile returns the tile at the given column and row of a tile layer, or 0 if there's none.
+func (l *Layer) Tile(x, y int) GID {
+	if l.Chunks != nil {
+		for _, c := range l.Chunks {
+			if x >= c.X && x < c.X+c.Width && y >= c.Y && y < c.Y+c.Height {
+				return c.Tiles[(y-c.Y)*c.Width+x-c.X]
+			}
+		}
+		return 0
+	}
+	if x < 0 || x >= l.Width || y < 0 || y >= l.Height {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// Chunk is a rectangular part of an infinite tile layer. X and Y are the column and the row of its
+// top left tile.
+type Chunk struct {
+	X, Y          int
+	Width, Height int
+	Tiles         []GID
+}
+
+// Object is an object of an object group. Its kind is given by the shape flags, the GID of tile
+// objects, or the Text of text objects. Other objects are rectangles.
+type Object struct {
+	ID         int
+	Name       string
+	Class      string
+	X, Y       float64 // in Tiled's coordinates, see Map.ToWorld
+	Width      float64
+	Height     float64
+	Rotation   float64 // in degrees, clockwise
+	GID        GID     // tile objects are positioned by their bottom left corner
+	Visible    bool
+	Properties Properties
+
+	Ellipse  bool
+	Point    bool
+	Polygon  []pixel.Vec // relative to the position, in Tiled's coordinates
+	Polyline []pixel.Vec // relative to the position, in Tiled's coordinates
+	Text     string
+}
+
+// Property is a custom property of a Map, Tileset, Tile, Layer or Object. Its Type is string,
+// int, float, bool, color, file, object or class. The Value is converted to a string, class
+// properties keep their members in Properties.
+type Property struct {
+	Name       string
+	Type       string
+	Value      string
+	Properties Properties
+}
+
+// Properties are custom properties.
+type Properties []Property
+
+// Get returns the property with the given name.
+func (p Properties) Get(name string) (Property, bool) {
+	for _, prop := range p {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+	return Property{}, false
+}
+
+// String returns the value of the property with the given name, or an empty string if there's
+// none.
+func (p Properties) String(name string) string {
+	prop, _ := p.Get(name)
+	return prop.Value
+}
+
+// Int returns the value of the property with the given name as an int, or 0 if there's none or it
+// isn't a number.
+func (p Properties) Int(name string) int {
+	v, _ := strconv.ParseFloat(p.String(name), 64)
+	return int(v)
+}
+
+// Float returns the value of the property with the given name as a float64, or 0 if there's none
+// or it isn't a number.
+func (p Properties) Float(name string) float64 {
+	v, _ := strconv.ParseFloat(p.String(name), 64)
+	return v
+}
+
+// Bool returns the value of the property with the given name as a bool, or false if there's none.
+func (p Properties) Bool(name string) bool {
+	v, _ := strconv.ParseBool(p.String(name))
+	return v
+}
+
+// Color returns the value of the property with the given name as a color, or a transparent color
+// if there's none or it isn't a color.
+func (p Properties) Color(name string) pixel.RGBA {
+	c, _ := parseColor(p.String(name))
+	return c
+}
+
+// parseColor parses a Tiled color, #RRGGBB or #AARRGGBB. An empty string is a transparent color.
+func parseColor(s string) (pixel.RGBA, error) {
+	if s == "" {
+		return pixel.Alpha(0), nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return pixel.Alpha(0), fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return pixel.Alpha(0), fmt.Errorf("invalid color %q", s)
+	}
+	a := uint64(0xff)
+	if len(hex) == 8 {
+		a = v >> 24
+	}
+	c := pixel.RGB(float64(v>>16&0xff)/0xff, float64(v>>8&0xff)/0xff, float64(v&0xff)/0xff)
+	// the colors of Pixel are premultiplied
+	return c.Mul(pixel.Alpha(float64(a) / 0xff)), nil
+}
+
+// floorDiv returns x divided by d, rounded down.
+func floorDiv(x float64, d int) int {
+	if d == 0 {
+		return 0
+	}
+	return int(math.Floor(x / float64(d)))
+}
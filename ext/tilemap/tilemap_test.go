@@ -0,0 +1,624 @@
+package tilemap_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/tilemap"
+)
+
+const levelTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" tiledversion="1.10.2" orientation="orthogonal" renderorder="right-down" width="3" height="2" tilewidth="2" tileheight="2" infinite="0" backgroundcolor="#336699" nextlayerid="7" nextobjectid="8">
+ <properties>
+  <property name="name" value="Level 1"/>
+  <property name="lives" type="int" value="3"/>
+  <property name="dark" type="bool" value="true"/>
+  <property name="gravity" type="float" value="9.8"/>
+  <property name="tint" type="color" value="#80ff0000"/>
+  <property name="spawn" type="class" propertytype="Spawn">
+   <properties>
+    <property name="x" type="int" value="1"/>
+    <property name="y" type="int" value="2"/>
+   </properties>
+  </property>
+ </properties>
+ <tileset firstgid="1" name="tiles" tilewidth="2" tileheight="2" tilecount="3" columns="3">
+  <image source="../images/tiles.png" width="6" height="2"/>
+  <tile id="0" type="Wall">
+   <properties>
+    <property name="solid" type="bool" value="true"/>
+   </properties>
+   <objectgroup draworder="index">
+    <object id="1" x="0" y="0" width="2" height="1"/>
+   </objectgroup>
+  </tile>
+  <tile id="2">
+   <animation>
+    <frame tileid="0" duration="100"/>
+    <frame tileid="1" duration="100"/>
+   </animation>
+  </tile>
+ </tileset>
+ <tileset firstgid="4" source="../tilesets/external.tsx"/>
+ <layer id="1" name="ground" width="3" height="2">
+  <data encoding="csv">
+1,2147483649,536870913,
+2,0,3
+</data>
+ </layer>
+ <group id="2" name="group" offsetx="2" offsety="0">
+  <layer id="3" name="top" width="3" height="2" opacity="0.5">
+   <properties>
+    <property name="note">multiple
+lines</property>
+   </properties>
+   <data>
+    <tile/>
+    <tile/>
+    <tile/>
+    <tile gid="5"/>
+    <tile/>
+    <tile/>
+   </data>
+  </layer>
+ </group>
+ <objectgroup id="4" name="objects" color="#ff0000">
+  <object id="1" name="spawn" type="Spawn" x="2" y="2" width="4" height="2">
+   <properties>
+    <property name="facing" value="left"/>
+   </properties>
+  </object>
+  <object id="2" x="1" y="1" width="2" height="2" rotation="45">
+   <ellipse/>
+  </object>
+  <object id="3" x="3" y="1">
+   <point/>
+  </object>
+  <object id="4" x="0" y="0">
+   <polygon points="0,0 2,0 2,2"/>
+  </object>
+  <object id="5" x="0" y="0" visible="0">
+   <polyline points="0,0 1.5,-1"/>
+  </object>
+  <object id="6" gid="1" x="0" y="4" width="2" height="2"/>
+  <object id="7" x="0" y="0" width="4" height="2">
+   <text wrap="1">Hello</text>
+  </object>
+ </objectgroup>
+ <imagelayer id="5" name="sky">
+  <image source="../images/sky.png" width="6" height="4"/>
+ </imagelayer>
+ <layer id="6" name="hidden" width="3" height="2" visible="0">
+  <data encoding="csv">1,1,1,1,1,1</data>
+ </layer>
+</map>
+`
+
+const externalTSX = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="external" tilewidth="2" tileheight="2" tilecount="3" columns="3">
+ <tileoffset x="0" y="0"/>
+ <image source="../images/tiles.png" width="6" height="2"/>
+</tileset>
+`
+
+const levelTMJ = `{
+ "type": "map", "version": "1.10", "orientation": "orthogonal", "renderorder": "right-down",
+ "width": 3, "height": 2, "tilewidth": 2, "tileheight": 2, "infinite": false,
+ "backgroundcolor": "#336699", "nextobjectid": 8,
+ "properties": [
+  {"name": "name", "type": "string", "value": "Level 1"},
+  {"name": "lives", "type": "int", "value": 3},
+  {"name": "dark", "type": "bool", "value": true},
+  {"name": "gravity", "type": "float", "value": 9.8},
+  {"name": "tint", "type": "color", "value": "#80ff0000"},
+  {"name": "spawn", "type": "class", "propertytype": "Spawn", "value": {"y": 2, "x": 1}}
+ ],
+ "tilesets": [
+  {
+   "firstgid": 1, "name": "tiles", "tilewidth": 2, "tileheight": 2, "tilecount": 3, "columns": 3,
+   "image": "../images/tiles.png", "imagewidth": 6, "imageheight": 2,
+   "tiles": [
+    {
+     "id": 0, "type": "Wall",
+     "properties": [{"name": "solid", "type": "bool", "value": true}],
+     "objectgroup": {"type": "objectgroup", "draworder": "index", "objects": [{"id": 1, "x": 0, "y": 0, "width": 2, "height": 1}]}
+    },
+    {"id": 2, "animation": [{"tileid": 0, "duration": 100}, {"tileid": 1, "duration": 100}]}
+   ]
+  },
+  {"firstgid": 4, "source": "../tilesets/external.tsj"}
+ ],
+ "layers": [
+  {"type": "tilelayer", "id": 1, "name": "ground", "width": 3, "height": 2, "visible": true, "opacity": 1,
+   "data": [1, 2147483649, 536870913, 2, 0, 3]},
+  {"type": "group", "id": 2, "name": "group", "offsetx": 2, "offsety": 0, "layers": [
+   {"type": "tilelayer", "id": 3, "name": "top", "width": 3, "height": 2, "opacity": 0.5,
+    "properties": [{"name": "note", "type": "string", "value": "multiple\nlines"}],
+    "data": [0, 0, 0, 5, 0, 0]}
+  ]},
+  {"type": "objectgroup", "id": 4, "name": "objects", "color": "#ff0000", "draworder": "topdown", "objects": [
+   {"id": 1, "name": "spawn", "type": "Spawn", "x": 2, "y": 2, "width": 4, "height": 2,
+    "properties": [{"name": "facing", "type": "string", "value": "left"}]},
+   {"id": 2, "x": 1, "y": 1, "width": 2, "height": 2, "rotation": 45, "ellipse": true},
+   {"id": 3, "x": 3, "y": 1, "point": true},
+   {"id": 4, "x": 0, "y": 0, "polygon": [{"x": 0, "y": 0}, {"x": 2, "y": 0}, {"x": 2, "y": 2}]},
+   {"id": 5, "x": 0, "y": 0, "visible": false, "polyline": [{"x": 0, "y": 0}, {"x": 1.5, "y": -1}]},
+   {"id": 6, "gid": 1, "x": 0, "y": 4, "width": 2, "height": 2},
+   {"id": 7, "x": 0, "y": 0, "width": 4, "height": 2, "text": {"text": "Hello", "wrap": true}}
+  ]},
+  {"type": "imagelayer", "id": 5, "name": "sky", "image": "../images/sky.png", "imagewidth": 6, "imageheight": 4},
+  {"type": "tilelayer", "id": 6, "name": "hidden", "width": 3, "height": 2, "visible": false,
+   "data": [1, 1, 1, 1, 1, 1]}
+ ]
+}
+`
+
+const externalTSJ = `{
+ "type": "tileset", "name": "external", "tilewidth": 2, "tileheight": 2, "tilecount": 3, "columns": 3,
+ "image": "../images/tiles.png", "imagewidth": 6, "imageheight": 2
+}
+`
+
+var (
+	red    = color.RGBA{255, 0, 0, 255}
+	green  = color.RGBA{0, 255, 0, 255}
+	blue   = color.RGBA{0, 0, 255, 255}
+	white  = color.RGBA{255, 255, 255, 255}
+	yellow = color.RGBA{255, 255, 0, 255}
+)
+
+// tilesPNG returns a tileset image of three 2x2 tiles: the first with red, green, blue and white
+// pixels from the top left corner, the second yellow and the third transparent.
+func tilesPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 2))
+	img.Set(0, 0, red)
+	img.Set(1, 0, green)
+	img.Set(0, 1, blue)
+	img.Set(1, 1, white)
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			img.Set(x, y, yellow)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testFS(t *testing.T) fstest.MapFS {
+	return fstest.MapFS{
+		"maps/level.tmx":        {Data: []byte(levelTMX)},
+		"maps/level.tmj":        {Data: []byte(levelTMJ)},
+		"tilesets/external.tsx": {Data: []byte(externalTSX)},
+		"tilesets/external.tsj": {Data: []byte(externalTSJ)},
+		"images/tiles.png":      {Data: tilesPNG(t)},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := testFS(t)
+	for _, name := range []string{"maps/level.tmx", "maps/level.tmj"} {
+		t.Run(name, func(t *testing.T) {
+			m, err := tilemap.Load(fsys, name)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			checkLevel(t, m)
+		})
+	}
+}
+
+// checkLevel checks the content of the level map, which is the same in both formats.
+func checkLevel(t *testing.T, m *tilemap.Map) {
+	t.Helper()
+
+	if m.Version != "1.10" || m.Orientation != "orthogonal" || m.RenderOrder != "right-down" ||
+		m.Width != 3 || m.Height != 2 || m.TileWidth != 2 || m.TileHeight != 2 || m.Infinite || m.NextObjectID != 8 {
+		t.Errorf("map = %+v", m)
+	}
+	if want := pixel.RGB(0x33/255.0, 0x66/255.0, 0x99/255.0); m.Background != want {
+		t.Errorf("Background = %v, want %v", m.Background, want)
+	}
+
+	// properties
+	props := m.Properties
+	if props.String("name") != "Level 1" || props.Int("lives") != 3 || !props.Bool("dark") || props.Float("gravity") != 9.8 {
+		t.Errorf("Properties = %+v", props)
+	}
+	if got, want := props.Color("tint"), pixel.RGB(1, 0, 0).Mul(pixel.Alpha(0x80/255.0)); got != want {
+		t.Errorf("Properties.Color() = %v, want %v", got, want)
+	}
+	if spawn, ok := props.Get("spawn"); !ok || spawn.Type != "class" || spawn.Properties.Int("x") != 1 || spawn.Properties.Int("y") != 2 {
+		t.Errorf("class property = %+v", spawn)
+	}
+
+	// tilesets
+	if len(m.Tilesets) != 2 {
+		t.Fatalf("%d tilesets, want 2", len(m.Tilesets))
+	}
+	tiles, external := m.Tilesets[0], m.Tilesets[1]
+	if tiles.FirstGID != 1 || tiles.Name != "tiles" || tiles.Columns != 3 || tiles.TileCount != 3 || tiles.Source != "" {
+		t.Errorf("tileset = %+v", tiles)
+	}
+	if tiles.Image == nil || *tiles.Image != (tilemap.Image{Source: "images/tiles.png", Width: 6, Height: 2}) {
+		t.Errorf("tileset image = %+v, want the path relative to the file system", tiles.Image)
+	}
+	if wall := tiles.Tile(0); wall == nil || wall.Class != "Wall" || !wall.Properties.Bool("solid") || len(wall.Objects) != 1 || wall.Objects[0].Height != 1 {
+		t.Errorf("tile 0 = %+v", wall)
+	}
+	if anim := tiles.Tile(2); anim == nil || len(anim.Animation) != 2 || anim.Animation[1] != (tilemap.Frame{TileID: 1, Duration: 100}) {
+		t.Errorf("animated tile = %+v", anim)
+	}
+	if external.FirstGID != 4 || external.Name != "external" || !strings.HasPrefix(external.Source, "../tilesets/external.ts") {
+		t.Errorf("external tileset = %+v", external)
+	}
+	if external.Image == nil || external.Image.Source != "images/tiles.png" {
+		t.Errorf("external tileset image = %+v, want the path relative to the file system", external.Image)
+	}
+	if ts, id := m.Tileset(5 | tilemap.FlippedVertically); ts != external || id != 1 {
+		t.Errorf("Tileset(5) = %v, %d, want the external tileset and 1", ts, id)
+	}
+
+	// layers
+	if len(m.Layers) != 5 {
+		t.Fatalf("%d layers, want 5", len(m.Layers))
+	}
+	ground := m.Layers[0]
+	wantTiles := []tilemap.GID{1, 1 | tilemap.FlippedHorizontally, 1 | tilemap.FlippedDiagonally, 2, 0, 3}
+	if ground.Kind != tilemap.TileLayer || ground.Name != "ground" || !ground.Visible || ground.Opacity != 1 || fmt.Sprint(ground.Tiles) != fmt.Sprint(wantTiles) {
+		t.Errorf("ground layer = %+v", ground)
+	}
+	if gid := ground.Tile(1, 0); !gid.FlippedHorizontally() || gid.FlippedVertically() || gid.ID() != 1 {
+		t.Errorf("Tile(1, 0) = %x", gid)
+	}
+	group := m.Layers[1]
+	if group.Kind != tilemap.GroupLayer || group.Offset != pixel.V(2, 0) || len(group.Layers) != 1 {
+		t.Fatalf("group layer = %+v", group)
+	}
+	top := group.Layers[0]
+	if top.Opacity != 0.5 || top.Tile(0, 1) != 5 || top.Properties.String("note") != "multiple\nlines" || m.LayerByName("top") != top {
+		t.Errorf("layer in group = %+v", top)
+	}
+	if sky := m.Layers[3]; sky.Kind != tilemap.ImageLayer || sky.Image == nil || sky.Image.Source != "images/sky.png" {
+		t.Errorf("image layer = %+v", sky)
+	}
+	if hidden := m.Layers[4]; hidden.Visible {
+		t.Errorf("hidden layer is visible")
+	}
+
+	// objects
+	objects := m.Layers[2]
+	if objects.Kind != tilemap.ObjectGroup || objects.Color != pixel.RGB(1, 0, 0) || objects.DrawOrder != "topdown" || len(objects.Objects) != 7 {
+		t.Fatalf("object group = %+v", objects)
+	}
+	o := objects.Objects
+	if o[0].Name != "spawn" || o[0].Class != "Spawn" || o[0].X != 2 || o[0].Width != 4 || o[0].Properties.String("facing") != "left" {
+		t.Errorf("rectangle object = %+v", o[0])
+	}
+	if !o[1].Ellipse || o[1].Rotation != 45 || !o[2].Point {
+		t.Errorf("ellipse and point objects = %+v, %+v", o[1], o[2])
+	}
+	if fmt.Sprint(o[3].Polygon) != fmt.Sprint([]pixel.Vec{pixel.V(0, 0), pixel.V(2, 0), pixel.V(2, 2)}) {
+		t.Errorf("polygon = %v", o[3].Polygon)
+	}
+	if o[4].Visible || fmt.Sprint(o[4].Polyline) != fmt.Sprint([]pixel.Vec{pixel.V(0, 0), pixel.V(1.5, -1)}) {
+		t.Errorf("polyline object = %+v", o[4])
+	}
+	if o[5].GID != 1 || o[6].Text != "Hello" {
+		t.Errorf("tile and text objects = %+v, %+v", o[5], o[6])
+	}
+	if got, want := m.ToWorld(pixel.V(o[5].X, o[5].Y)), pixel.V(0, 0); got != want {
+		t.Errorf("ToWorld() = %v, want %v", got, want)
+	}
+}
+
+// encodeTiles encodes the tiles like Tiled does in base64 with the compression.
+func encodeTiles(tiles []tilemap.GID, compression string) string {
+	var raw bytes.Buffer
+	binary.Write(&raw, binary.LittleEndian, tiles)
+
+	var buf bytes.Buffer
+	switch compression {
+	case "zlib":
+		w := zlib.NewWriter(&buf)
+		w.Write(raw.Bytes())
+		w.Close()
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		w.Write(raw.Bytes())
+		w.Close()
+	default:
+		buf = raw
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestLoad_encodings(t *testing.T) {
+	tiles := []tilemap.GID{1, 2, 3, 1 | tilemap.FlippedVertically, 0, 2}
+
+	tmx := func(data string) string {
+		return `<map orientation="orthogonal" width="3" height="2" tilewidth="2" tileheight="2">` +
+			`<layer name="l" width="3" height="2">` + data + `</layer></map>`
+	}
+	tmj := func(data string) string {
+		return `{"orientation": "orthogonal", "width": 3, "height": 2, "tilewidth": 2, "tileheight": 2,` +
+			`"layers": [{"type": "tilelayer", "name": "l", "width": 3, "height": 2, ` + data + `}]}`
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		wantErr bool
+	}{
+		{name: "tmx csv", file: "m.tmx", data: tmx(`<data encoding="csv">1,2,3,1073741825,0,2</data>`)},
+		{name: "tmx base64", file: "m.tmx", data: tmx(`<data encoding="base64">` + encodeTiles(tiles, "") + `</data>`)},
+		{name: "tmx zlib", file: "m.tmx", data: tmx(`<data encoding="base64" compression="zlib">` + encodeTiles(tiles, "zlib") + `</data>`)},
+		{name: "tmx gzip", file: "m.tmx", data: tmx(`<data encoding="base64" compression="gzip">` + encodeTiles(tiles, "gzip") + `</data>`)},
+		{name: "tmx zstd", file: "m.tmx", data: tmx(`<data encoding="base64" compression="zstd">AAAA</data>`), wantErr: true},
+		{name: "tmx too few", file: "m.tmx", data: tmx(`<data encoding="csv">1,2,3</data>`), wantErr: true},
+		{name: "tmj array", file: "m.tmj", data: tmj(`"data": [1, 2, 3, 1073741825, 0, 2]`)},
+		{name: "tmj base64", file: "m.tmj", data: tmj(`"encoding": "base64", "data": "` + encodeTiles(tiles, "") + `"`)},
+		{name: "tmj zlib", file: "m.tmj", data: tmj(`"encoding": "base64", "compression": "zlib", "data": "` + encodeTiles(tiles, "zlib") + `"`)},
+		{name: "tmj truncated", file: "m.tmj", data: tmj(`"encoding": "base64", "data": "` + encodeTiles(tiles[:5], "") + `"`), wantErr: true},
+		{name: "unknown format", file: "m.txt", data: tmj(`"data": [1, 2, 3, 1073741825, 0, 2]`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tilemap.Load(fstest.MapFS{tt.file: {Data: []byte(tt.data)}}, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := m.Layers[0].Tiles; fmt.Sprint(got) != fmt.Sprint(tiles) {
+				t.Errorf("tiles = %v, want %v", got, tiles)
+			}
+		})
+	}
+}
+
+func TestLoad_infinite(t *testing.T) {
+	chunk := encodeTiles([]tilemap.GID{1, 0, 0, 2}, "zlib")
+	files := fstest.MapFS{
+		"m.tmx": {Data: []byte(`<map orientation="orthogonal" width="2" height="2" tilewidth="2" tileheight="2" infinite="1">
+ <layer name="l" width="2" height="2">
+  <data encoding="base64" compression="zlib">
+   <chunk x="-2" y="-2" width="2" height="2">` + chunk + `</chunk>
+   <chunk x="0" y="0" width="2" height="2">` + chunk + `</chunk>
+  </data>
+ </layer>
+</map>`)},
+		"m.tmj": {Data: []byte(`{"orientation": "orthogonal", "width": 2, "height": 2, "tilewidth": 2, "tileheight": 2, "infinite": true,
+ "layers": [{"type": "tilelayer", "name": "l", "width": 2, "height": 2, "chunks": [
+  {"x": -2, "y": -2, "width": 2, "height": 2, "data": [1, 0, 0, 2]},
+  {"x": 0, "y": 0, "width": 2, "height": 2, "data": [1, 0, 0, 2]}
+ ]}]}`)},
+	}
+
+	for _, name := range []string{"m.tmx", "m.tmj"} {
+		t.Run(name, func(t *testing.T) {
+			m, err := tilemap.Load(files, name)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			l := m.Layers[0]
+			if !m.Infinite || len(l.Chunks) != 2 || l.Tiles != nil {
+				t.Fatalf("layer = %+v, want 2 chunks", l)
+			}
+			for _, tt := range []struct {
+				x, y int
+				want tilemap.GID
+			}{{-2, -2, 1}, {-1, -1, 2}, {-1, -2, 0}, {0, 0, 1}, {1, 1, 2}, {2, 2, 0}, {-3, 0, 0}} {
+				if got := l.Tile(tt.x, tt.y); got != tt.want {
+					t.Errorf("Tile(%d, %d) = %d, want %d", tt.x, tt.y, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMap_TileAt(t *testing.T) {
+	m := &tilemap.Map{Width: 4, Height: 3, TileWidth: 16, TileHeight: 8}
+
+	tests := []struct {
+		x, y int
+	}{{0, 0}, {3, 2}, {1, 2}, {-1, -1}, {5, 4}}
+	for _, tt := range tests {
+		rect := m.TileRect(tt.x, tt.y)
+		if x, y := m.TileAt(rect.Center()); x != tt.x || y != tt.y {
+			t.Errorf("TileAt(TileRect(%d, %d).Center()) = %d, %d", tt.x, tt.y, x, y)
+		}
+	}
+	// the top left tile is at the top left corner of the map
+	if got, want := m.TileRect(0, 0), pixel.R(0, 16, 16, 24); got != want {
+		t.Errorf("TileRect(0, 0) = %v, want %v", got, want)
+	}
+}
+
+func TestRenderer_Draw(t *testing.T) {
+	fsys := testFS(t)
+	m, err := tilemap.Load(fsys, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tilemap.NewRenderer(m); err == nil {
+		t.Errorf("NewRenderer() without Pictures succeeded")
+	}
+	if err := m.LoadPictures(fsys, png.Decode); err != nil {
+		t.Fatalf("LoadPictures() error = %v", err)
+	}
+	if m.Tilesets[0].Picture != m.Tilesets[1].Picture {
+		t.Errorf("the image of both tilesets was loaded twice")
+	}
+	r, err := tilemap.NewRenderer(m)
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+
+	c := software.NewCanvas(pixel.R(0, 0, 6, 4))
+	r.Update(150 * time.Millisecond)
+	r.Draw(c, c.Bounds())
+
+	var (
+		pRed    = pixel.RGB(1, 0, 0)
+		pGreen  = pixel.RGB(0, 1, 0)
+		pBlue   = pixel.RGB(0, 0, 1)
+		pWhite  = pixel.RGB(1, 1, 1)
+		pYellow = pixel.RGB(1, 1, 0)
+	)
+	tests := []struct {
+		name string
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		// the top row of the map is the top row of the canvas
+		{"tile top left", pixel.V(0.5, 3.5), pRed},
+		{"tile top right", pixel.V(1.5, 3.5), pGreen},
+		{"tile bottom left", pixel.V(0.5, 2.5), pBlue},
+		{"tile bottom right", pixel.V(1.5, 2.5), pWhite},
+		{"flipped horizontally top left", pixel.V(2.5, 3.5), pGreen},
+		{"flipped horizontally bottom right", pixel.V(3.5, 2.5), pBlue},
+		{"flipped diagonally top left", pixel.V(4.5, 3.5), pRed},
+		{"flipped diagonally top right", pixel.V(5.5, 3.5), pBlue},
+		{"flipped diagonally bottom left", pixel.V(4.5, 2.5), pGreen},
+		{"second tile", pixel.V(1, 1), pYellow},
+		// the group is offset by a tile and the layer in it is half transparent
+		{"layer in group", pixel.V(3, 1), pYellow.Scaled(0.5)},
+		// the animation shows its second frame after 150ms
+		{"animated tile", pixel.V(5, 1), pYellow},
+	}
+	for _, tt := range tests {
+		if got := c.Color(tt.at); !colorEq(got, tt.want) {
+			t.Errorf("%s: Color(%v) = %v, want %v", tt.name, tt.at, got, tt.want)
+		}
+	}
+}
+
+// colorEq reports whether the colors are equal up to the precision of the canvas.
+func colorEq(a, b pixel.RGBA) bool {
+	const eps = 1.0 / 255
+	return math.Abs(a.R-b.R) <= eps && math.Abs(a.G-b.G) <= eps && math.Abs(a.B-b.B) <= eps && math.Abs(a.A-b.A) <= eps
+}
+
+// countingTarget counts the vertices drawn onto it.
+type countingTarget struct {
+	vertices int
+}
+
+func (ct *countingTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return &countingTriangles{TrianglesData: pixel.MakeTrianglesData(t.Len()), dst: ct}
+}
+
+func (ct *countingTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return &countingPicture{Picture: p}
+}
+
+type countingTriangles struct {
+	*pixel.TrianglesData
+	dst *countingTarget
+}
+
+func (ct *countingTriangles) Draw() {
+	ct.dst.vertices += ct.Len()
+}
+
+type countingPicture struct {
+	pixel.Picture
+}
+
+func (cp *countingPicture) Draw(t pixel.TargetTriangles) {
+	t.Draw()
+}
+
+func TestRenderer_culling(t *testing.T) {
+	const size = 100
+	tiles := make([]tilemap.GID, size*size)
+	for i := range tiles {
+		tiles[i] = 1
+	}
+	m := &tilemap.Map{
+		Orientation: "orthogonal",
+		Width:       size,
+		Height:      size,
+		TileWidth:   2,
+		TileHeight:  2,
+		Tilesets: []*tilemap.Tileset{{
+			FirstGID:  1,
+			TileWidth: 2, TileHeight: 2, TileCount: 1, Columns: 1,
+			Image:   &tilemap.Image{Width: 2, Height: 2},
+			Picture: pixel.MakePictureData(pixel.R(0, 0, 2, 2)),
+		}},
+		Layers: []*tilemap.Layer{{Kind: tilemap.TileLayer, Visible: true, Opacity: 1, Tint: pixel.Alpha(1), Width: size, Height: size, Tiles: tiles}},
+	}
+	r, err := tilemap.NewRenderer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		view     pixel.Rect
+		maxTiles int
+	}{
+		{"whole map", pixel.R(0, 0, 2*size, 2*size), size * size},
+		{"part", pixel.R(10, 10, 30, 20), 14 * 9},
+		{"outside", pixel.R(-100, -100, -50, -50), 0},
+	}
+	for _, tt := range tests {
+		target := &countingTarget{}
+		r.Draw(target, tt.view)
+		if got := target.vertices / 6; got > tt.maxTiles || (tt.maxTiles > 0 && got == 0) {
+			t.Errorf("%s: drawn %d tiles, want at most %d", tt.name, got, tt.maxTiles)
+		}
+	}
+}
+
+func BenchmarkRenderer_Draw(b *testing.B) {
+	const size = 1000
+	tiles := make([]tilemap.GID, size*size)
+	for i := range tiles {
+		tiles[i] = tilemap.GID(i%2 + 1)
+	}
+	m := &tilemap.Map{
+		Orientation: "orthogonal",
+		Width:       size,
+		Height:      size,
+		TileWidth:   16,
+		TileHeight:  16,
+		Tilesets: []*tilemap.Tileset{{
+			FirstGID:  1,
+			TileWidth: 16, TileHeight: 16, TileCount: 2, Columns: 2,
+			Image:   &tilemap.Image{Width: 32, Height: 16},
+			Picture: pixel.MakePictureData(pixel.R(0, 0, 32, 16)),
+		}},
+		Layers: []*tilemap.Layer{{Kind: tilemap.TileLayer, Visible: true, Opacity: 1, Tint: pixel.Alpha(1), Width: size, Height: size, Tiles: tiles}},
+	}
+	r, err := tilemap.NewRenderer(m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	target := &countingTarget{}
+	view := pixel.R(0, 0, 1280, 720).Moved(pixel.V(4000, 4000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Draw(target, view)
+	}
+}
@@ -0,0 +1,488 @@
+package tilemap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// The following types mirror the objects of the TMJ format.
+
+type tmjMap struct {
+	Version         jsonString    `json:"version"`
+	Class           string        `json:"class"`
+	Orientation     string        `json:"orientation"`
+	RenderOrder     string        `json:"renderorder"`
+	Width           int           `json:"width"`
+	Height          int           `json:"height"`
+	TileWidth       int           `json:"tilewidth"`
+	TileHeight      int           `json:"tileheight"`
+	Infinite        bool          `json:"infinite"`
+	BackgroundColor string        `json:"backgroundcolor"`
+	NextObjectID    int           `json:"nextobjectid"`
+	Properties      []tmjProperty `json:"properties"`
+	Tilesets        []tmjTileset  `json:"tilesets"`
+	Layers          []tmjLayer    `json:"layers"`
+}
+
+// jsonString is a string, which may be stored as a number, like the version of older maps.
+type jsonString string
+
+func (s *jsonString) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var str string
+		err := json.Unmarshal(data, &str)
+		*s = jsonString(str)
+		return err
+	}
+	*s = jsonString(data)
+	return nil
+}
+
+type tmjProperty struct {
+	Name  string          `json:"name"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type tmjTileset struct {
+	FirstGID    GID           `json:"firstgid"`
+	Source      string        `json:"source"`
+	Name        string        `json:"name"`
+	Class       string        `json:"class"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Spacing     int           `json:"spacing"`
+	Margin      int           `json:"margin"`
+	TileCount   int           `json:"tilecount"`
+	Columns     int           `json:"columns"`
+	TileOffset  *tmjPoint     `json:"tileoffset"`
+	Image       string        `json:"image"`
+	ImageWidth  int           `json:"imagewidth"`
+	ImageHeight int           `json:"imageheight"`
+	Properties  []tmjProperty `json:"properties"`
+	Tiles       []tmjTile     `json:"tiles"`
+}
+
+type tmjPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type tmjTile struct {
+	ID          int           `json:"id"`
+	Type        string        `json:"type"`
+	Class       string        `json:"class"`
+	Probability *float64      `json:"probability"`
+	Properties  []tmjProperty `json:"properties"`
+	Image       string        `json:"image"`
+	ImageWidth  int           `json:"imagewidth"`
+	ImageHeight int           `json:"imageheight"`
+	Animation   []struct {
+		TileID   int `json:"tileid"`
+		Duration int `json:"duration"`
+	} `json:"animation"`
+	ObjectGroup *tmjLayer `json:"objectgroup"`
+}
+
+type tmjLayer struct {
+	Type        string          `json:"type"`
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Class       string          `json:"class"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	TintColor   string          `json:"tintcolor"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	ParallaxX   *float64        `json:"parallaxx"`
+	ParallaxY   *float64        `json:"parallaxy"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Properties  []tmjProperty   `json:"properties"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      []tmjChunk      `json:"chunks"`
+	Color       string          `json:"color"`
+	DrawOrder   string          `json:"draworder"`
+	Objects     []tmjObject     `json:"objects"`
+	Image       string          `json:"image"`
+	ImageWidth  int             `json:"imagewidth"`
+	ImageHeight int             `json:"imageheight"`
+	Layers      []tmjLayer      `json:"layers"`
+}
+
+type tmjChunk struct {
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type tmjObject struct {
+	ID         int           `json:"id"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Class      string        `json:"class"`
+	X          float64       `json:"x"`
+	Y          float64       `json:"y"`
+	Width      float64       `json:"width"`
+	Height     float64       `json:"height"`
+	Rotation   float64       `json:"rotation"`
+	GID        GID           `json:"gid"`
+	Visible    *bool         `json:"visible"`
+	Properties []tmjProperty `json:"properties"`
+	Ellipse    bool          `json:"ellipse"`
+	Point      bool          `json:"point"`
+	Polygon    []tmjPoint    `json:"polygon"`
+	Polyline   []tmjPoint    `json:"polyline"`
+	Text       *struct {
+		Text string `json:"text"`
+	} `json:"text"`
+}
+
+// decodeTMJ decodes a map in the TMJ format. The paths in the map are relative to dir and the
+// external tilesets are loaded by the function.
+func decodeTMJ(data []byte, dir string, external func(source string) (*Tileset, error)) (*Map, error) {
+	var tm tmjMap
+	if err := json.Unmarshal(data, &tm); err != nil {
+		return nil, err
+	}
+
+	bg, err := parseColor(tm.BackgroundColor)
+	if err != nil {
+		return nil, err
+	}
+	m := &Map{
+		Version:      string(tm.Version),
+		Class:        tm.Class,
+		Orientation:  tm.Orientation,
+		RenderOrder:  tm.RenderOrder,
+		Width:        tm.Width,
+		Height:       tm.Height,
+		TileWidth:    tm.TileWidth,
+		TileHeight:   tm.TileHeight,
+		Infinite:     tm.Infinite,
+		Background:   bg,
+		NextObjectID: tm.NextObjectID,
+	}
+	if m.Properties, err = convertTMJProperties(tm.Properties); err != nil {
+		return nil, err
+	}
+
+	for _, tts := range tm.Tilesets {
+		if tts.Source != "" {
+			ts, err := external(path.Join(dir, tts.Source))
+			if err != nil {
+				return nil, fmt.Errorf("tileset %s: %w", tts.Source, err)
+			}
+			ts.FirstGID = tts.FirstGID
+			ts.Source = tts.Source
+			m.Tilesets = append(m.Tilesets, ts)
+			continue
+		}
+		ts, err := tts.convert(dir)
+		if err != nil {
+			return nil, fmt.Errorf("tileset %s: %w", tts.Name, err)
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	if m.Layers, err = convertTMJLayers(tm.Layers, dir); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeTSJ decodes an external tileset in the TSJ format. The paths in the tileset are relative
+// to dir.
+func decodeTSJ(data []byte, dir string) (*Tileset, error) {
+	var tts tmjTileset
+	if err := json.Unmarshal(data, &tts); err != nil {
+		return nil, err
+	}
+	return tts.convert(dir)
+}
+
+func convertTMJProperties(tps []tmjProperty) (Properties, error) {
+	var props Properties
+	for _, tp := range tps {
+		prop := Property{Name: tp.Name, Type: tp.Type}
+		if prop.Type == "" {
+			prop.Type = "string"
+		}
+		var err error
+		if prop.Type == "class" {
+			prop.Properties, err = convertTMJMembers(tp.Value)
+		} else {
+			prop.Value, err = jsonValue(tp.Value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("property %s: %w", tp.Name, err)
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// convertTMJMembers converts the members of a class property, which are stored as an object
+// without their types. The members are sorted by their names.
+func convertTMJMembers(data json.RawMessage) (Properties, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var props Properties
+	for _, name := range names {
+		data := members[name]
+		prop := Property{Name: name}
+		var err error
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			prop.Type = "class"
+			prop.Properties, err = convertTMJMembers(data)
+		} else {
+			prop.Value, err = jsonValue(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("member %s: %w", name, err)
+		}
+		props = append(props, prop)
+	}
+	return props, nil
+}
+
+// jsonValue converts a JSON string, number or bool to a string.
+func jsonValue(data json.RawMessage) (string, error) {
+	var v interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return "", err
+		}
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("invalid value %s", data)
+}
+
+func tmjImage(source string, width, height int, dir string) *Image {
+	if source == "" {
+		return nil
+	}
+	return &Image{Source: path.Join(dir, source), Width: width, Height: height}
+}
+
+func (tts *tmjTileset) convert(dir string) (*Tileset, error) {
+	ts := &Tileset{
+		FirstGID:   tts.FirstGID,
+		Name:       tts.Name,
+		Class:      tts.Class,
+		TileWidth:  tts.TileWidth,
+		TileHeight: tts.TileHeight,
+		Spacing:    tts.Spacing,
+		Margin:     tts.Margin,
+		TileCount:  tts.TileCount,
+		Columns:    tts.Columns,
+		Image:      tmjImage(tts.Image, tts.ImageWidth, tts.ImageHeight, dir),
+	}
+	if tts.TileOffset != nil {
+		ts.Offset = pixel.V(tts.TileOffset.X, tts.TileOffset.Y)
+	}
+	var err error
+	if ts.Properties, err = convertTMJProperties(tts.Properties); err != nil {
+		return nil, err
+	}
+
+	for _, tt := range tts.Tiles {
+		t := &Tile{
+			ID:          tt.ID,
+			Class:       tt.Class,
+			Probability: 1,
+			Image:       tmjImage(tt.Image, tt.ImageWidth, tt.ImageHeight, dir),
+		}
+		if t.Class == "" {
+			t.Class = tt.Type
+		}
+		if tt.Probability != nil {
+			t.Probability = *tt.Probability
+		}
+		for _, f := range tt.Animation {
+			t.Animation = append(t.Animation, Frame{TileID: f.TileID, Duration: f.Duration})
+		}
+		if t.Properties, err = convertTMJProperties(tt.Properties); err != nil {
+			return nil, fmt.Errorf("tile %d: %w", tt.ID, err)
+		}
+		if tt.ObjectGroup != nil {
+			if t.Objects, err = convertTMJObjects(tt.ObjectGroup.Objects); err != nil {
+				return nil, fmt.Errorf("tile %d: %w", tt.ID, err)
+			}
+		}
+		ts.Tiles = append(ts.Tiles, t)
+	}
+	return ts, nil
+}
+
+func convertTMJLayers(tls []tmjLayer, dir string) ([]*Layer, error) {
+	var layers []*Layer
+	for _, tl := range tls {
+		l := &Layer{
+			ID:       tl.ID,
+			Name:     tl.Name,
+			Class:    tl.Class,
+			Visible:  tl.Visible == nil || *tl.Visible,
+			Opacity:  1,
+			Tint:     pixel.Alpha(1),
+			Offset:   pixel.V(tl.OffsetX, tl.OffsetY),
+			Parallax: pixel.V(1, 1),
+		}
+		if tl.Opacity != nil {
+			l.Opacity = *tl.Opacity
+		}
+		if tl.ParallaxX != nil {
+			l.Parallax.X = *tl.ParallaxX
+		}
+		if tl.ParallaxY != nil {
+			l.Parallax.Y = *tl.ParallaxY
+		}
+		var err error
+		if tl.TintColor != "" {
+			l.Tint, err = parseColor(tl.TintColor)
+		}
+		if err == nil {
+			l.Properties, err = convertTMJProperties(tl.Properties)
+		}
+
+		if err == nil {
+			switch tl.Type {
+			case "tilelayer":
+				l.Kind = TileLayer
+				l.Width, l.Height = tl.Width, tl.Height
+				err = tl.convertData(l)
+			case "objectgroup":
+				l.Kind = ObjectGroup
+				l.DrawOrder = tl.DrawOrder
+				if l.DrawOrder == "" {
+					l.DrawOrder = "topdown"
+				}
+				l.Color, err = parseColor(tl.Color)
+				if err == nil {
+					l.Objects, err = convertTMJObjects(tl.Objects)
+				}
+			case "imagelayer":
+				l.Kind = ImageLayer
+				l.Image = tmjImage(tl.Image, tl.ImageWidth, tl.ImageHeight, dir)
+			case "group":
+				l.Kind = GroupLayer
+				l.Layers, err = convertTMJLayers(tl.Layers, dir)
+			default:
+				err = fmt.Errorf("unknown layer type %q", tl.Type)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", tl.Name, err)
+		}
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+func (tl *tmjLayer) convertData(l *Layer) error {
+	if tl.Chunks == nil {
+		tiles, err := decodeTMJTiles(tl.Data, tl.Encoding, tl.Compression, l.Width*l.Height)
+		l.Tiles = tiles
+		return err
+	}
+
+	l.Chunks = []Chunk{}
+	for _, tc := range tl.Chunks {
+		tiles, err := decodeTMJTiles(tc.Data, tl.Encoding, tl.Compression, tc.Width*tc.Height)
+		if err != nil {
+			return fmt.Errorf("chunk %d,%d: %w", tc.X, tc.Y, err)
+		}
+		l.Chunks = append(l.Chunks, Chunk{X: tc.X, Y: tc.Y, Width: tc.Width, Height: tc.Height, Tiles: tiles})
+	}
+	return nil
+}
+
+// decodeTMJTiles decodes the tiles of a layer or a chunk, which are either an array of GIDs or a
+// base64 encoded string.
+func decodeTMJTiles(data json.RawMessage, encoding, compression string, n int) ([]GID, error) {
+	if encoding == "base64" {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return decodeBase64(s, compression, n)
+	}
+
+	var tiles []GID
+	if err := json.Unmarshal(data, &tiles); err != nil {
+		return nil, err
+	}
+	if len(tiles) != n {
+		return nil, fmt.Errorf("%d tiles, want %d", len(tiles), n)
+	}
+	return tiles, nil
+}
+
+func convertTMJObjects(tos []tmjObject) ([]*Object, error) {
+	var objects []*Object
+	for _, to := range tos {
+		o := &Object{
+			ID:       to.ID,
+			Name:     to.Name,
+			Class:    to.Class,
+			X:        to.X,
+			Y:        to.Y,
+			Width:    to.Width,
+			Height:   to.Height,
+			Rotation: to.Rotation,
+			GID:      to.GID,
+			Visible:  to.Visible == nil || *to.Visible,
+			Ellipse:  to.Ellipse,
+			Point:    to.Point,
+			Polygon:  tmjPoints(to.Polygon),
+			Polyline: tmjPoints(to.Polyline),
+		}
+		if o.Class == "" {
+			o.Class = to.Type
+		}
+		if to.Text != nil {
+			o.Text = to.Text.Text
+		}
+		var err error
+		if o.Properties, err = convertTMJProperties(to.Properties); err != nil {
+			return nil, fmt.Errorf("object %d: %w", to.ID, err)
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+func tmjPoints(tps []tmjPoint) []pixel.Vec {
+	var points []pixel.Vec
+	for _, p := range tps {
+		points = append(points, pixel.V(p.X, p.Y))
+	}
+	return points
+}
@@ -0,0 +1,455 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// The following types mirror the elements of the TMX format.
+
+type tmxMap struct {
+	Version         string        `xml:"version,attr"`
+	Class           string        `xml:"class,attr"`
+	Orientation     string        `xml:"orientation,attr"`
+	RenderOrder     string        `xml:"renderorder,attr"`
+	Width           int           `xml:"width,attr"`
+	Height          int           `xml:"height,attr"`
+	TileWidth       int           `xml:"tilewidth,attr"`
+	TileHeight      int           `xml:"tileheight,attr"`
+	Infinite        int           `xml:"infinite,attr"`
+	BackgroundColor string        `xml:"backgroundcolor,attr"`
+	NextObjectID    int           `xml:"nextobjectid,attr"`
+	Properties      tmxProperties `xml:"properties"`
+	Tilesets        []tmxTileset  `xml:"tileset"`
+	Layers          []tmxLayer    `xml:",any"`
+}
+
+type tmxProperties struct {
+	Properties []tmxProperty `xml:"property"`
+}
+
+type tmxProperty struct {
+	Name       string         `xml:"name,attr"`
+	Type       string         `xml:"type,attr"`
+	Value      *string        `xml:"value,attr"`
+	Text       string         `xml:",chardata"`
+	Properties *tmxProperties `xml:"properties"`
+}
+
+type tmxTileset struct {
+	FirstGID   GID           `xml:"firstgid,attr"`
+	Source     string        `xml:"source,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	TileWidth  int           `xml:"tilewidth,attr"`
+	TileHeight int           `xml:"tileheight,attr"`
+	Spacing    int           `xml:"spacing,attr"`
+	Margin     int           `xml:"margin,attr"`
+	TileCount  int           `xml:"tilecount,attr"`
+	Columns    int           `xml:"columns,attr"`
+	TileOffset *tmxOffset    `xml:"tileoffset"`
+	Image      *tmxImage     `xml:"image"`
+	Properties tmxProperties `xml:"properties"`
+	Tiles      []tmxTile     `xml:"tile"`
+}
+
+type tmxOffset struct {
+	X float64 `xml:"x,attr"`
+	Y float64 `xml:"y,attr"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTile struct {
+	ID          int           `xml:"id,attr"`
+	Type        string        `xml:"type,attr"`
+	Class       string        `xml:"class,attr"`
+	Probability *float64      `xml:"probability,attr"`
+	Properties  tmxProperties `xml:"properties"`
+	Image       *tmxImage     `xml:"image"`
+	Animation   *struct {
+		Frames []struct {
+			TileID   int `xml:"tileid,attr"`
+			Duration int `xml:"duration,attr"`
+		} `xml:"frame"`
+	} `xml:"animation"`
+	ObjectGroup *tmxLayer `xml:"objectgroup"`
+}
+
+type tmxLayer struct {
+	XMLName    xml.Name
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Class      string        `xml:"class,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	TintColor  string        `xml:"tintcolor,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	ParallaxX  *float64      `xml:"parallaxx,attr"`
+	ParallaxY  *float64      `xml:"parallaxy,attr"`
+	Width      int           `xml:"width,attr"`
+	Height     int           `xml:"height,attr"`
+	Color      string        `xml:"color,attr"`
+	DrawOrder  string        `xml:"draworder,attr"`
+	Properties tmxProperties `xml:"properties"`
+	Data       *tmxData      `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Image      *tmxImage     `xml:"image"`
+	Layers     []tmxLayer    `xml:",any"`
+}
+
+type tmxData struct {
+	Encoding    string     `xml:"encoding,attr"`
+	Compression string     `xml:"compression,attr"`
+	Text        string     `xml:",chardata"`
+	Tiles       []tmxGID   `xml:"tile"`
+	Chunks      []tmxChunk `xml:"chunk"`
+}
+
+type tmxGID struct {
+	GID GID `xml:"gid,attr"`
+}
+
+type tmxChunk struct {
+	X      int      `xml:"x,attr"`
+	Y      int      `xml:"y,attr"`
+	Width  int      `xml:"width,attr"`
+	Height int      `xml:"height,attr"`
+	Text   string   `xml:",chardata"`
+	Tiles  []tmxGID `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"`
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        GID           `xml:"gid,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Properties tmxProperties `xml:"properties"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *tmxPoints    `xml:"polygon"`
+	Polyline   *tmxPoints    `xml:"polyline"`
+	Text       *struct {
+		Text string `xml:",chardata"`
+	} `xml:"text"`
+}
+
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+// decodeTMX decodes a map in the TMX format. The paths in the map are relative to dir and the
+// external tilesets are loaded by the function.
+func decodeTMX(data []byte, dir string, external func(source string) (*Tileset, error)) (*Map, error) {
+	var tm tmxMap
+	if err := xml.Unmarshal(data, &tm); err != nil {
+		return nil, err
+	}
+
+	bg, err := parseColor(tm.BackgroundColor)
+	if err != nil {
+		return nil, err
+	}
+	m := &Map{
+		Version:      tm.Version,
+		Class:        tm.Class,
+		Orientation:  tm.Orientation,
+		RenderOrder:  tm.RenderOrder,
+		Width:        tm.Width,
+		Height:       tm.Height,
+		TileWidth:    tm.TileWidth,
+		TileHeight:   tm.TileHeight,
+		Infinite:     tm.Infinite != 0,
+		Background:   bg,
+		NextObjectID: tm.NextObjectID,
+		Properties:   tm.Properties.convert(),
+	}
+
+	for _, tts := range tm.Tilesets {
+		if tts.Source != "" {
+			ts, err := external(path.Join(dir, tts.Source))
+			if err != nil {
+				return nil, fmt.Errorf("tileset %s: %w", tts.Source, err)
+			}
+			ts.FirstGID = tts.FirstGID
+			ts.Source = tts.Source
+			m.Tilesets = append(m.Tilesets, ts)
+			continue
+		}
+		ts, err := tts.convert(dir)
+		if err != nil {
+			return nil, fmt.Errorf("tileset %s: %w", tts.Name, err)
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	m.Layers, err = convertTMXLayers(tm.Layers, dir)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeTSX decodes an external tileset in the TSX format. The paths in the tileset are relative
+// to dir.
+func decodeTSX(data []byte, dir string) (*Tileset, error) {
+	var tts tmxTileset
+	if err := xml.Unmarshal(data, &tts); err != nil {
+		return nil, err
+	}
+	return tts.convert(dir)
+}
+
+func (tp tmxProperties) convert() Properties {
+	var props Properties
+	for _, p := range tp.Properties {
+		prop := Property{Name: p.Name, Type: p.Type}
+		if prop.Type == "" {
+			prop.Type = "string"
+		}
+		// multiline strings are stored in the text of the element
+		if p.Value != nil {
+			prop.Value = *p.Value
+		} else if p.Properties == nil {
+			prop.Value = p.Text
+		}
+		if p.Properties != nil {
+			prop.Properties = p.Properties.convert()
+		}
+		props = append(props, prop)
+	}
+	return props
+}
+
+func (ti *tmxImage) convert(dir string) *Image {
+	if ti == nil {
+		return nil
+	}
+	return &Image{
+		Source: path.Join(dir, ti.Source),
+		Width:  ti.Width,
+		Height: ti.Height,
+	}
+}
+
+func (tts *tmxTileset) convert(dir string) (*Tileset, error) {
+	ts := &Tileset{
+		FirstGID:   tts.FirstGID,
+		Name:       tts.Name,
+		Class:      tts.Class,
+		TileWidth:  tts.TileWidth,
+		TileHeight: tts.TileHeight,
+		Spacing:    tts.Spacing,
+		Margin:     tts.Margin,
+		TileCount:  tts.TileCount,
+		Columns:    tts.Columns,
+		Image:      tts.Image.convert(dir),
+		Properties: tts.Properties.convert(),
+	}
+	if tts.TileOffset != nil {
+		ts.Offset = pixel.V(tts.TileOffset.X, tts.TileOffset.Y)
+	}
+
+	for _, tt := range tts.Tiles {
+		t := &Tile{
+			ID:          tt.ID,
+			Class:       tt.Class,
+			Probability: 1,
+			Properties:  tt.Properties.convert(),
+			Image:       tt.Image.convert(dir),
+		}
+		if t.Class == "" {
+			t.Class = tt.Type
+		}
+		if tt.Probability != nil {
+			t.Probability = *tt.Probability
+		}
+		if tt.Animation != nil {
+			for _, f := range tt.Animation.Frames {
+				t.Animation = append(t.Animation, Frame{TileID: f.TileID, Duration: f.Duration})
+			}
+		}
+		if tt.ObjectGroup != nil {
+			objects, err := convertTMXObjects(tt.ObjectGroup.Objects)
+			if err != nil {
+				return nil, fmt.Errorf("tile %d: %w", tt.ID, err)
+			}
+			t.Objects = objects
+		}
+		ts.Tiles = append(ts.Tiles, t)
+	}
+	return ts, nil
+}
+
+func convertTMXLayers(tls []tmxLayer, dir string) ([]*Layer, error) {
+	var layers []*Layer
+	for _, tl := range tls {
+		l := &Layer{
+			ID:         tl.ID,
+			Name:       tl.Name,
+			Class:      tl.Class,
+			Visible:    tl.Visible == nil || *tl.Visible != 0,
+			Opacity:    1,
+			Offset:     pixel.V(tl.OffsetX, tl.OffsetY),
+			Parallax:   pixel.V(1, 1),
+			Properties: tl.Properties.convert(),
+		}
+		if tl.Opacity != nil {
+			l.Opacity = *tl.Opacity
+		}
+		if tl.ParallaxX != nil {
+			l.Parallax.X = *tl.ParallaxX
+		}
+		if tl.ParallaxY != nil {
+			l.Parallax.Y = *tl.ParallaxY
+		}
+		tint, err := parseColor(tl.TintColor)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", tl.Name, err)
+		}
+		if tl.TintColor == "" {
+			tint = pixel.Alpha(1)
+		}
+		l.Tint = tint
+
+		switch tl.XMLName.Local {
+		case "layer":
+			l.Kind = TileLayer
+			l.Width, l.Height = tl.Width, tl.Height
+			err = tl.convertData(l)
+		case "objectgroup":
+			l.Kind = ObjectGroup
+			l.DrawOrder = tl.DrawOrder
+			if l.DrawOrder == "" {
+				l.DrawOrder = "topdown"
+			}
+			l.Color, err = parseColor(tl.Color)
+			if err == nil {
+				l.Objects, err = convertTMXObjects(tl.Objects)
+			}
+		case "imagelayer":
+			l.Kind = ImageLayer
+			l.Image = tl.Image.convert(dir)
+		case "group":
+			l.Kind = GroupLayer
+			l.Layers, err = convertTMXLayers(tl.Layers, dir)
+		default:
+			// other elements, like the editor settings, aren't layers
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", tl.Name, err)
+		}
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+func (tl *tmxLayer) convertData(l *Layer) error {
+	if tl.Data == nil {
+		return fmt.Errorf("no data")
+	}
+	d := tl.Data
+	if d.Chunks == nil {
+		tiles, err := decodeTMXTiles(d.Text, d.Tiles, d.Encoding, d.Compression, l.Width*l.Height)
+		l.Tiles = tiles
+		return err
+	}
+
+	l.Chunks = []Chunk{}
+	for _, tc := range d.Chunks {
+		tiles, err := decodeTMXTiles(tc.Text, tc.Tiles, d.Encoding, d.Compression, tc.Width*tc.Height)
+		if err != nil {
+			return fmt.Errorf("chunk %d,%d: %w", tc.X, tc.Y, err)
+		}
+		l.Chunks = append(l.Chunks, Chunk{X: tc.X, Y: tc.Y, Width: tc.Width, Height: tc.Height, Tiles: tiles})
+	}
+	return nil
+}
+
+// decodeTMXTiles decodes the tiles of a layer or a chunk, which are either encoded in the text or
+// stored as tile elements.
+func decodeTMXTiles(text string, elements []tmxGID, encoding, compression string, n int) ([]GID, error) {
+	if encoding != "" {
+		return decodeTiles(text, encoding, compression, n)
+	}
+	if len(elements) != n {
+		return nil, fmt.Errorf("%d tiles, want %d", len(elements), n)
+	}
+	tiles := make([]GID, n)
+	for i, e := range elements {
+		tiles[i] = e.GID
+	}
+	return tiles, nil
+}
+
+func convertTMXObjects(tos []tmxObject) ([]*Object, error) {
+	var objects []*Object
+	for _, to := range tos {
+		o := &Object{
+			ID:         to.ID,
+			Name:       to.Name,
+			Class:      to.Class,
+			X:          to.X,
+			Y:          to.Y,
+			Width:      to.Width,
+			Height:     to.Height,
+			Rotation:   to.Rotation,
+			GID:        to.GID,
+			Visible:    to.Visible == nil || *to.Visible != 0,
+			Properties: to.Properties.convert(),
+			Ellipse:    to.Ellipse != nil,
+			Point:      to.Point != nil,
+		}
+		if o.Class == "" {
+			o.Class = to.Type
+		}
+		if to.Text != nil {
+			o.Text = to.Text.Text
+		}
+		var err error
+		if to.Polygon != nil {
+			o.Polygon, err = parsePoints(to.Polygon.Points)
+		}
+		if to.Polyline != nil && err == nil {
+			o.Polyline, err = parsePoints(to.Polyline.Points)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %w", to.ID, err)
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+// parsePoints parses the points of a polygon or a polyline, "x1,y1 x2,y2 ...".
+func parsePoints(s string) ([]pixel.Vec, error) {
+	var points []pixel.Vec
+	for _, p := range strings.Fields(s) {
+		xs, ys, ok := strings.Cut(p, ",")
+		x, errX := strconv.ParseFloat(xs, 64)
+		y, errY := strconv.ParseFloat(ys, 64)
+		if !ok || errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid point %q", p)
+		}
+		points = append(points, pixel.V(x, y))
+	}
+	return points, nil
+}
@@ -0,0 +1,430 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Blurred returns the PictureData blurred with the Gaussian blur with the standard deviation sigma
+// (in pixels). The area outside of the PictureData is treated as transparent, so the edges of the
+// result fade out.
+func (pd *PictureData) Blurred(sigma float64) *PictureData {
+	x0, y0, _, _ := pd.grid()
+	return bufferFromPictureData(pd).blurred(sigma, false).pictureData(x0, y0)
+}
+
+// BoxBlurred returns the PictureData blurred by averaging each pixel with the pixels at most radius
+// pixels away horizontally and vertically. The area outside of the PictureData is treated as
+// transparent, so the edges of the result fade out.
+func (pd *PictureData) BoxBlurred(radius int) *PictureData {
+	x0, y0, _, _ := pd.grid()
+	if radius <= 0 {
+		return pd.Cropped(pd.Rect)
+	}
+	kernel := make([]float64, 2*radius+1)
+	for i := range kernel {
+		kernel[i] = 1 / float64(len(kernel))
+	}
+	buf := bufferFromPictureData(pd)
+	return buf.convolved(kernel, true, false).convolved(kernel, false, false).pictureData(x0, y0)
+}
+
+// Sharpened returns the PictureData sharpened with the unsharp mask: the difference between the
+// picture and the picture blurred with the standard deviation sigma, multiplied by the amount, is
+// added to the picture. Amounts around 1 and sigmas around 1 work well.
+func (pd *PictureData) Sharpened(amount, sigma float64) *PictureData {
+	x0, y0, _, _ := pd.grid()
+	buf := bufferFromPictureData(pd)
+	blurred := buf.blurred(sigma, true)
+	for i, c := range buf.pix {
+		buf.pix[i] = c.Add(c.Sub(blurred.pix[i]).Scaled(amount))
+	}
+	return buf.pictureData(x0, y0)
+}
+
+// ColorMatrix is a 4x5 matrix transforming the straight (not alpha-premultiplied) RGBA components
+// of a color. The rows compute the red, green, blue and alpha components of the result from the
+// red, green, blue and alpha components of the original color, plus the constant in the last
+// column:
+//
+//	R' = m[0]*R + m[1]*G + m[2]*B + m[3]*A + m[4]
+//	G' = m[5]*R + m[6]*G + ...
+type ColorMatrix [20]float64
+
+// IdentityColorMatrix is the ColorMatrix which doesn't change the colors.
+var IdentityColorMatrix = ColorMatrix{
+	1, 0, 0, 0, 0,
+	0, 1, 0, 0, 0,
+	0, 0, 1, 0, 0,
+	0, 0, 0, 1, 0,
+}
+
+// GrayscaleColorMatrix returns a ColorMatrix which desaturates the colors by the amount within
+// range [0, 1], where 1 makes them fully gray. The luminance of the colors is preserved.
+func GrayscaleColorMatrix(amount float64) ColorMatrix {
+	// the luma coefficients of sRGB
+	const lr, lg, lb = 0.2126, 0.7152, 0.0722
+	k := 1 - amount
+	return ColorMatrix{
+		lr + (1-lr)*k, lg - lg*k, lb - lb*k, 0, 0,
+		lr - lr*k, lg + (1-lg)*k, lb - lb*k, 0, 0,
+		lr - lr*k, lg - lg*k, lb + (1-lb)*k, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// SepiaColorMatrix returns a ColorMatrix which tints the colors with the brownish tone of old
+// photos by the amount within range [0, 1].
+func SepiaColorMatrix(amount float64) ColorMatrix {
+	k := 1 - amount
+	return ColorMatrix{
+		0.393 + 0.607*k, 0.769 - 0.769*k, 0.189 - 0.189*k, 0, 0,
+		0.349 - 0.349*k, 0.686 + 0.314*k, 0.168 - 0.168*k, 0, 0,
+		0.272 - 0.272*k, 0.534 - 0.534*k, 0.131 + 0.869*k, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// HueShiftColorMatrix returns a ColorMatrix which rotates the hue of the colors by the angle (in
+// radians), preserving their luminance.
+func HueShiftColorMatrix(angle float64) ColorMatrix {
+	sin, cos := math.Sincos(angle)
+	return ColorMatrix{
+		0.213 + cos*0.787 - sin*0.213, 0.715 - cos*0.715 - sin*0.715, 0.072 - cos*0.072 + sin*0.928, 0, 0,
+		0.213 - cos*0.213 + sin*0.143, 0.715 + cos*0.285 + sin*0.140, 0.072 - cos*0.072 - sin*0.283, 0, 0,
+		0.213 - cos*0.213 - sin*0.787, 0.715 - cos*0.715 + sin*0.715, 0.072 + cos*0.928 + sin*0.072, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+}
+
+// Chained returns a ColorMatrix which transforms the colors by m and then by next.
+func (m ColorMatrix) Chained(next ColorMatrix) ColorMatrix {
+	var c ColorMatrix
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 5; col++ {
+			var sum float64
+			for k := 0; k < 4; k++ {
+				sum += next[row*5+k] * m[k*5+col]
+			}
+			if col == 4 {
+				sum += next[row*5+4]
+			}
+			c[row*5+col] = sum
+		}
+	}
+	return c
+}
+
+// Apply returns the color transformed by the ColorMatrix. The color is unpremultiplied before the
+// transformation and premultiplied again after it, the components of the result are clamped to
+// range [0, 1].
+func (m ColorMatrix) Apply(c RGBA) RGBA {
+	r, g, b := c.straight()
+	x := [4]float64{r, g, b, c.A}
+	var y [4]float64
+	for row := range y {
+		m := m[row*5:]
+		y[row] = Clamp(m[0]*x[0]+m[1]*x[1]+m[2]*x[2]+m[3]*x[3]+m[4], 0, 1)
+	}
+	return premultiplied(y[0], y[1], y[2], y[3])
+}
+
+// ColorTransformed returns the PictureData with all its colors transformed by the ColorMatrix.
+//
+//	gray := pic.ColorTransformed(pixel.GrayscaleColorMatrix(1))
+func (pd *PictureData) ColorTransformed(m ColorMatrix) *PictureData {
+	x0, y0, _, _ := pd.grid()
+	buf := bufferFromPictureData(pd)
+	for i, c := range buf.pix {
+		buf.pix[i] = m.Apply(c)
+	}
+	return buf.pictureData(x0, y0)
+}
+
+// Palette returns at most n colors representing the colors of the PictureData, chosen by the
+// median cut algorithm. Use it to create a palette for Quantized.
+func (pd *PictureData) Palette(n int) []RGBA {
+	if n <= 0 {
+		return nil
+	}
+	colors := bufferFromPictureData(pd).pix
+	if len(colors) == 0 {
+		return nil
+	}
+
+	boxes := []colorBox{makeColorBox(colors)}
+	for len(boxes) < n {
+		// split the box with the largest range of a component at the median of that component
+		best := 0
+		for i := range boxes {
+			if boxes[i].spread > boxes[best].spread {
+				best = i
+			}
+		}
+		box := boxes[best]
+		if box.spread == 0 {
+			break
+		}
+		sort.Slice(box.colors, func(i, j int) bool {
+			return component(box.colors[i], box.widest) < component(box.colors[j], box.widest)
+		})
+		// move the split so that equal components stay in the same box
+		half := len(box.colors) / 2
+		median := component(box.colors[half], box.widest)
+		if i := sort.Search(half, func(i int) bool {
+			return component(box.colors[i], box.widest) >= median
+		}); i > 0 {
+			half = i
+		} else {
+			half = sort.Search(len(box.colors), func(i int) bool {
+				return component(box.colors[i], box.widest) > median
+			})
+		}
+		boxes[best] = makeColorBox(box.colors[:half])
+		boxes = append(boxes, makeColorBox(box.colors[half:]))
+	}
+
+	palette := make([]RGBA, len(boxes))
+	for i, box := range boxes {
+		var sum RGBA
+		for _, c := range box.colors {
+			sum = sum.Add(c)
+		}
+		palette[i] = sum.Scaled(1 / float64(len(box.colors)))
+	}
+	return palette
+}
+
+// Quantized returns the PictureData with every color replaced by the closest color of the palette.
+// If dither is true, the differences from the original colors are spread to the neighbouring pixels
+// with the Floyd-Steinberg dithering, which hides the banding of smooth gradients.
+//
+// Quantized panics if the palette is empty.
+func (pd *PictureData) Quantized(palette []RGBA, dither bool) *PictureData {
+	if len(palette) == 0 {
+		panic(fmt.Errorf("(%T).Quantized: empty palette", pd))
+	}
+	x0, y0, w, h := pd.grid()
+	buf := bufferFromPictureData(pd)
+	// the error diffusion goes from the top row down, as usual
+	for y := h - 1; y >= 0; y-- {
+		for x := 0; x < w; x++ {
+			old := buf.pix[y*w+x]
+			closest := palette[0]
+			best := math.Inf(+1)
+			for _, p := range palette {
+				d := p.Sub(old)
+				if dist := d.R*d.R + d.G*d.G + d.B*d.B + d.A*d.A; dist < best {
+					closest, best = p, dist
+				}
+			}
+			buf.pix[y*w+x] = closest
+
+			if !dither {
+				continue
+			}
+			diff := old.Sub(closest)
+			for _, n := range [...]struct {
+				dx, dy int
+				weight float64
+			}{
+				{1, 0, 7.0 / 16},
+				{-1, -1, 3.0 / 16},
+				{0, -1, 5.0 / 16},
+				{1, -1, 1.0 / 16},
+			} {
+				nx, ny := x+n.dx, y+n.dy
+				if nx >= 0 && nx < w && ny >= 0 {
+					buf.pix[ny*w+nx] = buf.pix[ny*w+nx].Add(diff.Scaled(n.weight))
+				}
+			}
+		}
+	}
+	return buf.pictureData(x0, y0)
+}
+
+// Outlined returns the PictureData drawn over its outline of the color, which surrounds the opaque
+// parts of the picture and is width pixels wide. The result is larger than the PictureData by width
+// pixels (rounded up) on each side, to fit the outline.
+func (pd *PictureData) Outlined(width float64, col RGBA) *PictureData {
+	x0, y0, w, h := pd.grid()
+	margin := int(math.Ceil(math.Max(width, 0)))
+	src := bufferFromPictureData(pd)
+	dst := makeBuffer(w+2*margin, h+2*margin)
+
+	// the alpha of the outline is the maximum alpha within the width, fading out over one pixel for
+	// smooth edges
+	for y := 0; y < dst.h; y++ {
+		for x := 0; x < dst.w; x++ {
+			var alpha float64
+			for dy := -margin; dy <= margin; dy++ {
+				for dx := -margin; dx <= margin; dx++ {
+					a := src.at(x-margin+dx, y-margin+dy).A
+					if a <= alpha {
+						continue
+					}
+					coverage := Clamp(width+1-math.Hypot(float64(dx), float64(dy)), 0, 1)
+					alpha = math.Max(alpha, a*coverage)
+				}
+			}
+			outline := col.Scaled(alpha)
+			dst.pix[y*dst.w+x] = ComposeOver.Compose(src.at(x-margin, y-margin), outline)
+		}
+	}
+	return dst.pictureData(x0-margin, y0-margin)
+}
+
+// Shadowed returns the PictureData drawn over its drop shadow of the color, moved by the offset
+// (rounded to whole pixels) and blurred with the standard deviation sigma. The result is large
+// enough to fit both the PictureData and the shadow.
+func (pd *PictureData) Shadowed(offset Vec, sigma float64, col RGBA) *PictureData {
+	x0, y0, w, h := pd.grid()
+	dx, dy := int(math.Round(offset.X)), int(math.Round(offset.Y))
+	blur := int(math.Ceil(3 * math.Max(sigma, 0)))
+
+	// the bounds of the result in the pixels of the PictureData
+	minX, minY := min(0, dx-blur), min(0, dy-blur)
+	maxX, maxY := max(w, w+dx+blur), max(h, h+dy+blur)
+
+	src := bufferFromPictureData(pd)
+	shadow := makeBuffer(maxX-minX, maxY-minY)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			shadow.pix[(y+dy-minY)*shadow.w+x+dx-minX] = col.Scaled(src.pix[y*w+x].A)
+		}
+	}
+	shadow = shadow.blurred(sigma, false)
+	for y := 0; y < shadow.h; y++ {
+		for x := 0; x < shadow.w; x++ {
+			i := y*shadow.w + x
+			shadow.pix[i] = ComposeOver.Compose(src.at(x+minX, y+minY), shadow.pix[i])
+		}
+	}
+	return shadow.pictureData(x0+minX, y0+minY)
+}
+
+// Premultiplied returns the PictureData with its colors multiplied by their alpha. Use it for
+// pixels loaded from a source with straight alpha, because PictureData, like image.RGBA, stores
+// alpha-premultiplied colors.
+func (pd *PictureData) Premultiplied() *PictureData {
+	x0, y0, w, h := pd.grid()
+	result := makePictureDataGrid(x0, y0, w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := pd.Pix[y*pd.Stride+x]
+			c.R = uint8((uint32(c.R)*uint32(c.A) + 127) / 255)
+			c.G = uint8((uint32(c.G)*uint32(c.A) + 127) / 255)
+			c.B = uint8((uint32(c.B)*uint32(c.A) + 127) / 255)
+			result.Pix[y*w+x] = c
+		}
+	}
+	return result
+}
+
+// Unpremultiplied returns the PictureData with its colors divided by their alpha. It is the inverse
+// of Premultiplied, up to the precision lost by the premultiplication. Use it to hand the pixels to
+// code expecting straight alpha.
+func (pd *PictureData) Unpremultiplied() *PictureData {
+	x0, y0, w, h := pd.grid()
+	result := makePictureDataGrid(x0, y0, w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := pd.Pix[y*pd.Stride+x]
+			if c.A != 0 {
+				a := uint32(c.A)
+				c.R = uint8(min((uint32(c.R)*255+a/2)/a, 255))
+				c.G = uint8(min((uint32(c.G)*255+a/2)/a, 255))
+				c.B = uint8(min((uint32(c.B)*255+a/2)/a, 255))
+			}
+			result.Pix[y*w+x] = c
+		}
+	}
+	return result
+}
+
+// colorBox is a set of colors of the median cut, with its widest component and the range of it.
+type colorBox struct {
+	colors []RGBA
+	widest int
+	spread float64
+}
+
+func makeColorBox(colors []RGBA) colorBox {
+	box := colorBox{colors: colors}
+	for k := 0; k < 4; k++ {
+		lo, hi := math.Inf(+1), math.Inf(-1)
+		for _, c := range colors {
+			lo, hi = math.Min(lo, component(c, k)), math.Max(hi, component(c, k))
+		}
+		if hi-lo > box.spread {
+			box.widest, box.spread = k, hi-lo
+		}
+	}
+	return box
+}
+
+// component returns the k-th component of the color, in the order R, G, B, A.
+func component(c RGBA, k int) float64 {
+	switch k {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	case 2:
+		return c.B
+	}
+	return c.A
+}
+
+// blurred returns the buffer blurred with the Gaussian blur with the standard deviation sigma. If
+// extend is true, the edge pixels of the buffer extend beyond it, otherwise the area outside of the
+// buffer is transparent.
+func (b *buffer) blurred(sigma float64, extend bool) *buffer {
+	if sigma <= 0 {
+		return &buffer{w: b.w, h: b.h, pix: append([]RGBA(nil), b.pix...)}
+	}
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var total float64
+	for i := range kernel {
+		x := float64(i - radius)
+		kernel[i] = math.Exp(-x * x / (2 * sigma * sigma))
+		total += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= total
+	}
+	return b.convolved(kernel, true, extend).convolved(kernel, false, extend)
+}
+
+// convolved returns the buffer convolved with the symmetric kernel, horizontally or vertically. If
+// extend is true, the edge pixels of the buffer extend beyond it, otherwise the area outside of the
+// buffer is transparent.
+func (b *buffer) convolved(kernel []float64, horizontal, extend bool) *buffer {
+	dst := makeBuffer(b.w, b.h)
+	radius := len(kernel) / 2
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			var c RGBA
+			for k, wt := range kernel {
+				sx, sy := x, y
+				if horizontal {
+					sx += k - radius
+				} else {
+					sy += k - radius
+				}
+				if extend {
+					sx, sy = min(max(sx, 0), b.w-1), min(max(sy, 0), b.h-1)
+				}
+				s := b.at(sx, sy)
+				c.R += s.R * wt
+				c.G += s.G * wt
+				c.B += s.B * wt
+				c.A += s.A * wt
+			}
+			dst.pix[y*b.w+x] = c
+		}
+	}
+	return dst
+}
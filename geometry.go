@@ -1,5 +1,7 @@
 package pixel
 
+import "math"
+
 // Bezier is cubic Bézier curve used for interpolation. For more info
 // see https://en.wikipedia.org/wiki/B%C3%A9zier_curve,
 // In case you are looking for visualization see https://www.desmos.com/calculator/d1ofwre0fr
@@ -26,6 +28,18 @@ func Linear(start, end Vec) Bezier {
 	return B(start, ZV, ZV, end)
 }
 
+// Quadratic returns a quadratic Bezier curve from start to end, pulled towards the control point.
+// Unlike the handles of B, the control point is not relative. The quadratic curve is represented
+// exactly by a cubic Bezier with handles 2/3 of the way to the control point.
+func Quadratic(start, control, end Vec) Bezier {
+	return Bezier{
+		Start:       start,
+		StartHandle: Lerp(start, control, 2.0/3),
+		EndHandle:   Lerp(end, control, 2.0/3),
+		End:         end,
+	}
+}
+
 // Constant returns Bezier curve that always return same point,
 // This is usefull as placeholder, because it skips calculation
 func Constant(constant Vec) Bezier {
@@ -52,3 +66,226 @@ func (b Bezier) Point(t float64) Vec {
 		b.Start.Y*c+b.StartHandle.Y*d+b.EndHandle.Y*e+b.End.Y*f,
 	)
 }
+
+// controls returns the four control points of the curve.
+func (b Bezier) controls() (p0, p1, p2, p3 Vec) {
+	if b.redundant {
+		return b.Start, b.Start, b.Start, b.Start
+	}
+	return b.Start, b.StartHandle, b.EndHandle, b.End
+}
+
+// at returns the point along the curve at t, without the shortcut for curves starting and ending
+// at the same point taken by Point.
+func (b Bezier) at(t float64) Vec {
+	p0, p1, p2, p3 := b.controls()
+	inv := 1.0 - t
+	return p0.Scaled(inv * inv * inv).
+		Add(p1.Scaled(3 * inv * inv * t)).
+		Add(p2.Scaled(3 * inv * t * t)).
+		Add(p3.Scaled(t * t * t))
+}
+
+// Derivative returns the derivative of the curve at t, that is the velocity of a point moving
+// along the curve as t goes from 0 to 1.
+func (b Bezier) Derivative(t float64) Vec {
+	p0, p1, p2, p3 := b.controls()
+	inv := 1.0 - t
+	return p0.To(p1).Scaled(3 * inv * inv).
+		Add(p1.To(p2).Scaled(6 * inv * t)).
+		Add(p2.To(p3).Scaled(3 * t * t))
+}
+
+// Tangent returns the unit vector in the direction of the curve at t. Where the derivative is zero,
+// such as at an end point with a zero handle, the direction the curve is heading to is used
+// instead. A curve which doesn't move at all has a zero tangent.
+func (b Bezier) Tangent(t float64) Vec {
+	if d := b.Derivative(t); d != ZV {
+		return d.Unit()
+	}
+	p0, p1, p2, p3 := b.controls()
+	inv := 1.0 - t
+	// near a point where the derivative is zero, the curve runs along the second derivative, which
+	// points backwards when arriving to the point
+	d := p0.Sub(p1.Scaled(2)).Add(p2).Scaled(inv).Add(p1.Sub(p2.Scaled(2)).Add(p3).Scaled(t))
+	if t > 0.5 {
+		d = d.Scaled(-1)
+	}
+	if d != ZV {
+		return d.Unit()
+	}
+	if d := p0.To(p3); d != ZV {
+		return d.Unit()
+	}
+	return ZV
+}
+
+// Normal returns the Tangent at t rotated by 90 degrees counter-clockwise, which points to the left
+// side of the curve.
+func (b Bezier) Normal(t float64) Vec {
+	return b.Tangent(t).Normal()
+}
+
+// Split splits the curve at t into two curves, the first one going from the start to the point at
+// t and the second one from there to the end. Together they trace the same shape as the original
+// curve.
+func (b Bezier) Split(t float64) (Bezier, Bezier) {
+	p0, p1, p2, p3 := b.controls()
+	p01, p12, p23 := Lerp(p0, p1, t), Lerp(p1, p2, t), Lerp(p2, p3, t)
+	p012, p123 := Lerp(p01, p12, t), Lerp(p12, p23, t)
+	mid := Lerp(p012, p123, t)
+	return Bezier{Start: p0, StartHandle: p01, EndHandle: p012, End: mid},
+		Bezier{Start: mid, StartHandle: p123, EndHandle: p23, End: p3}
+}
+
+// Bounds returns the smallest Rect containing the whole curve. Unlike the bounds of the control
+// points, these are tight.
+func (b Bezier) Bounds() Rect {
+	p0, p1, p2, p3 := b.controls()
+	r := Rect{Min: p0, Max: p0}.Norm()
+	r = r.Union(Rect{Min: p3, Max: p3})
+
+	// the extremes of each axis lie where the derivative of the axis is zero
+	extend := func(c0, c1, c2, c3 float64) {
+		a := -c0 + 3*c1 - 3*c2 + c3
+		bb := 2 * (c0 - 2*c1 + c2)
+		c := c1 - c0
+		for _, t := range quadraticRoots(a, bb, c) {
+			if t > 0 && t < 1 {
+				u := b.at(t)
+				r = r.Union(Rect{Min: u, Max: u})
+			}
+		}
+	}
+	extend(p0.X, p1.X, p2.X, p3.X)
+	extend(p0.Y, p1.Y, p2.Y, p3.Y)
+	return r
+}
+
+// quadraticRoots returns the real roots of a*x^2 + b*x + c.
+func quadraticRoots(a, b, c float64) []float64 {
+	if math.Abs(a) < 1e-12 {
+		if b == 0 {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+	d := b*b - 4*a*c
+	if d < 0 {
+		return nil
+	}
+	sq := math.Sqrt(d)
+	return []float64{(-b + sq) / (2 * a), (-b - sq) / (2 * a)}
+}
+
+// Len returns the arc length of the curve.
+func (b Bezier) Len() float64 {
+	return b.LenAt(1)
+}
+
+// LenAt returns the arc length of the curve from the start to the point at t.
+func (b Bezier) LenAt(t float64) float64 {
+	p0, p1, p2, p3 := b.controls()
+	// the length of the control polygon is an upper bound of the arc length
+	tolerance := 1e-9 * (p0.To(p1).Len() + p1.To(p2).Len() + p2.To(p3).Len() + 1)
+	whole := b.gaussLegendre(0, t)
+	return b.adaptiveLen(0, t, whole, tolerance, 16)
+}
+
+func (b Bezier) adaptiveLen(t0, t1, whole, tolerance float64, depth int) float64 {
+	mid := (t0 + t1) / 2
+	left, right := b.gaussLegendre(t0, mid), b.gaussLegendre(mid, t1)
+	if depth == 0 || math.Abs(left+right-whole) <= tolerance {
+		return left + right
+	}
+	return b.adaptiveLen(t0, mid, left, tolerance/2, depth-1) +
+		b.adaptiveLen(mid, t1, right, tolerance/2, depth-1)
+}
+
+var gaussLegendreNodes = [...]struct{ x, w float64 }{
+	{0, 0.5688888888888889},
+	{-0.5384693101056831, 0.4786286704993665},
+	{0.5384693101056831, 0.4786286704993665},
+	{-0.9061798459386640, 0.2369268850561891},
+	{0.9061798459386640, 0.2369268850561891},
+}
+
+// gaussLegendre integrates the speed of the curve between t0 and t1.
+func (b Bezier) gaussLegendre(t0, t1 float64) float64 {
+	half, center := (t1-t0)/2, (t0+t1)/2
+	sum := 0.0
+	for _, n := range gaussLegendreNodes {
+		sum += n.w * b.Derivative(center+half*n.x).Len()
+	}
+	return sum * half
+}
+
+// TAtLen returns the t at which the arc length of the curve from its start equals length. Moving
+// the length at a constant rate moves the point along the curve at a constant speed, unlike
+// moving t. The result is clamped to [0, 1].
+func (b Bezier) TAtLen(length float64) float64 {
+	total := b.Len()
+	if length <= 0 || total == 0 {
+		return 0
+	}
+	if length >= total {
+		return 1
+	}
+
+	// Newton's method, falling back to bisection whenever it leaves the bracket
+	lo, hi := 0.0, 1.0
+	t := length / total
+	for i := 0; i < 32; i++ {
+		diff := b.LenAt(t) - length
+		if math.Abs(diff) <= 1e-9*total {
+			break
+		}
+		if diff > 0 {
+			hi = t
+		} else {
+			lo = t
+		}
+		speed := b.Derivative(t).Len()
+		next := t - diff/speed
+		if speed == 0 || next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		t = next
+	}
+	return t
+}
+
+// PointAtLen returns the point along the curve in the arc length from its start, see TAtLen.
+func (b Bezier) PointAtLen(length float64) Vec {
+	return b.at(b.TAtLen(length))
+}
+
+// Flatten approximates the curve by a polyline, which doesn't stray further from the curve than
+// the tolerance. The returned points start at the start of the curve and end at its end.
+//
+//	imd.Push(curve.Flatten(0.5)...)
+//	imd.Line(2)
+func (b Bezier) Flatten(tolerance float64) []Vec {
+	p0, _, _, _ := b.controls()
+	return b.flatten([]Vec{p0}, tolerance, 16)
+}
+
+func (b Bezier) flatten(points []Vec, tolerance float64, depth int) []Vec {
+	p0, p1, p2, p3 := b.controls()
+	if depth == 0 || segmentDist(p1, p0, p3) <= tolerance && segmentDist(p2, p0, p3) <= tolerance {
+		return append(points, p3)
+	}
+	first, second := b.Split(0.5)
+	points = first.flatten(points, tolerance, depth-1)
+	return second.flatten(points, tolerance, depth-1)
+}
+
+// segmentDist returns the distance of the point u from the line segment ab.
+func segmentDist(u, a, b Vec) float64 {
+	ab := a.To(b)
+	if ab == ZV {
+		return a.To(u).Len()
+	}
+	t := Clamp(ab.Dot(a.To(u))/ab.SqLen(), 0, 1)
+	return a.Add(ab.Scaled(t)).To(u).Len()
+}
@@ -0,0 +1,177 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// GradientStop is a color at a position along a gradient. The Offset 0 is the start of the gradient
+// and the Offset 1 is its end.
+type GradientStop struct {
+	Offset float64
+	Color  RGBA
+}
+
+// SpreadMode specifies how a gradient continues beyond its start and end.
+type SpreadMode int
+
+// Here's the list of all available spread modes.
+const (
+	// SpreadPad extends the colors of the first and the last stop.
+	SpreadPad SpreadMode = iota
+	// SpreadRepeat repeats the gradient.
+	SpreadRepeat
+	// SpreadReflect repeats the gradient, reversing every other repetition.
+	SpreadReflect
+)
+
+// String returns the name of the SpreadMode.
+func (s SpreadMode) String() string {
+	switch s {
+	case SpreadPad:
+		return "SpreadPad"
+	case SpreadRepeat:
+		return "SpreadRepeat"
+	case SpreadReflect:
+		return "SpreadReflect"
+	}
+	return fmt.Sprintf("SpreadMode(%d)", int(s))
+}
+
+// Gradient holds the properties common to all gradients: the color stops, the spread mode and the
+// bounds of the gradient Picture.
+//
+// The Stops must be sorted by their Offset. The colors between two stops are interpolated linearly
+// in the alpha-premultiplied space, so that transparent stops don't darken their neighbours. A
+// Gradient without stops is fully transparent.
+//
+// Use pointers to the gradient types as Pictures. Targets may cache the content of a Picture, so
+// don't modify a gradient after drawing it, create a new one instead.
+type Gradient struct {
+	Stops  []GradientStop
+	Spread SpreadMode
+
+	// Rect is the bounds of the Picture. Gradients are transparent outside of it.
+	Rect Rect
+}
+
+// Bounds returns the bounds of the gradient Picture.
+func (g Gradient) Bounds() Rect {
+	return g.Rect
+}
+
+// At returns the color at the position t along the gradient, after applying the spread mode.
+func (g Gradient) At(t float64) RGBA {
+	if len(g.Stops) == 0 || math.IsNaN(t) {
+		return RGBA{}
+	}
+
+	switch g.Spread {
+	case SpreadRepeat:
+		t -= math.Floor(t)
+	case SpreadReflect:
+		t = math.Mod(math.Abs(t), 2)
+		if t > 1 {
+			t = 2 - t
+		}
+	}
+
+	stops := g.Stops
+	i := sort.Search(len(stops), func(i int) bool { return stops[i].Offset > t })
+	switch {
+	case i == 0:
+		return stops[0].Color
+	case i == len(stops):
+		return stops[len(stops)-1].Color
+	}
+	a, b := stops[i-1], stops[i]
+	return a.Color.Lerp(b.Color, (t-a.Offset)/(b.Offset-a.Offset))
+}
+
+// color returns the color at the position t along the gradient, or transparent if the point is
+// outside of the bounds.
+func (g Gradient) color(at Vec, t func() float64) RGBA {
+	if !g.Rect.Contains(at) {
+		return RGBA{}
+	}
+	return g.At(t())
+}
+
+var (
+	_ PictureColor = (*LinearGradient)(nil)
+	_ PictureColor = (*RadialGradient)(nil)
+	_ PictureColor = (*ConicGradient)(nil)
+)
+
+// LinearGradient is a Picture with colors changing along the line from Start to End. The stop with
+// the Offset 0 is at Start and the one with the Offset 1 is at End.
+//
+//	sky := &pixel.LinearGradient{
+//		Gradient: pixel.Gradient{
+//			Stops: []pixel.GradientStop{
+//				{Offset: 0, Color: pixel.RGB(1, 0.6, 0.3)},
+//				{Offset: 1, Color: pixel.RGB(0.1, 0.2, 0.6)},
+//			},
+//			Rect: win.Bounds(),
+//		},
+//		Start: win.Bounds().Min,
+//		End:   pixel.V(win.Bounds().Min.X, win.Bounds().Max.Y),
+//	}
+//	pixel.NewSprite(sky, sky.Bounds()).Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+//
+// If Start equals End, the gradient is transparent.
+type LinearGradient struct {
+	Gradient
+	Start, End Vec
+}
+
+// Color returns the color of the gradient at the given position.
+func (lg *LinearGradient) Color(at Vec) RGBA {
+	d := lg.Start.To(lg.End)
+	if d == ZV {
+		return RGBA{}
+	}
+	return lg.color(at, func() float64 {
+		return lg.Start.To(at).Dot(d) / d.SqLen()
+	})
+}
+
+// RadialGradient is a Picture with colors changing with the distance from the Center. The stop with
+// the Offset 0 is at the Center and the one with the Offset 1 is at the distance of Radius.
+//
+// If the Radius is not positive, the gradient is transparent.
+type RadialGradient struct {
+	Gradient
+	Center Vec
+	Radius float64
+}
+
+// Color returns the color of the gradient at the given position.
+func (rg *RadialGradient) Color(at Vec) RGBA {
+	if rg.Radius <= 0 {
+		return RGBA{}
+	}
+	return rg.color(at, func() float64 {
+		return rg.Center.To(at).Len() / rg.Radius
+	})
+}
+
+// ConicGradient is a Picture with colors changing with the angle around the Center,
+// counter-clockwise. The stops with the Offsets 0 and 1 are both in the direction of Angle (in
+// radians), the full circle is between them.
+//
+// The Spread of a ConicGradient has no effect, because all the positions are within [0, 1).
+type ConicGradient struct {
+	Gradient
+	Center Vec
+	Angle  float64
+}
+
+// Color returns the color of the gradient at the given position.
+func (cg *ConicGradient) Color(at Vec) RGBA {
+	return cg.color(at, func() float64 {
+		t := (cg.Center.To(at).Angle() - cg.Angle) / (2 * math.Pi)
+		return t - math.Floor(t)
+	})
+}
@@ -0,0 +1,210 @@
+package pixel
+
+import "fmt"
+
+// TrianglesIndexed specifies Triangles, which store each vertex only once and form the triangles by
+// indices into the vertices. Each three indices form a triangle.
+//
+// The i-th vertex of TrianglesIndexed, as returned by the Position, Color and other properties, is
+// the vertex of the i-th index, and Len returns the number of indices. This way, Targets unaware
+// of indices draw TrianglesIndexed like any other Triangles, while Targets supporting indices can
+// use the vertices and the indices directly.
+type TrianglesIndexed interface {
+	Triangles
+
+	// Vertices returns the vertices of the Triangles, each stored only once. Modifying the
+	// vertices must change the content of the TrianglesIndexed.
+	Vertices() Triangles
+
+	// Index returns the index of the vertex of the i-th corner of the triangles.
+	Index(i int) int
+}
+
+// VertexIndex is the type of the indices of IndexedTrianglesData. The uint16 indices take half the
+// memory of the uint32 ones, but can only address 65536 vertices.
+type VertexIndex interface {
+	~uint16 | ~uint32
+}
+
+var (
+	_ TrianglesIndexed  = (*IndexedTrianglesData[uint16])(nil)
+	_ TrianglesPosition = (*IndexedTrianglesData[uint16])(nil)
+	_ TrianglesColor    = (*IndexedTrianglesData[uint16])(nil)
+	_ TrianglesPicture  = (*IndexedTrianglesData[uint16])(nil)
+	_ TrianglesClipped  = (*IndexedTrianglesData[uint16])(nil)
+)
+
+// IndexedTrianglesData is TrianglesData together with the indices of its vertices, each three
+// forming a triangle. It implements TrianglesIndexed, TrianglesPosition, TrianglesColor,
+// TrianglesPicture and TrianglesClipped.
+//
+// A quad takes four vertices and six indices instead of six vertices, which saves memory and
+// bandwidth, because a vertex is much larger than an index:
+//
+//	quad := pixel.MakeIndexedTrianglesData[uint16](4, 6)
+//	copy(quad.Indices, []uint16{0, 1, 2, 0, 2, 3})
+//	(*quad.VertexData)[0].Position = pixel.V(0, 0)
+//	...
+//
+// The properties of the i-th vertex are the properties of the vertex at Indices[i], so changing the
+// properties of a vertex shared by several triangles changes all of them.
+type IndexedTrianglesData[I VertexIndex] struct {
+	VertexData *TrianglesData
+	Indices    []I
+}
+
+// MakeIndexedTrianglesData creates IndexedTrianglesData with the given number of vertices
+// initialized with default property values, see MakeTrianglesData, and the given number of zero
+// indices.
+func MakeIndexedTrianglesData[I VertexIndex](vertices, indices int) *IndexedTrianglesData[I] {
+	return &IndexedTrianglesData[I]{
+		VertexData: MakeTrianglesData(vertices),
+		Indices:    make([]I, indices),
+	}
+}
+
+// IndexTriangles converts Triangles into IndexedTrianglesData, storing the equal vertices only
+// once. TrianglesPosition, TrianglesColor, TrianglesPicture and TrianglesClipped are supported.
+//
+// IndexTriangles panics if the Triangles have more distinct vertices than the indices of type I
+// can address.
+func IndexTriangles[I VertexIndex](t Triangles) *IndexedTrianglesData[I] {
+	td := MakeTrianglesData(t.Len())
+	td.Update(t)
+
+	type vertex = struct {
+		Position  Vec
+		Color     RGBA
+		Picture   Vec
+		Intensity float64
+		ClipRect  Rect
+		IsClipped bool
+	}
+	itd := &IndexedTrianglesData[I]{
+		VertexData: &TrianglesData{},
+		Indices:    make([]I, td.Len()),
+	}
+	seen := make(map[vertex]I)
+	for i, v := range *td {
+		index, ok := seen[v]
+		if !ok {
+			if uint64(itd.VertexData.Len()) > uint64(^I(0)) {
+				panic(fmt.Errorf("IndexTriangles: too many vertices for %T indices", index))
+			}
+			index = I(itd.VertexData.Len())
+			seen[v] = index
+			*itd.VertexData = append(*itd.VertexData, v)
+		}
+		itd.Indices[i] = index
+	}
+	return itd
+}
+
+// Len returns the number of indices, which is three times the number of triangles.
+func (itd *IndexedTrianglesData[I]) Len() int {
+	return len(itd.Indices)
+}
+
+// SetLen resizes the indices to len, while keeping the original content. The new indices are zero.
+// The vertices are not changed, use VertexData to change them.
+func (itd *IndexedTrianglesData[I]) SetLen(len int) {
+	if len > cap(itd.Indices) {
+		indices := make([]I, len)
+		copy(indices, itd.Indices)
+		itd.Indices = indices
+		return
+	}
+	old := itd.Len()
+	itd.Indices = itd.Indices[:len]
+	for i := old; i < len; i++ {
+		itd.Indices[i] = 0
+	}
+}
+
+// Slice returns a sub-Triangles of this IndexedTrianglesData, covering the indices in range [i, j).
+// The returned Triangles share the vertices with this IndexedTrianglesData.
+func (itd *IndexedTrianglesData[I]) Slice(i, j int) Triangles {
+	return &IndexedTrianglesData[I]{
+		VertexData: itd.VertexData,
+		Indices:    itd.Indices[i:j],
+	}
+}
+
+// Update copies vertex properties from the supplied Triangles into this IndexedTrianglesData.
+//
+// If the supplied Triangles are TrianglesIndexed, their vertices and indices are copied, resizing
+// the vertices if needed. Otherwise, the properties of the i-th vertex of the supplied Triangles
+// are copied to the vertex at the i-th index.
+//
+// TrianglesPosition, TrianglesColor, TrianglesPicture and TrianglesClipped are supported.
+func (itd *IndexedTrianglesData[I]) Update(t Triangles) {
+	if itd.Len() != t.Len() {
+		panic(fmt.Errorf("(%T).Update: invalid triangles length", itd))
+	}
+
+	// fast path optimization
+	if t, ok := t.(*IndexedTrianglesData[I]); ok {
+		itd.VertexData.SetLen(t.VertexData.Len())
+		copy(*itd.VertexData, *t.VertexData)
+		copy(itd.Indices, t.Indices)
+		return
+	}
+
+	if t, ok := t.(TrianglesIndexed); ok {
+		vertices := t.Vertices()
+		itd.VertexData.SetLen(vertices.Len())
+		itd.VertexData.Update(vertices)
+		for i := range itd.Indices {
+			itd.Indices[i] = I(t.Index(i))
+		}
+		return
+	}
+
+	// the properties not supported by t stay the same
+	corners := MakeTrianglesData(itd.Len())
+	corners.Update(itd)
+	corners.Update(t)
+	for i, index := range itd.Indices {
+		(*itd.VertexData)[index] = (*corners)[i]
+	}
+}
+
+// Copy returns an exact independent copy of this IndexedTrianglesData.
+func (itd *IndexedTrianglesData[I]) Copy() Triangles {
+	return &IndexedTrianglesData[I]{
+		VertexData: itd.VertexData.Copy().(*TrianglesData),
+		Indices:    append([]I(nil), itd.Indices...),
+	}
+}
+
+// Vertices returns the VertexData.
+func (itd *IndexedTrianglesData[I]) Vertices() Triangles {
+	return itd.VertexData
+}
+
+// Index returns the index of the vertex of the i-th corner of the triangles.
+func (itd *IndexedTrianglesData[I]) Index(i int) int {
+	return int(itd.Indices[i])
+}
+
+// Position returns the position property of the vertex at the i-th index.
+func (itd *IndexedTrianglesData[I]) Position(i int) Vec {
+	return (*itd.VertexData)[itd.Indices[i]].Position
+}
+
+// Color returns the color property of the vertex at the i-th index.
+func (itd *IndexedTrianglesData[I]) Color(i int) RGBA {
+	return (*itd.VertexData)[itd.Indices[i]].Color
+}
+
+// Picture returns the picture property of the vertex at the i-th index.
+func (itd *IndexedTrianglesData[I]) Picture(i int) (pic Vec, intensity float64) {
+	v := &(*itd.VertexData)[itd.Indices[i]]
+	return v.Picture, v.Intensity
+}
+
+// ClipRect returns the clipping rectangle property of the vertex at the i-th index.
+func (itd *IndexedTrianglesData[I]) ClipRect(i int) (rect Rect, has bool) {
+	v := &(*itd.VertexData)[itd.Indices[i]]
+	return v.ClipRect, v.IsClipped
+}
@@ -97,3 +97,99 @@ func (m Matrix) Unproject(u Vec) Vec {
 		(-m[1]*(u.X-m[4]) + m[0]*(u.Y-m[5])) / det,
 	}
 }
+
+// Inverted returns the inverse of the Matrix, which undoes all its transformations, so that
+// m.Inverted().Project(m.Project(u)) == u.
+//
+// A Matrix which squashes everything onto a line or a point can't be inverted, its inverse will
+// contain infinities or NaNs, just like the result of Unproject.
+func (m Matrix) Inverted() Matrix {
+	det := m[0]*m[3] - m[2]*m[1]
+	return Matrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}
+}
+
+// Transform is a Matrix decomposed into separate components. The Matrix it stands for first scales
+// everything by Scale in each axis, then shears it horizontally by Shear (moving the x coordinate
+// by Shear times the y coordinate), then rotates it by Rotation in radians and finally moves it by
+// Translation. All of the transformations except for the last one are done around the origin.
+type Transform struct {
+	Translation Vec
+	Rotation    float64
+	Scale       Vec
+	Shear       float64
+}
+
+// Matrix composes the components of the Transform into a Matrix. It is the inverse operation to
+// Matrix.Decompose.
+//
+//	m := pixel.Transform{Translation: pos, Rotation: angle, Scale: pixel.V(2, 2)}.Matrix()
+//	// same as pixel.IM.Scaled(pixel.ZV, 2).Rotated(pixel.ZV, angle).Moved(pos)
+func (t Transform) Matrix() Matrix {
+	sint, cost := math.Sincos(t.Rotation)
+	return Matrix{
+		t.Scale.X * cost,
+		t.Scale.X * sint,
+		t.Scale.Y * (t.Shear*cost - sint),
+		t.Scale.Y * (t.Shear*sint + cost),
+		t.Translation.X,
+		t.Translation.Y,
+	}
+}
+
+// Decompose splits the Matrix into translation, rotation, scale and shear, such that
+// m.Decompose().Matrix() equals m up to rounding errors.
+//
+// The decomposition is not unique. Scale.X is never negative, a Matrix which mirrors everything
+// has negative Scale.Y instead. Rotation is in the range [-Pi, Pi].
+func (m Matrix) Decompose() Transform {
+	t := Transform{Translation: V(m[4], m[5])}
+
+	t.Scale.X = math.Hypot(m[0], m[1])
+	if t.Scale.X == 0 {
+		// the x axis is squashed to a point, only the y axis has a direction
+		t.Scale.Y = math.Hypot(m[2], m[3])
+		t.Rotation = math.Atan2(-m[2], m[3])
+		return t
+	}
+
+	t.Rotation = math.Atan2(m[1], m[0])
+	t.Scale.Y = (m[0]*m[3] - m[2]*m[1]) / t.Scale.X
+	if t.Scale.Y != 0 {
+		sint, cost := math.Sincos(t.Rotation)
+		t.Shear = (m[2]*cost + m[3]*sint) / t.Scale.Y
+	}
+	return t
+}
+
+// Lerp interpolates between the Matrix and another one by the amount t, where t=0 results in m
+// and t=1 results in the other Matrix.
+//
+// The matrices are decomposed and their components interpolated separately, so that a rotation
+// keeps its shape during the animation instead of squashing everything. The rotation takes the
+// shorter way around.
+//
+//	m := start.Lerp(end, elapsed/duration)
+func (m Matrix) Lerp(to Matrix, t float64) Matrix {
+	a, b := m.Decompose(), to.Decompose()
+
+	rotation := b.Rotation - a.Rotation
+	if rotation > math.Pi {
+		rotation -= 2 * math.Pi
+	} else if rotation < -math.Pi {
+		rotation += 2 * math.Pi
+	}
+
+	return Transform{
+		Translation: Lerp(a.Translation, b.Translation, t),
+		Rotation:    a.Rotation + rotation*t,
+		Scale:       Lerp(a.Scale, b.Scale, t),
+		Shear:       a.Shear + (b.Shear-a.Shear)*t,
+	}.Matrix()
+}
@@ -0,0 +1,331 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// NineSliceMode specifies how the edges and the center of a NineSliceSprite fill their area.
+type NineSliceMode int
+
+// Here's the list of all available nine-slice modes.
+const (
+	// NineSliceStretch stretches the part of the frame over the whole area.
+	NineSliceStretch NineSliceMode = iota
+	// NineSliceTile repeats the part of the frame in its original size, starting from the bottom
+	// left corner of the area. The last tiles are cut off.
+	NineSliceTile
+)
+
+// String returns the name of the NineSliceMode.
+func (m NineSliceMode) String() string {
+	switch m {
+	case NineSliceStretch:
+		return "NineSliceStretch"
+	case NineSliceTile:
+		return "NineSliceTile"
+	}
+	return fmt.Sprintf("NineSliceMode(%d)", int(m))
+}
+
+// Insets are the widths of the borders of a NineSliceSprite's frame, measured from the edges of the
+// frame inwards.
+type Insets struct {
+	Left, Bottom, Right, Top float64
+}
+
+// NineSliceSprite is a drawable frame of a Picture, which can be drawn in any size without
+// distorting its borders, like the frame of a UI panel or a button. It's anchored by its center,
+// like Sprite.
+//
+// The frame is divided by the Insets into nine parts. The corners are drawn in their original size,
+// the left and the right edges only change their height, the bottom and the top edges only change
+// their width and the center fills the rest. If the size is smaller than the borders, the borders
+// are shrunk to fit.
+//
+//	panel := pixel.NewNineSliceSprite(pic, pic.Bounds(), pixel.Insets{Left: 8, Bottom: 8, Right: 8, Top: 8})
+//	panel.SetSize(pixel.V(300, 200))
+//	panel.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+//
+// Note, that NineSliceSprite caches the results of MakePicture from Targets it's drawn to for each
+// Picture it's set to, like Sprite.
+type NineSliceSprite struct {
+	tri    *TrianglesData
+	frame  Rect
+	insets Insets
+	size   Vec
+	edges  NineSliceMode
+	center NineSliceMode
+	d      Drawer
+
+	matrix Matrix
+	mask   RGBA
+}
+
+// NewNineSliceSprite creates a NineSliceSprite from the supplied frame of a Picture divided by the
+// Insets. The size of the NineSliceSprite is the size of the frame and the edges and the center are
+// stretched.
+func NewNineSliceSprite(pic Picture, frame Rect, insets Insets) *NineSliceSprite {
+	tri := &TrianglesData{}
+	s := &NineSliceSprite{
+		tri:  tri,
+		d:    Drawer{Triangles: tri, Cached: true},
+		size: frame.Size(),
+	}
+	s.matrix = IM
+	s.mask = Alpha(1)
+	s.Set(pic, frame, insets)
+	return s
+}
+
+// Set sets a new frame of a Picture and its Insets for this NineSliceSprite.
+func (s *NineSliceSprite) Set(pic Picture, frame Rect, insets Insets) {
+	s.d.Picture = pic
+	if frame != s.frame || insets != s.insets {
+		s.frame = frame
+		s.insets = insets
+		s.calcData()
+	}
+}
+
+// SetSize sets the size the NineSliceSprite is drawn in, before it's transformed by the Matrix.
+func (s *NineSliceSprite) SetSize(size Vec) {
+	if size != s.size {
+		s.size = size
+		s.calcData()
+	}
+}
+
+// SetModes sets how the edges and the center of the NineSliceSprite fill their area.
+func (s *NineSliceSprite) SetModes(edges, center NineSliceMode) {
+	if edges != s.edges || center != s.center {
+		s.edges = edges
+		s.center = center
+		s.calcData()
+	}
+}
+
+// SetCached makes the NineSliceSprite cache all the incoming pictures if the argument is true, and
+// doesn't make it do that if the argument is false.
+func (s *NineSliceSprite) SetCached(cached bool) {
+	s.d.Cached = cached
+}
+
+// Picture returns the current NineSliceSprite's Picture.
+func (s *NineSliceSprite) Picture() Picture {
+	return s.d.Picture
+}
+
+// Frame returns the current NineSliceSprite's frame.
+func (s *NineSliceSprite) Frame() Rect {
+	return s.frame
+}
+
+// Insets returns the current NineSliceSprite's Insets.
+func (s *NineSliceSprite) Insets() Insets {
+	return s.insets
+}
+
+// Size returns the size the NineSliceSprite is drawn in.
+func (s *NineSliceSprite) Size() Vec {
+	return s.size
+}
+
+// Modes returns how the edges and the center of the NineSliceSprite fill their area.
+func (s *NineSliceSprite) Modes() (edges, center NineSliceMode) {
+	return s.edges, s.center
+}
+
+// Draw draws the NineSliceSprite onto the provided Target. The NineSliceSprite will be transformed
+// by the given Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (s *NineSliceSprite) Draw(t Target, matrix Matrix) {
+	s.DrawColorMask(t, matrix, nil)
+}
+
+// DrawColorMask draws the NineSliceSprite onto the provided Target. The NineSliceSprite will be
+// transformed by the given Matrix and all of it's color will be multiplied by the given mask.
+//
+// If the mask is nil, a fully opaque white mask will be used, which causes no effect.
+func (s *NineSliceSprite) DrawColorMask(t Target, matrix Matrix, mask color.Color) {
+	dirty := false
+	if matrix != s.matrix {
+		s.matrix = matrix
+		dirty = true
+	}
+	if mask == nil {
+		mask = Alpha(1)
+	}
+	rgba := ToRGBA(mask)
+	if rgba != s.mask {
+		s.mask = rgba
+		dirty = true
+	}
+
+	if dirty {
+		s.calcData()
+	}
+
+	s.d.Draw(t)
+}
+
+func (s *NineSliceSprite) calcData() {
+	var (
+		f    = s.frame
+		in   = s.insets
+		size = s.size
+	)
+
+	// the borders are shrunk if they don't fit into the size
+	left, right := in.Left, in.Right
+	if left+right > size.X {
+		k := size.X / (left + right)
+		left, right = left*k, right*k
+	}
+	bottom, top := in.Bottom, in.Top
+	if bottom+top > size.Y {
+		k := size.Y / (bottom + top)
+		bottom, top = bottom*k, top*k
+	}
+
+	var (
+		srcX = [4]float64{f.Min.X, f.Min.X + in.Left, f.Max.X - in.Right, f.Max.X}
+		srcY = [4]float64{f.Min.Y, f.Min.Y + in.Bottom, f.Max.Y - in.Top, f.Max.Y}
+		dstX = [4]float64{0, left, size.X - right, size.X}
+		dstY = [4]float64{0, bottom, size.Y - top, size.Y}
+	)
+
+	s.tri.SetLen(0)
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			src := R(srcX[i], srcY[j], srcX[i+1], srcY[j+1])
+			dst := R(dstX[i], dstY[j], dstX[i+1], dstY[j+1])
+			if src.W() <= 0 || src.H() <= 0 || dst.W() <= 0 || dst.H() <= 0 {
+				continue
+			}
+
+			// the middle column and row are tiled along the length of the edge
+			mode := s.center
+			if i != 1 || j != 1 {
+				mode = s.edges
+			}
+			tileX := mode == NineSliceTile && i == 1
+			tileY := mode == NineSliceTile && j == 1
+			s.addTiles(src, dst, tileX, tileY)
+		}
+	}
+
+	// the NineSliceSprite is anchored by its center
+	center := size.Scaled(0.5)
+	for i := range *s.tri {
+		(*s.tri)[i].Color = s.mask
+		(*s.tri)[i].Intensity = 1
+		(*s.tri)[i].Position = s.matrix.Project((*s.tri)[i].Position.Sub(center))
+	}
+
+	s.d.Dirty()
+}
+
+// addTiles fills the destination rectangle with the source rectangle, repeating it in its original
+// size along the axes which are tiled and stretching it along the others.
+func (s *NineSliceSprite) addTiles(src, dst Rect, tileX, tileY bool) {
+	tileW, tileH := dst.W(), dst.H()
+	if tileX {
+		tileW = src.W()
+	}
+	if tileY {
+		tileH = src.H()
+	}
+
+	// the tiles are counted, so that rounding errors don't add slivers of tiles
+	cols := int(math.Ceil(dst.W()/tileW - 1e-9))
+	rows := int(math.Ceil(dst.H()/tileH - 1e-9))
+	for ty := 0; ty < rows; ty++ {
+		y := dst.Min.Y + float64(ty)*tileH
+		h := math.Min(tileH, dst.Max.Y-y)
+		for tx := 0; tx < cols; tx++ {
+			x := dst.Min.X + float64(tx)*tileW
+			w := math.Min(tileW, dst.Max.X-x)
+			// the cut off tiles show only a part of the source
+			part := R(src.Min.X, src.Min.Y, src.Min.X+src.W()*w/tileW, src.Min.Y+src.H()*h/tileH)
+			s.addQuad(part, R(x, y, x+w, y+h))
+		}
+	}
+}
+
+// addQuad adds two triangles drawing the source rectangle of the Picture in the destination
+// rectangle.
+func (s *NineSliceSprite) addQuad(src, dst Rect) {
+	n := s.tri.Len()
+	s.tri.SetLen(n + 6)
+	for i, corner := range [6]Vec{{0, 0}, {1, 0}, {1, 1}, {0, 0}, {1, 1}, {0, 1}} {
+		(*s.tri)[n+i].Position = V(dst.Min.X+corner.X*dst.W(), dst.Min.Y+corner.Y*dst.H())
+		(*s.tri)[n+i].Picture = V(src.Min.X+corner.X*src.W(), src.Min.Y+corner.Y*src.H())
+	}
+}
+
+// ParseNinePatch reads the marker lines of an Android nine-patch image (.9.png) located in the
+// bounds of the PictureData. It returns the frame of the image inside the one pixel wide border of
+// the marker lines and the Insets marked by the lines, to be used with NewNineSliceSprite:
+//
+//	pd, err := pixel.PictureDataFromFile("button.9.png", nil)
+//	// handle error
+//	frame, insets, err := pixel.ParseNinePatch(pd, pd.Bounds())
+//	// handle error
+//	button := pixel.NewNineSliceSprite(pd, frame, insets)
+//
+// The top line marks the stretchable columns and the left line marks the stretchable rows, by
+// opaque black pixels. Each of them must mark a single contiguous range. The right and the bottom
+// lines, which mark the content area, are ignored.
+func ParseNinePatch(pd *PictureData, bounds Rect) (frame Rect, insets Insets, err error) {
+	bounds = bounds.Norm()
+	if bounds.W() < 3 || bounds.H() < 3 || bounds.Intersect(pd.Bounds()) != bounds {
+		return Rect{}, Insets{}, fmt.Errorf("ParseNinePatch: invalid bounds %v", bounds)
+	}
+	frame = R(bounds.Min.X+1, bounds.Min.Y+1, bounds.Max.X-1, bounds.Max.Y-1)
+
+	// the top line is the last row, the left line is the first column
+	topLine := func(i float64) Vec { return V(i, bounds.Max.Y-1) }
+	leftLine := func(i float64) Vec { return V(bounds.Min.X, i) }
+
+	x0, x1, err := ninePatchRange(pd, topLine, frame.Min.X, frame.Max.X)
+	if err != nil {
+		return Rect{}, Insets{}, fmt.Errorf("ParseNinePatch: top line: %w", err)
+	}
+	y0, y1, err := ninePatchRange(pd, leftLine, frame.Min.Y, frame.Max.Y)
+	if err != nil {
+		return Rect{}, Insets{}, fmt.Errorf("ParseNinePatch: left line: %w", err)
+	}
+
+	insets = Insets{
+		Left:   x0 - frame.Min.X,
+		Bottom: y0 - frame.Min.Y,
+		Right:  frame.Max.X - x1,
+		Top:    frame.Max.Y - y1,
+	}
+	return frame, insets, nil
+}
+
+// ninePatchRange returns the range [start, end) of the marked pixels of a marker line.
+func ninePatchRange(pd *PictureData, at func(i float64) Vec, min, max float64) (start, end float64, err error) {
+	marker := RGB(0, 0, 0)
+	start, end = math.NaN(), math.NaN()
+	for i := min; i < max; i++ {
+		if pd.Color(at(i)) != marker {
+			continue
+		}
+		switch {
+		case math.IsNaN(start):
+			start = i
+		case end != i:
+			return 0, 0, fmt.Errorf("multiple stretchable ranges are not supported")
+		}
+		end = i + 1
+	}
+	if math.IsNaN(start) {
+		return 0, 0, fmt.Errorf("no stretchable range marked")
+	}
+	return start, end, nil
+}
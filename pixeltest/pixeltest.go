@@ -0,0 +1,186 @@
+// Package pixeltest provides golden image testing for drawing code of the Pixel game development
+// library.
+//
+// Drawing code is rendered offscreen using the software backend and compared with a PNG file stored
+// in the testdata directory of the tested package. Run the tests with the -update flag to create or
+// regenerate the golden files:
+//
+//	go test . -update
+package pixeltest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+var update = flag.Bool("update", false, "update golden image files")
+
+// Options configure how strictly a rendered picture is compared with its golden file.
+//
+// A pixel differs if any of its channels differs by more than Tolerance (in the 0-255 range). The
+// comparison fails if more than MaxDiffPixels pixels differ. The zero value requires an exact
+// match.
+type Options struct {
+	Tolerance     uint8
+	MaxDiffPixels int
+
+	// Dir is the directory of the golden files. If empty, "testdata" is used.
+	Dir string
+}
+
+// Render draws the output of the given function onto an offscreen canvas with the given bounds and
+// returns the result.
+func Render(bounds pixel.Rect, draw func(t pixel.Target)) *pixel.PictureData {
+	c := software.NewCanvas(bounds)
+	draw(c)
+	return c.PictureData()
+}
+
+// Compare compares two pictures pixel by pixel. It returns the number of pixels that differ by more
+// than the tolerance in any channel, and a diff picture where the differing pixels are red and the
+// others are faded out versions of the wanted picture.
+//
+// Pictures of different sizes are never equal; in that case the diff is nil and all the pixels of
+// the larger picture count as different.
+func Compare(got, want *pixel.PictureData, tolerance uint8) (diffPixels int, diff *pixel.PictureData) {
+	if got.Stride != want.Stride || len(got.Pix) != len(want.Pix) {
+		return max(len(got.Pix), len(want.Pix)), nil
+	}
+
+	diff = pixel.MakePictureData(want.Rect)
+	for i := range want.Pix {
+		g, w := got.Pix[i], want.Pix[i]
+		if absDiff(g.R, w.R) > tolerance || absDiff(g.G, w.G) > tolerance ||
+			absDiff(g.B, w.B) > tolerance || absDiff(g.A, w.A) > tolerance {
+			diffPixels++
+			diff.Pix[i] = color.RGBA{R: 255, A: 255}
+			continue
+		}
+		diff.Pix[i] = color.RGBA{R: w.R / 4, G: w.G / 4, B: w.B / 4, A: w.A / 4}
+	}
+	return diffPixels, diff
+}
+
+// AssertGolden renders the drawing function like Render and checks the result against the golden
+// file named name+".png". See Golden for details.
+func AssertGolden(t testing.TB, name string, bounds pixel.Rect, draw func(t pixel.Target), opts Options) {
+	t.Helper()
+	Golden(t, name, Render(bounds, draw), opts)
+}
+
+// Golden checks the picture against the golden file named name+".png".
+//
+// If the test binary is run with the -update flag, the golden file is (re)written instead. On
+// mismatch, the rendered picture and a diff picture are written next to the golden file with the
+// ".got.png" and ".diff.png" suffixes and the test fails.
+func Golden(t testing.TB, name string, got *pixel.PictureData, opts Options) {
+	t.Helper()
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "testdata"
+	}
+	path := filepath.Join(dir, name+".png")
+	gotPath := filepath.Join(dir, name+".got.png")
+	diffPath := filepath.Join(dir, name+".diff.png")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := WritePNG(path, got); err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(gotPath)
+		os.Remove(diffPath)
+		return
+	}
+
+	want, err := ReadPNG(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to create the golden file)", err)
+	}
+
+	// PNG stores non-premultiplied colors, so semi-transparent pixels may not survive the round trip
+	// exactly; compare what the golden file would contain instead
+	if got, err = encoded(got); err != nil {
+		t.Fatal(err)
+	}
+
+	diffPixels, diff := Compare(got, want, opts.Tolerance)
+	if diffPixels <= opts.MaxDiffPixels {
+		os.Remove(gotPath)
+		os.Remove(diffPath)
+		return
+	}
+
+	if err := WritePNG(gotPath, got); err != nil {
+		t.Error(err)
+	}
+	if diff == nil {
+		t.Fatalf("%s: size %v differs from golden size %v, got written to %s",
+			name, got.Bounds().Size(), want.Bounds().Size(), gotPath)
+	}
+	if err := WritePNG(diffPath, diff); err != nil {
+		t.Error(err)
+	}
+	t.Fatalf("%s: %d pixels differ from golden (at most %d allowed), see %s",
+		name, diffPixels, opts.MaxDiffPixels, diffPath)
+}
+
+// ReadPNG loads a PNG file into a PictureData.
+func ReadPNG(path string) (*pixel.PictureData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return pixel.PictureDataFromImage(img), nil
+}
+
+func encoded(pd *pixel.PictureData) (*pixel.PictureData, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, pd.Image()); err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return pixel.PictureDataFromImage(img), nil
+}
+
+// WritePNG saves a PictureData into a PNG file.
+func WritePNG(path string, pd *pixel.PictureData) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, pd.Image()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,69 @@
+package pixeltest_test
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+func TestCompare(t *testing.T) {
+	want := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	got := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	got.Pix[0] = color.RGBA{R: 2}
+	got.Pix[5] = color.RGBA{G: 10}
+
+	tests := []struct {
+		tolerance uint8
+		want      int
+	}{
+		{0, 2},
+		{2, 1},
+		{10, 0},
+	}
+	for _, tt := range tests {
+		n, diff := pixeltest.Compare(got, want, tt.tolerance)
+		if n != tt.want {
+			t.Errorf("Compare(tolerance=%d) = %d, want %d", tt.tolerance, n, tt.want)
+		}
+		if diff == nil || diff.Rect != want.Rect {
+			t.Errorf("Compare(tolerance=%d) returned diff %v", tt.tolerance, diff)
+		}
+	}
+
+	if n, diff := pixeltest.Compare(pixel.MakePictureData(pixel.R(0, 0, 2, 2)), want, 255); n != 16 || diff != nil {
+		t.Errorf("Compare with different sizes = %d, %v; want 16, nil", n, diff)
+	}
+}
+
+func TestPNGRoundTrip(t *testing.T) {
+	pd := pixeltest.Render(pixel.R(0, 0, 16, 16), drawTriangle)
+
+	path := filepath.Join(t.TempDir(), "green.png")
+	if err := pixeltest.WritePNG(path, pd); err != nil {
+		t.Fatal(err)
+	}
+	got, err := pixeltest.ReadPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _ := pixeltest.Compare(got, pd, 0); n != 0 {
+		t.Errorf("%d pixels differ after PNG round trip", n)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	pixeltest.AssertGolden(t, "triangle", pixel.R(0, 0, 16, 16), drawTriangle, pixeltest.Options{})
+}
+
+func drawTriangle(t pixel.Target) {
+	tri := pixel.TrianglesData{
+		{Position: pixel.V(2, 2), Color: pixel.RGB(1, 0, 0)},
+		{Position: pixel.V(14, 2), Color: pixel.RGB(0, 1, 0)},
+		{Position: pixel.V(8, 14), Color: pixel.RGB(0, 0, 1)},
+	}
+	t.MakeTriangles(&tri).Draw()
+}
@@ -0,0 +1,257 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Polygon is a 2D polygon defined by its vertices. Consecutive vertices are connected by edges and
+// the last vertex is connected to the first one, so the first vertex should not be repeated at the
+// end.
+//
+// Vertices can be in counter-clockwise or clockwise order, see SignedArea and IsClockwise. Methods
+// which need an inside and an outside, such as Contains, treat self-intersecting polygons using the
+// even-odd rule.
+//
+//	p := pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(5, 10)}
+//	p.Area()                  // returns 50
+//	p.Contains(pixel.V(5, 5)) // returns true
+type Polygon []Vec
+
+// String returns the string representation of the Polygon.
+//
+//	p := pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(5, 10)}
+//	p.String()     // returns "Polygon(Vec(0, 0), Vec(10, 0), Vec(5, 10))"
+//	fmt.Println(p) // Polygon(Vec(0, 0), Vec(10, 0), Vec(5, 10))
+func (p Polygon) String() string {
+	vertices := make([]string, len(p))
+	for i, v := range p {
+		vertices[i] = v.String()
+	}
+	return fmt.Sprintf("Polygon(%s)", strings.Join(vertices, ", "))
+}
+
+// SignedArea returns the area of the Polygon, positive if its vertices are in counter-clockwise
+// order and negative if they are in clockwise order. Polygons with less than three vertices have
+// zero area.
+func (p Polygon) SignedArea() float64 {
+	if len(p) < 3 {
+		return 0
+	}
+	sum := 0.0
+	for i := range p {
+		sum += p[i].Cross(p[(i+1)%len(p)])
+	}
+	return sum / 2
+}
+
+// Area returns the area of the Polygon, regardless of the order of its vertices.
+func (p Polygon) Area() float64 {
+	return math.Abs(p.SignedArea())
+}
+
+// IsClockwise returns whether the vertices of the Polygon are in clockwise order, that is, whether
+// its signed area is negative.
+func (p Polygon) IsClockwise() bool {
+	return p.SignedArea() < 0
+}
+
+// Reversed returns a new Polygon with the vertices in the reverse order, turning a clockwise
+// Polygon into a counter-clockwise one and vice versa.
+func (p Polygon) Reversed() Polygon {
+	q := make(Polygon, len(p))
+	for i, v := range p {
+		q[len(p)-1-i] = v
+	}
+	return q
+}
+
+// Centroid returns the center of mass of the Polygon.
+//
+// For degenerate polygons with zero area, such as a line or a single point, the average of the
+// vertices is returned. An empty Polygon has a centroid of ZV.
+func (p Polygon) Centroid() Vec {
+	if len(p) == 0 {
+		return ZV
+	}
+
+	area := p.SignedArea()
+	if area == 0 {
+		sum := ZV
+		for _, v := range p {
+			sum = sum.Add(v)
+		}
+		return sum.Scaled(1 / float64(len(p)))
+	}
+
+	// translate to the first vertex to reduce the rounding errors of polygons far from the origin
+	origin := p[0]
+	c := ZV
+	for i := range p {
+		a, b := p[i].Sub(origin), p[(i+1)%len(p)].Sub(origin)
+		c = c.Add(a.Add(b).Scaled(a.Cross(b)))
+	}
+	return origin.Add(c.Scaled(1 / (6 * area)))
+}
+
+// Bounds returns the smallest Rect which contains all the vertices of the Polygon. An empty Polygon
+// has bounds of ZR.
+func (p Polygon) Bounds() Rect {
+	if len(p) == 0 {
+		return ZR
+	}
+	r := Rect{Min: p[0], Max: p[0]}
+	for _, v := range p[1:] {
+		r.Min.X = math.Min(r.Min.X, v.X)
+		r.Min.Y = math.Min(r.Min.Y, v.Y)
+		r.Max.X = math.Max(r.Max.X, v.X)
+		r.Max.Y = math.Max(r.Max.Y, v.Y)
+	}
+	return r
+}
+
+// Contains checks whether a vector u is contained within this Polygon, including its edges.
+func (p Polygon) Contains(u Vec) bool {
+	if len(p) == 0 {
+		return false
+	}
+
+	inside := false
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		if L(a, b).Contains(u) {
+			return true
+		}
+		// count the edges crossing a horizontal ray going from u to the right
+		if (a.Y > u.Y) != (b.Y > u.Y) {
+			x := a.X + (u.Y-a.Y)/(b.Y-a.Y)*(b.X-a.X)
+			if u.X < x {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// IsConvex returns whether the Polygon is convex, meaning that every vertex turns in the same
+// direction and the Polygon does not intersect itself. Collinear vertices are allowed.
+//
+// Polygons with less than three vertices or zero area are not convex.
+func (p Polygon) IsConvex() bool {
+	if len(p) < 3 || p.SignedArea() == 0 {
+		return false
+	}
+
+	sign := 0.0
+	turning := 0.0
+	for i := range p {
+		a, b, c := p[i], p[(i+1)%len(p)], p[(i+2)%len(p)]
+		ab, bc := a.To(b), b.To(c)
+		if ab == ZV || bc == ZV {
+			continue
+		}
+
+		cross := ab.Cross(bc)
+		if cross != 0 {
+			if sign != 0 && math.Signbit(cross) != math.Signbit(sign) {
+				return false
+			}
+			sign = cross
+		}
+		turning += math.Atan2(cross, ab.Dot(bc))
+	}
+
+	// a simple convex polygon turns around exactly once, a self-intersecting one (like a pentagram)
+	// turns multiple times
+	return math.Abs(math.Abs(turning)-2*math.Pi) < 1e-6
+}
+
+// Edges returns the lines which make up the edges of the Polygon, in order. The last edge connects
+// the last vertex with the first one.
+func (p Polygon) Edges() []Line {
+	if len(p) < 2 {
+		return []Line{}
+	}
+	edges := make([]Line, len(p))
+	for i := range p {
+		edges[i] = L(p[i], p[(i+1)%len(p)])
+	}
+	return edges
+}
+
+// Moved returns the Polygon moved by the given vector delta.
+func (p Polygon) Moved(delta Vec) Polygon {
+	q := make(Polygon, len(p))
+	for i, v := range p {
+		q[i] = v.Add(delta)
+	}
+	return q
+}
+
+// Transformed returns the Polygon with all of its vertices projected by the given Matrix.
+//
+// A Matrix which mirrors the Polygon (has a negative determinant) reverses its winding order.
+func (p Polygon) Transformed(m Matrix) Polygon {
+	q := make(Polygon, len(p))
+	for i, v := range p {
+		q[i] = m.Project(v)
+	}
+	return q
+}
+
+// IntersectionPoints returns all the points where the Polygon's edges intersect with the line
+// provided. The points of intersection will be returned in order of closest-to-l.A to
+// closest-to-l.B. A point where the line crosses a vertex is only returned once.
+//
+// Edges parallel to the line are not intersected, the same way as for Line.Intersect.
+func (p Polygon) IntersectionPoints(l Line) []Vec {
+	points := []Vec{}
+	for _, edge := range p.Edges() {
+		if intersect, ok := l.Intersect(edge); ok {
+			points = appendUnique(points, intersect)
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return l.A.To(points[i]).SqLen() < l.A.To(points[j]).SqLen()
+	})
+	return points
+}
+
+// IntersectionPointsRect returns all the points where the Polygon's edges intersect with the edges
+// of the Rect provided. The points are returned in the order they are found walking along the
+// edges of the Polygon, starting from its first vertex.
+func (p Polygon) IntersectionPointsRect(r Rect) []Vec {
+	points := []Vec{}
+	for _, edge := range p.Edges() {
+		for _, point := range r.IntersectionPoints(edge) {
+			points = appendUnique(points, point)
+		}
+	}
+	return points
+}
+
+// IntersectionPointsCircle returns all the points where the Polygon's edges intersect with the
+// circumference of the Circle provided. The points are returned in the order they are found walking
+// along the edges of the Polygon, starting from its first vertex.
+func (p Polygon) IntersectionPointsCircle(c Circle) []Vec {
+	points := []Vec{}
+	for _, edge := range p.Edges() {
+		for _, point := range c.IntersectionPoints(edge) {
+			points = appendUnique(points, point)
+		}
+	}
+	return points
+}
+
+// appendUnique appends u to points, unless it (nearly) equals a point already in there.
+func appendUnique(points []Vec, u Vec) []Vec {
+	for _, v := range points {
+		if v.Eq(u) {
+			return points
+		}
+	}
+	return append(points, u)
+}
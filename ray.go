@@ -0,0 +1,308 @@
+package pixel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Ray is a half-line starting at the Origin and going in the Direction, which doesn't need to be a
+// unit vector. If MaxLen is positive, the Ray ends after the distance of MaxLen from the Origin.
+//
+//	ray := pixel.Ray{Origin: player.Pos, Direction: aim, MaxLen: 500}
+//	if hit, ok := ray.CastRect(wall); ok {
+//		fmt.Println(hit.Point, hit.Normal, hit.Dist)
+//	}
+//
+// If the Ray starts inside of a shape, it hits the shape right at the Origin, with the Normal
+// pointing against the Direction.
+type Ray struct {
+	Origin    Vec
+	Direction Vec
+	MaxLen    float64
+}
+
+// RayHit describes where a Ray hit a shape.
+type RayHit struct {
+	// Point is where the Ray hit the shape.
+	Point Vec
+	// Normal is the unit normal of the surface of the shape at the Point, facing the Ray.
+	Normal Vec
+	// Dist is the distance of the Point from the Origin of the Ray.
+	Dist float64
+	// Fraction is the position of the Point along the Direction of the Ray, such that
+	// Point = Origin + Direction * Fraction.
+	Fraction float64
+}
+
+// RayCaster is a shape which can be hit by a Ray. Line, Rect, Circle and Polygon are RayCasters.
+type RayCaster interface {
+	// CastRay returns the first point where the Ray hits the shape, if it does.
+	CastRay(r Ray) (RayHit, bool)
+}
+
+// ShapeHit is a RayHit of one of the shapes passed to Ray.Cast or Ray.CastAll.
+type ShapeHit struct {
+	RayHit
+	// Index is the index of the hit shape in the shapes passed to the cast.
+	Index int
+}
+
+// String returns the string representation of the Ray.
+//
+//	r := pixel.Ray{Origin: pixel.V(1, 2), Direction: pixel.V(1, 0)}
+//	r.String() // returns "Ray(Vec(1, 2), Vec(1, 0), 0)"
+func (r Ray) String() string {
+	return fmt.Sprintf("Ray(%v, %v, %v)", r.Origin, r.Direction, r.MaxLen)
+}
+
+// At returns the point at the position t along the Direction of the Ray, that is
+// Origin + Direction * t.
+func (r Ray) At(t float64) Vec {
+	return r.Origin.Add(r.Direction.Scaled(t))
+}
+
+// maxFraction returns the largest fraction of the Direction the Ray reaches.
+func (r Ray) maxFraction() float64 {
+	if r.MaxLen > 0 {
+		return r.MaxLen / r.Direction.Len()
+	}
+	return math.Inf(+1)
+}
+
+// hit returns the RayHit at the fraction t of the Direction, if the Ray reaches that far.
+func (r Ray) hit(t float64, normal Vec) (RayHit, bool) {
+	if t < 0 || t > r.maxFraction() {
+		return RayHit{}, false
+	}
+	return RayHit{
+		Point:    r.At(t),
+		Normal:   normal,
+		Dist:     t * r.Direction.Len(),
+		Fraction: t,
+	}, true
+}
+
+// inside returns the RayHit of a Ray starting inside of a shape.
+func (r Ray) inside() (RayHit, bool) {
+	return RayHit{Point: r.Origin, Normal: r.Direction.Unit().Scaled(-1)}, true
+}
+
+// CastLine returns where the Ray hits the line segment, if it does. If the Ray runs along the
+// segment, it hits the end of the segment closer to the Origin.
+func (r Ray) CastLine(l Line) (RayHit, bool) {
+	d := r.Direction
+	if d == ZV {
+		return RayHit{}, false
+	}
+	e := l.A.To(l.B)
+	toA := r.Origin.To(l.A)
+
+	denom := d.Cross(e)
+	if denom == 0 {
+		if toA.Cross(d) != 0 {
+			// parallel
+			return RayHit{}, false
+		}
+		// collinear
+		ta, tb := toA.Dot(d)/d.SqLen(), r.Origin.To(l.B).Dot(d)/d.SqLen()
+		if math.Max(ta, tb) < 0 {
+			return RayHit{}, false
+		}
+		return r.hit(math.Max(0, math.Min(ta, tb)), d.Unit().Scaled(-1))
+	}
+
+	t := toA.Cross(e) / denom
+	u := toA.Cross(d) / denom
+	if u < 0 || u > 1 {
+		return RayHit{}, false
+	}
+	normal := e.Normal().Unit()
+	if normal.Dot(d) > 0 {
+		normal = normal.Scaled(-1)
+	}
+	return r.hit(t, normal)
+}
+
+// CastRect returns where the Ray hits the Rect, if it does.
+func (r Ray) CastRect(rect Rect) (RayHit, bool) {
+	rect = rect.Norm()
+	if r.Direction == ZV {
+		return RayHit{}, false
+	}
+	if rect.Contains(r.Origin) {
+		return r.inside()
+	}
+
+	tMin, tMax := math.Inf(-1), math.Inf(+1)
+	var normal Vec
+	slab := func(o, d, min, max float64, axis Vec) bool {
+		if d == 0 {
+			return o >= min && o <= max
+		}
+		t1, t2 := (min-o)/d, (max-o)/d
+		n := axis.Scaled(-1)
+		if t1 > t2 {
+			t1, t2 = t2, t1
+			n = axis
+		}
+		if t1 > tMin {
+			tMin, normal = t1, n
+		}
+		tMax = math.Min(tMax, t2)
+		return tMin <= tMax
+	}
+	if !slab(r.Origin.X, r.Direction.X, rect.Min.X, rect.Max.X, V(1, 0)) ||
+		!slab(r.Origin.Y, r.Direction.Y, rect.Min.Y, rect.Max.Y, V(0, 1)) {
+		return RayHit{}, false
+	}
+	return r.hit(tMin, normal)
+}
+
+// CastCircle returns where the Ray hits the Circle, if it does.
+func (r Ray) CastCircle(c Circle) (RayHit, bool) {
+	c = c.Norm()
+	d := r.Direction
+	if d == ZV {
+		return RayHit{}, false
+	}
+	if c.Contains(r.Origin) {
+		return r.inside()
+	}
+
+	toOrigin := c.Center.To(r.Origin)
+	a := d.SqLen()
+	b := toOrigin.Dot(d)
+	k := toOrigin.SqLen() - c.Radius*c.Radius
+	discriminant := b*b - a*k
+	if b > 0 || discriminant < 0 {
+		return RayHit{}, false
+	}
+	t := (-b - math.Sqrt(discriminant)) / a
+	return r.hit(t, c.Center.To(r.At(t)).Unit())
+}
+
+// CastPolygon returns where the Ray hits the Polygon, if it does.
+func (r Ray) CastPolygon(p Polygon) (RayHit, bool) {
+	if r.Direction == ZV || len(p) == 0 {
+		return RayHit{}, false
+	}
+	if len(p) >= 3 && p.Contains(r.Origin) {
+		return r.inside()
+	}
+
+	var best RayHit
+	found := false
+	for _, edge := range p.Edges() {
+		if hit, ok := r.CastLine(edge); ok && (!found || hit.Dist < best.Dist) {
+			best, found = hit, true
+		}
+	}
+	return best, found
+}
+
+// Cast returns where the Ray first hits any of the shapes and the index of the hit shape, if it
+// hits any.
+func (r Ray) Cast(shapes ...RayCaster) (ShapeHit, bool) {
+	var best ShapeHit
+	found := false
+	for i, shape := range shapes {
+		if hit, ok := shape.CastRay(r); ok && (!found || hit.Dist < best.Dist) {
+			best, found = ShapeHit{RayHit: hit, Index: i}, true
+		}
+	}
+	return best, found
+}
+
+// CastAll returns all the shapes the Ray hits, each with the first point where the Ray hits it,
+// ordered by the distance from the Origin.
+//
+//	for _, hit := range ray.CastAll(targets...) {
+//		// pierce through up to three targets
+//	}
+func (r Ray) CastAll(shapes ...RayCaster) []ShapeHit {
+	var hits []ShapeHit
+	for i, shape := range shapes {
+		if hit, ok := shape.CastRay(r); ok {
+			hits = append(hits, ShapeHit{RayHit: hit, Index: i})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Dist < hits[j].Dist
+	})
+	return hits
+}
+
+// CastRay returns where the Ray hits the line segment, see Ray.CastLine.
+func (l Line) CastRay(r Ray) (RayHit, bool) {
+	return r.CastLine(l)
+}
+
+// CastRay returns where the Ray hits the Rect, see Ray.CastRect.
+func (r Rect) CastRay(ray Ray) (RayHit, bool) {
+	return ray.CastRect(r)
+}
+
+// CastRay returns where the Ray hits the Circle, see Ray.CastCircle.
+func (c Circle) CastRay(r Ray) (RayHit, bool) {
+	return r.CastCircle(c)
+}
+
+// CastRay returns where the Ray hits the Polygon, see Ray.CastPolygon.
+func (p Polygon) CastRay(r Ray) (RayHit, bool) {
+	return r.CastPolygon(p)
+}
+
+// VisibilityPolygon returns the area visible from the origin, when the line segments block the
+// view. The view is limited by the bounds, which must contain the origin. The result is a
+// counter-clockwise Polygon, which can be used for 2D lighting or line-of-sight tests.
+//
+//	walls := []pixel.Line{...}
+//	light := pixel.VisibilityPolygon(lamp, walls, win.Bounds())
+//	imd.Push(light...)
+//	imd.Polygon(0)
+//
+// The time complexity is O(n^2) in the number of segments.
+func VisibilityPolygon(origin Vec, segments []Line, bounds Rect) Polygon {
+	bounds = bounds.Norm()
+	edges := bounds.Edges()
+	all := append(append([]Line{}, segments...), edges[:]...)
+
+	// cast rays at every end point of the segments and slightly to both sides of them, to see
+	// past the corners
+	const epsilon = 1e-6
+	var angles []float64
+	for _, l := range all {
+		for _, end := range [...]Vec{l.A, l.B} {
+			if end == origin {
+				continue
+			}
+			a := origin.To(end).Angle()
+			angles = append(angles, a-epsilon, a, a+epsilon)
+		}
+	}
+	sort.Float64s(angles)
+
+	var p Polygon
+	for _, a := range angles {
+		ray := Ray{Origin: origin, Direction: Unit(a)}
+		best := math.Inf(+1)
+		var point Vec
+		for _, l := range all {
+			if hit, ok := ray.CastLine(l); ok && hit.Dist < best {
+				best, point = hit.Dist, hit.Point
+			}
+		}
+		if math.IsInf(best, +1) {
+			continue
+		}
+		if len(p) > 0 && p[len(p)-1].Eq(point) {
+			continue
+		}
+		p = append(p, point)
+	}
+	if len(p) > 1 && p[0].Eq(p[len(p)-1]) {
+		p = p[:len(p)-1]
+	}
+	return p
+}
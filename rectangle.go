@@ -165,6 +165,15 @@ func (r Rect) Moved(delta Vec) Rect {
 	}
 }
 
+// Transformed returns the corners of the Rect, in the order of Polygon, projected by the Matrix. A
+// rotated or sheared Rect is no longer a Rect, use Bounds of the result to get the smallest Rect
+// containing it.
+//
+//	bounds := sprite.Frame().Transformed(m).Bounds()
+func (r Rect) Transformed(m Matrix) Polygon {
+	return r.Polygon().Transformed(m)
+}
+
 // Resized returns the Rect resized to the given size while keeping the position of the given
 // anchor.
 //
@@ -292,3 +301,9 @@ func (r Rect) Vertices() [4]Vec {
 		V(r.Max.X, r.Min.Y),
 	}
 }
+
+// Polygon returns the Rect as a counter-clockwise Polygon with four vertices, starting at Min.
+func (r Rect) Polygon() Polygon {
+	r = r.Norm()
+	return Polygon{r.Min, V(r.Max.X, r.Min.Y), r.Max, V(r.Min.X, r.Max.Y)}
+}
@@ -0,0 +1,389 @@
+package pixel
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// The operations on PictureData in this file and in filter.go work on the CPU and return a new
+// PictureData, leaving the original one unchanged. They treat the PictureData as a grid of whole
+// pixels, with the bottom-left pixel at the floored Min of its bounds.
+
+// ResampleFilter is a filter used to compute the colors of resized or rotated pictures.
+type ResampleFilter int
+
+// Here's the list of all available resample filters, from the fastest to the sharpest.
+const (
+	// ResampleNearest picks the nearest pixel, which keeps pixel art crisp.
+	ResampleNearest ResampleFilter = iota
+	// ResampleBilinear interpolates linearly between the nearest 2x2 pixels.
+	ResampleBilinear
+	// ResampleBicubic interpolates between the nearest 4x4 pixels with the Catmull-Rom spline.
+	ResampleBicubic
+	// ResampleLanczos uses the Lanczos filter with the nearest 6x6 pixels, which gives the
+	// sharpest results for photos, but may add halos around sharp edges.
+	ResampleLanczos
+)
+
+// String returns the name of the ResampleFilter.
+func (f ResampleFilter) String() string {
+	switch f {
+	case ResampleNearest:
+		return "ResampleNearest"
+	case ResampleBilinear:
+		return "ResampleBilinear"
+	case ResampleBicubic:
+		return "ResampleBicubic"
+	case ResampleLanczos:
+		return "ResampleLanczos"
+	}
+	return fmt.Sprintf("ResampleFilter(%d)", int(f))
+}
+
+// support returns the radius of the filter kernel.
+func (f ResampleFilter) support() float64 {
+	switch f {
+	case ResampleNearest:
+		return 0.5
+	case ResampleBilinear:
+		return 1
+	case ResampleBicubic:
+		return 2
+	case ResampleLanczos:
+		return 3
+	}
+	panic(fmt.Errorf("(%T).support: invalid ResampleFilter", f))
+}
+
+// weight returns the value of the filter kernel at the distance x.
+func (f ResampleFilter) weight(x float64) float64 {
+	x = math.Abs(x)
+	switch f {
+	case ResampleNearest:
+		if x < 0.5 {
+			return 1
+		}
+		return 0
+	case ResampleBilinear:
+		return math.Max(0, 1-x)
+	case ResampleBicubic:
+		// Catmull-Rom, that is a = -0.5
+		switch {
+		case x < 1:
+			return (1.5*x-2.5)*x*x + 1
+		case x < 2:
+			return ((-0.5*x+2.5)*x-4)*x + 2
+		}
+		return 0
+	case ResampleLanczos:
+		if x >= 3 {
+			return 0
+		}
+		return sinc(x) * sinc(x/3)
+	}
+	panic(fmt.Errorf("(%T).weight: invalid ResampleFilter", f))
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// Cropped returns the part of the PictureData within the Rect. The bounds of the result are the
+// intersection of the Rect and the bounds of the PictureData, extended to whole pixels.
+//
+//	frame := sheet.Cropped(pixel.R(32, 0, 64, 32))
+func (pd *PictureData) Cropped(r Rect) *PictureData {
+	x0, y0, w, h := pd.grid()
+	r = r.Norm().Intersect(R(float64(x0), float64(y0), float64(x0+w), float64(y0+h)))
+	cx0, cy0 := int(math.Floor(r.Min.X)), int(math.Floor(r.Min.Y))
+	cx1, cy1 := int(math.Ceil(r.Max.X)), int(math.Ceil(r.Max.Y))
+
+	cropped := makePictureDataGrid(cx0, cy0, cx1-cx0, cy1-cy0)
+	for y := cy0; y < cy1; y++ {
+		src := pd.Pix[(y-y0)*pd.Stride+cx0-x0:]
+		copy(cropped.Pix[(y-cy0)*cropped.Stride:(y-cy0+1)*cropped.Stride], src)
+	}
+	return cropped
+}
+
+// Resized returns the PictureData scaled to the size of w x h pixels using the filter. The bounds of
+// the result have the same Min as the bounds of the PictureData. An empty PictureData is resized to
+// transparent pixels.
+//
+// Resized panics if the size is negative.
+func (pd *PictureData) Resized(w, h int, filter ResampleFilter) *PictureData {
+	if w < 0 || h < 0 {
+		panic(fmt.Errorf("(%T).Resized: negative size %dx%d", pd, w, h))
+	}
+	x0, y0, sw, sh := pd.grid()
+	if sw == 0 || sh == 0 {
+		return makePictureDataGrid(x0, y0, w, h)
+	}
+	if filter == ResampleNearest {
+		resized := makePictureDataGrid(x0, y0, w, h)
+		for y := 0; y < h; y++ {
+			sy := min((2*y+1)*sh/(2*h), sh-1)
+			for x := 0; x < w; x++ {
+				sx := min((2*x+1)*sw/(2*w), sw-1)
+				resized.Pix[y*w+x] = pd.Pix[sy*pd.Stride+sx]
+			}
+		}
+		return resized
+	}
+
+	// resize separately in each direction
+	buf := bufferFromPictureData(pd)
+	if w != sw {
+		buf = buf.resampled(w, filter, true)
+	}
+	if h != sh {
+		buf = buf.resampled(h, filter, false)
+	}
+	return buf.pictureData(x0, y0)
+}
+
+// Rotated90 returns the PictureData rotated counter-clockwise by the given number of quarter turns.
+// Negative turns rotate clockwise. The bounds of the result have the same Min as the bounds of the
+// PictureData.
+func (pd *PictureData) Rotated90(turns int) *PictureData {
+	x0, y0, w, h := pd.grid()
+	turns = ((turns % 4) + 4) % 4
+
+	var rotated *PictureData
+	if turns%2 == 0 {
+		rotated = makePictureDataGrid(x0, y0, w, h)
+	} else {
+		rotated = makePictureDataGrid(x0, y0, h, w)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var rx, ry int
+			switch turns {
+			case 0:
+				rx, ry = x, y
+			case 1:
+				rx, ry = h-1-y, x
+			case 2:
+				rx, ry = w-1-x, h-1-y
+			case 3:
+				rx, ry = y, w-1-x
+			}
+			rotated.Pix[ry*rotated.Stride+rx] = pd.Pix[y*pd.Stride+x]
+		}
+	}
+	return rotated
+}
+
+// Rotated returns the PictureData rotated counter-clockwise by the angle (in radians) around its
+// center, using the filter. The result is big enough to contain the whole rotated picture, the
+// area around it is transparent. The bounds of the result have the same Min as the bounds of the
+// PictureData.
+//
+// Use Rotated90 to rotate by multiples of 90 degrees without any loss of quality.
+func (pd *PictureData) Rotated(angle float64, filter ResampleFilter) *PictureData {
+	x0, y0, sw, sh := pd.grid()
+	sin, cos := math.Sincos(angle)
+	// the epsilon keeps rounding errors from adding a pixel, e.g. for the angle of math.Pi/2
+	const epsilon = 1e-9
+	w := int(math.Ceil(math.Abs(float64(sw)*cos) + math.Abs(float64(sh)*sin) - epsilon))
+	h := int(math.Ceil(math.Abs(float64(sw)*sin) + math.Abs(float64(sh)*cos) - epsilon))
+
+	src := bufferFromPictureData(pd)
+	dst := makeBuffer(w, h)
+	support := filter.support()
+	sc := V(float64(sw), float64(sh)).Scaled(0.5)
+	dc := V(float64(w), float64(h)).Scaled(0.5)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// the center of the pixel rotated back into the source, in the coordinates of its
+			// pixel centers
+			d := V(float64(x)+0.5, float64(y)+0.5).Sub(dc)
+			sx := d.X*cos + d.Y*sin + sc.X - 0.5
+			sy := -d.X*sin + d.Y*cos + sc.Y - 0.5
+			if sx < -support || sy < -support || sx > float64(sw)-1+support || sy > float64(sh)-1+support {
+				continue
+			}
+			dst.pix[y*w+x] = src.sample(sx, sy, filter)
+		}
+	}
+	return dst.pictureData(x0, y0)
+}
+
+// FlippedX returns the PictureData mirrored horizontally, so that its left side becomes its right
+// side.
+func (pd *PictureData) FlippedX() *PictureData {
+	x0, y0, w, h := pd.grid()
+	flipped := makePictureDataGrid(x0, y0, w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			flipped.Pix[y*w+x] = pd.Pix[y*pd.Stride+w-1-x]
+		}
+	}
+	return flipped
+}
+
+// FlippedY returns the PictureData mirrored vertically, so that its bottom becomes its top.
+func (pd *PictureData) FlippedY() *PictureData {
+	x0, y0, w, h := pd.grid()
+	flipped := makePictureDataGrid(x0, y0, w, h)
+	for y := 0; y < h; y++ {
+		copy(flipped.Pix[y*w:(y+1)*w], pd.Pix[(h-1-y)*pd.Stride:])
+	}
+	return flipped
+}
+
+// grid returns the position of the bottom-left pixel of the PictureData and its size in pixels.
+func (pd *PictureData) grid() (x0, y0, w, h int) {
+	x0, y0 = int(math.Floor(pd.Rect.Min.X)), int(math.Floor(pd.Rect.Min.Y))
+	w = pd.Stride
+	if w > 0 {
+		h = len(pd.Pix) / w
+	}
+	return x0, y0, w, h
+}
+
+// makePictureDataGrid creates a zero-initialized PictureData of w x h pixels with the bottom-left
+// one at (x0, y0).
+func makePictureDataGrid(x0, y0, w, h int) *PictureData {
+	return MakePictureData(R(float64(x0), float64(y0), float64(x0+w), float64(y0+h)))
+}
+
+// buffer is a grid of pixels with float components, used for the computations on PictureData.
+type buffer struct {
+	w, h int
+	pix  []RGBA
+}
+
+func makeBuffer(w, h int) *buffer {
+	return &buffer{w: w, h: h, pix: make([]RGBA, w*h)}
+}
+
+func bufferFromPictureData(pd *PictureData) *buffer {
+	_, _, w, h := pd.grid()
+	b := makeBuffer(w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			b.pix[y*w+x] = ToRGBA(pd.Pix[y*pd.Stride+x])
+		}
+	}
+	return b
+}
+
+// pictureData converts the buffer into a PictureData with the bottom-left pixel at (x0, y0). The
+// components are clamped, so that the colors are valid alpha-premultiplied colors.
+func (b *buffer) pictureData(x0, y0 int) *PictureData {
+	pd := makePictureDataGrid(x0, y0, b.w, b.h)
+	for i, c := range b.pix {
+		pd.Pix[i] = toColorRGBA(c)
+	}
+	return pd
+}
+
+func (b *buffer) at(x, y int) RGBA {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return RGBA{}
+	}
+	return b.pix[y*b.w+x]
+}
+
+// sample returns the color at the position (x, y), in the coordinates of the pixel centers, using
+// the filter. The area outside of the buffer is transparent.
+func (b *buffer) sample(x, y float64, filter ResampleFilter) RGBA {
+	if filter == ResampleNearest {
+		return b.at(int(math.Floor(x+0.5)), int(math.Floor(y+0.5)))
+	}
+	support := filter.support()
+	var c RGBA
+	var total float64
+	for sy := int(math.Ceil(y - support)); float64(sy) <= y+support; sy++ {
+		wy := filter.weight(y - float64(sy))
+		if wy == 0 {
+			continue
+		}
+		for sx := int(math.Ceil(x - support)); float64(sx) <= x+support; sx++ {
+			wt := wy * filter.weight(x-float64(sx))
+			total += wt
+			if wt != 0 {
+				c = c.Add(b.at(sx, sy).Scaled(wt))
+			}
+		}
+	}
+	if total == 0 {
+		return RGBA{}
+	}
+	return c.Scaled(1 / total)
+}
+
+// resampled returns the buffer resized to n pixels in one direction, horizontally or vertically.
+// The pixels at the edges are extended, so the edges don't fade out.
+func (b *buffer) resampled(n int, filter ResampleFilter, horizontal bool) *buffer {
+	srcLen, other := b.h, b.w
+	if horizontal {
+		srcLen, other = b.w, b.h
+	}
+	var dst *buffer
+	if horizontal {
+		dst = makeBuffer(n, b.h)
+	} else {
+		dst = makeBuffer(b.w, n)
+	}
+	if srcLen == 0 || n == 0 {
+		return dst
+	}
+
+	// when shrinking, the kernel is stretched to cover all the source pixels
+	scale := float64(srcLen) / float64(n)
+	stretch := math.Max(1, scale)
+	support := filter.support() * stretch
+
+	weights := make([]float64, 0, int(2*support)+2)
+	for i := 0; i < n; i++ {
+		center := (float64(i)+0.5)*scale - 0.5
+		start := int(math.Ceil(center - support))
+		weights = weights[:0]
+		var total float64
+		for s := start; float64(s) <= center+support; s++ {
+			wt := filter.weight((center - float64(s)) / stretch)
+			weights = append(weights, wt)
+			total += wt
+		}
+		for j := 0; j < other; j++ {
+			var c RGBA
+			for k, wt := range weights {
+				s := min(max(start+k, 0), srcLen-1)
+				if horizontal {
+					c = c.Add(b.pix[j*b.w+s].Scaled(wt))
+				} else {
+					c = c.Add(b.pix[s*b.w+j].Scaled(wt))
+				}
+			}
+			c = c.Scaled(1 / total)
+			if horizontal {
+				dst.pix[j*dst.w+i] = c
+			} else {
+				dst.pix[i*dst.w+j] = c
+			}
+		}
+	}
+	return dst
+}
+
+// toColorRGBA converts the color to color.RGBA, clamping the components to valid
+// alpha-premultiplied values.
+func toColorRGBA(c RGBA) color.RGBA {
+	a := Clamp(c.A, 0, 1)
+	to8 := func(x float64) uint8 {
+		return uint8(math.Round(x * 0xff))
+	}
+	return color.RGBA{
+		R: to8(Clamp(c.R, 0, a)),
+		G: to8(Clamp(c.G, 0, a)),
+		B: to8(Clamp(c.B, 0, a)),
+		A: to8(a),
+	}
+}
@@ -0,0 +1,176 @@
+package pixel
+
+import "math"
+
+// Path is a sequence of Bezier curves, each starting where the previous one ends. It can be used
+// for motion paths or for drawing roads and rivers.
+//
+//	path := pixel.CatmullRom([]pixel.Vec{pixel.V(0, 0), pixel.V(100, 50), pixel.V(200, 0)}, false)
+//	pos := path.PointAtLen(speed * elapsed) // moves along the path at a constant speed
+type Path []Bezier
+
+// CatmullRom returns a Path of a uniform Catmull-Rom spline, which passes through all the points
+// smoothly. If closed is true, the Path continues from the last point back to the first one.
+// Fewer than two points make an empty Path.
+func CatmullRom(points []Vec, closed bool) Path {
+	n := len(points)
+	if n < 2 {
+		return nil
+	}
+	at := func(i int) Vec {
+		if closed {
+			return points[(i%n+n)%n]
+		}
+		return points[int(Clamp(float64(i), 0, float64(n-1)))]
+	}
+
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	path := make(Path, segments)
+	for i := range path {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+		path[i] = Bezier{
+			Start:       p1,
+			StartHandle: p1.Add(p0.To(p2).Scaled(1.0 / 6)),
+			EndHandle:   p2.Sub(p1.To(p3).Scaled(1.0 / 6)),
+			End:         p2,
+		}
+	}
+	return path
+}
+
+// BSpline returns a Path of a uniform cubic B-spline with the points as its control points. The
+// spline doesn't pass through the points, it is pulled towards them, which makes it smoother than
+// CatmullRom. An open spline starts at the first point and ends at the last one. If closed is
+// true, the spline makes a loop around all the points. Fewer than two points make an empty Path.
+func BSpline(points []Vec, closed bool) Path {
+	n := len(points)
+	if n < 2 {
+		return nil
+	}
+	if !closed {
+		// repeating the end points three times makes the spline reach them
+		padded := []Vec{points[0], points[0]}
+		padded = append(padded, points...)
+		points = append(padded, points[n-1], points[n-1])
+	}
+	at := func(i int) Vec {
+		return points[i%len(points)]
+	}
+
+	segments := len(points) - 3
+	if closed {
+		segments = n
+	}
+	path := make(Path, segments)
+	for i := range path {
+		p0, p1, p2, p3 := at(i), at(i+1), at(i+2), at(i+3)
+		path[i] = Bezier{
+			Start:       p0.Add(p1.Scaled(4)).Add(p2).Scaled(1.0 / 6),
+			StartHandle: p1.Scaled(2).Add(p2).Scaled(1.0 / 3),
+			EndHandle:   p1.Add(p2.Scaled(2)).Scaled(1.0 / 3),
+			End:         p1.Add(p2.Scaled(4)).Add(p3).Scaled(1.0 / 6),
+		}
+	}
+	return path
+}
+
+// segment returns the curve of the Path at t in [0, 1], where each curve takes the same range of
+// t, and the t within that curve.
+func (p Path) segment(t float64) (Bezier, float64) {
+	t = Clamp(t, 0, 1) * float64(len(p))
+	i := math.Min(math.Floor(t), float64(len(p)-1))
+	return p[int(i)], t - i
+}
+
+// Point returns the point along the Path at t in [0, 1]. Each curve of the Path takes the same
+// range of t, regardless of its length. An empty Path returns ZV.
+func (p Path) Point(t float64) Vec {
+	if len(p) == 0 {
+		return ZV
+	}
+	b, t := p.segment(t)
+	return b.at(t)
+}
+
+// Tangent returns the unit direction of the Path at t in [0, 1], see Point and Bezier.Tangent.
+func (p Path) Tangent(t float64) Vec {
+	if len(p) == 0 {
+		return ZV
+	}
+	b, t := p.segment(t)
+	return b.Tangent(t)
+}
+
+// Len returns the total arc length of the Path.
+func (p Path) Len() float64 {
+	length := 0.0
+	for _, b := range p {
+		length += b.Len()
+	}
+	return length
+}
+
+// segmentAtLen returns the curve of the Path in the arc length from the start of the Path, and
+// the t within that curve.
+func (p Path) segmentAtLen(length float64) (Bezier, float64) {
+	for i, b := range p {
+		l := b.Len()
+		if length <= l || i == len(p)-1 {
+			return b, b.TAtLen(length)
+		}
+		length -= l
+	}
+	return Bezier{}, 0
+}
+
+// PointAtLen returns the point along the Path in the arc length from its start. The length is
+// clamped to the length of the Path. An empty Path returns ZV.
+//
+// Finding the point takes time proportional to the number of curves in the Path.
+func (p Path) PointAtLen(length float64) Vec {
+	if len(p) == 0 {
+		return ZV
+	}
+	b, t := p.segmentAtLen(length)
+	return b.at(t)
+}
+
+// TangentAtLen returns the unit direction of the Path in the arc length from its start, see
+// PointAtLen.
+func (p Path) TangentAtLen(length float64) Vec {
+	if len(p) == 0 {
+		return ZV
+	}
+	b, t := p.segmentAtLen(length)
+	return b.Tangent(t)
+}
+
+// Bounds returns the smallest Rect containing the whole Path. An empty Path has bounds of ZR.
+func (p Path) Bounds() Rect {
+	if len(p) == 0 {
+		return ZR
+	}
+	r := p[0].Bounds()
+	for _, b := range p[1:] {
+		r = r.Union(b.Bounds())
+	}
+	return r
+}
+
+// Flatten approximates the Path by a polyline, which doesn't stray further from the Path than the
+// tolerance, see Bezier.Flatten.
+func (p Path) Flatten(tolerance float64) []Vec {
+	var points []Vec
+	for i, b := range p {
+		flat := b.Flatten(tolerance)
+		if i > 0 {
+			// the first point is the end of the previous curve
+			flat = flat[1:]
+		}
+		points = append(points, flat...)
+	}
+	return points
+}
@@ -9,12 +9,15 @@ import "image/color"
 //
 //	sprite := pixel.NewSprite(pic, pic.Bounds())
 //
+// The Sprite is made of IndexedTrianglesData with four vertices, so a Batch with an
+// IndexedTrianglesData container stores each Sprite drawn onto it as four vertices instead of six.
+//
 // Note, that Sprite caches the results of MakePicture from Targets it's drawn to for each Picture
 // it's set to. What it means is that using a Sprite with an unbounded number of Pictures leads to a
 // memory leak, since Sprite caches them and never forgets. In such a situation, create a new Sprite
 // for each Picture.
 type Sprite struct {
-	tri   *TrianglesData
+	tri   *IndexedTrianglesData[uint16]
 	frame Rect
 	d     Drawer
 
@@ -24,7 +27,8 @@ type Sprite struct {
 
 // NewSprite creates a Sprite from the supplied frame of a Picture.
 func NewSprite(pic Picture, frame Rect) *Sprite {
-	tri := MakeTrianglesData(6)
+	tri := MakeIndexedTrianglesData[uint16](4, 6)
+	copy(tri.Indices, []uint16{0, 1, 2, 0, 2, 3})
 	s := &Sprite{
 		tri: tri,
 		d:   Drawer{Triangles: tri, Cached: true},
@@ -101,18 +105,17 @@ func (s *Sprite) calcData() {
 		vertical   = V(0, s.frame.H()/2)
 	)
 
-	(*s.tri)[0].Position = Vec{}.Sub(horizontal).Sub(vertical)
-	(*s.tri)[1].Position = Vec{}.Add(horizontal).Sub(vertical)
-	(*s.tri)[2].Position = Vec{}.Add(horizontal).Add(vertical)
-	(*s.tri)[3].Position = Vec{}.Sub(horizontal).Sub(vertical)
-	(*s.tri)[4].Position = Vec{}.Add(horizontal).Add(vertical)
-	(*s.tri)[5].Position = Vec{}.Sub(horizontal).Add(vertical)
-
-	for i := range *s.tri {
-		(*s.tri)[i].Color = s.mask
-		(*s.tri)[i].Picture = center.Add((*s.tri)[i].Position)
-		(*s.tri)[i].Intensity = 1
-		(*s.tri)[i].Position = s.matrix.Project((*s.tri)[i].Position)
+	vertices := *s.tri.VertexData
+	vertices[0].Position = Vec{}.Sub(horizontal).Sub(vertical)
+	vertices[1].Position = Vec{}.Add(horizontal).Sub(vertical)
+	vertices[2].Position = Vec{}.Add(horizontal).Add(vertical)
+	vertices[3].Position = Vec{}.Sub(horizontal).Add(vertical)
+
+	for i := range vertices {
+		vertices[i].Color = s.mask
+		vertices[i].Picture = center.Add(vertices[i].Position)
+		vertices[i].Intensity = 1
+		vertices[i].Position = s.matrix.Project(vertices[i].Position)
 	}
 
 	s.d.Dirty()
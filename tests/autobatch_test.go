@@ -0,0 +1,219 @@
+package pixel_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+// callTarget records the draw calls made onto it.
+type callTarget struct {
+	cmp   pixel.ComposeMethod
+	blend pixel.BlendMode
+	calls []drawCall
+}
+
+type drawCall struct {
+	pic   pixel.Picture
+	cmp   pixel.ComposeMethod
+	blend pixel.BlendMode
+	len   int
+}
+
+func (ct *callTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	ctt := &callTriangles{TrianglesData: pixel.MakeTrianglesData(t.Len()), dst: ct}
+	ctt.TrianglesData.Update(t)
+	return ctt
+}
+
+func (ct *callTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return &callPicture{Picture: p, dst: ct}
+}
+
+func (ct *callTarget) SetMatrix(pixel.Matrix)                 {}
+func (ct *callTarget) SetColorMask(color.Color)               {}
+func (ct *callTarget) SetComposeMethod(c pixel.ComposeMethod) { ct.cmp = c }
+func (ct *callTarget) SetBlendMode(b pixel.BlendMode)         { ct.blend = b }
+
+func (ct *callTarget) draw(pic pixel.Picture, t pixel.Triangles) {
+	ct.calls = append(ct.calls, drawCall{pic: pic, cmp: ct.cmp, blend: ct.blend, len: t.Len()})
+}
+
+type callTriangles struct {
+	*pixel.TrianglesData
+	dst *callTarget
+}
+
+func (ctt *callTriangles) Draw() {
+	ctt.dst.draw(nil, ctt)
+}
+
+type callPicture struct {
+	pixel.Picture
+	dst *callTarget
+}
+
+func (cp *callPicture) Draw(t pixel.TargetTriangles) {
+	cp.dst.draw(cp.Picture, t)
+}
+
+func TestAutoBatch_Flush(t *testing.T) {
+	a := pixel.MakePictureData(pixel.R(0, 0, 1, 1))
+	b := pixel.MakePictureData(pixel.R(0, 0, 2, 2))
+	quad := flatQuad(pixel.R(0, 0, 1, 1))
+
+	type draw struct {
+		pic pixel.Picture
+		z   float64
+		cmp pixel.ComposeMethod
+	}
+	tests := []struct {
+		name   string
+		sorted bool
+		draws  []draw
+		want   []drawCall
+	}{
+		{
+			name:  "consecutive",
+			draws: []draw{{pic: a}, {pic: a}, {pic: b}, {pic: b}, {pic: a}},
+			want:  []drawCall{{pic: a, len: 12}, {pic: b, len: 12}, {pic: a, len: 6}},
+		},
+		{
+			name:  "without picture",
+			draws: []draw{{}, {}, {pic: a}},
+			want:  []drawCall{{len: 12}, {pic: a, len: 6}},
+		},
+		{
+			name:  "compose method",
+			draws: []draw{{pic: a}, {pic: a, cmp: pixel.ComposePlus}, {pic: a}},
+			want: []drawCall{
+				{pic: a, len: 6},
+				{pic: a, cmp: pixel.ComposePlus, len: 6},
+				{pic: a, len: 6},
+			},
+		},
+		{
+			name:   "sorted same z",
+			sorted: true,
+			draws:  []draw{{pic: a}, {pic: b}, {pic: a}, {pic: b}, {pic: a}},
+			want:   []drawCall{{pic: a, len: 18}, {pic: b, len: 12}},
+		},
+		{
+			name:   "sorted by z",
+			sorted: true,
+			draws:  []draw{{pic: a, z: 2}, {pic: b, z: 1}, {pic: a, z: 0}},
+			want:   []drawCall{{pic: a, len: 6}, {pic: b, len: 6}, {pic: a, len: 6}},
+		},
+		{
+			name:   "sorted continues across z",
+			sorted: true,
+			draws: []draw{
+				{pic: a, z: 0}, {pic: b, z: 0},
+				{pic: a, z: 1}, {pic: b, z: 1},
+				{pic: b, z: 2}, {pic: a, z: 2},
+			},
+			// each z-order ends with the Picture the next one starts with
+			want: []drawCall{{pic: b, len: 6}, {pic: a, len: 12}, {pic: b, len: 12}, {pic: a, len: 6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &callTarget{}
+			ab := pixel.NewAutoBatch(target)
+			ab.SetSorted(tt.sorted)
+			d := pixel.Drawer{Triangles: quad}
+			for _, dr := range tt.draws {
+				ab.SetZ(dr.z)
+				ab.SetComposeMethod(dr.cmp)
+				d.Picture = dr.pic
+				d.Draw(ab)
+			}
+			ab.Flush()
+
+			if len(target.calls) != len(tt.want) || ab.DrawCalls() != len(tt.want) {
+				t.Fatalf("calls = %v (DrawCalls() = %d), want %v", target.calls, ab.DrawCalls(), tt.want)
+			}
+			for i := range tt.want {
+				if target.calls[i] != tt.want[i] {
+					t.Fatalf("calls = %v, want %v", target.calls, tt.want)
+				}
+			}
+			if target.cmp != pixel.ComposeOver || target.blend != pixel.BlendNormal {
+				t.Errorf("state after Flush = %v, %v, want ComposeOver, BlendNormal", target.cmp, target.blend)
+			}
+
+			// the draws are removed by Flush
+			target.calls = nil
+			ab.Flush()
+			if len(target.calls) != 0 {
+				t.Errorf("calls of the second Flush = %v, want none", target.calls)
+			}
+		})
+	}
+}
+
+func TestAutoBatch_software(t *testing.T) {
+	red := makePicture(0, 0, 2, 2, func(x, y int) color.RGBA { return color.RGBA{255, 0, 0, 255} })
+	blue := makePicture(0, 0, 2, 2, func(x, y int) color.RGBA { return color.RGBA{0, 0, 255, 255} })
+	redSprite, blueSprite := pixel.NewSprite(red, red.Bounds()), pixel.NewSprite(blue, blue.Bounds())
+
+	// the overlapping sprites are drawn in the order of their z
+	want := software.NewCanvas(pixel.R(0, 0, 8, 8))
+	blueSprite.Draw(want, pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(3, 3)))
+	redSprite.Draw(want, pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(5, 5)))
+	blueSprite.DrawColorMask(want, pixel.IM.Moved(pixel.V(6, 2)), pixel.Alpha(0.5))
+
+	got := software.NewCanvas(pixel.R(0, 0, 8, 8))
+	ab := pixel.NewAutoBatch(got)
+	ab.SetSorted(true)
+	ab.SetZ(1)
+	redSprite.Draw(ab, pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(5, 5)))
+	ab.SetZ(0)
+	blueSprite.Draw(ab, pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(3, 3)))
+	ab.SetZ(2)
+	ab.SetColorMask(pixel.Alpha(0.5))
+	blueSprite.Draw(ab, pixel.IM.Moved(pixel.V(6, 2)))
+	ab.Flush()
+
+	if ab.DrawCalls() != 3 {
+		t.Errorf("DrawCalls() = %d, want 3", ab.DrawCalls())
+	}
+	wantPix, gotPix := want.PictureData().Pix, got.PictureData().Pix
+	for i := range wantPix {
+		if gotPix[i] != wantPix[i] {
+			t.Fatalf("pixel %d = %v, want %v", i, gotPix[i], wantPix[i])
+		}
+	}
+}
+
+func BenchmarkAutoBatch_Flush(b *testing.B) {
+	pics := []pixel.Picture{
+		pixel.MakePictureData(pixel.R(0, 0, 1, 1)),
+		pixel.MakePictureData(pixel.R(0, 0, 1, 1)),
+		pixel.MakePictureData(pixel.R(0, 0, 1, 1)),
+	}
+	sprites := make([]*pixel.Sprite, len(pics))
+	for i, pic := range pics {
+		sprites[i] = pixel.NewSprite(pic, pic.Bounds())
+	}
+
+	for _, sorted := range []bool{false, true} {
+		name := map[bool]string{false: "unsorted", true: "sorted"}[sorted]
+		b.Run(name, func(b *testing.B) {
+			target := &callTarget{}
+			ab := pixel.NewAutoBatch(target)
+			ab.SetSorted(sorted)
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < 1000; j++ {
+					ab.SetZ(float64(j % 4))
+					sprites[j%len(sprites)].Draw(ab, pixel.IM.Moved(pixel.V(float64(j), 0)))
+				}
+				target.calls = target.calls[:0]
+				ab.Flush()
+			}
+			b.ReportMetric(float64(ab.DrawCalls()), "calls/flush")
+		})
+	}
+}
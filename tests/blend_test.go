@@ -0,0 +1,72 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+var blendModes = []pixel.BlendMode{
+	pixel.BlendNormal,
+	pixel.BlendMultiply,
+	pixel.BlendScreen,
+	pixel.BlendOverlay,
+	pixel.BlendDarken,
+	pixel.BlendLighten,
+	pixel.BlendColorDodge,
+	pixel.BlendColorBurn,
+	pixel.BlendDifference,
+	pixel.BlendAdditive,
+}
+
+func TestBlendMode_Compose(t *testing.T) {
+	fg := pixel.RGB(0.5, 1, 0.2)
+	bg := pixel.RGB(0.4, 0.5, 1)
+
+	tests := []struct {
+		mode pixel.BlendMode
+		want pixel.RGBA
+	}{
+		{mode: pixel.BlendNormal, want: fg},
+		{mode: pixel.BlendMultiply, want: pixel.RGB(0.2, 0.5, 0.2)},
+		{mode: pixel.BlendScreen, want: pixel.RGB(0.7, 1, 1)},
+		{mode: pixel.BlendOverlay, want: pixel.RGB(0.4, 1, 1)},
+		{mode: pixel.BlendDarken, want: pixel.RGB(0.4, 0.5, 0.2)},
+		{mode: pixel.BlendLighten, want: pixel.RGB(0.5, 1, 1)},
+		{mode: pixel.BlendColorDodge, want: pixel.RGB(0.8, 1, 1)},
+		{mode: pixel.BlendColorBurn, want: pixel.RGB(0, 0.5, 1)},
+		{mode: pixel.BlendDifference, want: pixel.RGB(0.1, 0.5, 0.8)},
+		{mode: pixel.BlendAdditive, want: pixel.RGB(0.9, 1.5, 1.2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			if got := tt.mode.Compose(fg, bg); !colorEq(got, tt.want, 1e-9) {
+				t.Errorf("Compose() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlendMode_Compose_alpha(t *testing.T) {
+	fg := pixel.RGB(1, 0.5, 0).Mul(pixel.Alpha(0.6))
+	bg := pixel.RGB(0.2, 0.4, 1).Mul(pixel.Alpha(0.4))
+
+	for _, mode := range blendModes {
+		t.Run(mode.String(), func(t *testing.T) {
+			if got := mode.Compose(pixel.RGBA{}, bg); !colorEq(got, bg, 1e-9) {
+				t.Errorf("Compose() of transparent foreground = %v, want %v", got, bg)
+			}
+			if got := mode.Compose(fg, pixel.RGBA{}); !colorEq(got, fg, 1e-9) {
+				t.Errorf("Compose() over transparent background = %v, want %v", got, fg)
+			}
+			over := pixel.ComposeOver.Compose(fg, bg)
+			if got := mode.Compose(fg, bg); got.A != over.A {
+				t.Errorf("Compose() alpha = %v, want %v", got.A, over.A)
+			}
+		})
+	}
+
+	if got, want := pixel.BlendNormal.Compose(fg, bg), pixel.ComposeOver.Compose(fg, bg); got != want {
+		t.Errorf("BlendNormal.Compose() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,261 @@
+package pixel_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestPolygonSet_Boolean(t *testing.T) {
+	set := func(outline pixel.Polygon, holes ...pixel.Polygon) pixel.PolygonSet {
+		return pixel.PolygonSet{{Outline: outline, Holes: holes}}
+	}
+	square := set(pixel.R(0, 0, 10, 10).Polygon())
+
+	type result struct {
+		area     float64
+		polygons int
+		holes    int
+	}
+	tests := []struct {
+		name                           string
+		a, b                           pixel.PolygonSet
+		union, intersect, diff, xorRes result
+	}{
+		{
+			name:      "overlapping",
+			a:         square,
+			b:         set(pixel.R(5, 5, 15, 15).Polygon()),
+			union:     result{175, 1, 0},
+			intersect: result{25, 1, 0},
+			diff:      result{75, 1, 0},
+			xorRes:    result{150, 2, 0},
+		},
+		{
+			name:      "disjoint",
+			a:         square,
+			b:         set(pixel.R(20, 0, 30, 10).Polygon()),
+			union:     result{200, 2, 0},
+			intersect: result{0, 0, 0},
+			diff:      result{100, 1, 0},
+			xorRes:    result{200, 2, 0},
+		},
+		{
+			name:      "contained",
+			a:         square,
+			b:         set(pixel.R(2, 2, 8, 8).Polygon()),
+			union:     result{100, 1, 0},
+			intersect: result{36, 1, 0},
+			diff:      result{64, 1, 1},
+			xorRes:    result{64, 1, 1},
+		},
+		{
+			name:      "shared edge",
+			a:         square,
+			b:         set(pixel.R(10, 0, 20, 10).Polygon()),
+			union:     result{200, 1, 0},
+			intersect: result{0, 0, 0},
+			diff:      result{100, 1, 0},
+			xorRes:    result{200, 1, 0},
+		},
+		{
+			name:      "touching corner",
+			a:         square,
+			b:         set(pixel.R(10, 10, 20, 20).Polygon()),
+			union:     result{200, 2, 0},
+			intersect: result{0, 0, 0},
+			diff:      result{100, 1, 0},
+			xorRes:    result{200, 2, 0},
+		},
+		{
+			name:      "identical",
+			a:         square,
+			b:         set(pixel.R(0, 0, 10, 10).Polygon().Reversed()),
+			union:     result{100, 1, 0},
+			intersect: result{100, 1, 0},
+			diff:      result{0, 0, 0},
+			xorRes:    result{0, 0, 0},
+		},
+		{
+			name:      "with hole",
+			a:         set(pixel.R(0, 0, 10, 10).Polygon(), pixel.R(4, 4, 6, 6).Polygon()),
+			b:         set(pixel.R(5, 0, 15, 10).Polygon()),
+			union:     result{148, 1, 1},
+			intersect: result{48, 1, 0},
+			diff:      result{48, 1, 0},
+			xorRes:    result{100, 3, 0},
+		},
+		{
+			name:      "cross",
+			a:         set(pixel.R(0, 4, 10, 6).Polygon()),
+			b:         set(pixel.R(4, 0, 6, 10).Polygon()),
+			union:     result{36, 1, 0},
+			intersect: result{4, 1, 0},
+			diff:      result{16, 2, 0},
+			xorRes:    result{32, 4, 0},
+		},
+		{
+			name:      "ring",
+			a:         square,
+			b:         set(pixel.R(-5, -5, 15, 15).Polygon(), pixel.R(2, 2, 8, 8).Polygon()),
+			union:     result{400, 1, 0},
+			intersect: result{64, 1, 1},
+			diff:      result{36, 1, 0},
+			xorRes:    result{336, 2, 1},
+		},
+	}
+
+	check := func(t *testing.T, op string, got pixel.PolygonSet, want result) {
+		t.Helper()
+		holes := 0
+		for _, cp := range got {
+			holes += len(cp.Holes)
+			if cp.Outline.IsClockwise() {
+				t.Errorf("%s: outline %v is clockwise", op, cp.Outline)
+			}
+			for _, hole := range cp.Holes {
+				if !hole.IsClockwise() {
+					t.Errorf("%s: hole %v is counter-clockwise", op, hole)
+				}
+			}
+		}
+		if math.Abs(got.Area()-want.area) > 1e-9 || len(got) != want.polygons || holes != want.holes {
+			t.Errorf("%s = %v (area %v, %d polygons, %d holes), want area %v, %d polygons, %d holes",
+				op, got, got.Area(), len(got), holes, want.area, want.polygons, want.holes)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check(t, "Union", tt.a.Union(tt.b), tt.union)
+			check(t, "Intersect", tt.a.Intersect(tt.b), tt.intersect)
+			check(t, "Difference", tt.a.Difference(tt.b), tt.diff)
+			check(t, "Xor", tt.a.Xor(tt.b), tt.xorRes)
+		})
+	}
+}
+
+func TestPolygonSet_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomSet := func() pixel.PolygonSet {
+		center := pixel.V(rnd.Float64()*10, rnd.Float64()*10)
+		switch rnd.Intn(3) {
+		case 0:
+			return pixel.PolygonSet{{Outline: star(3+rnd.Intn(6), 10, 3+rnd.Float64()*5).Moved(center)}}
+		case 1:
+			return pixel.PolygonSet{{Outline: pixel.C(center, 2+rnd.Float64()*8).Polygon(8 + rnd.Intn(24))}}
+		default:
+			return pixel.PolygonSet{{
+				Outline: pixel.R(-8, -8, 8, 8).Polygon().Transformed(pixel.IM.Rotated(pixel.ZV, rnd.Float64()).Moved(center)),
+				Holes:   []pixel.Polygon{pixel.C(center, 4).Polygon(6)},
+			}}
+		}
+	}
+
+	for i := 0; i < 200; i++ {
+		a, b := randomSet(), randomSet()
+		union, intersect, diff, xor := a.Union(b), a.Intersect(b), a.Difference(b), a.Xor(b)
+
+		eps := 1e-6
+		if d := union.Area() + intersect.Area() - a.Area() - b.Area(); math.Abs(d) > eps {
+			t.Errorf("case %d: union and intersection areas don't add up, difference %v", i, d)
+		}
+		if d := diff.Area() + intersect.Area() - a.Area(); math.Abs(d) > eps {
+			t.Errorf("case %d: difference and intersection areas don't add up, difference %v", i, d)
+		}
+		if d := xor.Area() + intersect.Area() - union.Area(); math.Abs(d) > eps {
+			t.Errorf("case %d: xor and intersection areas don't add up, difference %v", i, d)
+		}
+
+		for j := 0; j < 50; j++ {
+			u := pixel.V(rnd.Float64()*30-10, rnd.Float64()*30-10)
+			inA, inB := a.Contains(u), b.Contains(u)
+			if got := union.Contains(u); got != (inA || inB) {
+				t.Errorf("case %d: union contains %v = %v, want %v", i, u, got, inA || inB)
+			}
+			if got := intersect.Contains(u); got != (inA && inB) {
+				t.Errorf("case %d: intersection contains %v = %v, want %v", i, u, got, inA && inB)
+			}
+		}
+	}
+}
+
+func TestPolygonSet_TrianglesData(t *testing.T) {
+	a := pixel.PolygonSet{{Outline: pixel.R(0, 0, 10, 10).Polygon()}}
+	b := pixel.PolygonSet{{Outline: pixel.R(2, 2, 8, 8).Polygon()}, {Outline: pixel.R(20, 0, 30, 10).Polygon()}}
+
+	td := a.Xor(b).TrianglesData()
+	area := 0.0
+	for i := 0; i < td.Len(); i += 3 {
+		area += pixel.Polygon{(*td)[i].Position, (*td)[i+1].Position, (*td)[i+2].Position}.SignedArea()
+	}
+	if math.Abs(area-164) > 1e-9 {
+		t.Errorf("area of triangles = %v, want %v", area, 164)
+	}
+}
+
+func TestRect_Polygon(t *testing.T) {
+	p := pixel.R(10, 10, 0, 0).Polygon()
+	want := pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10), pixel.V(0, 10)}
+	if len(p) != len(want) {
+		t.Fatalf("Polygon() = %v, want %v", p, want)
+	}
+	for i := range p {
+		if p[i] != want[i] {
+			t.Fatalf("Polygon() = %v, want %v", p, want)
+		}
+	}
+}
+
+func TestCircle_Polygon(t *testing.T) {
+	c := pixel.C(pixel.V(5, 5), 2)
+	for _, n := range []int{3, 8, 64} {
+		p := c.Polygon(n)
+		if len(p) != n {
+			t.Errorf("Polygon(%d) has %d vertices", n, len(p))
+		}
+		if p.IsClockwise() {
+			t.Errorf("Polygon(%d) is clockwise", n)
+		}
+		for _, v := range p {
+			if math.Abs(c.Center.To(v).Len()-c.Radius) > 1e-9 {
+				t.Errorf("Polygon(%d) vertex %v doesn't lie on the circle", n, v)
+			}
+		}
+		want := float64(n) / 2 * c.Radius * c.Radius * math.Sin(2*math.Pi/float64(n))
+		if math.Abs(p.Area()-want) > 1e-9 {
+			t.Errorf("Polygon(%d).Area() = %v, want %v", n, p.Area(), want)
+		}
+	}
+	for _, n := range []int{-1, 0, 2} {
+		if p := c.Polygon(n); len(p) != 3 {
+			t.Errorf("Polygon(%d) has %d vertices, want 3", n, len(p))
+		}
+	}
+}
+
+func BenchmarkPolygonSet_Union(b *testing.B) {
+	for _, n := range []int{8, 32, 128} {
+		p := pixel.PolygonSet{{Outline: pixel.C(pixel.ZV, 10).Polygon(n)}}
+		q := pixel.PolygonSet{{Outline: pixel.C(pixel.V(5, 3), 10).Polygon(n)}}
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				p.Union(q)
+			}
+		})
+	}
+}
+
+func BenchmarkPolygonSet_Difference(b *testing.B) {
+	// carving holes along the edge of a large terrain, which gets more and more vertices
+	terrain := pixel.PolygonSet{{Outline: pixel.C(pixel.ZV, 1000).Polygon(2000)}}
+	rnd := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		at := pixel.Unit(rnd.Float64() * 2 * math.Pi).Scaled(900 + rnd.Float64()*100)
+		terrain = terrain.Difference(pixel.PolygonSet{{Outline: pixel.C(at, 20).Polygon(16)}})
+	}
+}
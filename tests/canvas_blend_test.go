@@ -0,0 +1,65 @@
+package pixel_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+)
+
+// TestCanvas_BlendMode draws with every blend mode onto an opengl Canvas and a software Canvas and
+// compares both with BlendMode.Compose.
+func TestCanvas_BlendMode(t *testing.T) {
+	win, err := opengl.NewWindow(opengl.WindowConfig{
+		Title:     "testing",
+		Bounds:    pixel.R(0, 0, 16, 16),
+		Invisible: true,
+	})
+	if err != nil {
+		t.Fatalf("Could not create window: %v", err)
+	}
+	defer win.Destroy()
+
+	src := pixel.RGB(1, 0.5, 0).Mul(pixel.Alpha(0.6))
+	// an 8-bit color, so that storing it in the Canvases doesn't change it
+	dst := pixel.RGBA{R: 40.0 / 255, G: 80.0 / 255, B: 204.0 / 255, A: 204.0 / 255}
+
+	imd := imdraw.New(nil)
+	imd.Color = src
+	imd.Push(pixel.V(0, 0), pixel.V(4, 4))
+	imd.Rectangle(0)
+
+	const tolerance = 2.0 / 255
+	for _, bm := range blendModes {
+		want := bm.Compose(src, dst)
+
+		gc := opengl.NewCanvas(pixel.R(0, 0, 4, 4))
+		gc.Clear(dst)
+		gc.SetBlendMode(bm)
+		imd.Draw(gc)
+
+		sc := software.NewCanvas(pixel.R(0, 0, 4, 4))
+		sc.Clear(dst)
+		sc.SetBlendMode(bm)
+		imd.Draw(sc)
+
+		for name, got := range map[string]pixel.RGBA{
+			"opengl":   gc.Color(pixel.V(2, 2)),
+			"software": sc.Color(pixel.V(2, 2)),
+		} {
+			// the framebuffer clamps the components
+			clamped := pixel.RGBA{
+				R: math.Min(want.R, 1),
+				G: math.Min(want.G, 1),
+				B: math.Min(want.B, 1),
+				A: math.Min(want.A, 1),
+			}
+			if !colorEq(got, clamped, tolerance) {
+				t.Errorf("%v on %s Canvas: Color() = %v, want %v", bm, name, got, clamped)
+			}
+		}
+	}
+}
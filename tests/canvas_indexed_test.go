@@ -0,0 +1,63 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+)
+
+// TestCanvas_indexed draws the same quads as flat and as indexed triangles onto opengl Canvases and
+// compares the results.
+func TestCanvas_indexed(t *testing.T) {
+	win, err := opengl.NewWindow(opengl.WindowConfig{
+		Title:     "testing",
+		Bounds:    pixel.R(0, 0, 16, 16),
+		Invisible: true,
+	})
+	if err != nil {
+		t.Fatalf("Could not create window: %v", err)
+	}
+	defer win.Destroy()
+
+	flat := flatQuad(pixel.R(0, 0, 4, 4))
+
+	// the uint16 indices are uploaded as they are, the uint32 ones too
+	containers := map[string]func() (pixel.Triangles, pixel.Triangles){
+		"uint16": func() (pixel.Triangles, pixel.Triangles) {
+			return pixel.MakeIndexedTrianglesData[uint16](0, 0), pixel.IndexTriangles[uint16](flat)
+		},
+		"uint32": func() (pixel.Triangles, pixel.Triangles) {
+			return pixel.MakeIndexedTrianglesData[uint32](0, 0), pixel.IndexTriangles[uint32](flat)
+		},
+	}
+	for name, makeContainer := range containers {
+		container, quad := makeContainer()
+		flatBatch, indexedBatch := pixel.NewBatch(&pixel.TrianglesData{}, nil), pixel.NewBatch(container, nil)
+		want, got := opengl.NewCanvas(pixel.R(0, 0, 16, 4)), opengl.NewCanvas(pixel.R(0, 0, 16, 4))
+
+		// the second frame grows the vertices and the indices of the GLTriangles made by the Batch
+		for frame, quads := range []int{2, 4} {
+			flatBatch.Clear()
+			indexedBatch.Clear()
+			for i := 0; i < quads; i++ {
+				m := pixel.IM.Moved(pixel.V(float64(4*i), 0))
+				flatBatch.SetMatrix(m)
+				flatBatch.MakeTriangles(flat).Draw()
+				indexedBatch.SetMatrix(m)
+				indexedBatch.MakeTriangles(quad).Draw()
+			}
+			want.Clear(pixel.Alpha(0))
+			got.Clear(pixel.Alpha(0))
+			flatBatch.Draw(want)
+			indexedBatch.Draw(got)
+
+			wantPix, gotPix := want.Pixels(), got.Pixels()
+			for i := range wantPix {
+				if gotPix[i] != wantPix[i] {
+					t.Fatalf("%s frame %d: indexed pixels differ from flat at byte %d", name, frame, i)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package pixel_test
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+// TestCanvas_sdfText draws text of a signed distance field Atlas with text.SDFFragmentShader and
+// compares the covered area with the text of a plain Atlas.
+func TestCanvas_sdfText(t *testing.T) {
+	win, err := opengl.NewWindow(opengl.WindowConfig{
+		Title:     "testing",
+		Bounds:    pixel.R(0, 0, 16, 16),
+		Invisible: true,
+	})
+	if err != nil {
+		t.Fatalf("Could not create window: %v", err)
+	}
+	defer win.Destroy()
+
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: 32, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdf, err := text.NewSDFAtlas(f, text.SDFOptions{Size: 32, MultiChannel: true}, text.ASCII)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// covered returns the number of pixels of the text mostly covered
+	covered := func(atlas *text.Atlas, effects *text.SDFEffects) int {
+		canvas := opengl.NewCanvas(pixel.R(0, 0, 256, 64))
+		if effects != nil {
+			canvas.SetSmooth(true)
+			effects.SetUniforms(canvas, atlas)
+			canvas.SetFragmentShader(text.SDFFragmentShader)
+		}
+		canvas.Clear(pixel.Alpha(0))
+		txt := text.New(pixel.V(8, 24), atlas)
+		fmt.Fprint(txt, "Hello, SDF!")
+		txt.Draw(canvas, pixel.IM)
+
+		n := 0
+		pix := canvas.Pixels()
+		for i := 3; i < len(pix); i += 4 {
+			if pix[i] >= 128 {
+				n++
+			}
+		}
+		return n
+	}
+
+	plain := covered(text.NewAtlas(face, text.ASCII), nil)
+	got := covered(sdf, &text.SDFEffects{})
+	if diff := got - plain; diff < -plain/10 || diff > plain/10 {
+		t.Errorf("SDF text covers %d pixels, plain text %d", got, plain)
+	}
+	outlined := covered(sdf, &text.SDFEffects{OutlineWidth: 2, OutlineColor: pixel.RGB(1, 0, 0)})
+	if outlined <= got {
+		t.Errorf("outlined SDF text covers %d pixels, plain SDF text %d", outlined, got)
+	}
+}
@@ -3,6 +3,7 @@ package pixel_test
 import (
 	"fmt"
 	"image/color"
+	"math"
 	"testing"
 
 	"github.com/gopxl/pixel/v2"
@@ -22,3 +23,221 @@ func BenchmarkColorToRGBA(b *testing.B) {
 		})
 	}
 }
+
+// colorEq returns whether the colors are equal up to the given tolerance.
+func colorEq(c, d pixel.RGBA, tolerance float64) bool {
+	return math.Abs(c.R-d.R) <= tolerance && math.Abs(c.G-d.G) <= tolerance &&
+		math.Abs(c.B-d.B) <= tolerance && math.Abs(c.A-d.A) <= tolerance
+}
+
+func TestRGBA_HSV(t *testing.T) {
+	tests := []struct {
+		color   pixel.RGBA
+		h, s, v float64
+	}{
+		{color: pixel.RGB(0, 0, 0), h: 0, s: 0, v: 0},
+		{color: pixel.RGB(1, 1, 1), h: 0, s: 0, v: 1},
+		{color: pixel.RGB(1, 0, 0), h: 0, s: 1, v: 1},
+		{color: pixel.RGB(0, 1, 0), h: 120, s: 1, v: 1},
+		{color: pixel.RGB(0, 0, 1), h: 240, s: 1, v: 1},
+		{color: pixel.RGB(1, 0, 1), h: 300, s: 1, v: 1},
+		{color: pixel.RGB(0.5, 0.25, 0), h: 30, s: 1, v: 0.5},
+		{color: pixel.RGB(0.75, 0.75, 0.25), h: 60, s: 2.0 / 3, v: 0.75},
+		{color: pixel.RGB(1, 0, 0).Mul(pixel.Alpha(0.5)), h: 0, s: 1, v: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.color.Hex(), func(t *testing.T) {
+			h, s, v := tt.color.HSV()
+			if math.Abs(h-tt.h) > 1e-9 || math.Abs(s-tt.s) > 1e-9 || math.Abs(v-tt.v) > 1e-9 {
+				t.Errorf("HSV() = %v, %v, %v, want %v, %v, %v", h, s, v, tt.h, tt.s, tt.v)
+			}
+			want := tt.color.Scaled(1 / tt.color.A)
+			if got := pixel.HSV(tt.h, tt.s, tt.v); !colorEq(got, want, 1e-9) {
+				t.Errorf("HSV(%v, %v, %v) = %v, want %v", tt.h, tt.s, tt.v, got, want)
+			}
+		})
+	}
+}
+
+func TestRGBA_HSL(t *testing.T) {
+	tests := []struct {
+		color   pixel.RGBA
+		h, s, l float64
+	}{
+		{color: pixel.RGB(0, 0, 0), h: 0, s: 0, l: 0},
+		{color: pixel.RGB(1, 1, 1), h: 0, s: 0, l: 1},
+		{color: pixel.RGB(0.5, 0.5, 0.5), h: 0, s: 0, l: 0.5},
+		{color: pixel.RGB(1, 0, 0), h: 0, s: 1, l: 0.5},
+		{color: pixel.RGB(0, 0.5, 0), h: 120, s: 1, l: 0.25},
+		{color: pixel.RGB(0.5, 0.5, 1), h: 240, s: 1, l: 0.75},
+		{color: pixel.RGB(0.75, 0.25, 0.5), h: 330, s: 0.5, l: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.color.Hex(), func(t *testing.T) {
+			h, s, l := tt.color.HSL()
+			if math.Abs(h-tt.h) > 1e-9 || math.Abs(s-tt.s) > 1e-9 || math.Abs(l-tt.l) > 1e-9 {
+				t.Errorf("HSL() = %v, %v, %v, want %v, %v, %v", h, s, l, tt.h, tt.s, tt.l)
+			}
+			if got := pixel.HSL(tt.h, tt.s, tt.l); !colorEq(got, tt.color, 1e-9) {
+				t.Errorf("HSL(%v, %v, %v) = %v, want %v", tt.h, tt.s, tt.l, got, tt.color)
+			}
+		})
+	}
+
+	if got, want := pixel.HSL(-240, 1, 0.5), pixel.RGB(0, 1, 0); !colorEq(got, want, 1e-9) {
+		t.Errorf("HSL(-240, 1, 0.5) = %v, want %v", got, want)
+	}
+}
+
+func TestRGBA_Lab(t *testing.T) {
+	// commonly published reference values, rounded
+	tests := []struct {
+		name  string
+		color pixel.RGBA
+		lab   [3]float64
+		oklab [3]float64
+	}{
+		{name: "black", color: pixel.RGB(0, 0, 0)},
+		{name: "white", color: pixel.RGB(1, 1, 1), lab: [3]float64{100, 0, 0}, oklab: [3]float64{1, 0, 0}},
+		{name: "red", color: pixel.RGB(1, 0, 0), lab: [3]float64{53.24, 80.09, 67.20}, oklab: [3]float64{0.6280, 0.2249, 0.1258}},
+		{name: "green", color: pixel.RGB(0, 1, 0), lab: [3]float64{87.73, -86.18, 83.18}, oklab: [3]float64{0.8664, -0.2339, 0.1795}},
+		{name: "blue", color: pixel.RGB(0, 0, 1), lab: [3]float64{32.30, 79.19, -107.86}, oklab: [3]float64{0.4520, -0.0325, -0.3115}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, a, b := tt.color.Lab()
+			if math.Abs(l-tt.lab[0]) > 0.01 || math.Abs(a-tt.lab[1]) > 0.01 || math.Abs(b-tt.lab[2]) > 0.01 {
+				t.Errorf("Lab() = %v, %v, %v, want %v", l, a, b, tt.lab)
+			}
+			if got := pixel.Lab(l, a, b); !colorEq(got, tt.color, 1e-5) {
+				t.Errorf("Lab(%v, %v, %v) = %v, want %v", l, a, b, got, tt.color)
+			}
+
+			l, a, b = tt.color.OKLab()
+			if math.Abs(l-tt.oklab[0]) > 1e-4 || math.Abs(a-tt.oklab[1]) > 1e-4 || math.Abs(b-tt.oklab[2]) > 1e-4 {
+				t.Errorf("OKLab() = %v, %v, %v, want %v", l, a, b, tt.oklab)
+			}
+			if got := pixel.OKLab(l, a, b); !colorEq(got, tt.color, 1e-6) {
+				t.Errorf("OKLab(%v, %v, %v) = %v, want %v", l, a, b, got, tt.color)
+			}
+		})
+	}
+
+	// out of gamut
+	if got := pixel.OKLab(1.5, 0, 0); !colorEq(got, pixel.RGB(1, 1, 1), 1e-9) {
+		t.Errorf("OKLab(1.5, 0, 0) = %v, want white", got)
+	}
+}
+
+func TestRGBA_Linear(t *testing.T) {
+	c := pixel.RGB(0.5, 0.04, 1).Mul(pixel.Alpha(0.5))
+	lin := c.Linear()
+	want := pixel.RGBA{R: 0.2140411 * 0.5, G: 0.04 / 12.92 * 0.5, B: 0.5, A: 0.5}
+	if !colorEq(lin, want, 1e-6) {
+		t.Errorf("Linear() = %v, want %v", lin, want)
+	}
+	if got := lin.SRGB(); !colorEq(got, c, 1e-9) {
+		t.Errorf("SRGB() = %v, want %v", got, c)
+	}
+}
+
+func TestRGBA_Lerp(t *testing.T) {
+	red, blue := pixel.RGB(1, 0, 0), pixel.RGB(0, 0, 1)
+	if got, want := red.Lerp(blue, 0.25), pixel.RGB(0.75, 0, 0.25); !colorEq(got, want, 1e-9) {
+		t.Errorf("Lerp() = %v, want %v", got, want)
+	}
+
+	for _, tt := range []float64{0, 1} {
+		want := red.Lerp(blue, tt)
+		if got := red.LerpOKLab(blue, tt); !colorEq(got, want, 1e-6) {
+			t.Errorf("LerpOKLab(%v) = %v, want %v", tt, got, want)
+		}
+	}
+
+	// the lightness changes evenly
+	black, white := pixel.RGB(0, 0, 0), pixel.RGB(1, 1, 1)
+	if l, _, _ := black.LerpOKLab(white, 0.5).OKLab(); math.Abs(l-0.5) > 1e-6 {
+		t.Errorf("LerpOKLab() lightness = %v, want 0.5", l)
+	}
+
+	// fading in keeps the hue
+	faded := pixel.RGBA{}.LerpOKLab(red, 0.5)
+	if want := red.Scaled(0.5); !colorEq(faded, want, 1e-9) {
+		t.Errorf("LerpOKLab() from transparent = %v, want %v", faded, want)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	half := pixel.Alpha(0.5)
+	tests := []struct {
+		s    string
+		want pixel.RGBA
+	}{
+		{s: "#f80", want: pixel.RGB(1, 0x88/255.0, 0)},
+		{s: "#F808", want: pixel.RGB(1, 0x88/255.0, 0).Mul(pixel.Alpha(0x88 / 255.0))},
+		{s: "#ff8800", want: pixel.RGB(1, 0x88/255.0, 0)},
+		{s: "  #ff880080 ", want: pixel.RGB(1, 0x88/255.0, 0).Mul(pixel.Alpha(0x80 / 255.0))},
+		{s: "rgb(255, 136, 0)", want: pixel.RGB(1, 136/255.0, 0)},
+		{s: "RGBA(100%, 50%, 0%, 0.5)", want: pixel.RGB(1, 0.5, 0).Mul(half)},
+		{s: "rgb(255 128 0 / 50%)", want: pixel.RGB(1, 128/255.0, 0).Mul(half)},
+		{s: "rgb(300, -5, 0)", want: pixel.RGB(1, 0, 0)},
+		{s: "hsl(120, 100%, 25%)", want: pixel.RGB(0, 0.5, 0)},
+		{s: "hsla(240deg, 100%, 50%, 0.5)", want: pixel.RGB(0, 0, 1).Mul(half)},
+		{s: "hsl(0.5turn 100% 50% / 0.5)", want: pixel.RGB(0, 1, 1).Mul(half)},
+		{s: "hsl(3.14159265358979rad, 100%, 50%)", want: pixel.RGB(0, 1, 1)},
+		{s: "hsl(200grad 100% 50%)", want: pixel.RGB(0, 1, 1)},
+		{s: "orange", want: pixel.RGB(1, 165/255.0, 0)},
+		{s: "DarkSlateGray", want: pixel.RGB(47/255.0, 79/255.0, 79/255.0)},
+		{s: "transparent", want: pixel.RGBA{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := pixel.ParseColor(tt.s)
+			if err != nil {
+				t.Fatalf("ParseColor() error = %v", err)
+			}
+			if !colorEq(got, tt.want, 1e-6) {
+				t.Errorf("ParseColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, s := range []string{
+		"", "#", "#12", "#12345", "#ggg", "ff8800", "rgb(1, 2)", "rgb(1, 2, 3", "rgb(1 2, 3)",
+		"rgb(1 2 / 3)", "hsl(a, 1%, 1%)", "cmyk(1, 2, 3, 4)", "notacolor", "rgb(nan, 0, 0)",
+	} {
+		if c, err := pixel.ParseColor(s); err == nil {
+			t.Errorf("ParseColor(%q) = %v, want error", s, c)
+		}
+	}
+}
+
+func TestRGBA_Hex(t *testing.T) {
+	tests := []struct {
+		color pixel.RGBA
+		want  string
+	}{
+		{color: pixel.RGB(1, 0.5, 0), want: "#ff8000"},
+		{color: pixel.RGB(1, 0.5, 0).Mul(pixel.Alpha(0.5)), want: "#ff800080"},
+		{color: pixel.RGB(2, -1, 0), want: "#ff0000"},
+		{color: pixel.RGBA{}, want: "#00000000"},
+	}
+	for _, tt := range tests {
+		if got := tt.color.Hex(); got != tt.want {
+			t.Errorf("Hex() of %v = %v, want %v", tt.color, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"#123456", "#12345678", "#ff000000"} {
+		c, err := pixel.ParseColor(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s == "#ff000000" {
+			s = "#00000000"
+		}
+		if got := c.Hex(); got != s {
+			t.Errorf("ParseColor(%q).Hex() = %v", s, got)
+		}
+	}
+}
@@ -16,3 +16,194 @@ func BenchmarkSpriteDrawBatch(b *testing.B) {
 		sprite.Draw(batch, pixel.IM)
 	}
 }
+
+// recordingTarget records the ranges of the Triangles updated by a Drawer.
+type recordingTarget struct {
+	tris    *recordingTriangles
+	updates [][2]int
+}
+
+func (rt *recordingTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	rtt := &recordingTriangles{TrianglesData: pixel.MakeTrianglesData(t.Len()), dst: rt}
+	rtt.TrianglesData.Update(t)
+	rt.tris = rtt
+	return rtt
+}
+
+func (rt *recordingTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	panic("not implemented")
+}
+
+type recordingTriangles struct {
+	*pixel.TrianglesData
+	offset int
+	dst    *recordingTarget
+}
+
+func (rtt *recordingTriangles) Slice(i, j int) pixel.Triangles {
+	return &recordingTriangles{
+		TrianglesData: rtt.TrianglesData.Slice(i, j).(*pixel.TrianglesData),
+		offset:        rtt.offset + i,
+		dst:           rtt.dst,
+	}
+}
+
+func (rtt *recordingTriangles) Update(t pixel.Triangles) {
+	rtt.TrianglesData.Update(t)
+	rtt.dst.updates = append(rtt.dst.updates, [2]int{rtt.offset, rtt.offset + rtt.Len()})
+}
+
+func (rtt *recordingTriangles) Draw() {}
+
+func TestDrawer_DirtyRange(t *testing.T) {
+	numbered := func(n int) *pixel.TrianglesData {
+		td := pixel.MakeTrianglesData(n)
+		for i := range *td {
+			(*td)[i].Position = pixel.V(float64(i), 0)
+		}
+		return td
+	}
+
+	tests := []struct {
+		name  string
+		len   int
+		dirty func(d *pixel.Drawer, td *pixel.TrianglesData)
+		want  [][2]int
+	}{
+		{
+			name: "single range",
+			len:  12,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				d.DirtyRange(3, 6)
+			},
+			want: [][2]int{{3, 6}},
+		},
+		{
+			name: "merged ranges",
+			len:  12,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				d.DirtyRange(6, 9)
+				d.DirtyRange(0, 3)
+			},
+			want: [][2]int{{0, 9}},
+		},
+		{
+			name: "range after dirty",
+			len:  12,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				d.Dirty()
+				d.DirtyRange(0, 3)
+			},
+			want: [][2]int{{0, 12}},
+		},
+		{
+			name: "dirty after range",
+			len:  12,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				d.DirtyRange(0, 3)
+				d.Dirty()
+			},
+			want: [][2]int{{0, 12}},
+		},
+		{
+			name: "grown",
+			len:  6,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				*td = *numbered(9)
+				d.DirtyRange(6, 9)
+			},
+			want: [][2]int{{6, 9}},
+		},
+		{
+			name: "shrunk",
+			len:  12,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				td.SetLen(6)
+				d.DirtyRange(3, 12)
+			},
+			want: [][2]int{{3, 6}},
+		},
+		{
+			name: "empty range",
+			len:  12,
+			dirty: func(d *pixel.Drawer, td *pixel.TrianglesData) {
+				d.DirtyRange(3, 3)
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := numbered(tt.len)
+			d := pixel.Drawer{Triangles: td}
+			target := &recordingTarget{}
+			d.Draw(target)
+
+			for i := range *td {
+				(*td)[i].Position = pixel.V(float64(i), 1)
+			}
+			tt.dirty(&d, td)
+			d.Draw(target)
+
+			if len(target.updates) != len(tt.want) {
+				t.Fatalf("updates = %v, want %v", target.updates, tt.want)
+			}
+			for i := range tt.want {
+				if target.updates[i] != tt.want[i] {
+					t.Fatalf("updates = %v, want %v", target.updates, tt.want)
+				}
+			}
+			// only the updated vertices reached the Target
+			for i := 0; i < td.Len(); i++ {
+				updated := false
+				for _, u := range tt.want {
+					updated = updated || (u[0] <= i && i < u[1])
+				}
+				got := target.tris.Position(i)
+				if updated && got != td.Position(i) {
+					t.Errorf("updated vertex %d = %v, want %v", i, got, td.Position(i))
+				}
+				if !updated && got.Y != 0 {
+					t.Errorf("vertex %d = %v was updated", i, got)
+				}
+			}
+
+			// nothing is updated without a change
+			target.updates = nil
+			d.Draw(target)
+			if len(target.updates) != 0 {
+				t.Errorf("updates without a change = %v, want none", target.updates)
+			}
+		})
+	}
+}
+
+func TestBatch_DirtyRange(t *testing.T) {
+	quad := flatQuad(pixel.R(0, 0, 1, 1))
+
+	tests := []struct {
+		name      string
+		container pixel.Triangles
+		want      [2]int
+	}{
+		{name: "flat", container: &pixel.TrianglesData{}, want: [2]int{12, 18}},
+		{name: "indexed", container: pixel.MakeIndexedTrianglesData[uint16](0, 0), want: [2]int{0, 18}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batch := pixel.NewBatch(tt.container, nil)
+			quad := batch.MakeTriangles(quad)
+			quad.Draw()
+			quad.Draw()
+			target := &recordingTarget{}
+			batch.Draw(target)
+
+			// only the added quad is updated
+			quad.Draw()
+			batch.Draw(target)
+			if len(target.updates) != 1 || target.updates[0] != tt.want {
+				t.Errorf("updates = %v, want %v", target.updates, [][2]int{tt.want})
+			}
+		})
+	}
+}
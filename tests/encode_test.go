@@ -0,0 +1,160 @@
+package pixel_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// quadrants returns a 4x4 picture with red bottom-left, green bottom-right, blue top-left and
+// transparent top-right quadrants.
+func quadrants() *pixel.PictureData {
+	return makePicture(0, 0, 4, 4, func(x, y int) color.RGBA {
+		switch {
+		case x < 2 && y < 2:
+			return color.RGBA{255, 0, 0, 255}
+		case y < 2:
+			return color.RGBA{0, 255, 0, 255}
+		case x < 2:
+			return color.RGBA{0, 0, 255, 255}
+		}
+		return color.RGBA{}
+	})
+}
+
+func TestPictureData_Encode(t *testing.T) {
+	pd := quadrants()
+
+	tests := []struct {
+		format    pixel.ImageFormat
+		decode    func(*bytes.Buffer) (image.Image, error)
+		tolerance uint32
+		alpha     bool
+	}{
+		{format: pixel.ImageFormatPNG, decode: func(b *bytes.Buffer) (image.Image, error) { return png.Decode(b) }, alpha: true},
+		{format: pixel.ImageFormatJPEG, decode: func(b *bytes.Buffer) (image.Image, error) { return jpeg.Decode(b) }, tolerance: 0x2000},
+		{format: pixel.ImageFormatGIF, decode: func(b *bytes.Buffer) (image.Image, error) { return gif.Decode(b) }, alpha: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := pd.Encode(&buf, tt.format); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			img, err := tt.decode(&buf)
+			if err != nil {
+				t.Fatalf("decoding error = %v", err)
+			}
+			if img.Bounds() != image.Rect(0, 0, 4, 4) {
+				t.Fatalf("bounds = %v", img.Bounds())
+			}
+			// the image is top-down, so the red quadrant is at the bottom-left
+			for _, p := range []struct {
+				x, y int
+				want color.RGBA
+			}{
+				{0, 3, color.RGBA{255, 0, 0, 255}},
+				{3, 3, color.RGBA{0, 255, 0, 255}},
+				{0, 0, color.RGBA{0, 0, 255, 255}},
+				{3, 0, color.RGBA{}},
+			} {
+				want := p.want
+				if !tt.alpha {
+					want.A = 255
+				}
+				r, g, b, a := img.At(p.x, p.y).RGBA()
+				wr, wg, wb, wa := want.RGBA()
+				if diff(r, wr) > tt.tolerance || diff(g, wg) > tt.tolerance || diff(b, wb) > tt.tolerance || a != wa {
+					t.Errorf("pixel (%d, %d) = %v, want %v", p.x, p.y, img.At(p.x, p.y), want)
+				}
+			}
+		})
+	}
+
+	if err := pd.Encode(&bytes.Buffer{}, pixel.ImageFormat(-1)); err == nil {
+		t.Errorf("Encode() with an invalid format: want error")
+	}
+}
+
+func diff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func TestPictureData_Save(t *testing.T) {
+	pd := quadrants()
+	path := filepath.Join(t.TempDir(), "quadrants.png")
+	if err := pd.Save(path, pixel.ImageFormatPNG); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := pixel.PictureDataFromFile(path, png.Decode)
+	if err != nil {
+		t.Fatalf("PictureDataFromFile() error = %v", err)
+	}
+	if loaded.Bounds() != pd.Bounds() {
+		t.Fatalf("loaded bounds = %v, want %v", loaded.Bounds(), pd.Bounds())
+	}
+	for i := range pd.Pix {
+		if loaded.Pix[i] != pd.Pix[i] {
+			t.Fatalf("loaded pixel %d = %v, want %v", i, loaded.Pix[i], pd.Pix[i])
+		}
+	}
+
+	if err := pd.Save(filepath.Join(t.TempDir(), "missing", "x.png"), pixel.ImageFormatPNG); err == nil {
+		t.Errorf("Save() into a missing directory: want error")
+	}
+}
+
+func TestPictureData_Paletted(t *testing.T) {
+	pd := quadrants()
+	img := pd.Paletted(4, false)
+	if len(img.Palette) != 4 {
+		t.Errorf("palette = %v, want 4 colors", img.Palette)
+	}
+	want := pd.Image()
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if got := color.RGBAModel.Convert(img.At(x, y)); got != want.At(x, y) {
+				t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want.At(x, y))
+			}
+		}
+	}
+
+	if got := pd.Paletted(1, true); len(got.Palette) != 1 {
+		t.Errorf("Paletted(1) palette = %v, want 1 color", got.Palette)
+	}
+	if got := pixel.MakePictureData(pixel.ZR).Paletted(16, false); got.Bounds() != image.Rect(0, 0, 0, 0) {
+		t.Errorf("empty Paletted() bounds = %v", got.Bounds())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Paletted(257) didn't panic")
+		}
+	}()
+	pd.Paletted(257, false)
+}
+
+func BenchmarkPictureData_Encode(b *testing.B) {
+	pd := makePicture(0, 0, 256, 256, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255}
+	})
+	for _, format := range []pixel.ImageFormat{pixel.ImageFormatPNG, pixel.ImageFormatJPEG, pixel.ImageFormatGIF} {
+		b.Run(format.String(), func(b *testing.B) {
+			var buf bytes.Buffer
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				pd.Encode(&buf, format)
+			}
+		})
+	}
+}
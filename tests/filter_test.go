@@ -0,0 +1,300 @@
+package pixel_test
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestPictureData_Blurred(t *testing.T) {
+	// a single opaque pixel in the middle of a transparent picture
+	dot := makePicture(0, 0, 21, 21, func(x, y int) color.RGBA {
+		if x == 10 && y == 10 {
+			return color.RGBA{255, 255, 255, 255}
+		}
+		return color.RGBA{}
+	})
+	uniform := makePicture(0, 0, 21, 21, func(x, y int) color.RGBA { return color.RGBA{50, 100, 150, 200} })
+
+	tests := []struct {
+		name string
+		blur func(*pixel.PictureData) *pixel.PictureData
+	}{
+		{name: "Gaussian", blur: func(pd *pixel.PictureData) *pixel.PictureData { return pd.Blurred(1.5) }},
+		{name: "box", blur: func(pd *pixel.PictureData) *pixel.PictureData { return pd.BoxBlurred(2) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blurred := tt.blur(dot)
+			if blurred.Bounds() != dot.Bounds() {
+				t.Fatalf("bounds = %v, want %v", blurred.Bounds(), dot.Bounds())
+			}
+			// the blur spreads the pixel, but keeps its total
+			var total float64
+			for _, c := range blurred.Pix {
+				total += float64(c.A) / 255
+			}
+			if math.Abs(total-1) > 0.05 {
+				t.Errorf("total alpha = %v, want 1", total)
+			}
+			center, next := blurred.Pix[10*21+10], blurred.Pix[10*21+11]
+			if center.A == 255 || center.A < next.A || next.A == 0 {
+				t.Errorf("center = %v, next = %v, want spread from the center", center, next)
+			}
+
+			// a uniform color stays the same away from the edges, but fades out at them
+			got := tt.blur(uniform)
+			if c := got.Pix[10*21+10]; c != uniform.Pix[0] {
+				t.Errorf("uniform center = %v, want %v", c, uniform.Pix[0])
+			}
+			if c := got.Pix[0]; c.A >= uniform.Pix[0].A {
+				t.Errorf("uniform corner = %v, want faded", c)
+			}
+		})
+	}
+
+	if got := dot.Blurred(0); got.Pix[10*21+10] != dot.Pix[10*21+10] {
+		t.Errorf("Blurred(0) changed the picture")
+	}
+}
+
+func TestPictureData_Sharpened(t *testing.T) {
+	// a step from dark to light gray
+	step := makePicture(0, 0, 10, 1, func(x, y int) color.RGBA {
+		if x < 5 {
+			return color.RGBA{64, 64, 64, 255}
+		}
+		return color.RGBA{192, 192, 192, 255}
+	})
+	got := step.Sharpened(1, 1)
+	if dark, light := got.Pix[4], got.Pix[5]; dark.R >= 64 || light.R <= 192 {
+		t.Errorf("Sharpened() edge = %v, %v, want increased contrast", dark, light)
+	}
+	if c := got.Pix[0]; c.R != 64 {
+		t.Errorf("Sharpened() away from the edge = %v, want unchanged", c)
+	}
+}
+
+func TestColorMatrix(t *testing.T) {
+	red := pixel.RGB(1, 0, 0)
+	halfRed := pixel.RGBA{R: 0.5, A: 0.5}
+
+	tests := []struct {
+		name   string
+		matrix pixel.ColorMatrix
+		color  pixel.RGBA
+		want   pixel.RGBA
+	}{
+		{name: "identity", matrix: pixel.IdentityColorMatrix, color: halfRed, want: halfRed},
+		{name: "grayscale", matrix: pixel.GrayscaleColorMatrix(1), color: red, want: pixel.RGB(0.2126, 0.2126, 0.2126)},
+		{name: "grayscale premultiplied", matrix: pixel.GrayscaleColorMatrix(1), color: halfRed, want: pixel.RGB(0.2126, 0.2126, 0.2126).Mul(pixel.Alpha(0.5))},
+		{name: "grayscale none", matrix: pixel.GrayscaleColorMatrix(0), color: red, want: red},
+		{name: "sepia none", matrix: pixel.SepiaColorMatrix(0), color: red, want: red},
+		{name: "sepia", matrix: pixel.SepiaColorMatrix(1), color: pixel.RGB(1, 1, 1), want: pixel.RGB(1, 1, 0.937)},
+		{name: "hue shift none", matrix: pixel.HueShiftColorMatrix(0), color: red, want: red},
+		{name: "hue shift full turn", matrix: pixel.HueShiftColorMatrix(2 * math.Pi), color: red, want: red},
+		{name: "hue shift gray", matrix: pixel.HueShiftColorMatrix(1), color: pixel.RGB(0.5, 0.5, 0.5), want: pixel.RGB(0.5, 0.5, 0.5)},
+		{name: "chained", matrix: pixel.GrayscaleColorMatrix(1).Chained(pixel.IdentityColorMatrix), color: red, want: pixel.RGB(0.2126, 0.2126, 0.2126)},
+		{name: "chained constants", matrix: pixel.ColorMatrix{
+			1, 0, 0, 0, 0.25,
+			0, 1, 0, 0, 0,
+			0, 0, 1, 0, 0,
+			0, 0, 0, 1, 0,
+		}.Chained(pixel.ColorMatrix{
+			2, 0, 0, 0, 0,
+			0, 1, 0, 0, 0,
+			0, 0, 1, 0, 0,
+			0, 0, 0, 1, 0,
+		}), color: pixel.RGB(0.1, 0, 0), want: pixel.RGB(0.7, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matrix.Apply(tt.color); !colorEq(got, tt.want, 1e-3) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// the hue shift moves red towards green
+	if c := pixel.HueShiftColorMatrix(2 * math.Pi / 3).Apply(red); c.G <= c.R || c.G <= c.B {
+		t.Errorf("HueShiftColorMatrix(120°).Apply(red) = %v, want green", c)
+	}
+
+	pd := numbered(4, 3).ColorTransformed(pixel.GrayscaleColorMatrix(1))
+	for i, c := range pd.Pix {
+		if c.R != c.G || c.G != c.B {
+			t.Errorf("ColorTransformed() pixel %d = %v, want gray", i, c)
+		}
+	}
+}
+
+func TestPictureData_Quantized(t *testing.T) {
+	// a horizontal gradient from black to white
+	gradient := makePicture(0, 0, 64, 8, func(x, y int) color.RGBA {
+		v := uint8(x * 4)
+		return color.RGBA{v, v, v, 255}
+	})
+
+	palette := gradient.Palette(4)
+	if len(palette) != 4 {
+		t.Fatalf("Palette() has %d colors, want 4", len(palette))
+	}
+	if got := gradient.Palette(1000); len(got) != 64 {
+		t.Errorf("Palette(1000) has %d colors, want the 64 distinct colors", len(got))
+	}
+
+	for _, dither := range []bool{false, true} {
+		got := gradient.Quantized(palette, dither)
+		if got.Bounds() != gradient.Bounds() {
+			t.Fatalf("Quantized() bounds = %v, want %v", got.Bounds(), gradient.Bounds())
+		}
+		// every pixel is from the palette and the average brightness is kept
+		var sum, want float64
+		for i, c := range got.Pix {
+			col := pixel.ToRGBA(c)
+			found := false
+			for _, p := range palette {
+				if colorEq(col, p, 1.0/255) {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("Quantized(dither=%v) pixel %d = %v, not in the palette %v", dither, i, c, palette)
+			}
+			sum += float64(c.R)
+			want += float64(gradient.Pix[i].R)
+		}
+		if math.Abs(sum-want)/float64(len(got.Pix)) > 8 {
+			t.Errorf("Quantized(dither=%v) average = %v, want %v", dither, sum/float64(len(got.Pix)), want/float64(len(got.Pix)))
+		}
+	}
+
+	// without dithering, neighbouring pixels of the same color are quantized the same way
+	plain := gradient.Quantized(palette, false)
+	for x := 0; x < 64; x++ {
+		if plain.Pix[x] != plain.Pix[64+x] {
+			t.Errorf("Quantized() column %d differs between rows", x)
+		}
+	}
+}
+
+func TestPictureData_Outlined(t *testing.T) {
+	// an opaque 2x2 square within a transparent 4x4 picture
+	pd := makePicture(10, 10, 4, 4, func(x, y int) color.RGBA {
+		if x >= 1 && x <= 2 && y >= 1 && y <= 2 {
+			return color.RGBA{0, 0, 255, 255}
+		}
+		return color.RGBA{}
+	})
+	got := pd.Outlined(1.5, pixel.RGB(1, 0, 0))
+	if want := pixel.R(8, 8, 16, 16); got.Bounds() != want {
+		t.Fatalf("Outlined() bounds = %v, want %v", got.Bounds(), want)
+	}
+	tests := []struct {
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		{at: pixel.V(11.5, 11.5), want: pixel.RGB(0, 0, 1)},
+		{at: pixel.V(10.5, 11.5), want: pixel.RGB(1, 0, 0)},
+		{at: pixel.V(9.5, 11.5), want: pixel.Alpha(0.5).Mul(pixel.RGB(1, 0, 0))},
+		{at: pixel.V(8.5, 8.5), want: pixel.RGBA{}},
+	}
+	for _, tt := range tests {
+		if c := got.Color(tt.at); !colorEq(c, tt.want, 1.0/255) {
+			t.Errorf("Outlined() color at %v = %v, want %v", tt.at, c, tt.want)
+		}
+	}
+}
+
+func TestPictureData_Shadowed(t *testing.T) {
+	pd := makePicture(0, 0, 2, 2, func(x, y int) color.RGBA { return color.RGBA{255, 255, 255, 255} })
+	black := pixel.RGB(0, 0, 0)
+
+	got := pd.Shadowed(pixel.V(3, -1), 0, black)
+	if want := pixel.R(0, -1, 5, 2); got.Bounds() != want {
+		t.Fatalf("Shadowed() bounds = %v, want %v", got.Bounds(), want)
+	}
+	tests := []struct {
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		{at: pixel.V(0.5, 0.5), want: pixel.RGB(1, 1, 1)},
+		{at: pixel.V(3.5, -0.5), want: black},
+		{at: pixel.V(0.5, -0.5), want: pixel.RGBA{}},
+	}
+	for _, tt := range tests {
+		if c := got.Color(tt.at); !colorEq(c, tt.want, 1.0/255) {
+			t.Errorf("Shadowed() color at %v = %v, want %v", tt.at, c, tt.want)
+		}
+	}
+
+	// the blurred shadow grows by three standard deviations
+	if b := pd.Shadowed(pixel.V(1, 1), 1, black).Bounds(); b != pixel.R(-2, -2, 6, 6) {
+		t.Errorf("Shadowed() blurred bounds = %v, want %v", b, pixel.R(-2, -2, 6, 6))
+	}
+}
+
+func TestPictureData_Premultiplied(t *testing.T) {
+	straight := makePicture(0, 0, 256, 1, func(x, y int) color.RGBA {
+		return color.RGBA{255, 128, uint8(x), uint8(x)}
+	})
+	pre := straight.Premultiplied()
+	if c := pre.Pix[128]; c != (color.RGBA{128, 64, 64, 128}) {
+		t.Errorf("Premultiplied() = %v, want %v", c, color.RGBA{128, 64, 64, 128})
+	}
+	back := pre.Unpremultiplied()
+	for x := 1; x < 256; x++ {
+		want, got := straight.Pix[x], back.Pix[x]
+		// the precision is lost mostly for low alphas
+		tol := 255 / float64(x)
+		if math.Abs(float64(got.R)-float64(want.R)) > tol ||
+			math.Abs(float64(got.G)-float64(want.G)) > tol ||
+			math.Abs(float64(got.B)-float64(want.B)) > tol ||
+			got.A != want.A {
+			t.Errorf("Unpremultiplied() pixel %d = %v, want %v", x, got, want)
+		}
+	}
+	if c := back.Pix[0]; c.A != 0 {
+		t.Errorf("Unpremultiplied() transparent pixel = %v", c)
+	}
+}
+
+func BenchmarkPictureData_Blurred(b *testing.B) {
+	pd := makePicture(0, 0, 256, 256, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x), G: uint8(y), A: 255}
+	})
+	b.Run("Gaussian", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.Blurred(3)
+		}
+	})
+	b.Run("box", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.BoxBlurred(3)
+		}
+	})
+}
+
+func BenchmarkPictureData_Quantized(b *testing.B) {
+	pd := makePicture(0, 0, 256, 256, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255}
+	})
+	palette := pd.Palette(16)
+	b.Run("Palette", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.Palette(16)
+		}
+	})
+	b.Run("plain", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.Quantized(palette, false)
+		}
+	})
+	b.Run("dithered", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.Quantized(palette, true)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package pixel_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/gopxl/pixel/v2"
@@ -56,3 +57,253 @@ func TestBezier(t *testing.T) {
 		})
 	}
 }
+
+func TestQuadratic(t *testing.T) {
+	b := pixel.Quadratic(pixel.V(0, 0), pixel.V(1, 2), pixel.V(2, 0))
+	for _, tt := range []float64{0, 0.25, 0.5, 0.9, 1} {
+		inv := 1 - tt
+		want := pixel.V(0, 0).Scaled(inv * inv).Add(pixel.V(1, 2).Scaled(2 * inv * tt)).Add(pixel.V(2, 0).Scaled(tt * tt))
+		if got := b.Point(tt); !got.Eq(want) {
+			t.Errorf("Point(%v) = %v, want %v", tt, got, want)
+		}
+	}
+}
+
+func TestBezier_Tangent(t *testing.T) {
+	line := pixel.Linear(pixel.V(1, 1), pixel.V(4, 5))
+	for _, tt := range []float64{0, 0.5, 1} {
+		if got, want := line.Tangent(tt), pixel.V(0.6, 0.8); !got.Eq(want) {
+			t.Errorf("Tangent(%v) = %v, want %v", tt, got, want)
+		}
+		if got, want := line.Normal(tt), pixel.V(-0.8, 0.6); !got.Eq(want) {
+			t.Errorf("Normal(%v) = %v, want %v", tt, got, want)
+		}
+	}
+
+	// the derivative at the start is zero, the curve heads towards the end handle
+	b := pixel.B(pixel.V(0, 0), pixel.ZV, pixel.V(0, 1), pixel.V(1, 0))
+	if got, want := b.Tangent(0), pixel.V(1, 1).Unit(); !got.Eq(want) {
+		t.Errorf("Tangent(0) = %v, want %v", got, want)
+	}
+	if got := pixel.Constant(pixel.V(1, 1)).Tangent(0.5); got != pixel.ZV {
+		t.Errorf("Tangent() of a constant = %v, want %v", got, pixel.ZV)
+	}
+}
+
+func TestBezier_Split(t *testing.T) {
+	b := pixel.B(pixel.V(0, 1), pixel.V(1, 0), pixel.V(-1, 0), pixel.V(1, 0))
+	first, second := b.Split(0.3)
+	for i := 0; i <= 10; i++ {
+		tt := float64(i) / 10
+		if got, want := first.Point(tt), b.Point(0.3*tt); !got.Eq(want) {
+			t.Errorf("first.Point(%v) = %v, want %v", tt, got, want)
+		}
+		if got, want := second.Point(tt), b.Point(0.3+0.7*tt); !got.Eq(want) {
+			t.Errorf("second.Point(%v) = %v, want %v", tt, got, want)
+		}
+	}
+}
+
+func TestBezier_Bounds(t *testing.T) {
+	curves := []pixel.Bezier{
+		pixel.Linear(pixel.V(3, 1), pixel.V(-1, 2)),
+		pixel.B(pixel.V(0, 0), pixel.V(0, 2), pixel.V(0, 2), pixel.V(1, 0)),
+		pixel.B(pixel.V(0, 0), pixel.V(2, 1), pixel.V(-2, -1), pixel.V(0, 0.5)),
+		pixel.Quadratic(pixel.V(0, 0), pixel.V(1, -3), pixel.V(2, 0)),
+	}
+	for _, b := range curves {
+		want := pixel.Rect{Min: b.Point(0), Max: b.Point(0)}
+		for i := 1; i <= 10000; i++ {
+			u := b.Point(float64(i) / 10000)
+			want = want.Union(pixel.Rect{Min: u, Max: u})
+		}
+		got := b.Bounds()
+		if math.Abs(got.Min.X-want.Min.X) > 1e-6 || math.Abs(got.Min.Y-want.Min.Y) > 1e-6 ||
+			math.Abs(got.Max.X-want.Max.X) > 1e-6 || math.Abs(got.Max.Y-want.Max.Y) > 1e-6 {
+			t.Errorf("Bounds() of %v = %v, want %v", b, got, want)
+		}
+	}
+}
+
+func TestBezier_Len(t *testing.T) {
+	if got := pixel.Linear(pixel.V(1, 1), pixel.V(4, 5)).Len(); math.Abs(got-5) > 1e-9 {
+		t.Errorf("Len() of a line = %v, want 5", got)
+	}
+
+	// a quarter of a unit circle, the approximation is off by about 0.03%
+	k := 4 * (math.Sqrt2 - 1) / 3
+	arc := pixel.B(pixel.V(1, 0), pixel.V(0, k), pixel.V(k, 0), pixel.V(0, 1))
+	if got := arc.Len(); math.Abs(got-math.Pi/2) > 1e-3 {
+		t.Errorf("Len() of a quarter circle = %v, want %v", got, math.Pi/2)
+	}
+
+	b := pixel.B(pixel.V(0, 0), pixel.V(3, 5), pixel.V(-4, 2), pixel.V(2, 0))
+	want := 0.0
+	prev := b.Point(0)
+	for i := 1; i <= 100000; i++ {
+		u := b.Point(float64(i) / 100000)
+		want += prev.To(u).Len()
+		prev = u
+	}
+	if got := b.Len(); math.Abs(got-want) > 1e-6 {
+		t.Errorf("Len() = %v, want %v", got, want)
+	}
+	if got := b.LenAt(0); got != 0 {
+		t.Errorf("LenAt(0) = %v, want 0", got)
+	}
+}
+
+func TestBezier_PointAtLen(t *testing.T) {
+	b := pixel.B(pixel.V(0, 0), pixel.V(10, 0), pixel.V(0, -30), pixel.V(20, 10))
+	total := b.Len()
+	for i := 0; i <= 10; i++ {
+		length := total * float64(i) / 10
+		tt := b.TAtLen(length)
+		if got := b.LenAt(tt); math.Abs(got-length) > 1e-6 {
+			t.Errorf("LenAt(TAtLen(%v)) = %v", length, got)
+		}
+		if got, want := b.PointAtLen(length), b.Point(tt); !got.Eq(want) {
+			t.Errorf("PointAtLen(%v) = %v, want %v", length, got, want)
+		}
+	}
+	if got := b.TAtLen(-1); got != 0 {
+		t.Errorf("TAtLen(-1) = %v, want 0", got)
+	}
+	if got := b.TAtLen(total + 1); got != 1 {
+		t.Errorf("TAtLen(%v) = %v, want 1", total+1, got)
+	}
+}
+
+func TestBezier_Flatten(t *testing.T) {
+	b := pixel.B(pixel.V(0, 0), pixel.V(30, 50), pixel.V(-40, 20), pixel.V(20, 0))
+	for _, tolerance := range []float64{2, 0.5, 0.01} {
+		points := b.Flatten(tolerance)
+		if points[0] != b.Start || points[len(points)-1] != b.End {
+			t.Errorf("Flatten(%v) goes from %v to %v, want %v to %v",
+				tolerance, points[0], points[len(points)-1], b.Start, b.End)
+		}
+		for i := 0; i <= 1000; i++ {
+			u := b.Point(float64(i) / 1000)
+			dist := math.Inf(+1)
+			for j := 1; j < len(points); j++ {
+				dist = math.Min(dist, u.To(pixel.L(points[j-1], points[j]).Closest(u)).Len())
+			}
+			if dist > tolerance*1.001 {
+				t.Errorf("Flatten(%v) strays %v from the curve at %v", tolerance, dist, u)
+				break
+			}
+		}
+	}
+	if got := len(pixel.Linear(pixel.V(0, 0), pixel.V(5, 5)).Flatten(0.1)); got != 2 {
+		t.Errorf("Flatten() of a line has %v points, want 2", got)
+	}
+}
+
+func TestCatmullRom(t *testing.T) {
+	points := []pixel.Vec{pixel.V(0, 0), pixel.V(10, 5), pixel.V(20, -5), pixel.V(30, 0)}
+	for _, closed := range []bool{false, true} {
+		path := pixel.CatmullRom(points, closed)
+		want := len(points) - 1
+		if closed {
+			want = len(points)
+		}
+		if len(path) != want {
+			t.Fatalf("CatmullRom(closed=%v) has %d curves, want %d", closed, len(path), want)
+		}
+		for i, b := range path {
+			if b.Start != points[i] || b.End != points[(i+1)%len(points)] {
+				t.Errorf("curve %d goes from %v to %v", i, b.Start, b.End)
+			}
+			next := path[(i+1)%len(path)]
+			if i+1 < len(path) || closed {
+				if !b.Tangent(1).Eq(next.Tangent(0)) {
+					t.Errorf("tangents at point %d differ: %v and %v", i+1, b.Tangent(1), next.Tangent(0))
+				}
+			}
+		}
+	}
+	if path := pixel.CatmullRom(points[:1], false); len(path) != 0 {
+		t.Errorf("CatmullRom() of a single point = %v, want empty", path)
+	}
+}
+
+func TestBSpline(t *testing.T) {
+	points := []pixel.Vec{pixel.V(0, 0), pixel.V(10, 10), pixel.V(20, -10), pixel.V(30, 0)}
+
+	open := pixel.BSpline(points, false)
+	if got := open.Point(0); !got.Eq(points[0]) {
+		t.Errorf("open spline starts at %v, want %v", got, points[0])
+	}
+	if got := open.Point(1); !got.Eq(points[3]) {
+		t.Errorf("open spline ends at %v, want %v", got, points[3])
+	}
+
+	closed := pixel.BSpline(points, true)
+	if len(closed) != len(points) {
+		t.Fatalf("closed spline has %d curves, want %d", len(closed), len(points))
+	}
+	for i, b := range closed {
+		next := closed[(i+1)%len(closed)]
+		if !b.End.Eq(next.Start) || !b.Tangent(1).Eq(next.Tangent(0)) {
+			t.Errorf("curves %d and %d don't join smoothly", i, i+1)
+		}
+	}
+}
+
+func TestPath(t *testing.T) {
+	path := pixel.Path{
+		pixel.Linear(pixel.V(0, 0), pixel.V(10, 0)),
+		pixel.Linear(pixel.V(10, 0), pixel.V(10, 30)),
+	}
+	if got := path.Len(); math.Abs(got-40) > 1e-9 {
+		t.Errorf("Len() = %v, want 40", got)
+	}
+	for _, tt := range []struct {
+		length float64
+		point  pixel.Vec
+	}{
+		{-5, pixel.V(0, 0)},
+		{5, pixel.V(5, 0)},
+		{10, pixel.V(10, 0)},
+		{25, pixel.V(10, 15)},
+		{50, pixel.V(10, 30)},
+	} {
+		if got := path.PointAtLen(tt.length); !got.Eq(tt.point) {
+			t.Errorf("PointAtLen(%v) = %v, want %v", tt.length, got, tt.point)
+		}
+	}
+	if got, want := path.TangentAtLen(20), pixel.V(0, 1); !got.Eq(want) {
+		t.Errorf("TangentAtLen(20) = %v, want %v", got, want)
+	}
+	if got, want := path.Point(0.75), pixel.V(10, 15); !got.Eq(want) {
+		t.Errorf("Point(0.75) = %v, want %v", got, want)
+	}
+	if got, want := path.Bounds(), pixel.R(0, 0, 10, 30); got != want {
+		t.Errorf("Bounds() = %v, want %v", got, want)
+	}
+	if got := path.Flatten(1); len(got) != 3 {
+		t.Errorf("Flatten() = %v, want 3 points", got)
+	}
+	if got := (pixel.Path{}).PointAtLen(1); got != pixel.ZV {
+		t.Errorf("PointAtLen() of an empty path = %v, want %v", got, pixel.ZV)
+	}
+}
+
+func BenchmarkBezier(b *testing.B) {
+	curve := pixel.B(pixel.V(0, 0), pixel.V(30, 50), pixel.V(-40, 20), pixel.V(20, 0))
+	b.Run("Len", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.Len()
+		}
+	})
+	b.Run("PointAtLen", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.PointAtLen(20)
+		}
+	})
+	b.Run("Flatten", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			curve.Flatten(0.5)
+		}
+	})
+}
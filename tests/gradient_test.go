@@ -0,0 +1,165 @@
+package pixel_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+var (
+	gradientRed   = pixel.RGB(1, 0, 0)
+	gradientGreen = pixel.RGB(0, 1, 0)
+	gradientBlue  = pixel.RGB(0, 0, 1)
+)
+
+func gradient(spread pixel.SpreadMode) pixel.Gradient {
+	return pixel.Gradient{
+		Stops: []pixel.GradientStop{
+			{Offset: 0, Color: gradientRed},
+			{Offset: 0.5, Color: gradientGreen},
+			{Offset: 1, Color: gradientBlue},
+		},
+		Spread: spread,
+		Rect:   pixel.R(-100, -100, 100, 100),
+	}
+}
+
+func TestGradient_At(t *testing.T) {
+	tests := []struct {
+		name   string
+		spread pixel.SpreadMode
+		t      float64
+		want   pixel.RGBA
+	}{
+		{name: "start", t: 0, want: gradientRed},
+		{name: "between", t: 0.25, want: pixel.RGB(0.5, 0.5, 0)},
+		{name: "stop", t: 0.5, want: gradientGreen},
+		{name: "end", t: 1, want: gradientBlue},
+		{name: "pad before", spread: pixel.SpreadPad, t: -3, want: gradientRed},
+		{name: "pad after", spread: pixel.SpreadPad, t: 1.5, want: gradientBlue},
+		{name: "repeat", spread: pixel.SpreadRepeat, t: 1.25, want: pixel.RGB(0.5, 0.5, 0)},
+		{name: "repeat before", spread: pixel.SpreadRepeat, t: -0.75, want: pixel.RGB(0.5, 0.5, 0)},
+		{name: "reflect", spread: pixel.SpreadReflect, t: 1.25, want: pixel.RGB(0, 0.5, 0.5)},
+		{name: "reflect before", spread: pixel.SpreadReflect, t: -0.25, want: pixel.RGB(0.5, 0.5, 0)},
+		{name: "reflect twice", spread: pixel.SpreadReflect, t: 2.25, want: pixel.RGB(0.5, 0.5, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gradient(tt.spread).At(tt.t); !colorEq(got, tt.want, 1e-9) {
+				t.Errorf("At(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+
+	if got := (pixel.Gradient{}).At(0.5); got != (pixel.RGBA{}) {
+		t.Errorf("At() without stops = %v, want transparent", got)
+	}
+
+	// transparent stops don't darken the colors next to them
+	fade := pixel.Gradient{Stops: []pixel.GradientStop{
+		{Offset: 0, Color: gradientRed},
+		{Offset: 1, Color: pixel.RGBA{}},
+	}}
+	if got, want := fade.At(0.5), gradientRed.Scaled(0.5); !colorEq(got, want, 1e-9) {
+		t.Errorf("At() of fade = %v, want %v", got, want)
+	}
+}
+
+func TestGradient_Color(t *testing.T) {
+	tests := []struct {
+		name string
+		pic  pixel.PictureColor
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		{
+			name: "linear",
+			pic:  &pixel.LinearGradient{Gradient: gradient(pixel.SpreadPad), Start: pixel.V(0, 0), End: pixel.V(0, 40)},
+			at:   pixel.V(50, 10),
+			want: pixel.RGB(0.5, 0.5, 0),
+		},
+		{
+			name: "linear reflect",
+			pic:  &pixel.LinearGradient{Gradient: gradient(pixel.SpreadReflect), Start: pixel.V(0, 0), End: pixel.V(40, 0)},
+			at:   pixel.V(-70, 3),
+			want: pixel.RGB(0.5, 0.5, 0),
+		},
+		{
+			name: "linear degenerate",
+			pic:  &pixel.LinearGradient{Gradient: gradient(pixel.SpreadPad), Start: pixel.V(5, 5), End: pixel.V(5, 5)},
+			at:   pixel.V(0, 0),
+			want: pixel.RGBA{},
+		},
+		{
+			name: "radial",
+			pic:  &pixel.RadialGradient{Gradient: gradient(pixel.SpreadPad), Center: pixel.V(10, 10), Radius: 20},
+			at:   pixel.V(10, 25),
+			want: pixel.RGB(0, 0.5, 0.5),
+		},
+		{
+			name: "radial center",
+			pic:  &pixel.RadialGradient{Gradient: gradient(pixel.SpreadPad), Center: pixel.V(10, 10), Radius: 20},
+			at:   pixel.V(10, 10),
+			want: gradientRed,
+		},
+		{
+			name: "radial repeat",
+			pic:  &pixel.RadialGradient{Gradient: gradient(pixel.SpreadRepeat), Center: pixel.V(0, 0), Radius: 20},
+			at:   pixel.V(-25, 0),
+			want: pixel.RGB(0.5, 0.5, 0),
+		},
+		{
+			name: "conic",
+			pic:  &pixel.ConicGradient{Gradient: gradient(pixel.SpreadPad), Center: pixel.V(0, 0)},
+			at:   pixel.V(0, 10),
+			want: pixel.RGB(0.5, 0.5, 0),
+		},
+		{
+			name: "conic rotated",
+			pic:  &pixel.ConicGradient{Gradient: gradient(pixel.SpreadPad), Center: pixel.V(0, 0), Angle: math.Pi / 2},
+			at:   pixel.V(0, -10),
+			want: gradientGreen,
+		},
+		{
+			name: "outside",
+			pic:  &pixel.RadialGradient{Gradient: gradient(pixel.SpreadPad), Center: pixel.V(0, 0), Radius: 20},
+			at:   pixel.V(200, 0),
+			want: pixel.RGBA{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pic.Color(tt.at); !colorEq(got, tt.want, 1e-9) {
+				t.Errorf("Color(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGradient_Draw(t *testing.T) {
+	lg := &pixel.LinearGradient{
+		Gradient: pixel.Gradient{
+			Stops: []pixel.GradientStop{
+				{Offset: 0, Color: gradientRed},
+				{Offset: 1, Color: gradientBlue},
+			},
+			Rect: pixel.R(0, 0, 16, 16),
+		},
+		Start: pixel.V(0, 0),
+		End:   pixel.V(16, 0),
+	}
+
+	pd := pixel.PictureDataFromPicture(lg)
+	if pd.Bounds() != lg.Bounds() {
+		t.Fatalf("PictureDataFromPicture() bounds = %v, want %v", pd.Bounds(), lg.Bounds())
+	}
+
+	canvas := software.NewCanvas(pixel.R(0, 0, 16, 16))
+	pixel.NewSprite(lg, lg.Bounds()).Draw(canvas, pixel.IM.Moved(canvas.Bounds().Center()))
+	left, right := canvas.Color(pixel.V(0.5, 8)), canvas.Color(pixel.V(15.5, 8))
+	if left.R < 0.9 || left.B > 0.1 || right.R > 0.1 || right.B < 0.9 {
+		t.Errorf("drawn gradient goes from %v to %v, want from red to blue", left, right)
+	}
+}
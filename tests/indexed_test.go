@@ -0,0 +1,255 @@
+package pixel_test
+
+import (
+	"image/color"
+	"testing"
+	"unsafe"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+// flatQuad returns a quad as TrianglesData of two triangles with the colors changing by the corner.
+func flatQuad(r pixel.Rect) *pixel.TrianglesData {
+	td := pixel.MakeTrianglesData(6)
+	for i, corner := range []int{0, 1, 2, 0, 2, 3} {
+		(*td)[i].Position = r.Vertices()[corner]
+		(*td)[i].Color = pixel.RGB(float64(corner%2), 0.5, float64(corner/2))
+	}
+	return td
+}
+
+func TestIndexTriangles(t *testing.T) {
+	flat := flatQuad(pixel.R(0, 0, 10, 20))
+	itd := pixel.IndexTriangles[uint16](flat)
+
+	if itd.VertexData.Len() != 4 || itd.Len() != 6 {
+		t.Fatalf("IndexTriangles() has %d vertices and %d indices, want 4 and 6", itd.VertexData.Len(), itd.Len())
+	}
+	if want := []uint16{0, 1, 2, 0, 2, 3}; !equalIndices(itd.Indices, want) {
+		t.Errorf("Indices = %v, want %v", itd.Indices, want)
+	}
+	for i := 0; i < flat.Len(); i++ {
+		if itd.Position(i) != flat.Position(i) || itd.Color(i) != flat.Color(i) {
+			t.Errorf("vertex %d = %v %v, want %v %v", i, itd.Position(i), itd.Color(i), flat.Position(i), flat.Color(i))
+		}
+	}
+
+	// uint16 indices address at most 65536 vertices
+	many := pixel.MakeTrianglesData(1<<16 + 1)
+	for i := range *many {
+		(*many)[i].Position = pixel.V(float64(i), 0)
+	}
+	if got := pixel.IndexTriangles[uint32](many); got.VertexData.Len() != many.Len() {
+		t.Errorf("IndexTriangles[uint32]() has %d vertices, want %d", got.VertexData.Len(), many.Len())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("IndexTriangles[uint16]() with too many vertices didn't panic")
+		}
+	}()
+	pixel.IndexTriangles[uint16](many)
+}
+
+func equalIndices[I pixel.VertexIndex](a, b []I) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIndexedTrianglesData(t *testing.T) {
+	itd := pixel.IndexTriangles[uint32](flatQuad(pixel.R(0, 0, 10, 20)))
+
+	t.Run("Update from flat", func(t *testing.T) {
+		itd := itd.Copy().(*pixel.IndexedTrianglesData[uint32])
+		moved := flatQuad(pixel.R(5, 5, 15, 25))
+		itd.Update(moved)
+		if itd.VertexData.Len() != 4 {
+			t.Fatalf("vertices = %d, want 4", itd.VertexData.Len())
+		}
+		for i := 0; i < moved.Len(); i++ {
+			if itd.Position(i) != moved.Position(i) {
+				t.Errorf("vertex %d = %v, want %v", i, itd.Position(i), moved.Position(i))
+			}
+		}
+	})
+
+	t.Run("Update from indexed", func(t *testing.T) {
+		other := pixel.MakeIndexedTrianglesData[uint16](3, 6)
+		copy(other.Indices, []uint16{0, 1, 2, 2, 1, 0})
+		(*other.VertexData)[2].Position = pixel.V(7, 7)
+
+		itd := itd.Copy().(*pixel.IndexedTrianglesData[uint32])
+		itd.Update(other)
+		if itd.VertexData.Len() != 3 || itd.Index(3) != 2 || itd.Position(3) != pixel.V(7, 7) {
+			t.Errorf("Update() = %v %v, want the vertices and indices of the other", *itd.VertexData, itd.Indices)
+		}
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		c := itd.Copy().(*pixel.IndexedTrianglesData[uint32])
+		(*c.VertexData)[0].Position = pixel.V(-1, -1)
+		c.Indices[0] = 3
+		if itd.Position(0) == pixel.V(-1, -1) || itd.Index(0) == 3 {
+			t.Errorf("Copy() shares data with the original")
+		}
+	})
+
+	t.Run("Slice", func(t *testing.T) {
+		itd := itd.Copy().(*pixel.IndexedTrianglesData[uint32])
+		second := itd.Slice(3, 6).(*pixel.IndexedTrianglesData[uint32])
+		if second.Len() != 3 || second.Position(0) != itd.Position(3) {
+			t.Fatalf("Slice() = %v, want the second triangle", second.Indices)
+		}
+		// the vertices are shared, so changing a corner of the second triangle changes the first
+		second.Update(pixel.MakeTrianglesData(3))
+		if itd.Position(0) != pixel.ZV {
+			t.Errorf("Slice().Update() didn't change the shared vertex, got %v", itd.Position(0))
+		}
+	})
+
+	t.Run("SetLen", func(t *testing.T) {
+		itd := itd.Copy().(*pixel.IndexedTrianglesData[uint32])
+		itd.SetLen(3)
+		itd.SetLen(9)
+		if itd.Len() != 9 || itd.Index(2) != 2 || itd.Index(5) != 0 || itd.VertexData.Len() != 4 {
+			t.Errorf("SetLen() = %v, want the first triangle followed by zero indices", itd.Indices)
+		}
+	})
+}
+
+func TestBatch_indexed(t *testing.T) {
+	quad := pixel.IndexTriangles[uint16](flatQuad(pixel.R(0, 0, 4, 4)))
+	flat := flatQuad(pixel.R(0, 0, 4, 4))
+
+	container := pixel.MakeIndexedTrianglesData[uint16](0, 0)
+	batch := pixel.NewBatch(container, nil)
+	indexed := batch.MakeTriangles(quad)
+	for _, pos := range []pixel.Vec{pixel.V(0, 0), pixel.V(4, 0)} {
+		batch.SetMatrix(pixel.IM.Moved(pos))
+		indexed.Draw()
+	}
+	// flat Triangles are added with their own vertices
+	batch.SetMatrix(pixel.IM.Moved(pixel.V(8, 0)))
+	batch.MakeTriangles(flat).Draw()
+
+	if container.VertexData.Len() != 4+4+6 || container.Len() != 18 {
+		t.Fatalf("container has %d vertices and %d indices, want 14 and 18", container.VertexData.Len(), container.Len())
+	}
+	if container.Index(6) != 4 || container.Index(12) != 8 {
+		t.Errorf("indices = %v, want moved after the previous vertices", container.Indices)
+	}
+	if got := container.Position(8); got != pixel.V(8, 4) {
+		t.Errorf("moved vertex = %v, want %v", got, pixel.V(8, 4))
+	}
+
+	// the indexed Batch draws the same pixels as the flat one
+	flatBatch := pixel.NewBatch(&pixel.TrianglesData{}, nil)
+	for _, pos := range []pixel.Vec{pixel.V(0, 0), pixel.V(4, 0), pixel.V(8, 0)} {
+		flatBatch.SetMatrix(pixel.IM.Moved(pos))
+		flatBatch.MakeTriangles(flat).Draw()
+	}
+	got, want := software.NewCanvas(pixel.R(0, 0, 12, 4)), software.NewCanvas(pixel.R(0, 0, 12, 4))
+	batch.Draw(got)
+	flatBatch.Draw(want)
+	gotPix, wantPix := got.Pixels(), want.Pixels()
+	for i := range wantPix {
+		if gotPix[i] != wantPix[i] {
+			t.Fatalf("indexed Batch pixels differ from flat Batch at byte %d", i)
+		}
+	}
+
+	// indexed Triangles are expanded in a flat Batch
+	expanded := &pixel.TrianglesData{}
+	pixel.NewBatch(expanded, nil).MakeTriangles(quad).Draw()
+	if expanded.Len() != 6 || expanded.Position(4) != flat.Position(4) {
+		t.Errorf("flat container = %v, want the expanded quad", *expanded)
+	}
+
+	batch.Clear()
+	if container.Len() != 0 || container.VertexData.Len() != 0 {
+		t.Errorf("Clear() left %d vertices and %d indices", container.VertexData.Len(), container.Len())
+	}
+
+	// the uint16 indices can't address more vertices
+	defer func() {
+		if recover() == nil {
+			t.Errorf("drawing too many vertices into a uint16 Batch didn't panic")
+		}
+	}()
+	for i := 0; i < 1<<16/4+1; i++ {
+		indexed.Draw()
+	}
+}
+
+func TestSprite_indexed(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	for i := range pic.Pix {
+		pic.Pix[i] = color.RGBA{R: uint8(i % 4 * 80), G: uint8(i / 4 * 80), B: 255, A: 255}
+	}
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+
+	// a Sprite takes four vertices and six indices in an indexed Batch
+	container := pixel.MakeIndexedTrianglesData[uint16](0, 0)
+	batch := pixel.NewBatch(container, pic)
+	flatBatch := pixel.NewBatch(&pixel.TrianglesData{}, pic)
+	for _, pos := range []pixel.Vec{pixel.V(2, 2), pixel.V(6, 2), pixel.V(10, 2)} {
+		sprite.Draw(batch, pixel.IM.Moved(pos))
+		sprite.Draw(flatBatch, pixel.IM.Moved(pos))
+	}
+	if container.VertexData.Len() != 3*4 || container.Len() != 3*6 {
+		t.Fatalf("container has %d vertices and %d indices, want 12 and 18", container.VertexData.Len(), container.Len())
+	}
+
+	got, want := software.NewCanvas(pixel.R(0, 0, 12, 4)), software.NewCanvas(pixel.R(0, 0, 12, 4))
+	batch.Draw(got)
+	flatBatch.Draw(want)
+	gotPix, wantPix := got.Pixels(), want.Pixels()
+	if wantPix[3] == 0 {
+		t.Fatalf("flat Batch drew nothing")
+	}
+	for i := range wantPix {
+		if gotPix[i] != wantPix[i] {
+			t.Fatalf("indexed Batch pixels differ from flat Batch at byte %d", i)
+		}
+	}
+}
+
+// BenchmarkBatch_indexed draws quads into Batches. The B/quad metric is the memory taken by a quad
+// in the container of the Batch.
+func BenchmarkBatch_indexed(b *testing.B) {
+	const quads = 10000
+	flat := flatQuad(pixel.R(0, 0, 4, 4))
+	vertexSize := int(unsafe.Sizeof((*flat)[0]))
+
+	tests := []struct {
+		name      string
+		container pixel.Triangles
+		quad      pixel.Triangles
+		size      int
+	}{
+		{name: "flat", container: &pixel.TrianglesData{}, quad: flat, size: 6 * vertexSize},
+		{name: "uint16", container: pixel.MakeIndexedTrianglesData[uint16](0, 0), quad: pixel.IndexTriangles[uint16](flat), size: 4*vertexSize + 6*2},
+		{name: "uint32", container: pixel.MakeIndexedTrianglesData[uint32](0, 0), quad: pixel.IndexTriangles[uint32](flat), size: 4*vertexSize + 6*4},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			batch := pixel.NewBatch(tt.container, nil)
+			tri := batch.MakeTriangles(tt.quad)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				batch.Clear()
+				for j := 0; j < quads; j++ {
+					tri.Draw()
+				}
+			}
+			b.ReportMetric(float64(tt.size), "B/quad")
+		})
+	}
+}
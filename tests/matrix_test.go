@@ -147,3 +147,142 @@ func TestMatrix_Unproject(t *testing.T) {
 		assert.True(t, math.IsNaN(unprojected.Y))
 	})
 }
+
+func TestMatrix_Inverted(t *testing.T) {
+	const delta = 1e-12
+	matrices := []pixel.Matrix{
+		pixel.IM,
+		pixel.IM.Moved(pixel.V(3, -4)),
+		pixel.IM.ScaledXY(pixel.V(1, 2), pixel.V(2, -0.5)),
+		pixel.IM.Scaled(pixel.ZV, 0.5).Rotated(pixel.V(1, 1), math.Pi/3).Moved(pixel.V(1, 2)),
+		{1, 0.5, 2, 3, 4, 5},
+	}
+	for _, m := range matrices {
+		inv := m.Inverted()
+		for _, u := range []pixel.Vec{pixel.ZV, pixel.V(1, 0), pixel.V(-3, 7)} {
+			got := inv.Project(m.Project(u))
+			assert.InDelta(t, u.X, got.X, delta, "matrix %v", m)
+			assert.InDelta(t, u.Y, got.Y, delta, "matrix %v", m)
+			want := m.Unproject(u)
+			got = inv.Project(u)
+			assert.InDelta(t, want.X, got.X, delta, "matrix %v", m)
+			assert.InDelta(t, want.Y, got.Y, delta, "matrix %v", m)
+		}
+		chained := m.Chained(inv)
+		for i := range chained {
+			assert.InDelta(t, pixel.IM[i], chained[i], delta, "matrix %v", m)
+		}
+	}
+}
+
+func TestMatrix_Decompose(t *testing.T) {
+	const delta = 1e-12
+	tests := []struct {
+		name string
+		m    pixel.Matrix
+		want pixel.Transform
+	}{
+		{
+			name: "identity",
+			m:    pixel.IM,
+			want: pixel.Transform{Scale: pixel.V(1, 1)},
+		},
+		{
+			name: "scaled, rotated and moved",
+			m:    pixel.IM.ScaledXY(pixel.ZV, pixel.V(2, 3)).Rotated(pixel.ZV, math.Pi/4).Moved(pixel.V(5, 6)),
+			want: pixel.Transform{Translation: pixel.V(5, 6), Rotation: math.Pi / 4, Scale: pixel.V(2, 3)},
+		},
+		{
+			name: "mirrored",
+			m:    pixel.IM.ScaledXY(pixel.ZV, pixel.V(-1, 1)),
+			want: pixel.Transform{Rotation: math.Pi, Scale: pixel.V(1, -1)},
+		},
+		{
+			name: "sheared",
+			m:    pixel.Matrix{1, 0, 0.5, 1, 0, 0},
+			want: pixel.Transform{Scale: pixel.V(1, 1), Shear: 0.5},
+		},
+		{
+			name: "squashed",
+			m:    pixel.IM.ScaledXY(pixel.ZV, pixel.V(0, 2)).Rotated(pixel.ZV, math.Pi/2),
+			want: pixel.Transform{Rotation: math.Pi / 2, Scale: pixel.V(0, 2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.Decompose()
+			assert.InDelta(t, tt.want.Translation.X, got.Translation.X, delta)
+			assert.InDelta(t, tt.want.Translation.Y, got.Translation.Y, delta)
+			assert.InDelta(t, tt.want.Rotation, got.Rotation, delta)
+			assert.InDelta(t, tt.want.Scale.X, got.Scale.X, delta)
+			assert.InDelta(t, tt.want.Scale.Y, got.Scale.Y, delta)
+			assert.InDelta(t, tt.want.Shear, got.Shear, delta)
+
+			m := got.Matrix()
+			for i := range m {
+				assert.InDelta(t, tt.m[i], m[i], delta)
+			}
+		})
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		var m pixel.Matrix
+		for j := range m {
+			m[j] = rnd.Float64()*4 - 2
+		}
+		got := m.Decompose().Matrix()
+		for j := range m {
+			assert.InDelta(t, m[j], got[j], delta, "matrix %v", m)
+		}
+	}
+}
+
+func TestMatrix_Lerp(t *testing.T) {
+	const delta = 1e-12
+	a := pixel.IM.Rotated(pixel.ZV, 3)
+	b := pixel.IM.Scaled(pixel.ZV, 3).Rotated(pixel.ZV, -3).Moved(pixel.V(10, 20))
+
+	for _, tt := range []struct {
+		t    float64
+		want pixel.Matrix
+	}{
+		{0, a},
+		{1, b},
+		// the rotation goes the shorter way over Pi
+		{0.5, pixel.IM.Scaled(pixel.ZV, 2).Rotated(pixel.ZV, math.Pi).Moved(pixel.V(5, 10))},
+	} {
+		got := a.Lerp(b, tt.t)
+		for i := range got {
+			assert.InDelta(t, tt.want[i], got[i], delta, "t = %v", tt.t)
+		}
+	}
+}
+
+func TestMatrix_TransformedShapes(t *testing.T) {
+	const delta = 1e-12
+	m := pixel.IM.Rotated(pixel.ZV, math.Pi/2).Moved(pixel.V(10, 0))
+
+	l := pixel.L(pixel.V(1, 0), pixel.V(2, 0)).Transformed(m)
+	assert.InDelta(t, 10, l.A.X, delta)
+	assert.InDelta(t, 1, l.A.Y, delta)
+	assert.InDelta(t, 10, l.B.X, delta)
+	assert.InDelta(t, 2, l.B.Y, delta)
+
+	p := pixel.R(0, 0, 4, 2).Transformed(m)
+	assert.Len(t, p, 4)
+	assert.InDelta(t, 8, p.Area(), delta)
+	assert.False(t, p.IsClockwise())
+	bounds := p.Bounds()
+	assert.InDelta(t, 8, bounds.Min.X, delta)
+	assert.InDelta(t, 0, bounds.Min.Y, delta)
+	assert.InDelta(t, 10, bounds.Max.X, delta)
+	assert.InDelta(t, 4, bounds.Max.Y, delta)
+
+	c := pixel.C(pixel.V(1, 1), 2)
+	r := c.TransformedBounds(pixel.IM.ScaledXY(pixel.ZV, pixel.V(3, 1)).Moved(pixel.V(1, 0)))
+	assert.Equal(t, pixel.R(-2, -1, 10, 3), r)
+	r = c.TransformedBounds(pixel.IM.Rotated(pixel.ZV, math.Pi/4))
+	assert.InDelta(t, 4, r.W(), delta)
+	assert.InDelta(t, 4, r.H(), delta)
+}
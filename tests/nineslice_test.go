@@ -0,0 +1,191 @@
+package pixel_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+// regions returns a 30x30 picture with each of its nine 10x10 regions in a different color.
+func regions() *pixel.PictureData {
+	return makePicture(0, 0, 30, 30, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x / 10 * 100), G: uint8(y / 10 * 100), A: 255}
+	})
+}
+
+func regionColor(i, j int) pixel.RGBA {
+	return pixel.RGB(float64(i*100)/255, float64(j*100)/255, 0)
+}
+
+func TestNineSliceSprite_Draw(t *testing.T) {
+	pic := regions()
+	s := pixel.NewNineSliceSprite(pic, pic.Bounds(), pixel.Insets{Left: 10, Bottom: 10, Right: 10, Top: 10})
+	s.SetSize(pixel.V(60, 40))
+
+	c := software.NewCanvas(pixel.R(0, 0, 60, 40))
+	s.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+
+	tests := []struct {
+		at   pixel.Vec
+		want pixel.RGBA
+	}{
+		{pixel.V(0.5, 0.5), regionColor(0, 0)},
+		{pixel.V(9.5, 9.5), regionColor(0, 0)},
+		{pixel.V(30, 5), regionColor(1, 0)},
+		{pixel.V(55, 5), regionColor(2, 0)},
+		{pixel.V(5, 20), regionColor(0, 1)},
+		{pixel.V(30, 20), regionColor(1, 1)},
+		{pixel.V(50.5, 20), regionColor(2, 1)},
+		{pixel.V(5, 35), regionColor(0, 2)},
+		{pixel.V(30, 35), regionColor(1, 2)},
+		{pixel.V(59.5, 39.5), regionColor(2, 2)},
+	}
+	for _, tt := range tests {
+		if got := c.Color(tt.at); got != tt.want {
+			t.Errorf("Color(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestNineSliceSprite_modes(t *testing.T) {
+	pic := regions()
+	insets := pixel.Insets{Left: 10, Bottom: 10, Right: 10, Top: 10}
+
+	tests := []struct {
+		name          string
+		edges, center pixel.NineSliceMode
+		size          pixel.Vec
+		quads         int
+	}{
+		{name: "stretch", size: pixel.V(60, 40), quads: 9},
+		// the middle column is 4 tiles wide and the middle row 2 tiles high
+		{name: "tile", edges: pixel.NineSliceTile, center: pixel.NineSliceTile, size: pixel.V(60, 40), quads: 4 + 2*4 + 2*2 + 4*2},
+		{name: "tile edges", edges: pixel.NineSliceTile, size: pixel.V(60, 40), quads: 4 + 2*4 + 2*2 + 1},
+		{name: "tile center", center: pixel.NineSliceTile, size: pixel.V(60, 40), quads: 4 + 4 + 4*2},
+		// the last tiles are cut off
+		{name: "tile partial", edges: pixel.NineSliceTile, center: pixel.NineSliceTile, size: pixel.V(45, 35), quads: 4 + 2*3 + 2*2 + 3*2},
+		// the borders are shrunk and the middle disappears
+		{name: "shrunk", size: pixel.V(10, 30), quads: 6},
+		{name: "empty", size: pixel.V(0, 0), quads: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := pixel.NewNineSliceSprite(pic, pic.Bounds(), insets)
+			s.SetModes(tt.edges, tt.center)
+			s.SetSize(tt.size)
+
+			container := &pixel.TrianglesData{}
+			s.Draw(pixel.NewBatch(container, pic), pixel.IM)
+			if got := container.Len() / 6; got != tt.quads {
+				t.Fatalf("drawn %d quads, want %d", got, tt.quads)
+			}
+
+			// the quads cover the whole size and show only the frame
+			area := 0.0
+			bounds := pixel.R(-tt.size.X/2, -tt.size.Y/2, tt.size.X/2, tt.size.Y/2)
+			for i := 0; i < container.Len(); i += 3 {
+				a, b, c := container.Position(i), container.Position(i+1), container.Position(i+2)
+				area += b.Sub(a).Cross(c.Sub(a)) / 2
+				for _, v := range []pixel.Vec{a, b, c} {
+					if !bounds.Contains(v) {
+						t.Fatalf("vertex %v outside of %v", v, bounds)
+					}
+				}
+				for _, k := range []int{i, i + 1, i + 2} {
+					if p, _ := container.Picture(k); p.X < 0 || p.X > 30 || p.Y < 0 || p.Y > 30 {
+						t.Fatalf("picture position %v outside of the frame", p)
+					}
+				}
+			}
+			if want := tt.size.X * tt.size.Y; area < want-1e-9 || area > want+1e-9 {
+				t.Errorf("covered area = %v, want %v", area, want)
+			}
+		})
+	}
+}
+
+func TestNineSliceSprite_tilePicture(t *testing.T) {
+	pic := regions()
+	s := pixel.NewNineSliceSprite(pic, pic.Bounds(), pixel.Insets{Left: 10, Bottom: 10, Right: 10, Top: 10})
+	s.SetModes(pixel.NineSliceTile, pixel.NineSliceTile)
+	s.SetSize(pixel.V(35, 30))
+
+	c := software.NewCanvas(pixel.R(0, 0, 35, 30))
+	s.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+
+	// the middle column is a whole tile and a half tile, both starting with the middle region
+	for _, x := range []float64{10.5, 19.5, 20.5, 24.5} {
+		if got, want := c.Color(pixel.V(x, 15)), regionColor(1, 1); got != want {
+			t.Errorf("Color(%v, 15) = %v, want %v", x, got, want)
+		}
+	}
+	if got, want := c.Color(pixel.V(25.5, 15)), regionColor(2, 1); got != want {
+		t.Errorf("Color(25.5, 15) = %v, want %v", got, want)
+	}
+}
+
+func TestParseNinePatch(t *testing.T) {
+	// a 7x7 nine-patch with a 5x5 image inside the marker lines
+	ninePatch := func(top, left []int) *pixel.PictureData {
+		return makePicture(0, 0, 7, 7, func(x, y int) color.RGBA {
+			for _, tx := range top {
+				if y == 6 && x == tx {
+					return color.RGBA{A: 255}
+				}
+			}
+			for _, ly := range left {
+				if x == 0 && y == ly {
+					return color.RGBA{A: 255}
+				}
+			}
+			if x == 0 || y == 0 || x == 6 || y == 6 {
+				return color.RGBA{}
+			}
+			return color.RGBA{R: 255, A: 255}
+		})
+	}
+
+	tests := []struct {
+		name    string
+		pd      *pixel.PictureData
+		bounds  pixel.Rect
+		insets  pixel.Insets
+		wantErr bool
+	}{
+		{
+			name:   "single pixels",
+			pd:     ninePatch([]int{3}, []int{2}),
+			bounds: pixel.R(0, 0, 7, 7),
+			insets: pixel.Insets{Left: 2, Bottom: 1, Right: 2, Top: 3},
+		},
+		{
+			name:   "ranges",
+			pd:     ninePatch([]int{1, 2, 3, 4}, []int{3, 4, 5}),
+			bounds: pixel.R(0, 0, 7, 7),
+			insets: pixel.Insets{Left: 0, Bottom: 2, Right: 1, Top: 0},
+		},
+		{name: "no mark", pd: ninePatch(nil, []int{2}), bounds: pixel.R(0, 0, 7, 7), wantErr: true},
+		{name: "two ranges", pd: ninePatch([]int{3}, []int{1, 3}), bounds: pixel.R(0, 0, 7, 7), wantErr: true},
+		{name: "outside", pd: ninePatch([]int{3}, []int{2}), bounds: pixel.R(0, 0, 8, 7), wantErr: true},
+		{name: "too small", pd: ninePatch([]int{3}, []int{2}), bounds: pixel.R(0, 0, 2, 7), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, insets, err := pixel.ParseNinePatch(tt.pd, tt.bounds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNinePatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := pixel.R(1, 1, 6, 6); frame != want {
+				t.Errorf("ParseNinePatch() frame = %v, want %v", frame, want)
+			}
+			if insets != tt.insets {
+				t.Errorf("ParseNinePatch() insets = %+v, want %+v", insets, tt.insets)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package pixel_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+var (
+	square   = pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10), pixel.V(0, 10)}
+	squareCW = pixel.Polygon{pixel.V(0, 0), pixel.V(0, 10), pixel.V(10, 10), pixel.V(10, 0)}
+	triangle = pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(5, 10)}
+	// an L-shape, concave at (5, 5)
+	lShape = pixel.Polygon{
+		pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 5), pixel.V(5, 5), pixel.V(5, 10), pixel.V(0, 10),
+	}
+	pentagram = pixel.Polygon{
+		pixel.V(0, 10), pixel.V(5.878, -8.09), pixel.V(-9.511, 3.09), pixel.V(9.511, 3.09), pixel.V(-5.878, -8.09),
+	}
+)
+
+func TestPolygon_String(t *testing.T) {
+	if got, want := triangle.String(), "Polygon(Vec(0, 0), Vec(10, 0), Vec(5, 10))"; got != want {
+		t.Errorf("Polygon.String() = %v, want %v", got, want)
+	}
+}
+
+func TestPolygon_Area(t *testing.T) {
+	tests := []struct {
+		name       string
+		p          pixel.Polygon
+		signedArea float64
+		clockwise  bool
+	}{
+		{name: "empty", p: pixel.Polygon{}, signedArea: 0},
+		{name: "line", p: pixel.Polygon{pixel.V(0, 0), pixel.V(5, 5)}, signedArea: 0},
+		{name: "square", p: square, signedArea: 100},
+		{name: "clockwise square", p: squareCW, signedArea: -100, clockwise: true},
+		{name: "triangle", p: triangle, signedArea: 50},
+		{name: "L-shape", p: lShape, signedArea: 75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.SignedArea(); got != tt.signedArea {
+				t.Errorf("Polygon.SignedArea() = %v, want %v", got, tt.signedArea)
+			}
+			if got := tt.p.Area(); got != math.Abs(tt.signedArea) {
+				t.Errorf("Polygon.Area() = %v, want %v", got, math.Abs(tt.signedArea))
+			}
+			if got := tt.p.IsClockwise(); got != tt.clockwise {
+				t.Errorf("Polygon.IsClockwise() = %v, want %v", got, tt.clockwise)
+			}
+			if got := tt.p.Reversed().SignedArea(); got != -tt.signedArea {
+				t.Errorf("Polygon.Reversed().SignedArea() = %v, want %v", got, -tt.signedArea)
+			}
+		})
+	}
+}
+
+func TestPolygon_Centroid(t *testing.T) {
+	tests := []struct {
+		name string
+		p    pixel.Polygon
+		want pixel.Vec
+	}{
+		{name: "empty", p: pixel.Polygon{}, want: pixel.ZV},
+		{name: "line", p: pixel.Polygon{pixel.V(0, 0), pixel.V(4, 2)}, want: pixel.V(2, 1)},
+		{name: "square", p: square, want: pixel.V(5, 5)},
+		{name: "clockwise square", p: squareCW, want: pixel.V(5, 5)},
+		{name: "moved square", p: square.Moved(pixel.V(1000, -1000)), want: pixel.V(1005, -995)},
+		{name: "triangle", p: triangle, want: pixel.V(5, 10.0/3)},
+		// a 10x5 rectangle with centroid (5, 2.5) and a 5x5 square with centroid (2.5, 7.5)
+		{name: "L-shape", p: lShape, want: pixel.V(12.5/3, 12.5/3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Centroid(); !got.Eq(tt.want) {
+				t.Errorf("Polygon.Centroid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygon_Bounds(t *testing.T) {
+	if got, want := lShape.Moved(pixel.V(-5, 2)).Bounds(), pixel.R(-5, 2, 5, 12); got != want {
+		t.Errorf("Polygon.Bounds() = %v, want %v", got, want)
+	}
+	if got, want := (pixel.Polygon{}).Bounds(), pixel.ZR; got != want {
+		t.Errorf("Polygon.Bounds() = %v, want %v", got, want)
+	}
+}
+
+func TestPolygon_Contains(t *testing.T) {
+	tests := []struct {
+		name string
+		p    pixel.Polygon
+		u    pixel.Vec
+		want bool
+	}{
+		{name: "inside square", p: square, u: pixel.V(5, 5), want: true},
+		{name: "outside square", p: square, u: pixel.V(15, 5), want: false},
+		{name: "on edge", p: square, u: pixel.V(10, 5), want: true},
+		{name: "on vertex", p: square, u: pixel.V(10, 10), want: true},
+		{name: "clockwise", p: squareCW, u: pixel.V(5, 5), want: true},
+		{name: "inside triangle", p: triangle, u: pixel.V(5, 9), want: true},
+		{name: "outside triangle", p: triangle, u: pixel.V(1, 9), want: false},
+		{name: "inside L-shape", p: lShape, u: pixel.V(2, 8), want: true},
+		{name: "in L-shape notch", p: lShape, u: pixel.V(8, 8), want: false},
+		{name: "level with vertex", p: lShape, u: pixel.V(-1, 5), want: false},
+		{name: "pentagram center", p: pentagram, u: pixel.ZV, want: false},
+		{name: "pentagram tip", p: pentagram, u: pixel.V(0, 8), want: true},
+		{name: "empty", p: pixel.Polygon{}, u: pixel.ZV, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Contains(tt.u); got != tt.want {
+				t.Errorf("Polygon.Contains(%v) = %v, want %v", tt.u, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygon_IsConvex(t *testing.T) {
+	tests := []struct {
+		name string
+		p    pixel.Polygon
+		want bool
+	}{
+		{name: "square", p: square, want: true},
+		{name: "clockwise square", p: squareCW, want: true},
+		{name: "triangle", p: triangle, want: true},
+		{name: "collinear vertex", p: pixel.Polygon{pixel.V(0, 0), pixel.V(5, 0), pixel.V(10, 0), pixel.V(5, 10)}, want: true},
+		{name: "L-shape", p: lShape, want: false},
+		{name: "pentagram", p: pentagram, want: false},
+		{name: "line", p: pixel.Polygon{pixel.V(0, 0), pixel.V(5, 5), pixel.V(10, 10)}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.IsConvex(); got != tt.want {
+				t.Errorf("Polygon.IsConvex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygon_Edges(t *testing.T) {
+	want := []pixel.Line{
+		pixel.L(pixel.V(0, 0), pixel.V(10, 0)),
+		pixel.L(pixel.V(10, 0), pixel.V(5, 10)),
+		pixel.L(pixel.V(5, 10), pixel.V(0, 0)),
+	}
+	if got := triangle.Edges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Polygon.Edges() = %v, want %v", got, want)
+	}
+}
+
+func TestPolygon_Transformed(t *testing.T) {
+	got := square.Transformed(pixel.IM.Scaled(pixel.ZV, 2).Moved(pixel.V(1, 1)))
+	want := pixel.Polygon{pixel.V(1, 1), pixel.V(21, 1), pixel.V(21, 21), pixel.V(1, 21)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Polygon.Transformed() = %v, want %v", got, want)
+	}
+
+	if got := square.Transformed(pixel.IM.ScaledXY(pixel.ZV, pixel.V(-1, 1))); !got.IsClockwise() {
+		t.Errorf("mirrored Polygon should be clockwise: %v", got)
+	}
+}
+
+func TestPolygon_IntersectionPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		p    pixel.Polygon
+		l    pixel.Line
+		want []pixel.Vec
+	}{
+		{
+			name: "No intersection points",
+			p:    square,
+			l:    pixel.L(pixel.V(-5, 0), pixel.V(-2, 2)),
+			want: []pixel.Vec{},
+		},
+		{
+			name: "One intersection point",
+			p:    square,
+			l:    pixel.L(pixel.V(2, 2), pixel.V(2, 13)),
+			want: []pixel.Vec{pixel.V(2, 10)},
+		},
+		{
+			name: "Two intersection points",
+			p:    square,
+			l:    pixel.L(pixel.V(12, 2), pixel.V(-3, 2)),
+			want: []pixel.Vec{pixel.V(10, 2), pixel.V(0, 2)},
+		},
+		{
+			name: "Through a vertex",
+			p:    triangle,
+			l:    pixel.L(pixel.V(5, 12), pixel.V(5, -2)),
+			want: []pixel.Vec{pixel.V(5, 10), pixel.V(5, 0)},
+		},
+		{
+			name: "Concave",
+			p:    lShape,
+			l:    pixel.L(pixel.V(-1, 8), pixel.V(11, 8)),
+			want: []pixel.Vec{pixel.V(0, 8), pixel.V(5, 8)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.IntersectionPoints(tt.l); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Polygon.IntersectionPoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygon_IntersectionPointsRect(t *testing.T) {
+	got := triangle.IntersectionPointsRect(pixel.R(2, -5, 8, 5))
+	want := []pixel.Vec{pixel.V(2, 0), pixel.V(8, 0), pixel.V(8, 4), pixel.V(7.5, 5), pixel.V(2.5, 5), pixel.V(2, 4)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Polygon.IntersectionPointsRect() = %v, want %v", got, want)
+	}
+
+	if got := triangle.IntersectionPointsRect(pixel.R(20, 20, 30, 30)); len(got) != 0 {
+		t.Errorf("Polygon.IntersectionPointsRect() = %v, want none", got)
+	}
+}
+
+func TestPolygon_IntersectionPointsCircle(t *testing.T) {
+	got := square.IntersectionPointsCircle(pixel.C(pixel.V(10, 5), 2))
+	want := []pixel.Vec{pixel.V(10, 3), pixel.V(10, 7)}
+	if len(got) != len(want) {
+		t.Fatalf("Polygon.IntersectionPointsCircle() = %v, want %v", got, want)
+	}
+	for i := range got {
+		if !got[i].Eq(want[i]) {
+			t.Errorf("Polygon.IntersectionPointsCircle() = %v, want %v", got, want)
+		}
+	}
+
+	if got := square.IntersectionPointsCircle(pixel.C(pixel.V(5, 5), 1)); len(got) != 0 {
+		t.Errorf("Polygon.IntersectionPointsCircle() = %v, want none", got)
+	}
+}
@@ -0,0 +1,208 @@
+package pixel_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestRay_Cast(t *testing.T) {
+	right := pixel.Ray{Origin: pixel.V(0, 0), Direction: pixel.V(2, 0)}
+
+	tests := []struct {
+		name  string
+		ray   pixel.Ray
+		shape pixel.RayCaster
+		want  pixel.RayHit
+		hit   bool
+	}{
+		{
+			name:  "line",
+			ray:   right,
+			shape: pixel.L(pixel.V(5, -1), pixel.V(5, 1)),
+			want:  pixel.RayHit{Point: pixel.V(5, 0), Normal: pixel.V(-1, 0), Dist: 5, Fraction: 2.5},
+			hit:   true,
+		},
+		{
+			name:  "line behind",
+			ray:   right,
+			shape: pixel.L(pixel.V(-5, -1), pixel.V(-5, 1)),
+		},
+		{
+			name:  "line too far",
+			ray:   pixel.Ray{Origin: pixel.ZV, Direction: pixel.V(1, 0), MaxLen: 4},
+			shape: pixel.L(pixel.V(5, -1), pixel.V(5, 1)),
+		},
+		{
+			name:  "line missed",
+			ray:   right,
+			shape: pixel.L(pixel.V(5, 1), pixel.V(5, 3)),
+		},
+		{
+			name:  "line parallel",
+			ray:   right,
+			shape: pixel.L(pixel.V(0, 1), pixel.V(5, 1)),
+		},
+		{
+			name:  "line collinear",
+			ray:   right,
+			shape: pixel.L(pixel.V(8, 0), pixel.V(3, 0)),
+			want:  pixel.RayHit{Point: pixel.V(3, 0), Normal: pixel.V(-1, 0), Dist: 3, Fraction: 1.5},
+			hit:   true,
+		},
+		{
+			name:  "rect",
+			ray:   pixel.Ray{Origin: pixel.V(0, 0), Direction: pixel.V(1, 1)},
+			shape: pixel.R(2, 1, 5, 5),
+			want:  pixel.RayHit{Point: pixel.V(2, 2), Normal: pixel.V(-1, 0), Dist: 2 * math.Sqrt2, Fraction: 2},
+			hit:   true,
+		},
+		{
+			name:  "rect from above",
+			ray:   pixel.Ray{Origin: pixel.V(3, 10), Direction: pixel.V(0, -1)},
+			shape: pixel.R(2, 1, 5, 5),
+			want:  pixel.RayHit{Point: pixel.V(3, 5), Normal: pixel.V(0, 1), Dist: 5, Fraction: 5},
+			hit:   true,
+		},
+		{
+			name:  "rect missed",
+			ray:   right,
+			shape: pixel.R(2, 1, 5, 5),
+		},
+		{
+			name:  "inside rect",
+			ray:   right,
+			shape: pixel.R(-1, -1, 1, 1),
+			want:  pixel.RayHit{Point: pixel.V(0, 0), Normal: pixel.V(-1, 0)},
+			hit:   true,
+		},
+		{
+			name:  "circle",
+			ray:   right,
+			shape: pixel.C(pixel.V(10, 0), 2),
+			want:  pixel.RayHit{Point: pixel.V(8, 0), Normal: pixel.V(-1, 0), Dist: 8, Fraction: 4},
+			hit:   true,
+		},
+		{
+			name:  "circle tangent",
+			ray:   right,
+			shape: pixel.C(pixel.V(10, 2), 2),
+			want:  pixel.RayHit{Point: pixel.V(10, 0), Normal: pixel.V(0, -1), Dist: 10, Fraction: 5},
+			hit:   true,
+		},
+		{
+			name:  "circle behind",
+			ray:   right,
+			shape: pixel.C(pixel.V(-10, 0), 2),
+		},
+		{
+			name:  "polygon",
+			ray:   right,
+			shape: pixel.Polygon{pixel.V(4, -4), pixel.V(8, 0), pixel.V(4, 4)},
+			want:  pixel.RayHit{Point: pixel.V(4, 0), Normal: pixel.V(-1, 0), Dist: 4, Fraction: 2},
+			hit:   true,
+		},
+		{
+			name:  "polygon slanted edge",
+			ray:   pixel.Ray{Origin: pixel.V(12, 2), Direction: pixel.V(-1, 0)},
+			shape: pixel.Polygon{pixel.V(4, -4), pixel.V(8, 0), pixel.V(4, 4)},
+			want:  pixel.RayHit{Point: pixel.V(6, 2), Normal: pixel.V(1, 1).Unit(), Dist: 6, Fraction: 6},
+			hit:   true,
+		},
+		{
+			name:  "zero direction",
+			ray:   pixel.Ray{Origin: pixel.V(0, 0)},
+			shape: pixel.C(pixel.V(10, 0), 2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.shape.CastRay(tt.ray)
+			if ok != tt.hit {
+				t.Fatalf("CastRay() hit = %v, want %v", ok, tt.hit)
+			}
+			if !ok {
+				return
+			}
+			if !got.Point.Eq(tt.want.Point) || !got.Normal.Eq(tt.want.Normal) ||
+				math.Abs(got.Dist-tt.want.Dist) > 1e-9 || math.Abs(got.Fraction-tt.want.Fraction) > 1e-9 {
+				t.Errorf("CastRay() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRay_CastAll(t *testing.T) {
+	ray := pixel.Ray{Origin: pixel.V(0, 0), Direction: pixel.V(1, 0), MaxLen: 25}
+	shapes := []pixel.RayCaster{
+		pixel.C(pixel.V(20, 0), 1),
+		pixel.R(5, -1, 6, 1),
+		pixel.C(pixel.V(30, 0), 1),
+		pixel.L(pixel.V(10, 5), pixel.V(10, -5)),
+		pixel.R(5, 3, 6, 4),
+	}
+
+	hits := ray.CastAll(shapes...)
+	wantIndices := []int{1, 3, 0}
+	if len(hits) != len(wantIndices) {
+		t.Fatalf("CastAll() = %v, want shapes %v", hits, wantIndices)
+	}
+	for i, hit := range hits {
+		if hit.Index != wantIndices[i] {
+			t.Errorf("CastAll()[%d] hit shape %d, want %d", i, hit.Index, wantIndices[i])
+		}
+	}
+
+	first, ok := ray.Cast(shapes...)
+	if !ok || first.Index != 1 || !first.Point.Eq(pixel.V(5, 0)) {
+		t.Errorf("Cast() = %+v, %v, want shape 1 at %v", first, ok, pixel.V(5, 0))
+	}
+	if _, ok := ray.Cast(); ok {
+		t.Errorf("Cast() of no shapes hit something")
+	}
+}
+
+func TestVisibilityPolygon(t *testing.T) {
+	bounds := pixel.R(0, 0, 10, 10)
+
+	// nothing blocks the view
+	p := pixel.VisibilityPolygon(pixel.V(5, 5), nil, bounds)
+	if math.Abs(p.Area()-100) > 1e-3 || p.IsClockwise() {
+		t.Errorf("VisibilityPolygon() = %v with area %v, want the bounds", p, p.Area())
+	}
+
+	// a wall hides a part of the room
+	wall := pixel.L(pixel.V(7, 2), pixel.V(7, 8))
+	p = pixel.VisibilityPolygon(pixel.V(5, 5), []pixel.Line{wall}, bounds)
+	if p.IsClockwise() {
+		t.Errorf("VisibilityPolygon() = %v is clockwise", p)
+	}
+	// the shadow of the wall covers the right part of the room behind it, except for two triangles
+	// in the corners, which are 2 high and 4/3 wide
+	if want := 100 - 3*10 + 2*(2*4.0/3/2); math.Abs(p.Area()-want) > 1e-3 {
+		t.Errorf("VisibilityPolygon() area = %v, want %v", p.Area(), want)
+	}
+	for _, u := range []pixel.Vec{pixel.V(1, 1), pixel.V(6, 5), pixel.V(7.5, 0.5)} {
+		if !p.Contains(u) {
+			t.Errorf("VisibilityPolygon() doesn't contain visible point %v", u)
+		}
+	}
+	for _, u := range []pixel.Vec{pixel.V(8, 5), pixel.V(9.5, 7)} {
+		if p.Contains(u) {
+			t.Errorf("VisibilityPolygon() contains hidden point %v", u)
+		}
+	}
+}
+
+func BenchmarkVisibilityPolygon(b *testing.B) {
+	var walls []pixel.Line
+	for i := 0; i < 20; i++ {
+		c := pixel.V(float64(i%5)*20+10, float64(i/5)*20+10)
+		walls = append(walls, pixel.L(c, c.Add(pixel.V(5, 3))))
+	}
+	for i := 0; i < b.N; i++ {
+		pixel.VisibilityPolygon(pixel.V(1, 1), walls, pixel.R(0, 0, 100, 100))
+	}
+}
@@ -0,0 +1,254 @@
+package pixel_test
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// makePicture creates a w x h PictureData with the bottom-left pixel at (x0, y0), colored by the
+// function.
+func makePicture(x0, y0, w, h int, col func(x, y int) color.RGBA) *pixel.PictureData {
+	pd := pixel.MakePictureData(pixel.R(float64(x0), float64(y0), float64(x0+w), float64(y0+h)))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pd.Pix[y*pd.Stride+x] = col(x, y)
+		}
+	}
+	return pd
+}
+
+// numbered returns a picture with each pixel having a unique red and green component.
+func numbered(w, h int) *pixel.PictureData {
+	return makePicture(10, 20, w, h, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x), G: uint8(y), A: 255}
+	})
+}
+
+func TestPictureData_Cropped(t *testing.T) {
+	pd := numbered(8, 6)
+
+	tests := []struct {
+		name   string
+		rect   pixel.Rect
+		bounds pixel.Rect
+	}{
+		{name: "inside", rect: pixel.R(12, 21, 15, 25), bounds: pixel.R(12, 21, 15, 25)},
+		{name: "partial pixels", rect: pixel.R(12.5, 21.5, 14.5, 22.5), bounds: pixel.R(12, 21, 15, 23)},
+		{name: "overlapping", rect: pixel.R(0, 24, 11, 100), bounds: pixel.R(10, 24, 11, 26)},
+		{name: "outside", rect: pixel.R(100, 100, 200, 200), bounds: pixel.ZR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pd.Cropped(tt.rect)
+			if got.Bounds() != tt.bounds {
+				t.Fatalf("Cropped() bounds = %v, want %v", got.Bounds(), tt.bounds)
+			}
+			for y := tt.bounds.Min.Y; y < tt.bounds.Max.Y; y++ {
+				for x := tt.bounds.Min.X; x < tt.bounds.Max.X; x++ {
+					at := pixel.V(x, y)
+					if got.Color(at) != pd.Color(at) {
+						t.Fatalf("Cropped() color at %v = %v, want %v", at, got.Color(at), pd.Color(at))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPictureData_Rotated90(t *testing.T) {
+	pd := numbered(3, 2)
+	// the pixels of the rotated pictures, top row first, as x and y of the original pixels
+	tests := []struct {
+		turns int
+		want  [][][2]uint8
+	}{
+		{turns: 0, want: [][][2]uint8{{{0, 1}, {1, 1}, {2, 1}}, {{0, 0}, {1, 0}, {2, 0}}}},
+		{turns: 1, want: [][][2]uint8{{{2, 1}, {2, 0}}, {{1, 1}, {1, 0}}, {{0, 1}, {0, 0}}}},
+		{turns: 2, want: [][][2]uint8{{{2, 0}, {1, 0}, {0, 0}}, {{2, 1}, {1, 1}, {0, 1}}}},
+		{turns: -1, want: [][][2]uint8{{{0, 0}, {0, 1}}, {{1, 0}, {1, 1}}, {{2, 0}, {2, 1}}}},
+		{turns: 7, want: [][][2]uint8{{{0, 0}, {0, 1}}, {{1, 0}, {1, 1}}, {{2, 0}, {2, 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.turns), func(t *testing.T) {
+			got := pd.Rotated90(tt.turns)
+			h, w := len(tt.want), len(tt.want[0])
+			if want := pixel.R(10, 20, float64(10+w), float64(20+h)); got.Bounds() != want {
+				t.Fatalf("Rotated90() bounds = %v, want %v", got.Bounds(), want)
+			}
+			for row, pixels := range tt.want {
+				for x, p := range pixels {
+					c := got.Pix[(h-1-row)*got.Stride+x]
+					if c.R != p[0] || c.G != p[1] {
+						t.Errorf("Rotated90() pixel (%d, %d) = %v, want original (%d, %d)", x, h-1-row, c, p[0], p[1])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPictureData_Flipped(t *testing.T) {
+	pd := numbered(3, 2)
+	fx, fy := pd.FlippedX(), pd.FlippedY()
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			if c := fx.Pix[y*3+x]; int(c.R) != 2-x || int(c.G) != y {
+				t.Errorf("FlippedX() pixel (%d, %d) = %v", x, y, c)
+			}
+			if c := fy.Pix[y*3+x]; int(c.R) != x || int(c.G) != 1-y {
+				t.Errorf("FlippedY() pixel (%d, %d) = %v", x, y, c)
+			}
+		}
+	}
+	if fx.Bounds() != pd.Bounds() || fy.Bounds() != pd.Bounds() {
+		t.Errorf("Flipped bounds = %v, %v, want %v", fx.Bounds(), fy.Bounds(), pd.Bounds())
+	}
+}
+
+func TestPictureData_Resized(t *testing.T) {
+	gray := color.RGBA{R: 100, G: 100, B: 100, A: 255}
+	solid := makePicture(0, 0, 10, 6, func(x, y int) color.RGBA { return gray })
+
+	filters := []pixel.ResampleFilter{
+		pixel.ResampleNearest,
+		pixel.ResampleBilinear,
+		pixel.ResampleBicubic,
+		pixel.ResampleLanczos,
+	}
+	for _, filter := range filters {
+		t.Run(filter.String(), func(t *testing.T) {
+			for _, size := range [][2]int{{20, 12}, {5, 3}, {7, 13}, {1, 1}} {
+				got := solid.Resized(size[0], size[1], filter)
+				if want := pixel.R(0, 0, float64(size[0]), float64(size[1])); got.Bounds() != want {
+					t.Fatalf("Resized() bounds = %v, want %v", got.Bounds(), want)
+				}
+				// a solid color stays the same
+				for i, c := range got.Pix {
+					if c != gray {
+						t.Fatalf("Resized(%v) pixel %d = %v, want %v", size, i, c, gray)
+					}
+				}
+			}
+
+			// resizing to an empty size gives an empty picture
+			for _, size := range [][2]int{{0, 3}, {4, 0}, {0, 0}} {
+				if got, want := solid.Resized(size[0], size[1], filter).Bounds(), pixel.R(0, 0, float64(size[0]), float64(size[1])); got != want {
+					t.Errorf("Resized(%v) bounds = %v, want %v", size, got, want)
+				}
+			}
+
+			// an empty picture becomes transparent
+			for _, empty := range []*pixel.PictureData{
+				pixel.MakePictureData(pixel.R(0, 0, 0, 6)),
+				pixel.MakePictureData(pixel.R(0, 0, 10, 0)),
+			} {
+				got := empty.Resized(4, 3, filter)
+				if want := pixel.R(0, 0, 4, 3); got.Bounds() != want {
+					t.Fatalf("Resized() of %v bounds = %v, want %v", empty.Bounds(), got.Bounds(), want)
+				}
+				for i, c := range got.Pix {
+					if c != (color.RGBA{}) {
+						t.Fatalf("Resized() of %v pixel %d = %v, want transparent", empty.Bounds(), i, c)
+					}
+				}
+			}
+		})
+	}
+
+	// upscaling pixel art with nearest neighbour duplicates the pixels
+	pd := numbered(2, 2)
+	big := pd.Resized(4, 4, pixel.ResampleNearest)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if c := big.Pix[y*4+x]; int(c.R) != x/2 || int(c.G) != y/2 {
+				t.Errorf("Resized() nearest pixel (%d, %d) = %v", x, y, c)
+			}
+		}
+	}
+
+	// bilinear downscaling smooths the pixels, instead of picking some of them
+	checker := makePicture(0, 0, 4, 4, func(x, y int) color.RGBA {
+		if (x+y)%2 == 0 {
+			return color.RGBA{R: 200, G: 200, B: 200, A: 255}
+		}
+		return color.RGBA{A: 255}
+	})
+	small := checker.Resized(2, 2, pixel.ResampleBilinear)
+	for i, c := range small.Pix {
+		if c.R < 90 || c.R > 110 || c.A != 255 {
+			t.Errorf("Resized() bilinear pixel %d = %v, want gray around 100", i, c)
+		}
+	}
+}
+
+func TestPictureData_Rotated(t *testing.T) {
+	pd := numbered(6, 4)
+
+	// rotating by a quarter turn gives the same pixels as Rotated90
+	got, want := pd.Rotated(math.Pi/2, pixel.ResampleBilinear), pd.Rotated90(1)
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("Rotated() bounds = %v, want %v", got.Bounds(), want.Bounds())
+	}
+	for i := range want.Pix {
+		if got.Pix[i] != want.Pix[i] {
+			t.Fatalf("Rotated() pixel %d = %v, want %v", i, got.Pix[i], want.Pix[i])
+		}
+	}
+
+	// the result fits the rotated picture, the corners are transparent
+	white := makePicture(0, 0, 10, 10, func(x, y int) color.RGBA { return color.RGBA{255, 255, 255, 255} })
+	diag := white.Rotated(math.Pi/4, pixel.ResampleBicubic)
+	size := math.Ceil(10 * math.Sqrt2)
+	if want := pixel.R(0, 0, size, size); diag.Bounds() != want {
+		t.Fatalf("Rotated() bounds = %v, want %v", diag.Bounds(), want)
+	}
+	if c := diag.Pix[0]; c.A != 0 {
+		t.Errorf("Rotated() corner = %v, want transparent", c)
+	}
+	if c := diag.Color(diag.Bounds().Center()); c.A < 0.99 {
+		t.Errorf("Rotated() center = %v, want opaque", c)
+	}
+}
+
+func BenchmarkPictureData_Resized(b *testing.B) {
+	pd := makePicture(0, 0, 256, 256, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255}
+	})
+	for _, filter := range []pixel.ResampleFilter{
+		pixel.ResampleNearest,
+		pixel.ResampleBilinear,
+		pixel.ResampleBicubic,
+		pixel.ResampleLanczos,
+	} {
+		b.Run(filter.String()+" up", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pd.Resized(512, 512, filter)
+			}
+		})
+		b.Run(filter.String()+" down", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pd.Resized(100, 100, filter)
+			}
+		})
+	}
+}
+
+func BenchmarkPictureData_Rotated(b *testing.B) {
+	pd := makePicture(0, 0, 256, 256, func(x, y int) color.RGBA {
+		return color.RGBA{R: uint8(x), G: uint8(y), A: 255}
+	})
+	b.Run("Rotated90", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.Rotated90(1)
+		}
+	})
+	b.Run("Rotated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			pd.Rotated(0.3, pixel.ResampleBilinear)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package pixel_test
+
+import (
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+)
+
+func TestCanvas_Screenshot(t *testing.T) {
+	win, err := opengl.NewWindow(opengl.WindowConfig{
+		Title:     "testing",
+		Bounds:    pixel.R(0, 0, 16, 16),
+		Invisible: true,
+	})
+	if err != nil {
+		t.Fatalf("Could not create window: %v", err)
+	}
+	defer win.Destroy()
+
+	red := pixel.RGB(1, 0, 0)
+	canvas := opengl.NewCanvas(pixel.R(10, 20, 14, 24))
+	canvas.Clear(pixel.RGB(0, 0, 1))
+
+	// a red bottom-left quadrant
+	imd := imdraw.New(nil)
+	imd.Color = red
+	imd.Push(pixel.V(10, 20), pixel.V(12, 22))
+	imd.Rectangle(0)
+	imd.Draw(canvas)
+
+	pd := canvas.Screenshot()
+	if pd.Bounds() != canvas.Bounds() {
+		t.Fatalf("Screenshot() bounds = %v, want %v", pd.Bounds(), canvas.Bounds())
+	}
+	if c := pd.Color(pixel.V(10.5, 20.5)); c != red {
+		t.Errorf("Screenshot() bottom-left = %v, want %v", c, red)
+	}
+	if c := pd.Color(pixel.V(13.5, 23.5)); c != pixel.RGB(0, 0, 1) {
+		t.Errorf("Screenshot() top-right = %v, want blue", c)
+	}
+
+	// the image of the screenshot is top-down
+	img := pd.Image()
+	if r, _, _, _ := img.At(10, 23).RGBA(); r != 0xffff {
+		t.Errorf("Screenshot().Image() bottom-left = %v, want red", img.At(10, 23))
+	}
+
+	win.Clear(red)
+	if c := win.Screenshot().Color(pixel.V(8, 8)); c != red {
+		t.Errorf("Window.Screenshot() = %v, want %v", c, red)
+	}
+}
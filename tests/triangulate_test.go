@@ -0,0 +1,176 @@
+package pixel_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestTriangulate(t *testing.T) {
+	rect := func(x0, y0, x1, y1 float64) pixel.Polygon {
+		return pixel.Polygon{pixel.V(x0, y0), pixel.V(x1, y0), pixel.V(x1, y1), pixel.V(x0, y1)}
+	}
+	comb := pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10)}
+	for x := 9.0; x > 0; x -= 2 {
+		comb = append(comb, pixel.V(x, 2), pixel.V(x-1, 10))
+	}
+
+	tests := []struct {
+		name    string
+		outline pixel.Polygon
+		holes   []pixel.Polygon
+	}{
+		{name: "triangle", outline: pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(5, 10)}},
+		{name: "square", outline: rect(0, 0, 10, 10)},
+		{name: "clockwise square", outline: rect(0, 0, 10, 10).Reversed()},
+		{name: "L-shape", outline: pixel.Polygon{
+			pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 5), pixel.V(5, 5), pixel.V(5, 10), pixel.V(0, 10),
+		}},
+		{name: "collinear vertices", outline: pixel.Polygon{
+			pixel.V(0, 0), pixel.V(5, 0), pixel.V(10, 0), pixel.V(10, 5), pixel.V(10, 10), pixel.V(0, 10),
+		}},
+		{name: "comb", outline: comb},
+		{name: "star", outline: star(5, 10, 4)},
+		{name: "square with hole", outline: rect(0, 0, 10, 10), holes: []pixel.Polygon{rect(4, 4, 6, 6)}},
+		{
+			name:    "clockwise square with clockwise hole",
+			outline: rect(0, 0, 10, 10).Reversed(),
+			holes:   []pixel.Polygon{rect(4, 4, 6, 6).Reversed()},
+		},
+		{
+			name:    "square with two holes",
+			outline: rect(0, 0, 20, 10),
+			holes:   []pixel.Polygon{rect(2, 2, 8, 8), rect(12, 2, 18, 8)},
+		},
+		{
+			name:    "square with holes in a row",
+			outline: rect(0, 0, 30, 10),
+			holes:   []pixel.Polygon{rect(2, 4, 4, 6), rect(12, 4, 14, 6), rect(22, 4, 24, 6)},
+		},
+		{
+			name:    "comb with hole",
+			outline: comb,
+			holes:   []pixel.Polygon{rect(1, 0.5, 9, 1.5)},
+		},
+		{
+			name:    "star with star hole",
+			outline: star(8, 10, 6),
+			holes:   []pixel.Polygon{star(5, 3, 1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkTriangulation(t, tt.outline, tt.holes)
+		})
+	}
+}
+
+func TestTriangulate_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		// random star-shaped polygons are always simple
+		n := 3 + rnd.Intn(30)
+		outline := make(pixel.Polygon, n)
+		for j := range outline {
+			outline[j] = pixel.Unit(2 * math.Pi * float64(j) / float64(n)).Scaled(10 + 10*rnd.Float64())
+		}
+		checkTriangulation(t, outline, []pixel.Polygon{star(3+rnd.Intn(5), 5, 2+rnd.Float64()*2)})
+	}
+}
+
+func TestTriangulate_Degenerate(t *testing.T) {
+	if got := pixel.Triangulate(pixel.Polygon{pixel.V(0, 0), pixel.V(1, 1)}); len(got) != 0 {
+		t.Errorf("Triangulate() of a line = %v, want none", got)
+	}
+	if got := pixel.Triangulate(pixel.Polygon{pixel.V(0, 0), pixel.V(1, 1), pixel.V(2, 2)}); len(got) != 0 {
+		t.Errorf("Triangulate() of collinear points = %v, want none", got)
+	}
+}
+
+func TestPolygon_TrianglesData(t *testing.T) {
+	outline := pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10), pixel.V(0, 10)}
+	hole := pixel.Polygon{pixel.V(4, 4), pixel.V(6, 4), pixel.V(6, 6), pixel.V(4, 6)}
+
+	td := outline.TrianglesData(hole)
+	if td.Len()%3 != 0 {
+		t.Fatalf("TrianglesData().Len() = %v, not a multiple of 3", td.Len())
+	}
+	area := 0.0
+	for i := 0; i < td.Len(); i += 3 {
+		area += pixel.Polygon{(*td)[i].Position, (*td)[i+1].Position, (*td)[i+2].Position}.SignedArea()
+		if (*td)[i].Color != pixel.Alpha(1) {
+			t.Errorf("vertex %d has color %v, want %v", i, (*td)[i].Color, pixel.Alpha(1))
+		}
+	}
+	if area != 96 {
+		t.Errorf("area of triangles = %v, want %v", area, 96)
+	}
+}
+
+// star returns a star-shaped polygon with n points around the origin.
+func star(n int, outer, inner float64) pixel.Polygon {
+	p := make(pixel.Polygon, 0, 2*n)
+	for i := 0; i < 2*n; i++ {
+		r := outer
+		if i%2 == 1 {
+			r = inner
+		}
+		p = append(p, pixel.Unit(math.Pi*float64(i)/float64(n)).Scaled(r))
+	}
+	return p
+}
+
+// checkTriangulation checks that the triangles are counter-clockwise, lie inside of the outline and
+// outside of the holes and cover the whole area.
+func checkTriangulation(t *testing.T, outline pixel.Polygon, holes []pixel.Polygon) {
+	t.Helper()
+
+	vertices := append(pixel.Polygon{}, outline...)
+	wantArea := outline.Area()
+	for _, hole := range holes {
+		vertices = append(vertices, hole...)
+		wantArea -= hole.Area()
+	}
+
+	indices := pixel.Triangulate(outline, holes...)
+	if len(indices)%3 != 0 {
+		t.Fatalf("Triangulate() returned %d indices, not a multiple of 3", len(indices))
+	}
+
+	area := 0.0
+	for i := 0; i < len(indices); i += 3 {
+		tri := pixel.Polygon{vertices[indices[i]], vertices[indices[i+1]], vertices[indices[i+2]]}
+		if tri.SignedArea() <= 0 {
+			t.Errorf("triangle %v is not counter-clockwise", tri)
+		}
+		area += tri.Area()
+
+		c := tri.Centroid()
+		if !outline.Contains(c) {
+			t.Errorf("triangle %v lies outside of the outline", tri)
+		}
+		for _, hole := range holes {
+			if hole.Contains(c) {
+				t.Errorf("triangle %v lies inside of a hole", tri)
+			}
+		}
+	}
+
+	if math.Abs(area-wantArea) > 1e-9*wantArea {
+		t.Errorf("area of triangles = %v, want %v", area, wantArea)
+	}
+}
+
+func BenchmarkTriangulate(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		outline := star(n/2, 100, 50)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pixel.Triangulate(outline)
+			}
+		})
+	}
+}
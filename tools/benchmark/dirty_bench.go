@@ -0,0 +1,85 @@
+package benchmark
+
+import (
+	"math"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+)
+
+// dirtySpriteCells is the number of rows and columns of the sprites, 10000 sprites in total
+const dirtySpriteCells = 100
+
+func init() {
+	Benchmarks.Add(
+		Config{
+			Name:        "sprite-static-dirty",
+			Description: "Grid of batched sprites with one sprite moving, updating the whole batch",
+			New:         newSpriteStaticDirty(false),
+			Duration:    30 * time.Second,
+		},
+		Config{
+			Name:        "sprite-static-dirty-range",
+			Description: "Grid of batched sprites with one sprite moving, updating only the moved sprite",
+			New:         newSpriteStaticDirty(true),
+			Duration:    30 * time.Second,
+		},
+	)
+}
+
+func newSpriteStaticDirty(ranged bool) func(win *opengl.Window) (Benchmark, error) {
+	return func(win *opengl.Window) (Benchmark, error) {
+		sprite, err := loadSprite(logoPath, logoFrame)
+		if err != nil {
+			return nil, err
+		}
+
+		bounds := win.Bounds()
+		cell := gridCell(bounds.W(), bounds.H(), dirtySpriteCells, dirtySpriteCells)
+		sd := &spriteStaticDirty{
+			container: &pixel.TrianglesData{},
+			cell:      cell,
+			ranged:    ranged,
+		}
+		// the sprites are drawn onto the Batch only once, then their vertices are moved directly
+		sd.batch = pixel.NewBatch(sd.container, sprite.Picture())
+		spriteGrid(sprite, sd.batch, dirtySpriteCells, dirtySpriteCells, cell)
+		sd.spriteLen = sd.container.Len() / (dirtySpriteCells * dirtySpriteCells)
+		return sd, nil
+	}
+}
+
+type spriteStaticDirty struct {
+	batch     *pixel.Batch
+	container *pixel.TrianglesData
+	spriteLen int
+	cell      pixel.Vec
+	ranged    bool
+
+	sprite int
+	time   float64
+}
+
+func (sd *spriteStaticDirty) Step(win *opengl.Window, delta float64) {
+	win.Clear(backgroundColor)
+
+	// a different sprite bounces every second, the previous one stays where it stopped
+	sd.time += delta
+	if sd.time >= 1 {
+		sd.time = 0
+		sd.sprite = (sd.sprite + 1) % (dirtySpriteCells * dirtySpriteCells)
+	}
+	dy := math.Sin(sd.time*2*math.Pi) * sd.cell.Y * delta * 2
+
+	i, j := sd.sprite*sd.spriteLen, (sd.sprite+1)*sd.spriteLen
+	for k := i; k < j; k++ {
+		(*sd.container)[k].Position.Y += dy
+	}
+	if sd.ranged {
+		sd.batch.DirtyRange(i, j)
+	} else {
+		sd.batch.Dirty()
+	}
+	sd.batch.Draw(win)
+}
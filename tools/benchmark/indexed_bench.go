@@ -0,0 +1,79 @@
+package benchmark
+
+import (
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+)
+
+const indexedQuadCells = 64
+
+func init() {
+	Benchmarks.Add(
+		Config{
+			Name:        "quads-batched",
+			Description: "Moving grid of quads of six vertices with batched draw",
+			New:         newQuadsBatched(false),
+			Duration:    30 * time.Second,
+		},
+		Config{
+			Name:        "quads-batched-indexed",
+			Description: "Moving grid of quads of four vertices and six indices with batched draw",
+			New:         newQuadsBatched(true),
+			Duration:    30 * time.Second,
+		},
+	)
+}
+
+func newQuadsBatched(indexed bool) func(win *opengl.Window) (Benchmark, error) {
+	return func(win *opengl.Window) (Benchmark, error) {
+		bounds := win.Bounds()
+		cell := gridCell(bounds.W(), bounds.H(), indexedQuadCells, indexedQuadCells)
+		quad := pixel.R(0, 0, cell.X, cell.Y).Resized(pixel.ZV, cell.Scaled(0.8))
+
+		flat := pixel.MakeTrianglesData(6)
+		for i, corner := range []int{0, 1, 2, 0, 2, 3} {
+			(*flat)[i].Position = quad.Vertices()[corner]
+			(*flat)[i].Color = pixel.RGB(float64(corner%2), 0.5, float64(corner/2))
+		}
+
+		qb := &quadsBatched{cell: cell}
+		if indexed {
+			tri := pixel.IndexTriangles[uint16](flat)
+			qb.batch = pixel.NewBatch(pixel.MakeIndexedTrianglesData[uint16](0, 0), nil)
+			qb.quad = qb.batch.MakeTriangles(tri)
+		} else {
+			qb.batch = pixel.NewBatch(&pixel.TrianglesData{}, nil)
+			qb.quad = qb.batch.MakeTriangles(flat)
+		}
+		return qb, nil
+	}
+}
+
+type quadsBatched struct {
+	batch  *pixel.Batch
+	quad   pixel.TargetTriangles
+	cell   pixel.Vec
+	offset float64
+}
+
+func (qb *quadsBatched) Step(win *opengl.Window, delta float64) {
+	win.Clear(backgroundColor)
+
+	qb.offset += qb.cell.X * delta
+	if qb.offset >= qb.cell.X {
+		qb.offset = 0
+	}
+
+	// the whole Batch is rebuilt and uploaded every frame
+	qb.batch.Clear()
+	for i := 0; i < indexedQuadCells; i++ {
+		for j := 0; j < indexedQuadCells; j++ {
+			pos := pixel.V(float64(i)*qb.cell.X+qb.offset, float64(j)*qb.cell.Y)
+			qb.batch.SetMatrix(pixel.IM.Moved(pos))
+			qb.quad.Draw()
+		}
+	}
+	qb.batch.Draw(win)
+}
@@ -0,0 +1,134 @@
+package benchmark
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/opengl"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/gopxl/pixel/v2/ext/spatial"
+)
+
+const (
+	spatialBoxCount  = 5000
+	spatialBoxSize   = 6
+	spatialNearRange = 20
+)
+
+func init() {
+	Benchmarks.Add(
+		Config{
+			Name:        "spatial-brute-force",
+			Description: "Moving boxes finding their neighbours by checking all other boxes",
+			New:         newSpatialBoxes(nil),
+			Duration:    30 * time.Second,
+		},
+		Config{
+			Name:        "spatial-quadtree",
+			Description: "Moving boxes finding their neighbours with a quadtree",
+			New: newSpatialBoxes(func(bounds pixel.Rect) spatial.Index[int] {
+				return spatial.NewQuadtree[int](bounds)
+			}),
+			Duration: 30 * time.Second,
+		},
+		Config{
+			Name:        "spatial-hash",
+			Description: "Moving boxes finding their neighbours with a spatial hash",
+			New: newSpatialBoxes(func(bounds pixel.Rect) spatial.Index[int] {
+				return spatial.NewHash[int](2 * spatialNearRange)
+			}),
+			Duration: 30 * time.Second,
+		},
+	)
+}
+
+func newSpatialBoxes(newIndex func(bounds pixel.Rect) spatial.Index[int]) func(win *opengl.Window) (Benchmark, error) {
+	return func(win *opengl.Window) (Benchmark, error) {
+		bounds := win.Bounds()
+		rnd := rand.New(rand.NewSource(1))
+		sb := &spatialBoxes{
+			bounds: bounds,
+			boxes:  make([]spatialBox, spatialBoxCount),
+			imd:    imdraw.New(nil),
+		}
+		for i := range sb.boxes {
+			sb.boxes[i] = spatialBox{
+				pos: pixel.V(rnd.Float64()*bounds.W(), rnd.Float64()*bounds.H()).Add(bounds.Min),
+				vel: pixel.V(rnd.Float64()*2-1, rnd.Float64()*2-1).Scaled(100),
+			}
+		}
+		if newIndex != nil {
+			sb.index = newIndex(bounds)
+		}
+		return sb, nil
+	}
+}
+
+type spatialBox struct {
+	pos, vel pixel.Vec
+}
+
+func (b spatialBox) bounds() pixel.Rect {
+	half := pixel.V(spatialBoxSize/2, spatialBoxSize/2)
+	return pixel.Rect{Min: b.pos.Sub(half), Max: b.pos.Add(half)}
+}
+
+type spatialBoxes struct {
+	bounds pixel.Rect
+	boxes  []spatialBox
+	index  spatial.Index[int]
+	imd    *imdraw.IMDraw
+}
+
+func (sb *spatialBoxes) Step(win *opengl.Window, delta float64) {
+	win.Clear(backgroundColor)
+
+	for i := range sb.boxes {
+		b := &sb.boxes[i]
+		b.pos = b.pos.Add(b.vel.Scaled(delta))
+		if b.pos.X < sb.bounds.Min.X || b.pos.X > sb.bounds.Max.X {
+			b.vel.X = -b.vel.X
+		}
+		if b.pos.Y < sb.bounds.Min.Y || b.pos.Y > sb.bounds.Max.Y {
+			b.vel.Y = -b.vel.Y
+		}
+		if sb.index != nil {
+			sb.index.Update(i, b.bounds())
+		}
+	}
+
+	sb.imd.Clear()
+	for i, b := range sb.boxes {
+		if sb.neighbours(i) > 0 {
+			sb.imd.Color = pixel.RGB(1, 0, 0)
+		} else {
+			sb.imd.Color = pixel.RGB(0, 1, 0)
+		}
+		r := b.bounds()
+		sb.imd.Push(r.Min, r.Max)
+		sb.imd.Rectangle(0)
+	}
+	sb.imd.Draw(win)
+}
+
+// neighbours returns the number of other boxes near the i-th box.
+func (sb *spatialBoxes) neighbours(i int) int {
+	near := pixel.C(sb.boxes[i].pos, spatialNearRange)
+	if sb.index != nil {
+		return len(sb.index.QueryCircle(near)) - 1
+	}
+
+	count := 0
+	for j, b := range sb.boxes {
+		r := b.bounds()
+		closest := pixel.V(
+			pixel.Clamp(near.Center.X, r.Min.X, r.Max.X),
+			pixel.Clamp(near.Center.Y, r.Min.Y, r.Max.Y),
+		)
+		if j != i && near.Contains(closest) {
+			count++
+		}
+	}
+	return count
+}
@@ -20,5 +20,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.9 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/image v0.19.0 // indirect
+	golang.org/x/image v0.23.0 // indirect
 )
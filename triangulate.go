@@ -0,0 +1,259 @@
+package pixel
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangulate splits the polygon outline with optional holes into triangles, using ear clipping.
+// The polygons don't need to be convex, but they must be simple (not self-intersecting), the holes
+// must lie inside the outline and must not overlap each other. Both clockwise and counter-clockwise
+// polygons are accepted.
+//
+// The vertices are numbered in the order the outline followed by the holes are passed. Triangulate
+// returns three indices into these vertices for each triangle, each triangle in counter-clockwise
+// order.
+//
+//	outline := pixel.Polygon{pixel.V(0, 0), pixel.V(10, 0), pixel.V(10, 10), pixel.V(0, 10)}
+//	hole := pixel.Polygon{pixel.V(4, 4), pixel.V(6, 4), pixel.V(6, 6), pixel.V(4, 6)}
+//	indices := pixel.Triangulate(outline, hole) // indices 4 to 7 belong to the hole
+func Triangulate(outline Polygon, holes ...Polygon) []int {
+	if len(outline) < 3 {
+		return []int{}
+	}
+
+	var vertices []Vec
+	vertices = append(vertices, outline...)
+
+	ring := make([]int, len(outline))
+	for i := range ring {
+		ring[i] = i
+	}
+	if outline.IsClockwise() {
+		reverseInts(ring)
+	}
+
+	// holes must go the opposite way than the outline to be bridged into it
+	var holeRings [][]int
+	for _, hole := range holes {
+		if len(hole) < 3 {
+			vertices = append(vertices, hole...)
+			continue
+		}
+		r := make([]int, len(hole))
+		for i := range r {
+			r[i] = len(vertices) + i
+		}
+		if !hole.IsClockwise() {
+			reverseInts(r)
+		}
+		vertices = append(vertices, hole...)
+		holeRings = append(holeRings, r)
+	}
+
+	// bridge the holes from the right-most one, so that a bridge never crosses another hole
+	maxX := func(hole []int) float64 {
+		return vertices[hole[rightmost(vertices, hole)]].X
+	}
+	sort.SliceStable(holeRings, func(i, j int) bool {
+		return maxX(holeRings[i]) > maxX(holeRings[j])
+	})
+	for _, hole := range holeRings {
+		ring = bridgeHole(vertices, ring, hole)
+	}
+
+	return clipEars(vertices, ring)
+}
+
+// TrianglesData returns the triangles of the Polygon with optional holes, as triangulated by
+// Triangulate. All the other properties of the vertices are set to default values.
+func (p Polygon) TrianglesData(holes ...Polygon) *TrianglesData {
+	vertices := append(Polygon{}, p...)
+	for _, hole := range holes {
+		vertices = append(vertices, hole...)
+	}
+
+	indices := Triangulate(p, holes...)
+	td := MakeTrianglesData(len(indices))
+	for i, index := range indices {
+		(*td)[i].Position = vertices[index]
+	}
+	return td
+}
+
+// clipEars triangulates a simple counter-clockwise ring of vertex indices. The ring may contain the
+// same vertex multiple times, which happens at the bridges to the holes.
+func clipEars(vertices []Vec, ring []int) []int {
+	indices := make([]int, 0, 3*(len(ring)-2))
+	ring = append([]int(nil), ring...)
+
+	for len(ring) > 3 {
+		n := len(ring)
+		ear := -1
+		for i := 0; i < n; i++ {
+			if isEar(vertices, ring, i) {
+				ear = i
+				break
+			}
+		}
+		if ear < 0 {
+			// no proper ear due to rounding errors or an invalid polygon, clip the most convex vertex
+			// so that the algorithm always finishes
+			ear = mostConvex(vertices, ring)
+		}
+
+		a, b, c := ring[(ear+n-1)%n], ring[ear], ring[(ear+1)%n]
+		if triangleArea(vertices[a], vertices[b], vertices[c]) > 0 {
+			indices = append(indices, a, b, c)
+		}
+		ring = append(ring[:ear], ring[ear+1:]...)
+	}
+
+	if len(ring) == 3 && triangleArea(vertices[ring[0]], vertices[ring[1]], vertices[ring[2]]) > 0 {
+		indices = append(indices, ring...)
+	}
+	return indices
+}
+
+// isEar returns whether the i-th vertex of the ring is convex and the triangle it forms with its
+// neighbours contains no other vertex of the ring.
+func isEar(vertices []Vec, ring []int, i int) bool {
+	n := len(ring)
+	a, b, c := vertices[ring[(i+n-1)%n]], vertices[ring[i]], vertices[ring[(i+1)%n]]
+	if triangleArea(a, b, c) <= 0 {
+		return false
+	}
+	for j, index := range ring {
+		if j == i || j == (i+n-1)%n || j == (i+1)%n {
+			continue
+		}
+		p := vertices[index]
+		if p == a || p == b || p == c {
+			// duplicated vertices of the bridges
+			continue
+		}
+		// only a reflex vertex can be inside of the triangle
+		if prev, next := vertices[ring[(j+n-1)%n]], vertices[ring[(j+1)%n]]; triangleArea(prev, p, next) > 0 {
+			continue
+		}
+		if inTriangle(p, a, b, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func mostConvex(vertices []Vec, ring []int) int {
+	n := len(ring)
+	best, bestArea := 0, math.Inf(-1)
+	for i := range ring {
+		area := triangleArea(vertices[ring[(i+n-1)%n]], vertices[ring[i]], vertices[ring[(i+1)%n]])
+		if area > bestArea {
+			best, bestArea = i, area
+		}
+	}
+	return best
+}
+
+// bridgeHole merges the clockwise hole into the counter-clockwise ring by connecting the right-most
+// vertex of the hole with a visible vertex of the ring.
+func bridgeHole(vertices []Vec, ring, hole []int) []int {
+	hi := rightmost(vertices, hole)
+	m := vertices[hole[hi]]
+
+	// cast a ray from m to the right and find the closest edge of the ring it hits
+	best := -1
+	bestX := math.Inf(+1)
+	for i := range ring {
+		a, b := vertices[ring[i]], vertices[ring[(i+1)%len(ring)]]
+		// the ring is counter-clockwise, so the edges seen from the inside on the right go upwards
+		if a.Y > m.Y || b.Y < m.Y || a.Y == b.Y {
+			continue
+		}
+		x := a.X + (m.Y-a.Y)/(b.Y-a.Y)*(b.X-a.X)
+		if x < m.X || x >= bestX {
+			continue
+		}
+		bestX = x
+		// take the endpoint of the edge further to the right
+		if a.X > b.X {
+			best = i
+		} else {
+			best = (i + 1) % len(ring)
+		}
+		if x == a.X && m.Y == a.Y {
+			best = i
+		} else if x == b.X && m.Y == b.Y {
+			best = (i + 1) % len(ring)
+		}
+	}
+	if best < 0 {
+		// the hole is not inside the outline, connect it to the closest vertex anyway
+		best = closestIndex(vertices, ring, m)
+	} else if p := vertices[ring[best]]; p.Y != m.Y || p.X != bestX {
+		// the intersection is not a vertex, some reflex vertices of the ring may block the view of
+		// p from m, in that case pick the one with the smallest angle to the ray
+		in := V(bestX, m.Y)
+		bestAngle, bestDist := math.Inf(+1), math.Inf(+1)
+		for i, index := range ring {
+			v := vertices[index]
+			if v == p || !inTriangle(v, m, in, p) && !inTriangle(v, m, p, in) {
+				continue
+			}
+			prev, next := vertices[ring[(i+len(ring)-1)%len(ring)]], vertices[ring[(i+1)%len(ring)]]
+			if triangleArea(prev, v, next) > 0 {
+				continue
+			}
+			d := m.To(v)
+			angle := math.Abs(math.Atan2(d.Y, d.X))
+			if angle < bestAngle || angle == bestAngle && d.SqLen() < bestDist {
+				best, bestAngle, bestDist = i, angle, d.SqLen()
+			}
+		}
+	}
+
+	// ring[..best], hole[hi..], hole[..hi], hole[hi], ring[best], ring[best+1..]
+	merged := make([]int, 0, len(ring)+len(hole)+2)
+	merged = append(merged, ring[:best+1]...)
+	merged = append(merged, hole[hi:]...)
+	merged = append(merged, hole[:hi+1]...)
+	merged = append(merged, ring[best:]...)
+	return merged
+}
+
+func rightmost(vertices []Vec, ring []int) int {
+	best := 0
+	for i, index := range ring {
+		v, b := vertices[index], vertices[ring[best]]
+		if v.X > b.X || v.X == b.X && v.Y < b.Y {
+			best = i
+		}
+	}
+	return best
+}
+
+func closestIndex(vertices []Vec, ring []int, u Vec) int {
+	best := 0
+	for i, index := range ring {
+		if u.To(vertices[index]).SqLen() < u.To(vertices[ring[best]]).SqLen() {
+			best = i
+		}
+	}
+	return best
+}
+
+// triangleArea returns the doubled signed area of the triangle, positive if counter-clockwise.
+func triangleArea(a, b, c Vec) float64 {
+	return a.To(b).Cross(a.To(c))
+}
+
+// inTriangle returns whether p lies inside of the counter-clockwise triangle or on its edges.
+func inTriangle(p, a, b, c Vec) bool {
+	return triangleArea(a, b, p) >= 0 && triangleArea(b, c, p) >= 0 && triangleArea(c, a, p) >= 0
+}
+
+func reverseInts(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -457,6 +457,14 @@ func (l Line) ScaledXY(around Vec, scale float64) Line {
 	}
 }
 
+// Transformed returns the line with both end points projected by the Matrix.
+func (l Line) Transformed(m Matrix) Line {
+	return Line{
+		A: m.Project(l.A),
+		B: m.Project(l.B),
+	}
+}
+
 func (l Line) String() string {
 	return fmt.Sprintf("Line(%v, %v)", l.A, l.B)
 }